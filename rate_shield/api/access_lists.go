@@ -0,0 +1,75 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/x-sushant-x/RateShield/models"
+	"github.com/x-sushant-x/RateShield/service"
+	"github.com/x-sushant-x/RateShield/utils"
+)
+
+type AccessListAPIHandler struct {
+	accessListSvc service.AccessListService
+}
+
+func NewAccessListAPIHandler(svc service.AccessListService) AccessListAPIHandler {
+	return AccessListAPIHandler{
+		accessListSvc: svc,
+	}
+}
+
+// GetAccessLists handles GET /access-lists
+func (h AccessListAPIHandler) GetAccessLists(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.MethodNotAllowedError(w)
+		return
+	}
+
+	lists, err := h.accessListSvc.GetAccessLists()
+	if err != nil {
+		utils.InternalError(w, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(lists, w)
+}
+
+// AddEntries handles POST /access-lists/add
+// Body: {"list": "allow", "entries": ["10.0.0.0/8", "2001:db8::1"]}
+func (h AccessListAPIHandler) AddEntries(w http.ResponseWriter, r *http.Request) {
+	h.changeEntries(w, r, h.accessListSvc.AddEntries)
+}
+
+// RemoveEntries handles POST /access-lists/remove
+// Body: {"list": "deny", "entries": ["192.0.2.0/24"]}
+func (h AccessListAPIHandler) RemoveEntries(w http.ResponseWriter, r *http.Request) {
+	h.changeEntries(w, r, h.accessListSvc.RemoveEntries)
+}
+
+func (h AccessListAPIHandler) changeEntries(w http.ResponseWriter, r *http.Request, change func(list string, entries []string, actor, ipAddress, userAgent string) ([]string, error)) {
+	if r.Method != http.MethodPost {
+		utils.MethodNotAllowedError(w)
+		return
+	}
+
+	req, err := utils.ParseAPIBody[models.AccessListEntriesDTO](r)
+	if err != nil {
+		utils.BadRequestError(w)
+		return
+	}
+
+	changed, err := change(req.List, req.Entries, extractActorInfo(r), extractIPAddress(r), r.UserAgent())
+	if err != nil {
+		if errors.Is(err, service.ErrUnknownAccessList) ||
+			errors.Is(err, service.ErrNoAccessListEntries) ||
+			errors.Is(err, utils.ErrInvalidIPEntry) {
+			utils.InvalidRequestError(w, err.Error())
+			return
+		}
+		utils.InternalError(w, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(models.AccessListEntriesDTO{List: req.List, Entries: changed}, w)
+}
@@ -0,0 +1,96 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/x-sushant-x/RateShield/models"
+	"github.com/x-sushant-x/RateShield/service"
+	"github.com/x-sushant-x/RateShield/utils"
+)
+
+type AlertAPIHandler struct {
+	alertRuleSvc service.AlertRuleService
+}
+
+func NewAlertAPIHandler(svc service.AlertRuleService) AlertAPIHandler {
+	return AlertAPIHandler{
+		alertRuleSvc: svc,
+	}
+}
+
+// ListAlertRules handles GET /alerts/rules
+func (h AlertAPIHandler) ListAlertRules(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.MethodNotAllowedError(w)
+		return
+	}
+
+	alerts, err := h.alertRuleSvc.ListAlertRules()
+	if err != nil {
+		utils.InternalError(w, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(alerts, w)
+}
+
+// SaveAlertRule handles POST /alerts/rules/save, an alert rule with the same name is replaced
+// Body: {"name": "search-denials", "type": "DENY_RATE", "endpoint": "/api/v1/search", "threshold": 50, "window": 300}
+func (h AlertAPIHandler) SaveAlertRule(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		utils.MethodNotAllowedError(w)
+		return
+	}
+
+	req, err := utils.ParseAPIBody[models.AlertRule](r)
+	if err != nil {
+		utils.BadRequestError(w)
+		return
+	}
+
+	alert, err := h.alertRuleSvc.SaveAlertRule(req, extractActorInfo(r), extractIPAddress(r), r.UserAgent())
+	if err != nil {
+		var validationErr *utils.RuleValidationError
+		if errors.As(err, &validationErr) {
+			utils.ValidationErrorResponse(w, validationErr)
+			return
+		}
+		utils.InternalError(w, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(alert, w)
+}
+
+// DeleteAlertRule handles POST /alerts/rules/delete
+// Body: {"name": "search-denials"}
+func (h AlertAPIHandler) DeleteAlertRule(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		utils.MethodNotAllowedError(w)
+		return
+	}
+
+	req, err := utils.ParseAPIBody[models.AlertRuleNameDTO](r)
+	if err != nil {
+		utils.BadRequestError(w)
+		return
+	}
+
+	found, err := h.alertRuleSvc.DeleteAlertRule(req.Name, extractActorInfo(r), extractIPAddress(r), r.UserAgent())
+	if err != nil {
+		if errors.Is(err, service.ErrNoAlertRuleName) {
+			utils.InvalidRequestError(w, err.Error())
+			return
+		}
+		utils.InternalError(w, err.Error())
+		return
+	}
+
+	if !found {
+		utils.NotFoundError(w, "alert rule not found")
+		return
+	}
+
+	utils.SuccessResponse(req, w)
+}
@@ -0,0 +1,113 @@
+package api
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+const (
+	grpcHealthCheckInterval = 5 * time.Second
+	grpcHealthCheckTimeout  = 2 * time.Second
+)
+
+// HealthCheck returns an error while a dependency of RateShield, such as Redis, is unavailable
+type HealthCheck func(ctx context.Context) error
+
+// grpcHealthReporter runs the health checks in the background and reports the result through the standard
+// grpc.health.v1 service, for the server as a whole ("") and for every registered service
+type grpcHealthReporter struct {
+	server   *health.Server
+	services []string
+	checks   map[string]HealthCheck
+	status   healthpb.HealthCheckResponse_ServingStatus // Last reported status, only used by the checks
+
+	stop     chan struct{}
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+}
+
+func newGRPCHealthReporter(grpcServer *grpc.Server, checks map[string]HealthCheck) *grpcHealthReporter {
+	services := []string{""}
+	for name := range grpcServer.GetServiceInfo() {
+		services = append(services, name)
+	}
+	sort.Strings(services)
+
+	r := &grpcHealthReporter{
+		server:   health.NewServer(),
+		services: services,
+		checks:   checks,
+		stop:     make(chan struct{}),
+	}
+
+	// The status is unknown until the checks ran for the first time
+	r.setStatus(healthpb.HealthCheckResponse_UNKNOWN)
+	return r
+}
+
+func (r *grpcHealthReporter) start() {
+	r.check()
+
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+
+		ticker := time.NewTicker(grpcHealthCheckInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				r.check()
+			case <-r.stop:
+				return
+			}
+		}
+	}()
+}
+
+// shutdown reports every service as not serving for good, so load balancers stop sending calls
+func (r *grpcHealthReporter) shutdown() {
+	r.stopOnce.Do(func() {
+		close(r.stop)
+	})
+	r.wg.Wait()
+	r.server.Shutdown()
+}
+
+// check runs every health check and updates the status of the services
+func (r *grpcHealthReporter) check() {
+	ctx, cancel := context.WithTimeout(context.Background(), grpcHealthCheckTimeout)
+	defer cancel()
+
+	status := healthpb.HealthCheckResponse_SERVING
+	for name, check := range r.checks {
+		if err := check(ctx); err != nil {
+			// Only log when the server stops serving, not on every check while a dependency is down
+			if r.status != healthpb.HealthCheckResponse_NOT_SERVING {
+				log.Warn().Err(err).Str("check", name).Msg("health check failed, gRPC server reported as not serving")
+			}
+			status = healthpb.HealthCheckResponse_NOT_SERVING
+		}
+	}
+
+	if status == healthpb.HealthCheckResponse_SERVING && r.status == healthpb.HealthCheckResponse_NOT_SERVING {
+		log.Info().Msg("health checks passed, gRPC server reported as serving ✅")
+	}
+
+	r.setStatus(status)
+}
+
+func (r *grpcHealthReporter) setStatus(status healthpb.HealthCheckResponse_ServingStatus) {
+	r.status = status
+	for _, service := range r.services {
+		r.server.SetServingStatus(service, status)
+	}
+}
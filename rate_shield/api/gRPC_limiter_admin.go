@@ -0,0 +1,229 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+	"github.com/x-sushant-x/RateShield/limiter"
+	"github.com/x-sushant-x/RateShield/models"
+	"github.com/x-sushant-x/RateShield/proto/github.com/x-sushant-x/RateShield/ratelimitpb"
+	redisClient "github.com/x-sushant-x/RateShield/redis"
+	"github.com/x-sushant-x/RateShield/service"
+	"github.com/x-sushant-x/RateShield/utils"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+type limiterAdminGRPCService struct {
+	ratelimitpb.UnimplementedLimiterAdminServiceServer
+	limiterSvc *limiter.Limiter
+	auditSvc   service.AuditService
+}
+
+func newLimiterAdminGRPCService(limiterSvc *limiter.Limiter, auditSvc service.AuditService) *limiterAdminGRPCService {
+	return &limiterAdminGRPCService{
+		limiterSvc: limiterSvc,
+		auditSvc:   auditSvc,
+	}
+}
+
+func (s *limiterAdminGRPCService) GetLimiterState(ctx context.Context, req *ratelimitpb.LimiterStateRequest) (*ratelimitpb.LimiterStateResponse, error) {
+	if err := utils.ValidateLimitRequest(req.GetIp(), req.GetEndpoint()); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	state, err := s.limiterSvc.GetClientState(req.GetIp(), req.GetEndpoint())
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return toLimiterStateResponse(state), nil
+}
+
+func (s *limiterAdminGRPCService) ResetLimiterState(ctx context.Context, req *ratelimitpb.LimiterStateRequest) (*ratelimitpb.ResetLimiterStateResponse, error) {
+	if err := utils.ValidateLimitRequest(req.GetIp(), req.GetEndpoint()); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	if err := s.limiterSvc.ResetClientState(req.GetIp(), req.GetEndpoint()); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	s.logLimiterAction(ctx, models.AuditActionResetLimiter, req.GetEndpoint(), req.GetIp(), "limiter state reset")
+
+	return &ratelimitpb.ResetLimiterStateResponse{Success: true}, nil
+}
+
+func (s *limiterAdminGRPCService) GrantExtraQuota(ctx context.Context, req *ratelimitpb.GrantExtraQuotaRequest) (*ratelimitpb.LimiterStateResponse, error) {
+	if err := utils.ValidateLimitRequest(req.GetIp(), req.GetEndpoint()); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	state, err := s.limiterSvc.GrantExtraQuota(req.GetIp(), req.GetEndpoint(), req.GetExtraRequests())
+	if err != nil {
+		if errors.Is(err, limiter.ErrInvalidExtraQuota) ||
+			errors.Is(err, limiter.ErrNoRuleForEndpoint) ||
+			errors.Is(err, limiter.ErrStrategyNotSupportGrant) {
+			return nil, status.Error(codes.FailedPrecondition, err.Error())
+		}
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	details := fmt.Sprintf("granted %d extra requests", req.GetExtraRequests())
+	s.logLimiterAction(ctx, models.AuditActionGrantQuota, req.GetEndpoint(), req.GetIp(), details)
+
+	return toLimiterStateResponse(state), nil
+}
+
+func (s *limiterAdminGRPCService) ListLimiterKeys(ctx context.Context, req *ratelimitpb.ListLimiterKeysRequest) (*ratelimitpb.ListLimiterKeysResponse, error) {
+	if len(req.GetEndpoint()) == 0 {
+		return nil, status.Error(codes.InvalidArgument, utils.ErrorInvalidEndpoint.Error())
+	}
+
+	count := req.GetCount()
+	if count <= 0 {
+		count = defaultLimiterKeysPageSize
+	}
+	if count > maxLimiterKeysPageSize {
+		count = maxLimiterKeysPageSize
+	}
+
+	page, err := s.limiterSvc.ListClientStates(req.GetEndpoint(), req.GetStrategy(), req.GetCursor(), count)
+	if err != nil {
+		if errors.Is(err, limiter.ErrNoRuleForEndpoint) ||
+			errors.Is(err, limiter.ErrUnknownStrategy) ||
+			errors.Is(err, redisClient.ErrInvalidScanCursor) {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	resp := &ratelimitpb.ListLimiterKeysResponse{
+		Endpoint:   page.Endpoint,
+		Strategy:   page.Strategy,
+		NextCursor: page.NextCursor,
+		Entries:    make([]*ratelimitpb.LimiterKeyEntry, 0, len(page.Entries)),
+	}
+
+	for _, entry := range page.Entries {
+		resp.Entries = append(resp.Entries, &ratelimitpb.LimiterKeyEntry{
+			Key:       entry.Key,
+			Ip:        entry.ClientIP,
+			Used:      entry.Used,
+			Limit:     entry.Limit,
+			Remaining: entry.Remaining,
+		})
+	}
+
+	return resp, nil
+}
+
+func (s *limiterAdminGRPCService) logLimiterAction(ctx context.Context, action, endpoint, clientIP, details string) {
+	if s.auditSvc == nil {
+		return
+	}
+
+	err := s.auditSvc.LogLimiterAction(extractGRPCActorInfo(ctx), action, endpoint, clientIP, details, extractGRPCPeerAddress(ctx), extractGRPCUserAgent(ctx))
+	if err != nil {
+		// Don't fail the operation if audit logging fails
+		log.Warn().Err(err).Msg("failed to log audit event for limiter action")
+	}
+}
+
+func toLimiterStateResponse(state *models.LimiterState) *ratelimitpb.LimiterStateResponse {
+	resp := &ratelimitpb.LimiterStateResponse{
+		Ip:       state.ClientIP,
+		Endpoint: state.Endpoint,
+		Strategy: state.Strategy,
+	}
+
+	if state.TokenBucket != nil {
+		resp.TokenBucket = &ratelimitpb.TokenBucketState{
+			Capacity:        int64(state.TokenBucket.Capacity),
+			AvailableTokens: int64(state.TokenBucket.AvailableTokens),
+			TokenAddRate:    int64(state.TokenBucket.TokenAddRate),
+			LastRefill:      state.TokenBucket.LastRefill.Unix(),
+			RetentionTime:   int32(state.TokenBucket.RetentionTime),
+		}
+	}
+
+	if state.FixedWindow != nil {
+		resp.FixedWindow = &ratelimitpb.FixedWindowState{
+			MaxRequests:     state.FixedWindow.MaxRequests,
+			CurrentRequests: state.FixedWindow.CurrRequests,
+			Window:          int32(state.FixedWindow.Window),
+			CreatedAt:       state.FixedWindow.CreatedAt,
+			LastAccessTime:  state.FixedWindow.LastAccessTime,
+		}
+	}
+
+	if state.SlidingWindow != nil {
+		resp.SlidingWindow = &ratelimitpb.SlidingWindowState{
+			ActiveRequests: state.SlidingWindow.ActiveRequests,
+			MaxRequests:    state.SlidingWindow.MaxRequests,
+			Window:         int32(state.SlidingWindow.WindowSize),
+		}
+	}
+
+	return resp
+}
+
+// extractGRPCActorInfo mirrors extractActorInfo for gRPC metadata
+// Priority: actor of the admin token > x-user-id > authorization (parsed) > "anonymous"
+func extractGRPCActorInfo(ctx context.Context) string {
+	if actor, ok := ctx.Value(grpcActorKey{}).(string); ok {
+		return actor
+	}
+
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return "anonymous"
+	}
+
+	if userID := md.Get("x-user-id"); len(userID) > 0 && userID[0] != "" {
+		return userID[0]
+	}
+
+	if auth := md.Get("authorization"); len(auth) > 0 && auth[0] != "" {
+		if strings.HasPrefix(auth[0], "Bearer ") {
+			token := strings.TrimPrefix(auth[0], "Bearer ")
+			if len(token) > 8 {
+				return "token:" + token[:8] + "..."
+			}
+			return "token:" + token
+		}
+		return "auth:provided"
+	}
+
+	return "anonymous"
+}
+
+func extractGRPCPeerAddress(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+
+	ip := p.Addr.String()
+	if idx := strings.LastIndex(ip, ":"); idx != -1 {
+		ip = ip[:idx]
+	}
+	return ip
+}
+
+func extractGRPCUserAgent(ctx context.Context) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+
+	if ua := md.Get("user-agent"); len(ua) > 0 {
+		return ua[0]
+	}
+	return ""
+}
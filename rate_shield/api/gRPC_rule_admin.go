@@ -0,0 +1,285 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sort"
+
+	"github.com/x-sushant-x/RateShield/models"
+	"github.com/x-sushant-x/RateShield/proto/github.com/x-sushant-x/RateShield/ratelimitpb"
+	"github.com/x-sushant-x/RateShield/service"
+	"github.com/x-sushant-x/RateShield/utils"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type ruleAdminGRPCService struct {
+	ratelimitpb.UnimplementedRuleAdminServiceServer
+	rulesSvc service.RulesService
+	auditSvc service.AuditService
+}
+
+func newRuleAdminGRPCService(rulesSvc service.RulesService, auditSvc service.AuditService) *ruleAdminGRPCService {
+	return &ruleAdminGRPCService{
+		rulesSvc: rulesSvc,
+		auditSvc: auditSvc,
+	}
+}
+
+// ListRules returns the rules sorted by endpoint, a page of them if page and items are set
+func (s *ruleAdminGRPCService) ListRules(ctx context.Context, req *ratelimitpb.ListRulesRequest) (*ratelimitpb.ListRulesResponse, error) {
+	if req.GetPage() < 0 || req.GetItems() < 0 {
+		return nil, status.Error(codes.InvalidArgument, "page and items must not be negative")
+	}
+
+	rules, err := s.rulesSvc.GetAllRules()
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return toListRulesResponse(rules, int(req.GetPage()), int(req.GetItems()))
+}
+
+func (s *ruleAdminGRPCService) SearchRules(ctx context.Context, req *ratelimitpb.SearchRulesRequest) (*ratelimitpb.ListRulesResponse, error) {
+	if len(req.GetEndpoint()) == 0 {
+		return nil, status.Error(codes.InvalidArgument, utils.ErrorInvalidEndpoint.Error())
+	}
+
+	rules, err := s.rulesSvc.SearchRule(req.GetEndpoint())
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return toListRulesResponse(rules, 0, 0)
+}
+
+func (s *ruleAdminGRPCService) GetRule(ctx context.Context, req *ratelimitpb.GetRuleRequest) (*ratelimitpb.RuleResponse, error) {
+	if len(req.GetEndpoint()) == 0 {
+		return nil, status.Error(codes.InvalidArgument, utils.ErrorInvalidEndpoint.Error())
+	}
+
+	rule, found, err := s.rulesSvc.GetRule(req.GetEndpoint())
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	if !found {
+		return nil, status.Error(codes.NotFound, "rule not found")
+	}
+
+	pbRule, err := toPBRule(*rule)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &ratelimitpb.RuleResponse{Rule: pbRule}, nil
+}
+
+// UpsertRule saves a rule. With expected_version it is only saved if the stored rule still has that
+// version, 0 if it must not exist yet, like If-Match and If-None-Match on the HTTP API.
+func (s *ruleAdminGRPCService) UpsertRule(ctx context.Context, req *ratelimitpb.UpsertRuleRequest) (*ratelimitpb.RuleResponse, error) {
+	var rule models.Rule
+	if err := json.Unmarshal(req.GetRuleJson(), &rule); err != nil {
+		return nil, status.Error(codes.InvalidArgument, "rule_json must be a rule as JSON: "+err.Error())
+	}
+
+	expectedVersion := service.AnyRuleVersion
+	if req.ExpectedVersion != nil {
+		if req.GetExpectedVersion() < 0 {
+			return nil, status.Error(codes.InvalidArgument, "expected_version must not be negative")
+		}
+		expectedVersion = req.GetExpectedVersion()
+	}
+
+	savedRule, err := s.rulesSvc.CreateOrUpdateRuleIfMatch(rule, expectedVersion, extractGRPCActorInfo(ctx), extractGRPCPeerAddress(ctx), extractGRPCUserAgent(ctx))
+	if err != nil {
+		return nil, ruleErrorStatus(err)
+	}
+
+	pbRule, err := toPBRule(*savedRule)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &ratelimitpb.RuleResponse{Rule: pbRule}, nil
+}
+
+func (s *ruleAdminGRPCService) DeleteRule(ctx context.Context, req *ratelimitpb.DeleteRuleRequest) (*ratelimitpb.DeleteRuleResponse, error) {
+	if len(req.GetEndpoint()) == 0 {
+		return nil, status.Error(codes.InvalidArgument, utils.ErrorInvalidEndpoint.Error())
+	}
+
+	err := s.rulesSvc.DeleteRule(req.GetEndpoint(), extractGRPCActorInfo(ctx), extractGRPCPeerAddress(ctx), extractGRPCUserAgent(ctx))
+	if err != nil {
+		return nil, ruleErrorStatus(err)
+	}
+
+	return &ratelimitpb.DeleteRuleResponse{Success: true}, nil
+}
+
+// ListAuditLogs returns the audit logs matching every given filter, newest first
+func (s *ruleAdminGRPCService) ListAuditLogs(ctx context.Context, req *ratelimitpb.ListAuditLogsRequest) (*ratelimitpb.ListAuditLogsResponse, error) {
+	if req.GetPage() < 0 || req.GetItems() < 0 {
+		return nil, status.Error(codes.InvalidArgument, "page and items must not be negative")
+	}
+
+	var logs []models.AuditLog
+	var err error
+
+	switch {
+	case req.GetEndpoint() != "":
+		logs, err = s.auditSvc.GetAuditLogsByEndpoint(req.GetEndpoint())
+	case req.GetActor() != "":
+		logs, err = s.auditSvc.GetAuditLogsByActor(req.GetActor())
+	case req.GetAction() != "":
+		logs, err = s.auditSvc.GetAuditLogsByAction(req.GetAction())
+	default:
+		logs, err = s.auditSvc.GetAllAuditLogs()
+	}
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	filtered := make([]models.AuditLog, 0, len(logs))
+	for _, auditLog := range logs {
+		if (req.GetEndpoint() == "" || auditLog.Endpoint == req.GetEndpoint()) &&
+			(req.GetActor() == "" || auditLog.Actor == req.GetActor()) &&
+			(req.GetAction() == "" || auditLog.Action == req.GetAction()) {
+			filtered = append(filtered, auditLog)
+		}
+	}
+
+	sort.SliceStable(filtered, func(i, j int) bool {
+		return filtered[i].Timestamp > filtered[j].Timestamp
+	})
+
+	start, stop, page, hasNextPage := pageBounds(len(filtered), int(req.GetPage()), int(req.GetItems()))
+
+	resp := &ratelimitpb.ListAuditLogsResponse{
+		Logs:        make([]*ratelimitpb.AuditLog, 0, stop-start),
+		Page:        int32(page),
+		TotalItems:  int32(len(filtered)),
+		HasNextPage: hasNextPage,
+	}
+
+	for _, auditLog := range filtered[start:stop] {
+		pbLog, err := toPBAuditLog(auditLog)
+		if err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+		resp.Logs = append(resp.Logs, pbLog)
+	}
+
+	return resp, nil
+}
+
+// ruleErrorStatus maps errors of the rules service to gRPC status codes
+func ruleErrorStatus(err error) error {
+	var validationErr *utils.RuleValidationError
+	if errors.As(err, &validationErr) {
+		badRequest := &errdetails.BadRequest{}
+		for _, fieldErr := range validationErr.Errors {
+			badRequest.FieldViolations = append(badRequest.FieldViolations, &errdetails.BadRequest_FieldViolation{
+				Field:       fieldErr.Field,
+				Description: fieldErr.Message,
+			})
+		}
+
+		st, detailsErr := status.New(codes.InvalidArgument, validationErr.Error()).WithDetails(badRequest)
+		if detailsErr != nil {
+			return status.Error(codes.InvalidArgument, validationErr.Error())
+		}
+		return st.Err()
+	}
+
+	switch {
+	case errors.Is(err, service.ErrRuleVersionConflict):
+		return status.Error(codes.Aborted, err.Error())
+	case errors.Is(err, service.ErrRuleReadOnly):
+		return status.Error(codes.PermissionDenied, err.Error())
+	}
+
+	return status.Error(codes.Internal, err.Error())
+}
+
+func toListRulesResponse(rules []models.Rule, page, items int) (*ratelimitpb.ListRulesResponse, error) {
+	sort.Slice(rules, func(i, j int) bool {
+		return rules[i].APIEndpoint < rules[j].APIEndpoint
+	})
+
+	start, stop, page, hasNextPage := pageBounds(len(rules), page, items)
+
+	resp := &ratelimitpb.ListRulesResponse{
+		Rules:       make([]*ratelimitpb.Rule, 0, stop-start),
+		Page:        int32(page),
+		TotalItems:  int32(len(rules)),
+		HasNextPage: hasNextPage,
+	}
+
+	for _, rule := range rules[start:stop] {
+		pbRule, err := toPBRule(rule)
+		if err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+		resp.Rules = append(resp.Rules, pbRule)
+	}
+
+	return resp, nil
+}
+
+// pageBounds returns the slice bounds of a page of total items. Every item is on page 1 when page or items
+// is 0, pages after the last one are empty.
+func pageBounds(total, page, items int) (int, int, int, bool) {
+	if page == 0 || items == 0 {
+		return 0, total, 1, false
+	}
+
+	start := min((page-1)*items, total)
+	stop := min(start+items, total)
+
+	return start, stop, page, stop < total
+}
+
+func toPBRule(rule models.Rule) (*ratelimitpb.Rule, error) {
+	ruleJSON, err := json.Marshal(rule)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ratelimitpb.Rule{
+		Endpoint: rule.APIEndpoint,
+		Version:  rule.Version,
+		Json:     ruleJSON,
+	}, nil
+}
+
+func toPBAuditLog(auditLog models.AuditLog) (*ratelimitpb.AuditLog, error) {
+	pbLog := &ratelimitpb.AuditLog{
+		Id:        auditLog.ID,
+		Timestamp: auditLog.Timestamp,
+		Actor:     auditLog.Actor,
+		Action:    auditLog.Action,
+		Endpoint:  auditLog.Endpoint,
+		ClientIp:  auditLog.ClientIP,
+		Details:   auditLog.Details,
+		IpAddress: auditLog.IPAddress,
+		UserAgent: auditLog.UserAgent,
+	}
+
+	var err error
+	if auditLog.OldRule != nil {
+		if pbLog.OldRuleJson, err = json.Marshal(auditLog.OldRule); err != nil {
+			return nil, err
+		}
+	}
+
+	if auditLog.NewRule != nil {
+		if pbLog.NewRuleJson, err = json.Marshal(auditLog.NewRule); err != nil {
+			return nil, err
+		}
+	}
+
+	return pbLog, nil
+}
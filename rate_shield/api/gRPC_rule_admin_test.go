@@ -0,0 +1,228 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/x-sushant-x/RateShield/models"
+	"github.com/x-sushant-x/RateShield/proto/github.com/x-sushant-x/RateShield/ratelimitpb"
+	"github.com/x-sushant-x/RateShield/service"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// newTestGRPCConn serves the rule admin and rate limit services behind the admin token check over an
+// in-memory listener
+func newTestGRPCConn(tb testing.TB, rulesSvc service.RulesService, tokens map[string]string) *grpc.ClientConn {
+	tb.Helper()
+
+	auth := newGRPCAdminAuth(tokens)
+
+	lis := bufconn.Listen(1 << 20)
+	server := grpc.NewServer(grpc.ChainUnaryInterceptor(auth.unaryInterceptor), grpc.ChainStreamInterceptor(auth.streamInterceptor))
+	ratelimitpb.RegisterRuleAdminServiceServer(server, newRuleAdminGRPCService(rulesSvc, nil))
+	ratelimitpb.RegisterRateLimitServiceServer(server, newgRPCService(&fakeChecker{}, 8))
+	go server.Serve(lis)
+	tb.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(tb, err)
+	tb.Cleanup(func() { conn.Close() })
+
+	return conn
+}
+
+// Admin calls of tests that are not about authentication use this token
+var (
+	testAdminTokens = map[string]string{"secret-1": "alice"}
+	testAdminToken  = "Bearer secret-1"
+)
+
+func withToken(token string) context.Context {
+	return metadata.AppendToOutgoingContext(context.Background(), "authorization", token)
+}
+
+func upsertRequest(t *testing.T, rule models.Rule, expectedVersion *int64) *ratelimitpb.UpsertRuleRequest {
+	ruleJSON, err := json.Marshal(rule)
+	require.NoError(t, err)
+
+	return &ratelimitpb.UpsertRuleRequest{RuleJson: ruleJSON, ExpectedVersion: expectedVersion}
+}
+
+func TestGRPCAdminAuth(t *testing.T) {
+	conn := newTestGRPCConn(t, newFakeRulesService(testRule("/api/v1/search", 1)), map[string]string{"secret-1": "alice", "secret-2": "bob"})
+	admin := ratelimitpb.NewRuleAdminServiceClient(conn)
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		code codes.Code
+	}{
+		{"no token", context.Background(), codes.Unauthenticated},
+		{"wrong token", withToken("Bearer secret-3"), codes.Unauthenticated},
+		{"token without bearer", withToken("secret-1"), codes.Unauthenticated},
+		{"valid token", withToken("Bearer secret-2"), codes.OK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := admin.ListRules(tt.ctx, &ratelimitpb.ListRulesRequest{})
+			assert.Equal(t, tt.code, status.Code(err))
+		})
+	}
+
+	t.Run("rate limit checks stay open", func(t *testing.T) {
+		resp, err := ratelimitpb.NewRateLimitServiceClient(conn).CheckRateLimit(context.Background(),
+			&ratelimitpb.RateLimitRequest{Ip: "10.0.0.1", Endpoint: "/api/v1/search"})
+		require.NoError(t, err)
+		assert.Equal(t, int32(200), resp.GetHttpStatusCode())
+	})
+
+	t.Run("closed without tokens", func(t *testing.T) {
+		admin := ratelimitpb.NewRuleAdminServiceClient(newTestGRPCConn(t, newFakeRulesService(), nil))
+
+		_, err := admin.ListRules(context.Background(), &ratelimitpb.ListRulesRequest{})
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+		_, err = admin.ListRules(withToken("Bearer "), &ratelimitpb.ListRulesRequest{})
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+}
+
+func TestRuleAdminActor(t *testing.T) {
+	rulesSvc := newFakeRulesService()
+	admin := ratelimitpb.NewRuleAdminServiceClient(newTestGRPCConn(t, rulesSvc, testAdminTokens))
+	ctx := withToken(testAdminToken)
+
+	_, err := admin.UpsertRule(ctx, upsertRequest(t, testRule("/api/v1/search", 0), nil))
+	require.NoError(t, err)
+
+	_, err = admin.DeleteRule(ctx, &ratelimitpb.DeleteRuleRequest{Endpoint: "/api/v1/search"})
+	require.NoError(t, err)
+
+	// Changes are recorded under the name of the token, not the token itself
+	assert.Equal(t, []string{"alice", "alice"}, rulesSvc.actors)
+}
+
+func TestUpsertRuleErrors(t *testing.T) {
+	endpoint := "/api/v1/search"
+	version := func(v int64) *int64 { return &v }
+	ctx := withToken(testAdminToken)
+
+	t.Run("expected version", func(t *testing.T) {
+		admin := ratelimitpb.NewRuleAdminServiceClient(newTestGRPCConn(t, newFakeRulesService(testRule(endpoint, 3)), testAdminTokens))
+
+		_, err := admin.UpsertRule(ctx, upsertRequest(t, testRule(endpoint, 0), version(2)))
+		assert.Equal(t, codes.Aborted, status.Code(err), "stale version")
+
+		_, err = admin.UpsertRule(ctx, upsertRequest(t, testRule(endpoint, 0), version(0)))
+		assert.Equal(t, codes.Aborted, status.Code(err), "rule must not exist")
+
+		_, err = admin.UpsertRule(ctx, upsertRequest(t, testRule(endpoint, 0), version(-1)))
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+		resp, err := admin.UpsertRule(ctx, upsertRequest(t, testRule(endpoint, 0), version(3)))
+		require.NoError(t, err)
+		assert.Equal(t, int64(4), resp.GetRule().GetVersion())
+	})
+
+	t.Run("field violations", func(t *testing.T) {
+		admin := ratelimitpb.NewRuleAdminServiceClient(newTestGRPCConn(t, newFakeRulesService(), testAdminTokens))
+
+		rule := testRule("/api/v1/ search", 0)
+		rule.HTTPMethod = "FETCH"
+
+		_, err := admin.UpsertRule(ctx, upsertRequest(t, rule, nil))
+		st := status.Convert(err)
+		require.Equal(t, codes.InvalidArgument, st.Code())
+
+		fields := []string{}
+		for _, detail := range st.Details() {
+			if badRequest, ok := detail.(*errdetails.BadRequest); ok {
+				for _, violation := range badRequest.GetFieldViolations() {
+					assert.NotEmpty(t, violation.GetDescription())
+					fields = append(fields, violation.GetField())
+				}
+			}
+		}
+		assert.ElementsMatch(t, []string{"endpoint", "http_method"}, fields)
+	})
+
+	t.Run("malformed rule", func(t *testing.T) {
+		admin := ratelimitpb.NewRuleAdminServiceClient(newTestGRPCConn(t, newFakeRulesService(), testAdminTokens))
+
+		_, err := admin.UpsertRule(ctx, &ratelimitpb.UpsertRuleRequest{RuleJson: []byte("{")})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+
+	t.Run("read only rule", func(t *testing.T) {
+		readOnly := testRule(endpoint, 1)
+		readOnly.ReadOnly = true
+		admin := ratelimitpb.NewRuleAdminServiceClient(newTestGRPCConn(t, newFakeRulesService(readOnly), testAdminTokens))
+
+		_, err := admin.UpsertRule(ctx, upsertRequest(t, testRule(endpoint, 0), nil))
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+		_, err = admin.DeleteRule(ctx, &ratelimitpb.DeleteRuleRequest{Endpoint: endpoint})
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	})
+}
+
+func TestListRulesPages(t *testing.T) {
+	rulesSvc := newFakeRulesService(testRule("/c", 1), testRule("/a", 1), testRule("/b", 1))
+	admin := ratelimitpb.NewRuleAdminServiceClient(newTestGRPCConn(t, rulesSvc, testAdminTokens))
+	ctx := withToken(testAdminToken)
+
+	resp, err := admin.ListRules(ctx, &ratelimitpb.ListRulesRequest{Page: 1, Items: 2})
+	require.NoError(t, err)
+	require.Len(t, resp.GetRules(), 2)
+	assert.Equal(t, "/a", resp.GetRules()[0].GetEndpoint())
+	assert.Equal(t, "/b", resp.GetRules()[1].GetEndpoint())
+	assert.Equal(t, int32(3), resp.GetTotalItems())
+	assert.True(t, resp.GetHasNextPage())
+
+	_, err = admin.ListRules(ctx, &ratelimitpb.ListRulesRequest{Page: -1})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestPageBounds(t *testing.T) {
+	tests := []struct {
+		name                    string
+		total, page, items      int
+		start, stop, resultPage int
+		hasNextPage             bool
+	}{
+		{"everything without page", 5, 0, 10, 0, 5, 1, false},
+		{"everything without items", 5, 2, 0, 0, 5, 1, false},
+		{"first page", 5, 1, 2, 0, 2, 1, true},
+		{"middle page", 5, 2, 2, 2, 4, 2, true},
+		{"last page", 5, 3, 2, 4, 5, 3, false},
+		{"exactly full last page", 4, 2, 2, 2, 4, 2, false},
+		{"after the last page", 5, 4, 2, 5, 5, 4, false},
+		{"no items", 0, 1, 10, 0, 0, 1, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, stop, page, hasNextPage := pageBounds(tt.total, tt.page, tt.items)
+			assert.Equal(t, tt.start, start)
+			assert.Equal(t, tt.stop, stop)
+			assert.Equal(t, tt.resultPage, page)
+			assert.Equal(t, tt.hasNextPage, hasNextPage)
+		})
+	}
+}
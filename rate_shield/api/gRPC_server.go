@@ -2,60 +2,339 @@ package api
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
 	"net"
+	"os"
+	"sync"
 
 	"github.com/rs/zerolog/log"
 	"github.com/x-sushant-x/RateShield/limiter"
+	"github.com/x-sushant-x/RateShield/models"
 	"github.com/x-sushant-x/RateShield/proto/github.com/x-sushant-x/RateShield/ratelimitpb"
+	"github.com/x-sushant-x/RateShield/service"
 	"github.com/x-sushant-x/RateShield/utils"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/keepalive"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
 )
 
+const (
+	defaultStreamMaxInFlight = 64
+)
+
+// rateLimitChecker is the part of the limiter the rate limit service uses
+type rateLimitChecker interface {
+	CheckLimitFor(req models.RateLimitRequest) *models.RateLimitResponse
+	ReleaseLease(req models.RateLimitRequest, leaseID string) error
+}
+
 type gRPCService struct {
 	ratelimitpb.UnimplementedRateLimitServiceServer
-	limiterSvc *limiter.Limiter
+	limiterSvc        rateLimitChecker
+	streamMaxInFlight int
 }
 
-func newgRPCService(limiterSvc *limiter.Limiter) *gRPCService {
+func newgRPCService(limiterSvc rateLimitChecker, streamMaxInFlight int) *gRPCService {
+	if streamMaxInFlight <= 0 {
+		streamMaxInFlight = defaultStreamMaxInFlight
+	}
+
 	return &gRPCService{
-		limiterSvc: limiterSvc,
+		limiterSvc:        limiterSvc,
+		streamMaxInFlight: streamMaxInFlight,
 	}
 }
 
 func (s *gRPCService) CheckRateLimit(ctx context.Context, req *ratelimitpb.RateLimitRequest) (*ratelimitpb.RateLimitResponse, error) {
+	return s.checkRateLimit(req), nil
+}
+
+// CheckRateLimitStream checks the requests of a stream concurrently, up to streamMaxInFlight at a time.
+// Once that many checks are running no further requests are read, so flow control makes the client wait.
+func (s *gRPCService) CheckRateLimitStream(stream ratelimitpb.RateLimitService_CheckRateLimitStreamServer) error {
+	ctx := stream.Context()
+
+	// Running checks never wait for the sender for long, there is room for the response of each of them
+	responses := make(chan *ratelimitpb.RateLimitStreamResponse, s.streamMaxInFlight)
+	inFlight := make(chan struct{}, s.streamMaxInFlight)
+
+	sendErr := make(chan error, 1)
+	senderDone := make(chan struct{})
+
+	go func() {
+		defer close(senderDone)
+
+		for resp := range responses {
+			if err := stream.Send(resp); err != nil {
+				sendErr <- err
+				// The remaining responses can't be sent, keep draining so checks finish
+				for range responses {
+				}
+				return
+			}
+		}
+	}()
+
+	var wg sync.WaitGroup
+	var recvErr error
+
+receive:
+	for {
+		req, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			recvErr = err
+			break
+		}
+
+		select {
+		case inFlight <- struct{}{}:
+		case <-ctx.Done():
+			recvErr = status.FromContextError(ctx.Err()).Err()
+			break receive
+		case err := <-sendErr:
+			recvErr = err
+			break receive
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			responses <- &ratelimitpb.RateLimitStreamResponse{
+				Id:       req.GetId(),
+				Response: s.checkRateLimit(req.GetRequest()),
+			}
+			<-inFlight
+		}()
+	}
+
+	// Responses of requests that were read are still sent after the client closed its side of the stream
+	wg.Wait()
+	close(responses)
+	<-senderDone
+
+	if recvErr != nil {
+		return recvErr
+	}
+
+	select {
+	case err := <-sendErr:
+		return err
+	default:
+		return nil
+	}
+}
+
+func (s *gRPCService) checkRateLimit(req *ratelimitpb.RateLimitRequest) *ratelimitpb.RateLimitResponse {
 	ip := req.GetIp()
 	endpoint := req.GetEndpoint()
 
-	if err := utils.ValidateLimitRequest(req.Ip, req.Endpoint); err != nil {
+	if err := utils.ValidateLimitRequest(ip, endpoint); err != nil {
 		return &ratelimitpb.RateLimitResponse{
 			HttpStatusCode: 400,
-		}, nil
+		}
 	}
 
-	resp := s.limiterSvc.CheckLimit(ip, endpoint)
+	resp := s.limiterSvc.CheckLimitFor(models.RateLimitRequest{
+		ClientIP: ip,
+		Endpoint: endpoint,
+		ClientID: req.GetClientId(),
+		Tier:     req.GetTier(),
+	})
 
 	return &ratelimitpb.RateLimitResponse{
 		HttpStatusCode: int32(resp.HTTPStatusCode),
 		Limit:          int32(resp.RateLimit_Limit),
 		Remaining:      int32(resp.RateLimit_Remaining),
+		Reason:         resp.Reason,
+		LeaseId:        resp.LeaseID,
+		RetryAfter:     resp.RetryAfter,
+	}
+}
+
+func (s *gRPCService) ReleaseLease(ctx context.Context, req *ratelimitpb.ReleaseLeaseRequest) (*ratelimitpb.ReleaseLeaseResponse, error) {
+	if err := utils.ValidateLimitRequest(req.GetIp(), req.GetEndpoint()); err != nil {
+		return &ratelimitpb.ReleaseLeaseResponse{
+			HttpStatusCode: 400,
+		}, nil
+	}
+
+	err := s.limiterSvc.ReleaseLease(models.RateLimitRequest{
+		ClientIP: req.GetIp(),
+		Endpoint: req.GetEndpoint(),
+		ClientID: req.GetClientId(),
+	}, req.GetLeaseId())
+
+	statusCode := 200
+	switch {
+	case err == nil:
+	case errors.Is(err, limiter.ErrLeaseNotFound):
+		statusCode = 404
+	case errors.Is(err, limiter.ErrRuleNotConcurrency):
+		statusCode = 400
+	default:
+		log.Err(err).Msg("unable to release lease")
+		statusCode = 500
+	}
+
+	return &ratelimitpb.ReleaseLeaseResponse{
+		HttpStatusCode: int32(statusCode),
 	}, nil
 }
 
-func StartGRPCServer(limiterSvc *limiter.Limiter, port string) {
-	grpcServer := grpc.NewServer()
+// GRPCServer serves the rate limit checks, the admin services, health checks and reflection
+type GRPCServer struct {
+	config models.GRPCServerConfig
+	server *grpc.Server
+	health *grpcHealthReporter
+}
 
-	grpcService := newgRPCService(limiterSvc)
+// NewGRPCServer sets up the gRPC server. Calls to the admin services need one of the admin tokens of the
+// config, without tokens the admin services are not served at all. The checks decide whether the health
+// service reports the server as serving.
+func NewGRPCServer(config models.GRPCServerConfig, limiterSvc *limiter.Limiter, rulesSvc service.RulesService, auditSvc service.AuditService, checks map[string]HealthCheck) (*GRPCServer, error) {
+	adminAuth := newGRPCAdminAuth(config.AdminTokens)
+
+	options := []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(adminAuth.unaryInterceptor),
+		grpc.ChainStreamInterceptor(adminAuth.streamInterceptor),
+		grpc.KeepaliveParams(keepalive.ServerParameters{
+			Time:    config.KeepaliveTime,
+			Timeout: config.KeepaliveTimeout,
+		}),
+		grpc.KeepaliveEnforcementPolicy(keepalive.EnforcementPolicy{
+			MinTime:             config.KeepaliveMinTime,
+			PermitWithoutStream: true,
+		}),
+	}
+
+	if config.MaxConcurrentStreams > 0 {
+		options = append(options, grpc.MaxConcurrentStreams(config.MaxConcurrentStreams))
+	}
+
+	if len(config.TLSCertFile) != 0 {
+		tlsConfig, err := loadGRPCTLSConfig(config)
+		if err != nil {
+			return nil, err
+		}
+		options = append(options, grpc.Creds(credentials.NewTLS(tlsConfig)))
+	}
+
+	grpcServer := grpc.NewServer(options...)
+
+	grpcService := newgRPCService(limiterSvc, config.StreamMaxInFlight)
 	ratelimitpb.RegisterRateLimitServiceServer(grpcServer, grpcService)
 
-	lis, err := net.Listen("tcp", ":"+port)
+	if len(config.AdminTokens) > 0 {
+		limiterAdminService := newLimiterAdminGRPCService(limiterSvc, auditSvc)
+		ratelimitpb.RegisterLimiterAdminServiceServer(grpcServer, limiterAdminService)
+
+		ruleAdminService := newRuleAdminGRPCService(rulesSvc, auditSvc)
+		ratelimitpb.RegisterRuleAdminServiceServer(grpcServer, ruleAdminService)
+	} else {
+		log.Warn().Msg("GRPC_ADMIN_TOKENS is not set, the gRPC admin services are disabled")
+	}
+
+	healthReporter := newGRPCHealthReporter(grpcServer, checks)
+	healthpb.RegisterHealthServer(grpcServer, healthReporter.server)
+
+	if config.Reflection {
+		reflection.Register(grpcServer)
+	}
+
+	return &GRPCServer{
+		config: config,
+		server: grpcServer,
+		health: healthReporter,
+	}, nil
+}
+
+// Start listens on the configured port and serves until Shutdown is called
+func (s *GRPCServer) Start() error {
+	lis, err := net.Listen("tcp", ":"+s.config.Port)
 	if err != nil {
-		log.Fatal().Err(err)
+		return err
+	}
+
+	return s.serve(lis)
+}
+
+// serve starts the health checks and serves on lis until Shutdown is called
+func (s *GRPCServer) serve(lis net.Listener) error {
+	s.health.start()
+
+	mode := "plaintext"
+	switch {
+	case len(s.config.TLSClientCAFile) != 0:
+		mode = "mTLS"
+	case len(s.config.TLSCertFile) != 0:
+		mode = "TLS"
 	}
+	log.Info().Msgf("gRPC server listening on :%s (%s) ✅", s.config.Port, mode)
+
+	err := s.server.Serve(lis)
+	if errors.Is(err, grpc.ErrServerStopped) {
+		return nil
+	}
+	return err
+}
+
+// Shutdown reports the server as not serving, stops accepting calls and waits for running calls to finish.
+// Calls still running when ctx is done are cancelled, Shutdown then returns once their handlers returned.
+func (s *GRPCServer) Shutdown(ctx context.Context) error {
+	s.health.shutdown()
+
+	stopped := make(chan struct{})
+	go func() {
+		s.server.GracefulStop()
+		close(stopped)
+	}()
 
-	log.Info().Msg("gRPC server listening on :" + port + " ✅")
+	select {
+	case <-stopped:
+		return nil
+	case <-ctx.Done():
+		s.server.Stop()
+		return ctx.Err()
+	}
+}
 
-	err = grpcServer.Serve(lis)
+// loadGRPCTLSConfig loads the server certificate, and the client CA when clients must present certificates
+func loadGRPCTLSConfig(config models.GRPCServerConfig) (*tls.Config, error) {
+	certificate, err := tls.LoadX509KeyPair(config.TLSCertFile, config.TLSKeyFile)
 	if err != nil {
-		log.Fatal().Err(err)
+		return nil, fmt.Errorf("unable to load gRPC TLS certificate: %w", err)
 	}
+
+	tlsConfig := &tls.Config{
+		Certificates: []tls.Certificate{certificate},
+		MinVersion:   tls.VersionTLS12,
+	}
+
+	if len(config.TLSClientCAFile) != 0 {
+		caPEM, err := os.ReadFile(config.TLSClientCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read gRPC client CA: %w", err)
+		}
+
+		clientCAs := x509.NewCertPool()
+		if !clientCAs.AppendCertsFromPEM(caPEM) {
+			return nil, errors.New("gRPC client CA file contains no PEM certificates")
+		}
+
+		tlsConfig.ClientCAs = clientCAs
+		tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+
+	return tlsConfig, nil
 }
@@ -0,0 +1,379 @@
+package api
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/x-sushant-x/RateShield/models"
+	"github.com/x-sushant-x/RateShield/proto/github.com/x-sushant-x/RateShield/ratelimitpb"
+	"github.com/x-sushant-x/RateShield/service"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// newTestGRPCServer serves a GRPCServer over an in-memory listener until the test ends
+func newTestGRPCServer(t *testing.T, config models.GRPCServerConfig, rulesSvc service.RulesService, checks map[string]HealthCheck) (*GRPCServer, *bufconn.Listener) {
+	t.Helper()
+
+	server, err := NewGRPCServer(config, nil, rulesSvc, nil, checks)
+	require.NoError(t, err)
+
+	lis := bufconn.Listen(1 << 20)
+	go server.serve(lis)
+
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		server.Shutdown(ctx)
+	})
+
+	return server, lis
+}
+
+func dialTestGRPCServer(t *testing.T, lis *bufconn.Listener, creds credentials.TransportCredentials) *grpc.ClientConn {
+	t.Helper()
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(creds),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	return conn
+}
+
+func checkHealth(t *testing.T, conn *grpc.ClientConn, service string) (healthpb.HealthCheckResponse_ServingStatus, error) {
+	t.Helper()
+
+	resp, err := healthpb.NewHealthClient(conn).Check(context.Background(), &healthpb.HealthCheckRequest{Service: service})
+	return resp.GetStatus(), err
+}
+
+func TestGRPCHealthStatus(t *testing.T) {
+	var redisErr atomic.Pointer[error]
+	checks := map[string]HealthCheck{
+		"redis": func(ctx context.Context) error {
+			if err := redisErr.Load(); err != nil {
+				return *err
+			}
+			return nil
+		},
+	}
+
+	server, err := NewGRPCServer(models.GRPCServerConfig{}, nil, newFakeRulesService(), nil, checks)
+	require.NoError(t, err)
+
+	// Unknown until the checks ran for the first time
+	resp, err := server.health.server.Check(context.Background(), &healthpb.HealthCheckRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_UNKNOWN, resp.GetStatus())
+
+	lis := bufconn.Listen(1 << 20)
+	go server.serve(lis)
+	t.Cleanup(func() { server.Shutdown(context.Background()) })
+	conn := dialTestGRPCServer(t, lis, insecure.NewCredentials())
+
+	for _, service := range []string{"", "ratelimit.RateLimitService"} {
+		status, err := checkHealth(t, conn, service)
+		require.NoError(t, err)
+		assert.Equal(t, healthpb.HealthCheckResponse_SERVING, status, service)
+	}
+
+	// The admin services are not served without admin tokens
+	_, err = checkHealth(t, conn, "ratelimit.RuleAdminService")
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	failure := errors.New("connection refused")
+	redisErr.Store(&failure)
+	server.health.check()
+
+	for _, service := range []string{"", "ratelimit.RateLimitService"} {
+		status, err := checkHealth(t, conn, service)
+		require.NoError(t, err)
+		assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, status, service)
+	}
+
+	redisErr.Store(nil)
+	server.health.check()
+
+	status, err := checkHealth(t, conn, "")
+	require.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, status)
+
+	// Watchers learn about the shutdown before their call is ended
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	watch, err := healthpb.NewHealthClient(conn).Watch(ctx, &healthpb.HealthCheckRequest{})
+	require.NoError(t, err)
+
+	update, err := watch.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, update.GetStatus())
+
+	shutdown := make(chan error, 1)
+	go func() { shutdown <- server.Shutdown(context.Background()) }()
+
+	update, err = watch.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, update.GetStatus())
+
+	cancel()
+	require.NoError(t, <-shutdown)
+}
+
+// blockingRulesService holds ListRules calls until release is closed
+type blockingRulesService struct {
+	*fakeRulesService
+	started chan struct{}
+	release chan struct{}
+}
+
+func newBlockingRulesService(t *testing.T) *blockingRulesService {
+	svc := &blockingRulesService{
+		fakeRulesService: newFakeRulesService(),
+		started:          make(chan struct{}, 1),
+		release:          make(chan struct{}),
+	}
+	t.Cleanup(func() {
+		select {
+		case <-svc.release:
+		default:
+			close(svc.release)
+		}
+	})
+	return svc
+}
+
+func (b *blockingRulesService) GetAllRules() ([]models.Rule, error) {
+	b.started <- struct{}{}
+	<-b.release
+	return b.fakeRulesService.GetAllRules()
+}
+
+func TestGRPCShutdown(t *testing.T) {
+	config := models.GRPCServerConfig{AdminTokens: testAdminTokens}
+
+	t.Run("running calls finish", func(t *testing.T) {
+		rulesSvc := newBlockingRulesService(t)
+		server, lis := newTestGRPCServer(t, config, rulesSvc, nil)
+		admin := ratelimitpb.NewRuleAdminServiceClient(dialTestGRPCServer(t, lis, insecure.NewCredentials()))
+
+		result := make(chan error, 1)
+		go func() {
+			_, err := admin.ListRules(withToken(testAdminToken), &ratelimitpb.ListRulesRequest{})
+			result <- err
+		}()
+		<-rulesSvc.started
+
+		shutdown := make(chan error, 1)
+		go func() { shutdown <- server.Shutdown(context.Background()) }()
+
+		select {
+		case err := <-shutdown:
+			t.Fatalf("shutdown returned while a call was running: %v", err)
+		case <-time.After(100 * time.Millisecond):
+		}
+
+		close(rulesSvc.release)
+		assert.NoError(t, <-result)
+		assert.NoError(t, <-shutdown)
+	})
+
+	t.Run("calls are cancelled after the timeout", func(t *testing.T) {
+		rulesSvc := newBlockingRulesService(t)
+		server, lis := newTestGRPCServer(t, config, rulesSvc, nil)
+		admin := ratelimitpb.NewRuleAdminServiceClient(dialTestGRPCServer(t, lis, insecure.NewCredentials()))
+
+		result := make(chan error, 1)
+		go func() {
+			_, err := admin.ListRules(withToken(testAdminToken), &ratelimitpb.ListRulesRequest{})
+			result <- err
+		}()
+		<-rulesSvc.started
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		shutdown := make(chan error, 1)
+		go func() { shutdown <- server.Shutdown(ctx) }()
+
+		// The client is cut off even though the handler is still running
+		assert.Equal(t, codes.Unavailable, status.Code(<-result))
+
+		close(rulesSvc.release)
+		assert.ErrorIs(t, <-shutdown, context.DeadlineExceeded)
+	})
+}
+
+// testCertificates are the files of a CA, and of a server and a client certificate it signed
+type testCertificates struct {
+	caFile                    string
+	serverCertFile, serverKey string
+	clientCertFile, clientKey string
+}
+
+func writeTestCertificates(t *testing.T) testCertificates {
+	t.Helper()
+	dir := t.TempDir()
+
+	writePEM := func(name, blockType string, der []byte) string {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0o600))
+		return path
+	}
+
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "RateShield test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	require.NoError(t, err)
+	caCert, err := x509.ParseCertificate(caDER)
+	require.NoError(t, err)
+
+	issue := func(serial int64, name string, usage x509.ExtKeyUsage) (string, string) {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		require.NoError(t, err)
+
+		template := &x509.Certificate{
+			SerialNumber: big.NewInt(serial),
+			Subject:      pkix.Name{CommonName: name},
+			DNSNames:     []string{"localhost"},
+			NotBefore:    time.Now().Add(-time.Hour),
+			NotAfter:     time.Now().Add(time.Hour),
+			KeyUsage:     x509.KeyUsageDigitalSignature,
+			ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+		}
+		der, err := x509.CreateCertificate(rand.Reader, template, caCert, &key.PublicKey, caKey)
+		require.NoError(t, err)
+
+		keyDER, err := x509.MarshalECPrivateKey(key)
+		require.NoError(t, err)
+
+		return writePEM(name+".crt", "CERTIFICATE", der), writePEM(name+".key", "EC PRIVATE KEY", keyDER)
+	}
+
+	certs := testCertificates{caFile: writePEM("ca.crt", "CERTIFICATE", caDER)}
+	certs.serverCertFile, certs.serverKey = issue(2, "server", x509.ExtKeyUsageServerAuth)
+	certs.clientCertFile, certs.clientKey = issue(3, "client", x509.ExtKeyUsageClientAuth)
+	return certs
+}
+
+func TestLoadGRPCTLSConfig(t *testing.T) {
+	certs := writeTestCertificates(t)
+	missing := filepath.Join(t.TempDir(), "missing.pem")
+
+	notPEM := filepath.Join(t.TempDir(), "ca.crt")
+	require.NoError(t, os.WriteFile(notPEM, []byte("not a certificate"), 0o600))
+
+	tests := []struct {
+		name       string
+		config     models.GRPCServerConfig
+		clientAuth tls.ClientAuthType
+		err        string
+	}{
+		{"TLS", models.GRPCServerConfig{TLSCertFile: certs.serverCertFile, TLSKeyFile: certs.serverKey}, tls.NoClientCert, ""},
+		{"mTLS", models.GRPCServerConfig{TLSCertFile: certs.serverCertFile, TLSKeyFile: certs.serverKey, TLSClientCAFile: certs.caFile}, tls.RequireAndVerifyClientCert, ""},
+		{"missing certificate", models.GRPCServerConfig{TLSCertFile: missing, TLSKeyFile: certs.serverKey}, 0, "unable to load gRPC TLS certificate"},
+		{"missing key", models.GRPCServerConfig{TLSCertFile: certs.serverCertFile, TLSKeyFile: missing}, 0, "unable to load gRPC TLS certificate"},
+		{"key of another certificate", models.GRPCServerConfig{TLSCertFile: certs.serverCertFile, TLSKeyFile: certs.clientKey}, 0, "unable to load gRPC TLS certificate"},
+		{"missing client CA", models.GRPCServerConfig{TLSCertFile: certs.serverCertFile, TLSKeyFile: certs.serverKey, TLSClientCAFile: missing}, 0, "unable to read gRPC client CA"},
+		{"client CA without certificates", models.GRPCServerConfig{TLSCertFile: certs.serverCertFile, TLSKeyFile: certs.serverKey, TLSClientCAFile: notPEM}, 0, "contains no PEM certificates"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tlsConfig, err := loadGRPCTLSConfig(tt.config)
+			if tt.err != "" {
+				assert.ErrorContains(t, err, tt.err)
+
+				// The server is not set up with a broken TLS config
+				_, err = NewGRPCServer(tt.config, nil, newFakeRulesService(), nil, nil)
+				assert.ErrorContains(t, err, tt.err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Len(t, tlsConfig.Certificates, 1)
+			assert.Equal(t, tt.clientAuth, tlsConfig.ClientAuth)
+			assert.Equal(t, uint16(tls.VersionTLS12), tlsConfig.MinVersion)
+		})
+	}
+}
+
+func TestGRPCServerMTLS(t *testing.T) {
+	certs := writeTestCertificates(t)
+
+	_, lis := newTestGRPCServer(t, models.GRPCServerConfig{
+		TLSCertFile:     certs.serverCertFile,
+		TLSKeyFile:      certs.serverKey,
+		TLSClientCAFile: certs.caFile,
+	}, newFakeRulesService(), nil)
+
+	caPEM, err := os.ReadFile(certs.caFile)
+	require.NoError(t, err)
+	rootCAs := x509.NewCertPool()
+	require.True(t, rootCAs.AppendCertsFromPEM(caPEM))
+
+	clientCert, err := tls.LoadX509KeyPair(certs.clientCertFile, certs.clientKey)
+	require.NoError(t, err)
+
+	t.Run("client certificate", func(t *testing.T) {
+		conn := dialTestGRPCServer(t, lis, credentials.NewTLS(&tls.Config{
+			RootCAs:      rootCAs,
+			ServerName:   "localhost",
+			Certificates: []tls.Certificate{clientCert},
+		}))
+
+		status, err := checkHealth(t, conn, "")
+		require.NoError(t, err)
+		assert.Equal(t, healthpb.HealthCheckResponse_SERVING, status)
+	})
+
+	t.Run("no client certificate", func(t *testing.T) {
+		conn := dialTestGRPCServer(t, lis, credentials.NewTLS(&tls.Config{RootCAs: rootCAs, ServerName: "localhost"}))
+
+		_, err := checkHealth(t, conn, "")
+		assert.Equal(t, codes.Unavailable, status.Code(err))
+	})
+
+	t.Run("plaintext", func(t *testing.T) {
+		conn := dialTestGRPCServer(t, lis, insecure.NewCredentials())
+
+		_, err := checkHealth(t, conn, "")
+		assert.Equal(t, codes.Unavailable, status.Code(err))
+	})
+}
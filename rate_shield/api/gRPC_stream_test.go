@@ -0,0 +1,208 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/x-sushant-x/RateShield/models"
+	"github.com/x-sushant-x/RateShield/proto/github.com/x-sushant-x/RateShield/ratelimitpb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// fakeChecker allows every request with the client ID as limit. While release is set, checks wait for it.
+type fakeChecker struct {
+	release  chan struct{}
+	running  atomic.Int32
+	maxSeen  atomic.Int32
+	finished atomic.Int32
+}
+
+func (f *fakeChecker) CheckLimitFor(req models.RateLimitRequest) *models.RateLimitResponse {
+	running := f.running.Add(1)
+	defer f.running.Add(-1)
+
+	for {
+		seen := f.maxSeen.Load()
+		if running <= seen || f.maxSeen.CompareAndSwap(seen, running) {
+			break
+		}
+	}
+
+	if f.release != nil {
+		<-f.release
+	}
+
+	limit, _ := strconv.ParseInt(req.ClientID, 10, 64)
+	f.finished.Add(1)
+	return &models.RateLimitResponse{HTTPStatusCode: 200, RateLimit_Limit: limit, Success: true}
+}
+
+func (f *fakeChecker) ReleaseLease(req models.RateLimitRequest, leaseID string) error {
+	return nil
+}
+
+func newTestRateLimitClient(tb testing.TB, checker rateLimitChecker, streamMaxInFlight int) ratelimitpb.RateLimitServiceClient {
+	tb.Helper()
+
+	lis := bufconn.Listen(1 << 20)
+	server := grpc.NewServer()
+	ratelimitpb.RegisterRateLimitServiceServer(server, newgRPCService(checker, streamMaxInFlight))
+	go server.Serve(lis)
+	tb.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(tb, err)
+	tb.Cleanup(func() { conn.Close() })
+
+	return ratelimitpb.NewRateLimitServiceClient(conn)
+}
+
+func streamRequest(i int) *ratelimitpb.RateLimitStreamRequest {
+	return &ratelimitpb.RateLimitStreamRequest{
+		Id: fmt.Sprintf("req-%d", i),
+		Request: &ratelimitpb.RateLimitRequest{
+			Ip:       "127.0.0.1",
+			Endpoint: "/api/v1/orders",
+			ClientId: strconv.Itoa(i),
+		},
+	}
+}
+
+func TestCheckRateLimitStream(t *testing.T) {
+	client := newTestRateLimitClient(t, &fakeChecker{}, 8)
+
+	stream, err := client.CheckRateLimitStream(context.Background())
+	require.NoError(t, err)
+
+	for i := 1; i <= 100; i++ {
+		require.NoError(t, stream.Send(streamRequest(i)))
+	}
+	require.NoError(t, stream.Send(&ratelimitpb.RateLimitStreamRequest{Id: "invalid", Request: &ratelimitpb.RateLimitRequest{}}))
+	require.NoError(t, stream.CloseSend())
+
+	// Every response belongs to the request with its id
+	seen := map[string]bool{}
+	for {
+		resp, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+
+		if resp.GetId() == "invalid" {
+			assert.Equal(t, int32(400), resp.GetResponse().GetHttpStatusCode())
+		} else {
+			assert.Equal(t, fmt.Sprintf("req-%d", resp.GetResponse().GetLimit()), resp.GetId())
+		}
+		seen[resp.GetId()] = true
+	}
+
+	assert.Len(t, seen, 101)
+}
+
+func TestCheckRateLimitStreamBackpressure(t *testing.T) {
+	checker := &fakeChecker{release: make(chan struct{})}
+	client := newTestRateLimitClient(t, checker, 4)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	stream, err := client.CheckRateLimitStream(ctx)
+	require.NoError(t, err)
+
+	var sent sync.WaitGroup
+	sent.Add(1)
+	go func() {
+		defer sent.Done()
+		for i := 1; i <= 20; i++ {
+			stream.Send(streamRequest(i))
+		}
+		stream.CloseSend()
+	}()
+
+	// No more checks run than allowed while the first ones are blocked
+	require.Eventually(t, func() bool { return checker.running.Load() == 4 }, 5*time.Second, time.Millisecond)
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, int32(4), checker.maxSeen.Load())
+	assert.Equal(t, int32(0), checker.finished.Load())
+
+	close(checker.release)
+
+	received := 0
+	for {
+		_, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		received++
+	}
+
+	sent.Wait()
+	assert.Equal(t, 20, received)
+	assert.Equal(t, int32(4), checker.maxSeen.Load())
+}
+
+// BenchmarkCheckRateLimit compares unary calls made from parallel goroutines with a single stream that
+// keeps many checks in flight, both over an in-memory connection
+func BenchmarkCheckRateLimit(b *testing.B) {
+	b.Run("unary", func(b *testing.B) {
+		client := newTestRateLimitClient(b, &fakeChecker{}, defaultStreamMaxInFlight)
+		req := streamRequest(1).GetRequest()
+
+		b.ReportAllocs()
+		b.ResetTimer()
+
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				if _, err := client.CheckRateLimit(context.Background(), req); err != nil {
+					b.Error(err)
+					return
+				}
+			}
+		})
+	})
+
+	b.Run("stream", func(b *testing.B) {
+		client := newTestRateLimitClient(b, &fakeChecker{}, defaultStreamMaxInFlight)
+
+		stream, err := client.CheckRateLimitStream(context.Background())
+		require.NoError(b, err)
+
+		req := streamRequest(1)
+
+		b.ReportAllocs()
+		b.ResetTimer()
+
+		go func() {
+			for i := 0; i < b.N; i++ {
+				if err := stream.Send(req); err != nil {
+					return
+				}
+			}
+			stream.CloseSend()
+		}()
+
+		for i := 0; i < b.N; i++ {
+			if _, err := stream.Recv(); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
@@ -1,10 +1,13 @@
 package api
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 
+	"github.com/rs/zerolog/log"
 	"github.com/x-sushant-x/RateShield/limiter"
+	"github.com/x-sushant-x/RateShield/models"
 	"github.com/x-sushant-x/RateShield/utils"
 )
 
@@ -27,16 +30,68 @@ func (h RateLimitHandler) CheckRateLimit(w http.ResponseWriter, r *http.Request)
 		w.WriteHeader(http.StatusBadRequest)
 	}
 
-	resp := h.limiterSvc.CheckLimit(ip, endpoint)
+	resp := h.limiterSvc.CheckLimitFor(models.RateLimitRequest{
+		ClientIP: ip,
+		Endpoint: endpoint,
+		ClientID: r.Header.Get("client-id"),
+		Tier:     r.Header.Get("tier"),
+	})
+
+	if resp.Reason != "" {
+		w.Header().Set("rate-limit-reason", resp.Reason)
+	}
 
 	switch resp.HTTPStatusCode {
 	case 200:
 		w.Header().Set("rate-limit", fmt.Sprint(resp.RateLimit_Limit))
 		w.Header().Set("rate-limit-remaining", fmt.Sprint(resp.RateLimit_Remaining))
+		if resp.LeaseID != "" {
+			w.Header().Set("rate-limit-lease-id", resp.LeaseID)
+		}
 		w.WriteHeader(http.StatusOK)
 	case 429:
 		w.WriteHeader(http.StatusTooManyRequests)
+	case 403:
+		if resp.RetryAfter > 0 {
+			w.Header().Set("retry-after", fmt.Sprint(resp.RetryAfter))
+		}
+		w.WriteHeader(http.StatusForbidden)
+	default:
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+}
+
+// ReleaseLease handles POST /release-lease with the headers of the check that acquired the lease and its
+// lease-id. Responds 200 once the lease is returned and 404 if it was already released or has expired.
+func (h RateLimitHandler) ReleaseLease(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		utils.MethodNotAllowedError(w)
+		return
+	}
+
+	ip := r.Header.Get("ip")
+	endpoint := r.Header.Get("endpoint")
+
+	if err := utils.ValidateLimitRequest(ip, endpoint); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	err := h.limiterSvc.ReleaseLease(models.RateLimitRequest{
+		ClientIP: ip,
+		Endpoint: endpoint,
+		ClientID: r.Header.Get("client-id"),
+	}, r.Header.Get("lease-id"))
+
+	switch {
+	case err == nil:
+		w.WriteHeader(http.StatusOK)
+	case errors.Is(err, limiter.ErrLeaseNotFound):
+		w.WriteHeader(http.StatusNotFound)
+	case errors.Is(err, limiter.ErrRuleNotConcurrency):
+		w.WriteHeader(http.StatusBadRequest)
 	default:
+		log.Err(err).Msg("unable to release lease")
 		w.WriteHeader(http.StatusInternalServerError)
 	}
 }
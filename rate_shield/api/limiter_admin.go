@@ -0,0 +1,228 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/rs/zerolog/log"
+	"github.com/x-sushant-x/RateShield/limiter"
+	"github.com/x-sushant-x/RateShield/models"
+	redisClient "github.com/x-sushant-x/RateShield/redis"
+	"github.com/x-sushant-x/RateShield/service"
+	"github.com/x-sushant-x/RateShield/utils"
+)
+
+const (
+	defaultLimiterKeysPageSize = 50
+	maxLimiterKeysPageSize     = 1000
+)
+
+type LimiterAdminAPIHandler struct {
+	limiterSvc *limiter.Limiter
+	auditSvc   service.AuditService
+}
+
+func NewLimiterAdminAPIHandler(limiterSvc *limiter.Limiter, auditSvc service.AuditService) LimiterAdminAPIHandler {
+	return LimiterAdminAPIHandler{
+		limiterSvc: limiterSvc,
+		auditSvc:   auditSvc,
+	}
+}
+
+// GetClientState handles GET /limiter/state?ip=127.0.0.1&endpoint=/api/v1/test
+func (h LimiterAdminAPIHandler) GetClientState(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.MethodNotAllowedError(w)
+		return
+	}
+
+	ip := r.URL.Query().Get("ip")
+	endpoint := r.URL.Query().Get("endpoint")
+
+	if err := utils.ValidateLimitRequest(ip, endpoint); err != nil {
+		utils.BadRequestError(w)
+		return
+	}
+
+	state, err := h.limiterSvc.GetClientState(ip, endpoint)
+	if err != nil {
+		utils.InternalError(w, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(state, w)
+}
+
+// ListClientStates handles GET /limiter/keys?endpoint=/api/v1/test
+// Supports pagination: ?cursor=<next_cursor from previous page>&count=50
+// Supports choosing strategy: ?strategy=TOKEN BUCKET (defaults to the strategy of the endpoint's rule)
+func (h LimiterAdminAPIHandler) ListClientStates(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.MethodNotAllowedError(w)
+		return
+	}
+
+	query := r.URL.Query()
+
+	endpoint := query.Get("endpoint")
+	if len(endpoint) == 0 {
+		utils.BadRequestError(w)
+		return
+	}
+
+	count := int64(defaultLimiterKeysPageSize)
+	if c := query.Get("count"); c != "" {
+		countInt, err := strconv.ParseInt(c, 10, 64)
+		if err != nil || countInt <= 0 || countInt > maxLimiterKeysPageSize {
+			utils.BadRequestError(w)
+			return
+		}
+		count = countInt
+	}
+
+	page, err := h.limiterSvc.ListClientStates(endpoint, query.Get("strategy"), query.Get("cursor"), count)
+	if err != nil {
+		if errors.Is(err, limiter.ErrNoRuleForEndpoint) ||
+			errors.Is(err, limiter.ErrUnknownStrategy) ||
+			errors.Is(err, redisClient.ErrInvalidScanCursor) {
+			utils.BadRequestError(w)
+			return
+		}
+		utils.InternalError(w, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(page, w)
+}
+
+// ResetClientState handles POST /limiter/reset
+func (h LimiterAdminAPIHandler) ResetClientState(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		utils.MethodNotAllowedError(w)
+		return
+	}
+
+	resetReq, err := utils.ParseAPIBody[models.LimiterStateDTO](r)
+	if err != nil || utils.ValidateLimitRequest(resetReq.ClientIP, resetReq.Endpoint) != nil {
+		utils.BadRequestError(w)
+		return
+	}
+
+	err = h.limiterSvc.ResetClientState(resetReq.ClientIP, resetReq.Endpoint)
+	if err != nil {
+		utils.InternalError(w, err.Error())
+		return
+	}
+
+	h.logLimiterAction(r, models.AuditActionResetLimiter, resetReq.Endpoint, resetReq.ClientIP, "limiter state reset")
+
+	utils.SuccessResponse("Limiter State Reset Successfully", w)
+}
+
+// GrantExtraQuota handles POST /limiter/grant
+func (h LimiterAdminAPIHandler) GrantExtraQuota(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		utils.MethodNotAllowedError(w)
+		return
+	}
+
+	grantReq, err := utils.ParseAPIBody[models.GrantQuotaDTO](r)
+	if err != nil || utils.ValidateLimitRequest(grantReq.ClientIP, grantReq.Endpoint) != nil {
+		utils.BadRequestError(w)
+		return
+	}
+
+	state, err := h.limiterSvc.GrantExtraQuota(grantReq.ClientIP, grantReq.Endpoint, grantReq.ExtraRequests)
+	if err != nil {
+		if errors.Is(err, limiter.ErrInvalidExtraQuota) ||
+			errors.Is(err, limiter.ErrNoRuleForEndpoint) ||
+			errors.Is(err, limiter.ErrStrategyNotSupportGrant) {
+			utils.BadRequestError(w)
+			return
+		}
+		utils.InternalError(w, err.Error())
+		return
+	}
+
+	details := fmt.Sprintf("granted %d extra requests", grantReq.ExtraRequests)
+	h.logLimiterAction(r, models.AuditActionGrantQuota, grantReq.Endpoint, grantReq.ClientIP, details)
+
+	utils.SuccessResponse(state, w)
+}
+
+// ListBans handles GET /limiter/bans
+// Supports filtering by endpoint: ?endpoint=/api/v1/test (defaults to every endpoint)
+// Supports pagination: ?cursor=<next_cursor from previous page>&count=50
+func (h LimiterAdminAPIHandler) ListBans(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.MethodNotAllowedError(w)
+		return
+	}
+
+	query := r.URL.Query()
+
+	count := int64(defaultLimiterKeysPageSize)
+	if c := query.Get("count"); c != "" {
+		countInt, err := strconv.ParseInt(c, 10, 64)
+		if err != nil || countInt <= 0 || countInt > maxLimiterKeysPageSize {
+			utils.BadRequestError(w)
+			return
+		}
+		count = countInt
+	}
+
+	page, err := h.limiterSvc.ListBans(query.Get("endpoint"), query.Get("cursor"), count)
+	if err != nil {
+		if errors.Is(err, redisClient.ErrInvalidScanCursor) {
+			utils.BadRequestError(w)
+			return
+		}
+		utils.InternalError(w, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(page, w)
+}
+
+// LiftBan handles POST /limiter/bans/lift
+// Body: {"client_ip": "127.0.0.1", "endpoint": "/api/v1/test"}, client_ip is the client ID for clients with one
+func (h LimiterAdminAPIHandler) LiftBan(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		utils.MethodNotAllowedError(w)
+		return
+	}
+
+	liftReq, err := utils.ParseAPIBody[models.LimiterStateDTO](r)
+	if err != nil || utils.ValidateLimitRequest(liftReq.ClientIP, liftReq.Endpoint) != nil {
+		utils.BadRequestError(w)
+		return
+	}
+
+	err = h.limiterSvc.LiftBan(liftReq.ClientIP, liftReq.Endpoint)
+	if err != nil {
+		if errors.Is(err, limiter.ErrBanNotFound) {
+			utils.NotFoundError(w, err.Error())
+			return
+		}
+		utils.InternalError(w, err.Error())
+		return
+	}
+
+	h.logLimiterAction(r, models.AuditActionLiftBan, liftReq.Endpoint, liftReq.ClientIP, "penalty ban lifted")
+
+	utils.SuccessResponse("Ban Lifted Successfully", w)
+}
+
+func (h LimiterAdminAPIHandler) logLimiterAction(r *http.Request, action, endpoint, clientIP, details string) {
+	if h.auditSvc == nil {
+		return
+	}
+
+	err := h.auditSvc.LogLimiterAction(extractActorInfo(r), action, endpoint, clientIP, details, extractIPAddress(r), r.UserAgent())
+	if err != nil {
+		// Don't fail the operation if audit logging fails
+		log.Warn().Err(err).Msg("failed to log audit event for limiter action")
+	}
+}
@@ -0,0 +1,28 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/x-sushant-x/RateShield/service"
+	"github.com/x-sushant-x/RateShield/utils"
+)
+
+type NotificationAPIHandler struct {
+	dispatcher *service.NotificationDispatcher
+}
+
+func NewNotificationAPIHandler(dispatcher *service.NotificationDispatcher) NotificationAPIHandler {
+	return NotificationAPIHandler{
+		dispatcher: dispatcher,
+	}
+}
+
+// GetStats handles GET /notifications/stats
+func (h NotificationAPIHandler) GetStats(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.MethodNotAllowedError(w)
+		return
+	}
+
+	utils.SuccessResponse(h.dispatcher.Stats(), w)
+}
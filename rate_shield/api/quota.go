@@ -0,0 +1,49 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/x-sushant-x/RateShield/limiter"
+	"github.com/x-sushant-x/RateShield/service"
+	"github.com/x-sushant-x/RateShield/utils"
+)
+
+type QuotaAPIHandler struct {
+	limiterSvc *limiter.Limiter
+}
+
+func NewQuotaAPIHandler(limiterSvc *limiter.Limiter) QuotaAPIHandler {
+	return QuotaAPIHandler{
+		limiterSvc: limiterSvc,
+	}
+}
+
+// GetQuotaUsage handles GET /quota/usage?identity=customer-42
+// Supports a single endpoint: ?endpoint=/api/v1/search (defaults to every endpoint with a quota)
+// Supports the client's tier: ?tier=pro (defaults to the tier mapping)
+func (h QuotaAPIHandler) GetQuotaUsage(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.MethodNotAllowedError(w)
+		return
+	}
+
+	query := r.URL.Query()
+	identity := query.Get("identity")
+	if identity == "" {
+		utils.InvalidRequestError(w, service.ErrNoClientIdentity.Error())
+		return
+	}
+
+	usages, err := h.limiterSvc.GetQuotaUsage(identity, query.Get("endpoint"), query.Get("tier"))
+	if err != nil {
+		if errors.Is(err, limiter.ErrNoRuleForEndpoint) || errors.Is(err, limiter.ErrRuleNotQuota) {
+			utils.InvalidRequestError(w, err.Error())
+			return
+		}
+		utils.InternalError(w, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(usages, w)
+}
@@ -0,0 +1,165 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/x-sushant-x/RateShield/models"
+	"github.com/x-sushant-x/RateShield/service"
+	"github.com/x-sushant-x/RateShield/utils"
+)
+
+const (
+	maxRuleWebhookDeliveries = 1000
+)
+
+type RuleWebhookAPIHandler struct {
+	ruleWebhookSvc service.RuleWebhookService
+}
+
+func NewRuleWebhookAPIHandler(svc service.RuleWebhookService) RuleWebhookAPIHandler {
+	return RuleWebhookAPIHandler{
+		ruleWebhookSvc: svc,
+	}
+}
+
+// ListRuleWebhooks handles GET /webhooks/rules, secrets are not returned
+func (h RuleWebhookAPIHandler) ListRuleWebhooks(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.MethodNotAllowedError(w)
+		return
+	}
+
+	webhooks, err := h.ruleWebhookSvc.ListRuleWebhooks()
+	if err != nil {
+		utils.InternalError(w, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(webhooks, w)
+}
+
+// SaveRuleWebhook handles POST /webhooks/rules/save, a webhook with the same name is replaced
+// Body: {"name": "change-management", "url": "https://cm.example.com/hooks", "secret": "s3cret"}
+func (h RuleWebhookAPIHandler) SaveRuleWebhook(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		utils.MethodNotAllowedError(w)
+		return
+	}
+
+	req, err := utils.ParseAPIBody[models.RuleWebhook](r)
+	if err != nil {
+		utils.BadRequestError(w)
+		return
+	}
+
+	webhook, err := h.ruleWebhookSvc.SaveRuleWebhook(req, extractActorInfo(r), extractIPAddress(r), r.UserAgent())
+	if err != nil {
+		var validationErr *utils.RuleValidationError
+		if errors.As(err, &validationErr) {
+			utils.ValidationErrorResponse(w, validationErr)
+			return
+		}
+		utils.InternalError(w, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(webhook, w)
+}
+
+// DeleteRuleWebhook handles POST /webhooks/rules/delete
+// Body: {"name": "change-management"}
+func (h RuleWebhookAPIHandler) DeleteRuleWebhook(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		utils.MethodNotAllowedError(w)
+		return
+	}
+
+	req, err := utils.ParseAPIBody[models.RuleWebhookNameDTO](r)
+	if err != nil {
+		utils.BadRequestError(w)
+		return
+	}
+
+	found, err := h.ruleWebhookSvc.DeleteRuleWebhook(req.Name, extractActorInfo(r), extractIPAddress(r), r.UserAgent())
+	if err != nil {
+		if errors.Is(err, service.ErrNoRuleWebhookName) {
+			utils.InvalidRequestError(w, err.Error())
+			return
+		}
+		utils.InternalError(w, err.Error())
+		return
+	}
+
+	if !found {
+		utils.NotFoundError(w, "rule webhook not found")
+		return
+	}
+
+	utils.SuccessResponse(req, w)
+}
+
+// ListDeliveries handles GET /webhooks/deliveries, newest first
+// Supports filtering: ?webhook=change-management&status=FAILED&limit=100
+func (h RuleWebhookAPIHandler) ListDeliveries(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.MethodNotAllowedError(w)
+		return
+	}
+
+	query := r.URL.Query()
+
+	limit := 0
+	if l := query.Get("limit"); l != "" {
+		limitInt, err := strconv.Atoi(l)
+		if err != nil || limitInt <= 0 || limitInt > maxRuleWebhookDeliveries {
+			utils.BadRequestError(w)
+			return
+		}
+		limit = limitInt
+	}
+
+	deliveries, err := h.ruleWebhookSvc.ListDeliveries(query.Get("webhook"), query.Get("status"), limit)
+	if err != nil {
+		utils.InternalError(w, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(deliveries, w)
+}
+
+// Redeliver handles POST /webhooks/deliveries/redeliver, the event is sent again as a new delivery
+// Body: {"id": "<delivery id>"}
+func (h RuleWebhookAPIHandler) Redeliver(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		utils.MethodNotAllowedError(w)
+		return
+	}
+
+	req, err := utils.ParseAPIBody[models.RuleWebhookDeliveryIDDTO](r)
+	if err != nil {
+		utils.BadRequestError(w)
+		return
+	}
+
+	delivery, found, err := h.ruleWebhookSvc.Redeliver(req.ID)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrNoDeliveryID):
+			utils.InvalidRequestError(w, err.Error())
+		case errors.Is(err, service.ErrRuleWebhookNotFound):
+			utils.NotFoundError(w, err.Error())
+		default:
+			utils.InternalError(w, err.Error())
+		}
+		return
+	}
+
+	if !found {
+		utils.NotFoundError(w, "delivery not found")
+		return
+	}
+
+	utils.SuccessResponse(delivery, w)
+}
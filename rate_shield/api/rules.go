@@ -1,6 +1,9 @@
 package api
 
 import (
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
 	"strconv"
 	"strings"
@@ -122,6 +125,45 @@ func (h RulesAPIHandler) SearchRules(w http.ResponseWriter, r *http.Request) {
 	utils.SuccessResponse(rules, w)
 }
 
+// GetRule handles GET /rule/get?endpoint=/api/v1/test
+// The rule's version is returned in the ETag header, send it back in If-Match when updating the rule.
+func (h RulesAPIHandler) GetRule(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.MethodNotAllowedError(w)
+		return
+	}
+
+	endpoint := r.URL.Query().Get("endpoint")
+	if len(endpoint) == 0 {
+		utils.BadRequestError(w)
+		return
+	}
+
+	rule, found, err := h.rulesSvc.GetRule(endpoint)
+	if err != nil {
+		utils.InternalError(w, err.Error())
+		return
+	}
+
+	if !found {
+		utils.NotFoundError(w, "rule not found")
+		return
+	}
+
+	etag := ruleETag(rule.Version)
+	w.Header().Set("ETag", etag)
+
+	if r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	utils.SuccessResponse(rule, w)
+}
+
+// CreateOrUpdateRule handles POST /rule/add
+// Supports optimistic concurrency: If-Match: "<version>" only saves the rule if it was not changed since
+// that version was read and If-None-Match: * only saves it if it does not exist yet. Stale writes get 409.
 func (h RulesAPIHandler) CreateOrUpdateRule(w http.ResponseWriter, r *http.Request) {
 	if r.Method == http.MethodOptions {
 		// Preflight
@@ -130,6 +172,12 @@ func (h RulesAPIHandler) CreateOrUpdateRule(w http.ResponseWriter, r *http.Reque
 	}
 
 	if r.Method == http.MethodPost {
+		expectedVersion, err := parseRulePrecondition(r)
+		if err != nil {
+			utils.InvalidPreconditionError(w, err.Error())
+			return
+		}
+
 		updateReq, err := utils.ParseAPIBody[models.Rule](r)
 		if err != nil {
 			utils.BadRequestError(w)
@@ -141,20 +189,72 @@ func (h RulesAPIHandler) CreateOrUpdateRule(w http.ResponseWriter, r *http.Reque
 		ipAddress := extractIPAddress(r)
 		userAgent := r.UserAgent()
 
-		err = h.rulesSvc.CreateOrUpdateRule(updateReq, actor, ipAddress, userAgent)
+		savedRule, err := h.rulesSvc.CreateOrUpdateRuleIfMatch(updateReq, expectedVersion, actor, ipAddress, userAgent)
 		if err != nil {
+			var validationErr *utils.RuleValidationError
+			if errors.As(err, &validationErr) {
+				utils.ValidationErrorResponse(w, validationErr)
+				return
+			}
+			if errors.Is(err, service.ErrRuleVersionConflict) {
+				utils.ConflictError(w, err.Error())
+				return
+			}
+			if errors.Is(err, service.ErrRuleReadOnly) {
+				utils.ForbiddenError(w, err.Error())
+				return
+			}
 			utils.InternalError(w, err.Error())
 			return
 		}
 
+		w.Header().Set("ETag", ruleETag(savedRule.Version))
 		utils.SuccessResponse("Rule Created Successfully", w)
 	} else {
 		utils.MethodNotAllowedError(w)
 	}
 }
 
+func ruleETag(version int64) string {
+	return fmt.Sprintf("\"%d\"", version)
+}
+
+// parseRulePrecondition returns the rule version the client expects to overwrite, or
+// service.AnyRuleVersion if the request has no precondition
+func parseRulePrecondition(r *http.Request) (int64, error) {
+	if r.Header.Get("If-None-Match") == "*" {
+		return 0, nil
+	}
+
+	return parseIfMatch(r)
+}
+
+// parseIfMatch returns the rule version in the If-Match header, or service.AnyRuleVersion if there is none
+func parseIfMatch(r *http.Request) (int64, error) {
+	ifMatch := strings.TrimSpace(r.Header.Get("If-Match"))
+	if ifMatch == "" || ifMatch == "*" {
+		return service.AnyRuleVersion, nil
+	}
+
+	ifMatch = strings.TrimPrefix(ifMatch, "W/")
+	version, err := strconv.ParseInt(strings.Trim(ifMatch, "\""), 10, 64)
+	if err != nil || version < 0 {
+		return 0, errors.New("If-Match must be the ETag of the rule, for example \"3\"")
+	}
+
+	return version, nil
+}
+
+// DeleteRule handles POST /rule/delete
+// If-Match: "<version>" only deletes the rule if it was not changed since that version was read, 409 otherwise.
 func (h RulesAPIHandler) DeleteRule(w http.ResponseWriter, r *http.Request) {
 	if r.Method == http.MethodPost {
+		expectedVersion, err := parseIfMatch(r)
+		if err != nil {
+			utils.InvalidPreconditionError(w, err.Error())
+			return
+		}
+
 		deleteReq, err := utils.ParseAPIBody[models.DeleteRuleDTO](r)
 		if err != nil {
 			utils.BadRequestError(w)
@@ -166,8 +266,16 @@ func (h RulesAPIHandler) DeleteRule(w http.ResponseWriter, r *http.Request) {
 		ipAddress := extractIPAddress(r)
 		userAgent := r.UserAgent()
 
-		err = h.rulesSvc.DeleteRule(deleteReq.RuleKey, actor, ipAddress, userAgent)
+		err = h.rulesSvc.DeleteRuleIfMatch(deleteReq.RuleKey, expectedVersion, actor, ipAddress, userAgent)
 		if err != nil {
+			if errors.Is(err, service.ErrRuleVersionConflict) {
+				utils.ConflictError(w, err.Error())
+				return
+			}
+			if errors.Is(err, service.ErrRuleReadOnly) {
+				utils.ForbiddenError(w, err.Error())
+				return
+			}
 			utils.InternalError(w, err.Error())
 			return
 		}
@@ -177,3 +285,193 @@ func (h RulesAPIHandler) DeleteRule(w http.ResponseWriter, r *http.Request) {
 		utils.MethodNotAllowedError(w)
 	}
 }
+
+// GetRuleHistory handles GET /rule/history?endpoint=/api/v1/test
+func (h RulesAPIHandler) GetRuleHistory(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.MethodNotAllowedError(w)
+		return
+	}
+
+	endpoint := r.URL.Query().Get("endpoint")
+	if len(endpoint) == 0 {
+		utils.BadRequestError(w)
+		return
+	}
+
+	history, err := h.rulesSvc.GetRuleHistory(endpoint)
+	if err != nil {
+		utils.InternalError(w, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(history, w)
+}
+
+// RollbackRule handles POST /rule/rollback
+// Like /rule/add, If-Match: "<version>" only rolls back the rule if it was not changed since that version was read.
+func (h RulesAPIHandler) RollbackRule(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		utils.MethodNotAllowedError(w)
+		return
+	}
+
+	expectedVersion, err := parseIfMatch(r)
+	if err != nil {
+		utils.InvalidPreconditionError(w, err.Error())
+		return
+	}
+
+	rollbackReq, err := utils.ParseAPIBody[models.RollbackRuleDTO](r)
+	if err != nil || len(rollbackReq.Endpoint) == 0 || rollbackReq.Version <= 0 {
+		utils.BadRequestError(w)
+		return
+	}
+
+	// Extract audit information
+	actor := extractActorInfo(r)
+	ipAddress := extractIPAddress(r)
+	userAgent := r.UserAgent()
+
+	rule, err := h.rulesSvc.RollbackRule(rollbackReq.Endpoint, rollbackReq.Version, expectedVersion, actor, ipAddress, userAgent)
+	if err != nil {
+		var validationErr *utils.RuleValidationError
+		if errors.As(err, &validationErr) {
+			utils.ValidationErrorResponse(w, validationErr)
+			return
+		}
+		if errors.Is(err, service.ErrRuleVersionNotFound) {
+			utils.NotFoundError(w, err.Error())
+			return
+		}
+		if errors.Is(err, service.ErrRuleVersionConflict) {
+			utils.ConflictError(w, err.Error())
+			return
+		}
+		if errors.Is(err, service.ErrRuleReadOnly) {
+			utils.ForbiddenError(w, err.Error())
+			return
+		}
+		utils.InternalError(w, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(rule, w)
+}
+
+// ExportRules handles GET /rule/export?format=json
+// Supports format=json (default) or format=yaml. The exported file can be imported with /rule/import or
+// used as a rules file.
+func (h RulesAPIHandler) ExportRules(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.MethodNotAllowedError(w)
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = "json"
+	}
+
+	if format != "json" && format != "yaml" {
+		utils.BadRequestError(w)
+		return
+	}
+
+	rules, err := h.rulesSvc.ExportRules()
+	if err != nil {
+		utils.InternalError(w, err.Error())
+		return
+	}
+
+	document, err := utils.MarshalRulesDocument(rules, format)
+	if err != nil {
+		utils.InternalError(w, err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/"+format)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"rules.%s\"", format))
+	w.WriteHeader(http.StatusOK)
+	w.Write(document)
+}
+
+// ImportRules handles POST /rule/import?mode=upsert&dry_run=true
+// The body is a rules bundle as returned by /rule/export, in YAML when format=yaml or the Content-Type
+// mentions yaml and in JSON otherwise. mode=upsert (default) keeps rules missing from the bundle while
+// mode=replace deletes them. With dry_run=true the changes are returned without being applied.
+func (h RulesAPIHandler) ImportRules(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		utils.MethodNotAllowedError(w)
+		return
+	}
+
+	query := r.URL.Query()
+
+	mode := query.Get("mode")
+	if mode == "" {
+		mode = service.RulesImportUpsert
+	}
+
+	dryRun := false
+	if value := query.Get("dry_run"); value != "" {
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			utils.BadRequestError(w)
+			return
+		}
+		dryRun = parsed
+	}
+
+	extension := ".json"
+	if query.Get("format") == "yaml" || strings.Contains(r.Header.Get("Content-Type"), "yaml") {
+		extension = ".yaml"
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		utils.BadRequestError(w)
+		return
+	}
+	defer r.Body.Close()
+
+	rules, err := utils.ParseRulesDocument(body, extension)
+	if err != nil {
+		utils.BadRequestError(w)
+		return
+	}
+
+	// Extract audit information
+	actor := extractActorInfo(r)
+	ipAddress := extractIPAddress(r)
+	userAgent := r.UserAgent()
+
+	diff, err := h.rulesSvc.ImportRules(rules, mode, dryRun, actor, ipAddress, userAgent)
+	if err != nil {
+		var validationErr *utils.RuleValidationError
+		if errors.As(err, &validationErr) {
+			utils.ValidationErrorResponse(w, validationErr)
+			return
+		}
+		if errors.Is(err, service.ErrInvalidImportMode) {
+			utils.BadRequestError(w)
+			return
+		}
+		if errors.Is(err, service.ErrRuleReadOnly) {
+			utils.ForbiddenError(w, err.Error())
+			return
+		}
+		if errors.Is(err, service.ErrRuleVersionConflict) {
+			utils.ConflictError(w, err.Error())
+			return
+		}
+		utils.InternalError(w, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(models.RulesImportResult{
+		Mode:      mode,
+		DryRun:    dryRun,
+		RulesDiff: diff,
+	}, w)
+}
@@ -0,0 +1,305 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/x-sushant-x/RateShield/models"
+	"github.com/x-sushant-x/RateShield/service"
+	"github.com/x-sushant-x/RateShield/utils"
+)
+
+// fakeRulesService keeps versioned rules in memory and checks versions like the Redis rules service
+type fakeRulesService struct {
+	service.RulesService
+
+	mutex   sync.Mutex
+	rules   map[string]models.Rule
+	history map[string][]models.Rule // Every stored version of a rule
+	actors  []string                 // Actor of every change
+}
+
+func newFakeRulesService(rules ...models.Rule) *fakeRulesService {
+	svc := &fakeRulesService{rules: map[string]models.Rule{}, history: map[string][]models.Rule{}}
+	for _, rule := range rules {
+		svc.rules[rule.APIEndpoint] = rule
+		svc.history[rule.APIEndpoint] = append(svc.history[rule.APIEndpoint], rule)
+	}
+	return svc
+}
+
+func (f *fakeRulesService) GetAllRules() ([]models.Rule, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	rules := []models.Rule{}
+	for _, rule := range f.rules {
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+func (f *fakeRulesService) SearchRule(searchText string) ([]models.Rule, error) {
+	rules, _ := f.GetAllRules()
+
+	found := []models.Rule{}
+	for _, rule := range rules {
+		if strings.Contains(rule.APIEndpoint, searchText) {
+			found = append(found, rule)
+		}
+	}
+	return found, nil
+}
+
+func (f *fakeRulesService) GetRule(key string) (*models.Rule, bool, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	rule, found := f.rules[key]
+	if !found {
+		return nil, false, nil
+	}
+	return &rule, true, nil
+}
+
+func (f *fakeRulesService) CreateOrUpdateRuleIfMatch(rule models.Rule, expectedVersion int64, actor, ipAddress, userAgent string) (*models.Rule, error) {
+	if err := utils.ValidateRule(rule); err != nil {
+		return nil, err
+	}
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if f.rules[rule.APIEndpoint].ReadOnly {
+		return nil, service.ErrRuleReadOnly
+	}
+
+	if !f.hasVersion(rule.APIEndpoint, expectedVersion) {
+		return nil, service.ErrRuleVersionConflict
+	}
+
+	history := f.history[rule.APIEndpoint]
+	rule.Version = 1
+	if len(history) > 0 {
+		rule.Version = history[len(history)-1].Version + 1
+	}
+	f.rules[rule.APIEndpoint] = rule
+	f.history[rule.APIEndpoint] = append(history, rule)
+	f.actors = append(f.actors, actor)
+
+	return &rule, nil
+}
+
+func (f *fakeRulesService) DeleteRule(endpoint, actor, ipAddress, userAgent string) error {
+	return f.DeleteRuleIfMatch(endpoint, service.AnyRuleVersion, actor, ipAddress, userAgent)
+}
+
+func (f *fakeRulesService) DeleteRuleIfMatch(endpoint string, expectedVersion int64, actor, ipAddress, userAgent string) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if f.rules[endpoint].ReadOnly {
+		return service.ErrRuleReadOnly
+	}
+
+	if _, found := f.rules[endpoint]; expectedVersion != service.AnyRuleVersion && (!found || !f.hasVersion(endpoint, expectedVersion)) {
+		return service.ErrRuleVersionConflict
+	}
+
+	delete(f.rules, endpoint)
+	f.actors = append(f.actors, actor)
+	return nil
+}
+
+func (f *fakeRulesService) RollbackRule(endpoint string, version, expectedVersion int64, actor, ipAddress, userAgent string) (*models.Rule, error) {
+	f.mutex.Lock()
+	history := f.history[endpoint]
+	f.mutex.Unlock()
+
+	for _, rule := range history {
+		if rule.Version == version {
+			return f.CreateOrUpdateRuleIfMatch(rule, expectedVersion, actor, ipAddress, userAgent)
+		}
+	}
+	return nil, service.ErrRuleVersionNotFound
+}
+
+// hasVersion checks the stored rule like the rules service, the caller holds the mutex
+func (f *fakeRulesService) hasVersion(endpoint string, expectedVersion int64) bool {
+	if expectedVersion == service.AnyRuleVersion {
+		return true
+	}
+
+	current, found := f.rules[endpoint]
+	if !found {
+		return expectedVersion == 0
+	}
+	return expectedVersion != 0 && current.Version == expectedVersion
+}
+
+func testRule(endpoint string, version int64) models.Rule {
+	return models.Rule{
+		APIEndpoint:            endpoint,
+		Strategy:               models.StrategyFixedWindowCounter,
+		FixedWindowCounterRule: &models.FixedWindowCounterRule{MaxRequests: 10, Window: 60},
+		Version:                version,
+	}
+}
+
+func TestCreateOrUpdateRulePreconditions(t *testing.T) {
+	endpoint := "/api/v1/search"
+
+	tests := []struct {
+		name    string
+		headers map[string]string
+		status  int
+		etag    string
+	}{
+		{"no precondition", nil, http.StatusOK, `"4"`},
+		{"matching if-match", map[string]string{"If-Match": `"3"`}, http.StatusOK, `"4"`},
+		{"weak if-match", map[string]string{"If-Match": `W/"3"`}, http.StatusOK, `"4"`},
+		{"any version", map[string]string{"If-Match": "*"}, http.StatusOK, `"4"`},
+		{"stale if-match", map[string]string{"If-Match": `"2"`}, http.StatusConflict, ""},
+		{"create only", map[string]string{"If-None-Match": "*"}, http.StatusConflict, ""},
+		{"invalid if-match", map[string]string{"If-Match": "three"}, http.StatusBadRequest, ""},
+		{"negative if-match", map[string]string{"If-Match": `"-1"`}, http.StatusBadRequest, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewRulesAPIHandler(newFakeRulesService(testRule(endpoint, 3)))
+
+			body, err := json.Marshal(testRule(endpoint, 0))
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodPost, "/rule/add", bytes.NewReader(body))
+			for name, value := range tt.headers {
+				req.Header.Set(name, value)
+			}
+
+			rec := httptest.NewRecorder()
+			handler.CreateOrUpdateRule(rec, req)
+
+			assert.Equal(t, tt.status, rec.Code, rec.Body.String())
+			assert.Equal(t, tt.etag, rec.Header().Get("ETag"))
+		})
+	}
+
+	t.Run("create only without a rule", func(t *testing.T) {
+		handler := NewRulesAPIHandler(newFakeRulesService())
+
+		body, err := json.Marshal(testRule(endpoint, 0))
+		require.NoError(t, err)
+
+		req := httptest.NewRequest(http.MethodPost, "/rule/add", bytes.NewReader(body))
+		req.Header.Set("If-None-Match", "*")
+
+		rec := httptest.NewRecorder()
+		handler.CreateOrUpdateRule(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, `"1"`, rec.Header().Get("ETag"))
+	})
+}
+
+func TestDeleteRulePreconditions(t *testing.T) {
+	endpoint := "/api/v1/search"
+
+	tests := []struct {
+		name    string
+		ifMatch string
+		status  int
+		deleted bool
+	}{
+		{"no precondition", "", http.StatusOK, true},
+		{"matching if-match", `"3"`, http.StatusOK, true},
+		{"any version", "*", http.StatusOK, true},
+		{"stale if-match", `"2"`, http.StatusConflict, false},
+		{"invalid if-match", "three", http.StatusBadRequest, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rulesSvc := newFakeRulesService(testRule(endpoint, 3))
+			handler := NewRulesAPIHandler(rulesSvc)
+
+			body, err := json.Marshal(models.DeleteRuleDTO{RuleKey: endpoint})
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodPost, "/rule/delete", bytes.NewReader(body))
+			if tt.ifMatch != "" {
+				req.Header.Set("If-Match", tt.ifMatch)
+			}
+
+			rec := httptest.NewRecorder()
+			handler.DeleteRule(rec, req)
+
+			assert.Equal(t, tt.status, rec.Code, rec.Body.String())
+
+			_, found, _ := rulesSvc.GetRule(endpoint)
+			assert.Equal(t, tt.deleted, !found)
+		})
+	}
+
+	t.Run("already deleted", func(t *testing.T) {
+		handler := NewRulesAPIHandler(newFakeRulesService())
+
+		body, err := json.Marshal(models.DeleteRuleDTO{RuleKey: endpoint})
+		require.NoError(t, err)
+
+		req := httptest.NewRequest(http.MethodPost, "/rule/delete", bytes.NewReader(body))
+		req.Header.Set("If-Match", `"3"`)
+
+		rec := httptest.NewRecorder()
+		handler.DeleteRule(rec, req)
+
+		assert.Equal(t, http.StatusConflict, rec.Code)
+	})
+}
+
+func TestRollbackRulePreconditions(t *testing.T) {
+	endpoint := "/api/v1/search"
+
+	tests := []struct {
+		name    string
+		ifMatch string
+		status  int
+		version int64 // Stored version after the request
+	}{
+		{"no precondition", "", http.StatusOK, 3},
+		{"matching if-match", `"2"`, http.StatusOK, 3},
+		{"stale if-match", `"1"`, http.StatusConflict, 2},
+		{"invalid if-match", "two", http.StatusBadRequest, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rulesSvc := newFakeRulesService(testRule(endpoint, 1), testRule(endpoint, 2))
+			handler := NewRulesAPIHandler(rulesSvc)
+
+			body, err := json.Marshal(models.RollbackRuleDTO{Endpoint: endpoint, Version: 1})
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodPost, "/rule/rollback", bytes.NewReader(body))
+			if tt.ifMatch != "" {
+				req.Header.Set("If-Match", tt.ifMatch)
+			}
+
+			rec := httptest.NewRecorder()
+			handler.RollbackRule(rec, req)
+
+			assert.Equal(t, tt.status, rec.Code, rec.Body.String())
+
+			rule, found, _ := rulesSvc.GetRule(endpoint)
+			require.True(t, found)
+			assert.Equal(t, tt.version, rule.Version)
+		})
+	}
+}
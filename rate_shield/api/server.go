@@ -13,14 +13,31 @@ import (
 )
 
 type Server struct {
-	port    int
-	limiter *limiter.Limiter
+	port         int
+	limiter      *limiter.Limiter
+	dispatcher   *service.NotificationDispatcher
+	ruleWebhooks *service.RuleWebhookServiceRedis
+	rulesClient  redisClient.RedisRuleClient
+	auditSvc     service.AuditService
 }
 
-func NewServer(limiter *limiter.Limiter) Server {
+// NewServer connects to the redis rules instance once, every route group shares the client and the
+// audit service
+func NewServer(limiter *limiter.Limiter, dispatcher *service.NotificationDispatcher, ruleWebhooks *service.RuleWebhookServiceRedis) Server {
+	redisRuleClient, err := redisClient.NewRulesClient()
+	if err != nil {
+		log.Fatal().Err(err).Msg("unable to setup new redis rules client")
+	}
+
+	auditClient := redisClient.NewAuditClient(redisRuleClient.(redisClient.RedisRules).GetClient())
+
 	return Server{
-		port:    getPort(),
-		limiter: limiter,
+		port:         getPort(),
+		limiter:      limiter,
+		dispatcher:   dispatcher,
+		ruleWebhooks: ruleWebhooks,
+		rulesClient:  redisRuleClient,
+		auditSvc:     service.NewAuditService(auditClient, ruleWebhooks),
 	}
 }
 
@@ -30,6 +47,12 @@ func (s Server) StartServer() error {
 
 	s.rulesRoutes(mux)
 	s.auditRoutes(mux)
+	s.limiterAdminRoutes(mux)
+	s.accessListRoutes(mux)
+	s.tierRoutes(mux)
+	s.alertRoutes(mux)
+	s.notificationRoutes(mux)
+	s.ruleWebhookRoutes(mux)
 	s.registerRateLimiterRoutes(mux)
 	s.setupHome(mux)
 
@@ -54,7 +77,8 @@ func (s Server) setupCORS(h http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Access-Control-Allow-Origin", "*")
 		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, If-Match, If-None-Match")
+		w.Header().Set("Access-Control-Expose-Headers", "ETag, Content-Disposition")
 
 		if r.Method == http.MethodOptions {
 			w.WriteHeader(http.StatusNoContent)
@@ -66,44 +90,89 @@ func (s Server) setupCORS(h http.Handler) http.Handler {
 }
 
 func (s Server) rulesRoutes(mux *http.ServeMux) {
-	redisRuleClient, err := redisClient.NewRulesClient()
-	if err != nil {
-		log.Err(err).Msg("unable to setup new redis rules client")
-		log.Fatal()
-	}
-
-	// Create audit client and service
-	auditClient := redisClient.NewAuditClient(redisRuleClient.(redisClient.RedisRules).GetClient())
-	auditSvc := service.NewAuditService(auditClient)
-
-	// Create rules service with audit service
-	rulesSvc := service.NewRedisRulesService(redisRuleClient, auditSvc)
+	rulesSvc := service.NewRedisRulesService(s.rulesClient, s.auditSvc)
 	rulesHandler := NewRulesAPIHandler(rulesSvc)
 
 	mux.HandleFunc("/rule/list", rulesHandler.ListAllRules)
+	mux.HandleFunc("/rule/get", rulesHandler.GetRule)
 	mux.HandleFunc("/rule/add", rulesHandler.CreateOrUpdateRule)
 	mux.HandleFunc("/rule/delete", rulesHandler.DeleteRule)
 	mux.HandleFunc("/rule/search", rulesHandler.SearchRules)
+	mux.HandleFunc("/rule/history", rulesHandler.GetRuleHistory)
+	mux.HandleFunc("/rule/rollback", rulesHandler.RollbackRule)
+	mux.HandleFunc("/rule/export", rulesHandler.ExportRules)
+	mux.HandleFunc("/rule/import", rulesHandler.ImportRules)
 }
 
 func (s Server) auditRoutes(mux *http.ServeMux) {
-	redisRuleClient, err := redisClient.NewRulesClient()
-	if err != nil {
-		log.Err(err).Msg("unable to setup new redis rules client for audit")
-		log.Fatal()
-	}
-
-	// Create audit client and service
-	auditClient := redisClient.NewAuditClient(redisRuleClient.(redisClient.RedisRules).GetClient())
-	auditSvc := service.NewAuditService(auditClient)
-	auditHandler := NewAuditAPIHandler(auditSvc)
+	auditHandler := NewAuditAPIHandler(s.auditSvc)
 
 	mux.HandleFunc("/audit/logs", auditHandler.ListAuditLogs)
 }
 
+func (s Server) limiterAdminRoutes(mux *http.ServeMux) {
+	limiterAdminHandler := NewLimiterAdminAPIHandler(s.limiter, s.auditSvc)
+
+	mux.HandleFunc("/limiter/state", limiterAdminHandler.GetClientState)
+	mux.HandleFunc("/limiter/keys", limiterAdminHandler.ListClientStates)
+	mux.HandleFunc("/limiter/reset", limiterAdminHandler.ResetClientState)
+	mux.HandleFunc("/limiter/grant", limiterAdminHandler.GrantExtraQuota)
+	mux.HandleFunc("/limiter/bans", limiterAdminHandler.ListBans)
+	mux.HandleFunc("/limiter/bans/lift", limiterAdminHandler.LiftBan)
+}
+
+func (s Server) accessListRoutes(mux *http.ServeMux) {
+	accessListClient := redisClient.NewAccessListClient(s.rulesClient.(redisClient.RedisRules).GetClient())
+	accessListSvc := service.NewAccessListService(accessListClient, s.auditSvc)
+	accessListHandler := NewAccessListAPIHandler(accessListSvc)
+
+	mux.HandleFunc("/access-lists", accessListHandler.GetAccessLists)
+	mux.HandleFunc("/access-lists/add", accessListHandler.AddEntries)
+	mux.HandleFunc("/access-lists/remove", accessListHandler.RemoveEntries)
+}
+
+func (s Server) tierRoutes(mux *http.ServeMux) {
+	tierClient := redisClient.NewTierClient(s.rulesClient.(redisClient.RedisRules).GetClient())
+	tierSvc := service.NewTierService(tierClient, s.auditSvc)
+	tierHandler := NewTierAPIHandler(tierSvc)
+
+	mux.HandleFunc("/tiers/client", tierHandler.GetClientTier)
+	mux.HandleFunc("/tiers/assign", tierHandler.AssignTier)
+	mux.HandleFunc("/tiers/unassign", tierHandler.UnassignTier)
+}
+
+func (s Server) alertRoutes(mux *http.ServeMux) {
+	alertRuleClient := redisClient.NewAlertRuleClient(s.rulesClient.(redisClient.RedisRules).GetClient())
+	alertRuleSvc := service.NewAlertRuleService(alertRuleClient, s.auditSvc)
+	alertHandler := NewAlertAPIHandler(alertRuleSvc)
+
+	mux.HandleFunc("/alerts/rules", alertHandler.ListAlertRules)
+	mux.HandleFunc("/alerts/rules/save", alertHandler.SaveAlertRule)
+	mux.HandleFunc("/alerts/rules/delete", alertHandler.DeleteAlertRule)
+}
+
+func (s Server) notificationRoutes(mux *http.ServeMux) {
+	notificationHandler := NewNotificationAPIHandler(s.dispatcher)
+	mux.HandleFunc("/notifications/stats", notificationHandler.GetStats)
+}
+
+func (s Server) ruleWebhookRoutes(mux *http.ServeMux) {
+	ruleWebhookHandler := NewRuleWebhookAPIHandler(s.ruleWebhooks)
+
+	mux.HandleFunc("/webhooks/rules", ruleWebhookHandler.ListRuleWebhooks)
+	mux.HandleFunc("/webhooks/rules/save", ruleWebhookHandler.SaveRuleWebhook)
+	mux.HandleFunc("/webhooks/rules/delete", ruleWebhookHandler.DeleteRuleWebhook)
+	mux.HandleFunc("/webhooks/deliveries", ruleWebhookHandler.ListDeliveries)
+	mux.HandleFunc("/webhooks/deliveries/redeliver", ruleWebhookHandler.Redeliver)
+}
+
 func (s Server) registerRateLimiterRoutes(mux *http.ServeMux) {
 	rateLimiterHandler := NewRateLimitHandler(s.limiter)
 	mux.HandleFunc("/check-limit", rateLimiterHandler.CheckRateLimit)
+	mux.HandleFunc("/release-lease", rateLimiterHandler.ReleaseLease)
+
+	quotaHandler := NewQuotaAPIHandler(s.limiter)
+	mux.HandleFunc("/quota/usage", quotaHandler.GetQuotaUsage)
 }
 
 func (s Server) setupHome(mux *http.ServeMux) {
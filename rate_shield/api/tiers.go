@@ -0,0 +1,106 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/x-sushant-x/RateShield/models"
+	"github.com/x-sushant-x/RateShield/service"
+	"github.com/x-sushant-x/RateShield/utils"
+)
+
+type TierAPIHandler struct {
+	tierSvc service.TierService
+}
+
+func NewTierAPIHandler(svc service.TierService) TierAPIHandler {
+	return TierAPIHandler{
+		tierSvc: svc,
+	}
+}
+
+// GetClientTier handles GET /tiers/client?identity=<client id or ip>
+func (h TierAPIHandler) GetClientTier(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		utils.MethodNotAllowedError(w)
+		return
+	}
+
+	identity := r.URL.Query().Get("identity")
+	if identity == "" {
+		utils.InvalidRequestError(w, service.ErrNoClientIdentity.Error())
+		return
+	}
+
+	tier, found, err := h.tierSvc.GetClientTier(identity)
+	if err != nil {
+		utils.InternalError(w, err.Error())
+		return
+	}
+
+	if !found {
+		utils.NotFoundError(w, "client has no tier")
+		return
+	}
+
+	utils.SuccessResponse(models.ClientTierDTO{Identity: identity, Tier: tier}, w)
+}
+
+// AssignTier handles POST /tiers/assign
+// Body: {"identity": "customer-42", "tier": "pro"}
+func (h TierAPIHandler) AssignTier(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		utils.MethodNotAllowedError(w)
+		return
+	}
+
+	req, err := utils.ParseAPIBody[models.ClientTierDTO](r)
+	if err != nil {
+		utils.BadRequestError(w)
+		return
+	}
+
+	err = h.tierSvc.AssignTier(req.Identity, req.Tier, extractActorInfo(r), extractIPAddress(r), r.UserAgent())
+	if err != nil {
+		if errors.Is(err, service.ErrNoClientIdentity) || errors.Is(err, service.ErrInvalidTierName) {
+			utils.InvalidRequestError(w, err.Error())
+			return
+		}
+		utils.InternalError(w, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(req, w)
+}
+
+// UnassignTier handles POST /tiers/unassign
+// Body: {"identity": "customer-42"}
+func (h TierAPIHandler) UnassignTier(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		utils.MethodNotAllowedError(w)
+		return
+	}
+
+	req, err := utils.ParseAPIBody[models.ClientTierDTO](r)
+	if err != nil {
+		utils.BadRequestError(w)
+		return
+	}
+
+	found, err := h.tierSvc.UnassignTier(req.Identity, extractActorInfo(r), extractIPAddress(r), r.UserAgent())
+	if err != nil {
+		if errors.Is(err, service.ErrNoClientIdentity) {
+			utils.InvalidRequestError(w, err.Error())
+			return
+		}
+		utils.InternalError(w, err.Error())
+		return
+	}
+
+	if !found {
+		utils.NotFoundError(w, "client has no tier")
+		return
+	}
+
+	utils.SuccessResponse(models.ClientTierDTO{Identity: req.Identity}, w)
+}
@@ -0,0 +1,30 @@
+// Package chimw rate limits chi routes with RateShield. Routes are checked by their pattern, so
+// /users/{id} needs a single rule instead of one per user.
+package chimw
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/x-sushant-x/RateShield/client"
+)
+
+// Middleware checks every request before the handler. The route pattern is only known once chi matched
+// the route, so add it with r.With or in a r.Group / r.Route. Used with r.Use on the top level router it
+// checks the path instead.
+func Middleware(c *client.Client, config client.MiddlewareConfig) func(http.Handler) http.Handler {
+	guard := c.Guard(config)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			info := client.HTTPRequestInfo(r)
+			if routeCtx := chi.RouteContext(r.Context()); routeCtx != nil {
+				info.Route = routeCtx.RoutePattern()
+			}
+
+			decision := guard.Check(r.Context(), info)
+			client.ServeDecision(w, r, decision, next)
+			guard.Done(decision)
+		})
+	}
+}
@@ -0,0 +1,23 @@
+module github.com/x-sushant-x/RateShield/client/chimw
+
+go 1.24.0
+
+require (
+	github.com/go-chi/chi/v5 v5.2.1
+	github.com/stretchr/testify v1.10.0
+	github.com/x-sushant-x/RateShield v0.0.0
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.2-0.20180830191138-d8f796af33cc // indirect
+	github.com/pmezard/go-difflib v1.0.1-0.20181226105442-5d4384ee4fb2 // indirect
+	golang.org/x/net v0.42.0 // indirect
+	golang.org/x/sys v0.34.0 // indirect
+	golang.org/x/text v0.27.0 // indirect
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20250804133106-a7a43d27e69b // indirect
+	google.golang.org/grpc v1.76.0 // indirect
+	google.golang.org/protobuf v1.36.10 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
+
+replace github.com/x-sushant-x/RateShield => ../../
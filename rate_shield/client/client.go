@@ -0,0 +1,222 @@
+// Package client checks requests against RateShield over its gRPC API. It keeps a pool of connections,
+// bounds every check with a timeout and can let requests through while RateShield is unreachable.
+// Middleware for net/http and chi, gin, echo and fiber is built on top of it.
+//
+//	rs, err := client.New(client.Config{Address: "rateshield:50051", FailOpen: true})
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	defer rs.Close()
+//
+//	http.ListenAndServe(":8080", rs.Middleware(client.MiddlewareConfig{})(mux))
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/x-sushant-x/RateShield/proto/github.com/x-sushant-x/RateShield/ratelimitpb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+const (
+	defaultAddress     = "127.0.0.1:50051"
+	defaultConnections = 4
+	defaultTimeout     = 200 * time.Millisecond
+)
+
+var (
+	ErrLeaseNotFound = errors.New("lease not found, it was already released or has expired")
+)
+
+// Config of a Client. Only Address is usually set, the other fields have working defaults.
+type Config struct {
+	// Address of the RateShield gRPC server, defaults to 127.0.0.1:50051
+	Address string
+
+	// Connections opened to the server, checks are spread over them round robin. Defaults to 4.
+	Connections int
+
+	// Timeout of a single check or lease release, defaults to 200ms
+	Timeout time.Duration
+
+	// FailOpen allows requests when RateShield can not be reached, times out or fails. Without it such
+	// requests are rejected with 503.
+	FailOpen bool
+
+	// OnError is called with every failed check, for example to log it. Optional.
+	OnError func(err error)
+
+	// DialOptions are passed to every connection, for example TLS credentials. Connections are
+	// unencrypted when none are given.
+	DialOptions []grpc.DialOption
+}
+
+// Request identifies the client and endpoint to check
+type Request struct {
+	IP       string
+	Endpoint string
+	ClientID string // Counts the client by this ID instead of its IP
+	Tier     string // Tier of the client, looked up by RateShield when empty
+}
+
+// Result of a check
+type Result struct {
+	Allowed    bool
+	StatusCode int // 200 when allowed, 429 when rate limited, 403 when denied or banned
+	Limit      int64
+	Remaining  int64
+	Reason     string // Set when the IP is on an allow or deny list or banned
+	LeaseID    string // Set for the CONCURRENCY strategy, release it once the request is done
+	RetryAfter int64  // Seconds until a banned client may retry
+	FailedOpen bool   // The request was allowed because the check failed and FailOpen is set
+}
+
+// Client checks requests against RateShield. It is safe for concurrent use.
+type Client struct {
+	config Config
+	conns  []*grpc.ClientConn
+	stubs  []ratelimitpb.RateLimitServiceClient
+	next   atomic.Uint64
+}
+
+// New opens the connections to RateShield. Connections are established lazily, so New does not fail when
+// the server is not up yet.
+func New(config Config) (*Client, error) {
+	if config.Address == "" {
+		config.Address = defaultAddress
+	}
+	if config.Connections <= 0 {
+		config.Connections = defaultConnections
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = defaultTimeout
+	}
+
+	dialOptions := config.DialOptions
+	if len(dialOptions) == 0 {
+		dialOptions = []grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())}
+	}
+
+	c := &Client{config: config}
+
+	for i := 0; i < config.Connections; i++ {
+		conn, err := grpc.NewClient(config.Address, dialOptions...)
+		if err != nil {
+			c.Close()
+			return nil, fmt.Errorf("unable to connect to RateShield at %s: %w", config.Address, err)
+		}
+
+		c.conns = append(c.conns, conn)
+		c.stubs = append(c.stubs, ratelimitpb.NewRateLimitServiceClient(conn))
+	}
+
+	return c, nil
+}
+
+// Close closes every connection
+func (c *Client) Close() error {
+	var errs []error
+	for _, conn := range c.conns {
+		if err := conn.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Check asks RateShield whether the request is allowed. When the check fails the request is allowed with
+// FailedOpen set if the client fails open, otherwise the error is returned.
+func (c *Client) Check(ctx context.Context, req Request) (Result, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.config.Timeout)
+	defer cancel()
+
+	resp, err := c.stub().CheckRateLimit(ctx, &ratelimitpb.RateLimitRequest{
+		Ip:       req.IP,
+		Endpoint: req.Endpoint,
+		ClientId: req.ClientID,
+		Tier:     req.Tier,
+	})
+	if err == nil && resp.GetHttpStatusCode() >= http.StatusInternalServerError {
+		err = fmt.Errorf("RateShield responded with status %d", resp.GetHttpStatusCode())
+	}
+
+	if err != nil {
+		if c.config.OnError != nil {
+			c.config.OnError(err)
+		}
+		if c.config.FailOpen {
+			return Result{Allowed: true, StatusCode: http.StatusOK, FailedOpen: true}, nil
+		}
+		return Result{}, err
+	}
+
+	return Result{
+		Allowed:    resp.GetHttpStatusCode() == http.StatusOK,
+		StatusCode: int(resp.GetHttpStatusCode()),
+		Limit:      int64(resp.GetLimit()),
+		Remaining:  int64(resp.GetRemaining()),
+		Reason:     resp.GetReason(),
+		LeaseID:    resp.GetLeaseId(),
+		RetryAfter: resp.GetRetryAfter(),
+	}, nil
+}
+
+// Release returns the lease of a CONCURRENCY check. Returns ErrLeaseNotFound if it was already released
+// or has expired.
+func (c *Client) Release(ctx context.Context, req Request, leaseID string) error {
+	ctx, cancel := context.WithTimeout(ctx, c.config.Timeout)
+	defer cancel()
+
+	resp, err := c.stub().ReleaseLease(ctx, &ratelimitpb.ReleaseLeaseRequest{
+		Ip:       req.IP,
+		Endpoint: req.Endpoint,
+		ClientId: req.ClientID,
+		LeaseId:  leaseID,
+	})
+	if err != nil {
+		return err
+	}
+
+	switch resp.GetHttpStatusCode() {
+	case http.StatusOK:
+		return nil
+	case http.StatusNotFound:
+		return ErrLeaseNotFound
+	}
+	return fmt.Errorf("unable to release lease, RateShield responded with status %d", resp.GetHttpStatusCode())
+}
+
+func (c *Client) stub() ratelimitpb.RateLimitServiceClient {
+	return c.stubs[c.next.Add(1)%uint64(len(c.stubs))]
+}
+
+// Headers returns the rate limit headers of the result, the same ones the HTTP API of RateShield sets
+func (r Result) Headers() map[string]string {
+	headers := map[string]string{}
+
+	if r.FailedOpen {
+		return headers
+	}
+
+	if r.Reason != "" {
+		headers["rate-limit-reason"] = r.Reason
+	}
+
+	if r.Allowed {
+		headers["rate-limit"] = strconv.FormatInt(r.Limit, 10)
+		headers["rate-limit-remaining"] = strconv.FormatInt(r.Remaining, 10)
+	}
+
+	if r.RetryAfter > 0 {
+		headers["retry-after"] = strconv.FormatInt(r.RetryAfter, 10)
+	}
+
+	return headers
+}
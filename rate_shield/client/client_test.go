@@ -0,0 +1,201 @@
+package client
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/x-sushant-x/RateShield/proto/github.com/x-sushant-x/RateShield/ratelimitpb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// fakeRateShield answers every check with response and records the requests
+type fakeRateShield struct {
+	ratelimitpb.UnimplementedRateLimitServiceServer
+
+	mutex    sync.Mutex
+	response *ratelimitpb.RateLimitResponse
+	checks   []*ratelimitpb.RateLimitRequest
+	releases []*ratelimitpb.ReleaseLeaseRequest
+}
+
+func (f *fakeRateShield) CheckRateLimit(ctx context.Context, req *ratelimitpb.RateLimitRequest) (*ratelimitpb.RateLimitResponse, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.checks = append(f.checks, req)
+	return f.response, nil
+}
+
+func (f *fakeRateShield) ReleaseLease(ctx context.Context, req *ratelimitpb.ReleaseLeaseRequest) (*ratelimitpb.ReleaseLeaseResponse, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.releases = append(f.releases, req)
+	return &ratelimitpb.ReleaseLeaseResponse{HttpStatusCode: http.StatusOK}, nil
+}
+
+// startFakeRateShield serves fake over an in-memory listener and returns a client connected to it
+func startFakeRateShield(t *testing.T, fake *fakeRateShield, config Config) (*Client, func()) {
+	listener := bufconn.Listen(1 << 20)
+	server := grpc.NewServer()
+	ratelimitpb.RegisterRateLimitServiceServer(server, fake)
+	go server.Serve(listener)
+
+	config.Address = "passthrough:///bufnet"
+	config.DialOptions = []grpc.DialOption{
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+	}
+
+	c, err := New(config)
+	require.NoError(t, err)
+	t.Cleanup(func() { c.Close() })
+
+	return c, server.Stop
+}
+
+func TestCheck(t *testing.T) {
+	fake := &fakeRateShield{response: &ratelimitpb.RateLimitResponse{HttpStatusCode: 200, Limit: 100, Remaining: 42}}
+	c, _ := startFakeRateShield(t, fake, Config{Connections: 2})
+
+	result, err := c.Check(context.Background(), Request{IP: "10.0.0.1", Endpoint: "/api/v1/search", ClientID: "customer-42", Tier: "pro"})
+	require.NoError(t, err)
+
+	assert.Equal(t, Result{Allowed: true, StatusCode: 200, Limit: 100, Remaining: 42}, result)
+	assert.Equal(t, map[string]string{"rate-limit": "100", "rate-limit-remaining": "42"}, result.Headers())
+
+	require.Len(t, fake.checks, 1)
+	assert.Equal(t, "10.0.0.1", fake.checks[0].GetIp())
+	assert.Equal(t, "/api/v1/search", fake.checks[0].GetEndpoint())
+	assert.Equal(t, "customer-42", fake.checks[0].GetClientId())
+	assert.Equal(t, "pro", fake.checks[0].GetTier())
+}
+
+func TestCheckFailure(t *testing.T) {
+	t.Run("fail open", func(t *testing.T) {
+		var errs []error
+		c, stop := startFakeRateShield(t, &fakeRateShield{}, Config{FailOpen: true, OnError: func(err error) { errs = append(errs, err) }})
+		stop()
+
+		result, err := c.Check(context.Background(), Request{IP: "10.0.0.1", Endpoint: "/api/v1/search"})
+		require.NoError(t, err)
+		assert.True(t, result.Allowed)
+		assert.True(t, result.FailedOpen)
+		assert.Empty(t, result.Headers())
+		assert.Len(t, errs, 1)
+	})
+
+	t.Run("fail closed", func(t *testing.T) {
+		c, stop := startFakeRateShield(t, &fakeRateShield{}, Config{})
+		stop()
+
+		_, err := c.Check(context.Background(), Request{IP: "10.0.0.1", Endpoint: "/api/v1/search"})
+		assert.Error(t, err)
+
+		rec := httptest.NewRecorder()
+		c.Middleware(MiddlewareConfig{})(http.NotFoundHandler()).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/search", nil))
+		assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	})
+
+	t.Run("server error", func(t *testing.T) {
+		c, _ := startFakeRateShield(t, &fakeRateShield{response: &ratelimitpb.RateLimitResponse{HttpStatusCode: 500}}, Config{})
+
+		_, err := c.Check(context.Background(), Request{IP: "10.0.0.1", Endpoint: "/api/v1/search"})
+		assert.Error(t, err)
+	})
+}
+
+func TestMiddleware(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	})
+
+	t.Run("allowed request gets the rate limit headers", func(t *testing.T) {
+		fake := &fakeRateShield{response: &ratelimitpb.RateLimitResponse{HttpStatusCode: 200, Limit: 10, Remaining: 9}}
+		c, _ := startFakeRateShield(t, fake, Config{})
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/search", nil)
+		req.RemoteAddr = "10.0.0.1:51234"
+		rec := httptest.NewRecorder()
+		c.Middleware(MiddlewareConfig{})(handler).ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "ok", rec.Body.String())
+		assert.Equal(t, "10", rec.Header().Get("rate-limit"))
+		assert.Equal(t, "9", rec.Header().Get("rate-limit-remaining"))
+		assert.Equal(t, "10.0.0.1", fake.checks[0].GetIp())
+		assert.Equal(t, "/api/v1/search", fake.checks[0].GetEndpoint())
+	})
+
+	t.Run("banned request is rejected", func(t *testing.T) {
+		fake := &fakeRateShield{response: &ratelimitpb.RateLimitResponse{HttpStatusCode: 403, Reason: "banned until 2024-09-01T18:00:00Z", RetryAfter: 120}}
+		c, _ := startFakeRateShield(t, fake, Config{})
+
+		rec := httptest.NewRecorder()
+		c.Middleware(MiddlewareConfig{})(handler).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/login", nil))
+
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+		assert.NotContains(t, rec.Body.String(), "ok")
+		assert.Equal(t, "120", rec.Header().Get("retry-after"))
+		assert.Equal(t, "banned until 2024-09-01T18:00:00Z", rec.Header().Get("rate-limit-reason"))
+	})
+
+	t.Run("skipped request is not checked", func(t *testing.T) {
+		fake := &fakeRateShield{response: &ratelimitpb.RateLimitResponse{HttpStatusCode: 429}}
+		c, _ := startFakeRateShield(t, fake, Config{})
+
+		skipHealth := MiddlewareConfig{Skip: func(req RequestInfo) bool { return req.Path == "/health" }}
+		rec := httptest.NewRecorder()
+		c.Middleware(skipHealth)(handler).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/health", nil))
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Empty(t, fake.checks)
+	})
+
+	t.Run("lease is released after the handler", func(t *testing.T) {
+		fake := &fakeRateShield{response: &ratelimitpb.RateLimitResponse{HttpStatusCode: 200, Limit: 5, Remaining: 4, LeaseId: "lease-1"}}
+		c, _ := startFakeRateShield(t, fake, Config{})
+
+		config := MiddlewareConfig{
+			Identity: HeaderClientID("X-API-Key", nil),
+			Endpoint: func(req RequestInfo) string { return "/reports" },
+		}
+		req := httptest.NewRequest(http.MethodPost, "/reports/42", nil)
+		req.Header.Set("X-API-Key", "customer-42")
+		c.Middleware(config)(handler).ServeHTTP(httptest.NewRecorder(), req)
+
+		require.Len(t, fake.releases, 1)
+		assert.Equal(t, "lease-1", fake.releases[0].GetLeaseId())
+		assert.Equal(t, "customer-42", fake.releases[0].GetClientId())
+		assert.Equal(t, "/reports", fake.releases[0].GetEndpoint())
+	})
+}
+
+func TestIdentityExtractors(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = "192.168.1.10:40000"
+
+	assert.Equal(t, Identity{IP: "192.168.1.10"}, RemoteIP()(HTTPRequestInfo(req)))
+	assert.Equal(t, Identity{IP: "192.168.1.10"}, ForwardedIP()(HTTPRequestInfo(req)))
+
+	req.Header.Set("X-Real-IP", "203.0.113.9")
+	assert.Equal(t, Identity{IP: "203.0.113.9"}, ForwardedIP()(HTTPRequestInfo(req)))
+
+	req.Header.Set("X-Forwarded-For", "198.51.100.7, 10.0.0.1")
+	assert.Equal(t, Identity{IP: "198.51.100.7"}, ForwardedIP()(HTTPRequestInfo(req)))
+
+	req.Header.Set("X-API-Key", "customer-42")
+	req.Header.Set("X-Plan", "pro")
+	identify := WithTierHeader("X-Plan", HeaderClientID("X-API-Key", ForwardedIP()))
+	assert.Equal(t, Identity{IP: "198.51.100.7", ClientID: "customer-42", Tier: "pro"}, identify(HTTPRequestInfo(req)))
+}
@@ -0,0 +1,33 @@
+// Package echomw rate limits echo routes with RateShield. Routes are checked by their pattern, so
+// /users/:id needs a single rule instead of one per user.
+package echomw
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/x-sushant-x/RateShield/client"
+)
+
+// Middleware checks every request before the handler. Rejected requests get an *echo.HTTPError with the
+// status of the check, so the error handler of the app renders them.
+func Middleware(c *client.Client, config client.MiddlewareConfig) echo.MiddlewareFunc {
+	guard := c.Guard(config)
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			info := client.HTTPRequestInfo(ctx.Request())
+			info.Route = ctx.Path()
+
+			decision := guard.Check(ctx.Request().Context(), info)
+			for name, value := range decision.Headers {
+				ctx.Response().Header().Set(name, value)
+			}
+
+			if decision.Status != 0 {
+				return echo.NewHTTPError(decision.Status, decision.Message)
+			}
+
+			defer guard.Done(decision)
+			return next(ctx)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package echomw
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/x-sushant-x/RateShield/client"
+	"github.com/x-sushant-x/RateShield/client/internal/clienttest"
+	"github.com/x-sushant-x/RateShield/proto/github.com/x-sushant-x/RateShield/ratelimitpb"
+)
+
+func newTestServer(c *client.Client) *echo.Echo {
+	e := echo.New()
+	e.GET("/users/:id", func(ctx echo.Context) error {
+		return ctx.String(http.StatusOK, "ok")
+	}, Middleware(c, client.MiddlewareConfig{}))
+	return e
+}
+
+func TestMiddleware(t *testing.T) {
+	t.Run("allowed request is checked by its route", func(t *testing.T) {
+		c, fake := clienttest.Start(t, &ratelimitpb.RateLimitResponse{HttpStatusCode: 200, Limit: 10, Remaining: 9, LeaseId: "lease-1"})
+
+		req := httptest.NewRequest(http.MethodGet, "/users/42", nil)
+		req.RemoteAddr = "10.0.0.1:51234"
+		rec := httptest.NewRecorder()
+		newTestServer(c).ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "ok", rec.Body.String())
+		assert.Equal(t, "9", rec.Header().Get("rate-limit-remaining"))
+
+		checks := fake.Checks()
+		require.Len(t, checks, 1)
+		assert.Equal(t, "/users/:id", checks[0].GetEndpoint())
+		assert.Equal(t, "10.0.0.1", checks[0].GetIp())
+
+		releases := fake.Releases()
+		require.Len(t, releases, 1)
+		assert.Equal(t, "lease-1", releases[0].GetLeaseId())
+	})
+
+	t.Run("rejected request is rendered by the error handler", func(t *testing.T) {
+		c, fake := clienttest.Start(t, &ratelimitpb.RateLimitResponse{HttpStatusCode: 429, RetryAfter: 30})
+
+		rec := httptest.NewRecorder()
+		newTestServer(c).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/users/42", nil))
+
+		assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+		assert.Contains(t, rec.Body.String(), "Rate limit exceeded")
+		assert.Equal(t, "30", rec.Header().Get("retry-after"))
+		assert.Empty(t, fake.Releases())
+	})
+}
@@ -0,0 +1,38 @@
+// Package fibermw rate limits fiber v2 routes with RateShield
+package fibermw
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/x-sushant-x/RateShield/client"
+)
+
+// Middleware checks every request before the handler. Fiber only knows the route pattern of a request
+// once it reached the handler, so requests are checked by their path unless config.Endpoint is set.
+// Rejected requests get a *fiber.Error with the status of the check, so the error handler of the app
+// renders them.
+func Middleware(c *client.Client, config client.MiddlewareConfig) fiber.Handler {
+	guard := c.Guard(config)
+
+	return func(ctx *fiber.Ctx) error {
+		info := client.RequestInfo{
+			Method:     ctx.Method(),
+			Path:       ctx.Path(),
+			RemoteAddr: ctx.Context().RemoteAddr().String(),
+			Header: func(name string) string {
+				return ctx.Get(name)
+			},
+		}
+
+		decision := guard.Check(ctx.UserContext(), info)
+		for name, value := range decision.Headers {
+			ctx.Set(name, value)
+		}
+
+		if decision.Status != 0 {
+			return fiber.NewError(decision.Status, decision.Message)
+		}
+
+		defer guard.Done(decision)
+		return ctx.Next()
+	}
+}
@@ -0,0 +1,70 @@
+package fibermw
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/x-sushant-x/RateShield/client"
+	"github.com/x-sushant-x/RateShield/client/internal/clienttest"
+	"github.com/x-sushant-x/RateShield/proto/github.com/x-sushant-x/RateShield/ratelimitpb"
+)
+
+func newTestApp(c *client.Client, config client.MiddlewareConfig) *fiber.App {
+	app := fiber.New()
+	app.Get("/users/:id", Middleware(c, config), func(ctx *fiber.Ctx) error {
+		return ctx.SendString("ok")
+	})
+	return app
+}
+
+func TestMiddleware(t *testing.T) {
+	t.Run("allowed request is checked by its path", func(t *testing.T) {
+		c, fake := clienttest.Start(t, &ratelimitpb.RateLimitResponse{HttpStatusCode: 200, Limit: 10, Remaining: 9, LeaseId: "lease-1"})
+
+		resp, err := newTestApp(c, client.MiddlewareConfig{}).Test(httptest.NewRequest(http.MethodGet, "/users/42", nil))
+		require.NoError(t, err)
+		body, _ := io.ReadAll(resp.Body)
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "ok", string(body))
+		assert.Equal(t, "9", resp.Header.Get("rate-limit-remaining"))
+
+		checks := fake.Checks()
+		require.Len(t, checks, 1)
+		assert.Equal(t, "/users/42", checks[0].GetEndpoint())
+		assert.NotEmpty(t, checks[0].GetIp())
+
+		releases := fake.Releases()
+		require.Len(t, releases, 1)
+		assert.Equal(t, "lease-1", releases[0].GetLeaseId())
+	})
+
+	t.Run("endpoint from the config", func(t *testing.T) {
+		c, fake := clienttest.Start(t, &ratelimitpb.RateLimitResponse{HttpStatusCode: 200, Limit: 10, Remaining: 9})
+
+		config := client.MiddlewareConfig{Endpoint: func(req client.RequestInfo) string { return "/users/:id" }}
+		_, err := newTestApp(c, config).Test(httptest.NewRequest(http.MethodGet, "/users/42", nil))
+		require.NoError(t, err)
+
+		require.Len(t, fake.Checks(), 1)
+		assert.Equal(t, "/users/:id", fake.Checks()[0].GetEndpoint())
+	})
+
+	t.Run("rejected request is rendered by the error handler", func(t *testing.T) {
+		c, fake := clienttest.Start(t, &ratelimitpb.RateLimitResponse{HttpStatusCode: 429, RetryAfter: 30})
+
+		resp, err := newTestApp(c, client.MiddlewareConfig{}).Test(httptest.NewRequest(http.MethodGet, "/users/42", nil))
+		require.NoError(t, err)
+		body, _ := io.ReadAll(resp.Body)
+
+		assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+		assert.Equal(t, "Rate limit exceeded", string(body))
+		assert.Equal(t, "30", resp.Header.Get("retry-after"))
+		assert.Empty(t, fake.Releases())
+	})
+}
@@ -0,0 +1,33 @@
+// Package ginmw rate limits gin routes with RateShield. Routes are checked by their pattern, so
+// /users/:id needs a single rule instead of one per user.
+package ginmw
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/x-sushant-x/RateShield/client"
+)
+
+// Middleware checks every request before the handler. Rejected requests are aborted with the status of
+// the check and a plain text message.
+func Middleware(c *client.Client, config client.MiddlewareConfig) gin.HandlerFunc {
+	guard := c.Guard(config)
+
+	return func(ctx *gin.Context) {
+		info := client.HTTPRequestInfo(ctx.Request)
+		info.Route = ctx.FullPath()
+
+		decision := guard.Check(ctx.Request.Context(), info)
+		for name, value := range decision.Headers {
+			ctx.Header(name, value)
+		}
+
+		if decision.Status != 0 {
+			ctx.String(decision.Status, decision.Message)
+			ctx.Abort()
+			return
+		}
+
+		defer guard.Done(decision)
+		ctx.Next()
+	}
+}
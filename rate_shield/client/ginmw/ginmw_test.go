@@ -0,0 +1,60 @@
+package ginmw
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/x-sushant-x/RateShield/client"
+	"github.com/x-sushant-x/RateShield/client/internal/clienttest"
+	"github.com/x-sushant-x/RateShield/proto/github.com/x-sushant-x/RateShield/ratelimitpb"
+)
+
+func newTestRouter(c *client.Client) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.GET("/users/:id", Middleware(c, client.MiddlewareConfig{}), func(ctx *gin.Context) {
+		ctx.String(http.StatusOK, "ok")
+	})
+	return router
+}
+
+func TestMiddleware(t *testing.T) {
+	t.Run("allowed request is checked by its route", func(t *testing.T) {
+		c, fake := clienttest.Start(t, &ratelimitpb.RateLimitResponse{HttpStatusCode: 200, Limit: 10, Remaining: 9, LeaseId: "lease-1"})
+
+		req := httptest.NewRequest(http.MethodGet, "/users/42", nil)
+		req.RemoteAddr = "10.0.0.1:51234"
+		rec := httptest.NewRecorder()
+		newTestRouter(c).ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "ok", rec.Body.String())
+		assert.Equal(t, "9", rec.Header().Get("rate-limit-remaining"))
+
+		checks := fake.Checks()
+		require.Len(t, checks, 1)
+		assert.Equal(t, "/users/:id", checks[0].GetEndpoint())
+		assert.Equal(t, "10.0.0.1", checks[0].GetIp())
+
+		releases := fake.Releases()
+		require.Len(t, releases, 1)
+		assert.Equal(t, "lease-1", releases[0].GetLeaseId())
+	})
+
+	t.Run("rejected request does not reach the handler", func(t *testing.T) {
+		c, fake := clienttest.Start(t, &ratelimitpb.RateLimitResponse{HttpStatusCode: 429, RetryAfter: 30})
+
+		rec := httptest.NewRecorder()
+		newTestRouter(c).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/users/42", nil))
+
+		assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+		assert.Equal(t, "Rate limit exceeded", rec.Body.String())
+		assert.Equal(t, "30", rec.Header().Get("retry-after"))
+		assert.Empty(t, fake.Releases())
+	})
+}
@@ -0,0 +1,95 @@
+package client
+
+import (
+	"net"
+	"net/http"
+	"strings"
+)
+
+// RequestInfo is what identity extractors and endpoint functions see of an incoming request. Every
+// middleware fills it from the request of its framework.
+type RequestInfo struct {
+	Method     string
+	Path       string
+	Route      string // Route pattern such as /users/{id} when the framework knows it, empty otherwise
+	RemoteAddr string // host:port of the connection
+	Header     func(name string) string
+}
+
+// HTTPRequestInfo describes a net/http request
+func HTTPRequestInfo(r *http.Request) RequestInfo {
+	return RequestInfo{
+		Method:     r.Method,
+		Path:       r.URL.Path,
+		RemoteAddr: r.RemoteAddr,
+		Header:     r.Header.Get,
+	}
+}
+
+// Identity is the client a request is counted for
+type Identity struct {
+	IP       string
+	ClientID string
+	Tier     string
+}
+
+// IdentityExtractor tells which client sent a request
+type IdentityExtractor func(req RequestInfo) Identity
+
+// RemoteIP identifies clients by the IP of the connection
+func RemoteIP() IdentityExtractor {
+	return func(req RequestInfo) Identity {
+		return Identity{IP: hostOf(req.RemoteAddr)}
+	}
+}
+
+// ForwardedIP identifies clients by the first address in X-Forwarded-For, then X-Real-IP, then the IP of
+// the connection. Only use it behind a proxy that sets these headers, clients can send them too.
+func ForwardedIP() IdentityExtractor {
+	return func(req RequestInfo) Identity {
+		if forwarded := req.Header("X-Forwarded-For"); forwarded != "" {
+			first, _, _ := strings.Cut(forwarded, ",")
+			if ip := strings.TrimSpace(first); ip != "" {
+				return Identity{IP: ip}
+			}
+		}
+
+		if ip := strings.TrimSpace(req.Header("X-Real-IP")); ip != "" {
+			return Identity{IP: ip}
+		}
+
+		return Identity{IP: hostOf(req.RemoteAddr)}
+	}
+}
+
+// HeaderClientID counts clients by the ID in a header such as X-API-Key, the IP is still taken from ip
+// which defaults to RemoteIP. Requests without the header are counted by IP.
+func HeaderClientID(header string, ip IdentityExtractor) IdentityExtractor {
+	if ip == nil {
+		ip = RemoteIP()
+	}
+
+	return func(req RequestInfo) Identity {
+		identity := ip(req)
+		identity.ClientID = req.Header(header)
+		return identity
+	}
+}
+
+// WithTierHeader adds the tier from a header, for example one set by an authenticating proxy, to the
+// identity of identify
+func WithTierHeader(header string, identify IdentityExtractor) IdentityExtractor {
+	return func(req RequestInfo) Identity {
+		identity := identify(req)
+		identity.Tier = req.Header(header)
+		return identity
+	}
+}
+
+func hostOf(addr string) string {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return addr
+	}
+	return host
+}
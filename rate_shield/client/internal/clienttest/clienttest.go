@@ -0,0 +1,86 @@
+// Package clienttest serves a fake RateShield for the tests of the framework middlewares
+package clienttest
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/x-sushant-x/RateShield/client"
+	"github.com/x-sushant-x/RateShield/proto/github.com/x-sushant-x/RateShield/ratelimitpb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// FakeRateShield answers every check with the same response and records the requests
+type FakeRateShield struct {
+	ratelimitpb.UnimplementedRateLimitServiceServer
+
+	mutex    sync.Mutex
+	response *ratelimitpb.RateLimitResponse
+	checks   []*ratelimitpb.RateLimitRequest
+	releases []*ratelimitpb.ReleaseLeaseRequest
+}
+
+func (f *FakeRateShield) CheckRateLimit(ctx context.Context, req *ratelimitpb.RateLimitRequest) (*ratelimitpb.RateLimitResponse, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.checks = append(f.checks, req)
+	return f.response, nil
+}
+
+func (f *FakeRateShield) ReleaseLease(ctx context.Context, req *ratelimitpb.ReleaseLeaseRequest) (*ratelimitpb.ReleaseLeaseResponse, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.releases = append(f.releases, req)
+	return &ratelimitpb.ReleaseLeaseResponse{HttpStatusCode: http.StatusOK}, nil
+}
+
+// Checks returns the checks received so far
+func (f *FakeRateShield) Checks() []*ratelimitpb.RateLimitRequest {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	return append([]*ratelimitpb.RateLimitRequest{}, f.checks...)
+}
+
+// Releases returns the lease releases received so far
+func (f *FakeRateShield) Releases() []*ratelimitpb.ReleaseLeaseRequest {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	return append([]*ratelimitpb.ReleaseLeaseRequest{}, f.releases...)
+}
+
+// Start serves a fake answering every check with response over an in-memory listener and returns a client
+// connected to it
+func Start(t *testing.T, response *ratelimitpb.RateLimitResponse) (*client.Client, *FakeRateShield) {
+	fake := &FakeRateShield{response: response}
+
+	listener := bufconn.Listen(1 << 20)
+	server := grpc.NewServer()
+	ratelimitpb.RegisterRateLimitServiceServer(server, fake)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	c, err := client.New(client.Config{
+		Address: "passthrough:///bufnet",
+		DialOptions: []grpc.DialOption{
+			grpc.WithTransportCredentials(insecure.NewCredentials()),
+			grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+				return listener.DialContext(ctx)
+			}),
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { c.Close() })
+
+	return c, fake
+}
@@ -0,0 +1,150 @@
+package client
+
+import (
+	"context"
+	"net/http"
+)
+
+// MiddlewareConfig tells the middleware how to identify clients and endpoints. Every field is optional.
+type MiddlewareConfig struct {
+	// Identity of the client, defaults to RemoteIP
+	Identity IdentityExtractor
+
+	// Endpoint the request is checked against, defaults to the route pattern when the framework knows it
+	// and to the path otherwise. The endpoint has to match the endpoint of a RateShield rule.
+	Endpoint func(req RequestInfo) string
+
+	// Skip lets requests through without a check, for example health checks
+	Skip func(req RequestInfo) bool
+}
+
+// Decision is the outcome of checking a request in a middleware
+type Decision struct {
+	Request Request
+	Result  Result
+
+	// Status and Message of the response when the request is rejected, Status is 0 when the request may
+	// continue
+	Status  int
+	Message string
+
+	// Headers to set on the response whether the request is rejected or not
+	Headers map[string]string
+}
+
+// Guard is the framework independent part of the middleware. The middleware of every framework calls
+// Check before the handler and Done after it.
+type Guard struct {
+	client *Client
+	config MiddlewareConfig
+}
+
+// Guard creates the framework independent part of a middleware, to add RateShield to frameworks without
+// a middleware in this module
+func (c *Client) Guard(config MiddlewareConfig) *Guard {
+	if config.Identity == nil {
+		config.Identity = RemoteIP()
+	}
+
+	return &Guard{
+		client: c,
+		config: config,
+	}
+}
+
+// Check checks the request. A request that is skipped, allowed or allowed because the check failed open
+// gets a Decision with Status 0.
+func (g *Guard) Check(ctx context.Context, req RequestInfo) Decision {
+	if g.config.Skip != nil && g.config.Skip(req) {
+		return Decision{}
+	}
+
+	identity := g.config.Identity(req)
+
+	endpoint := req.Route
+	if g.config.Endpoint != nil {
+		endpoint = g.config.Endpoint(req)
+	} else if endpoint == "" {
+		endpoint = req.Path
+	}
+
+	request := Request{
+		IP:       identity.IP,
+		Endpoint: endpoint,
+		ClientID: identity.ClientID,
+		Tier:     identity.Tier,
+	}
+
+	result, err := g.client.Check(ctx, request)
+	if err != nil {
+		return Decision{
+			Request: request,
+			Status:  http.StatusServiceUnavailable,
+			Message: "Rate limit check failed",
+		}
+	}
+
+	decision := Decision{
+		Request: request,
+		Result:  result,
+		Headers: result.Headers(),
+	}
+
+	if !result.Allowed {
+		decision.Status = result.StatusCode
+		decision.Message = rejectionMessage(result.StatusCode)
+	}
+
+	return decision
+}
+
+// Done releases the lease of an allowed CONCURRENCY check, call it once the handler returned
+func (g *Guard) Done(decision Decision) {
+	if decision.Status != 0 || decision.Result.LeaseID == "" {
+		return
+	}
+
+	// The request context may already be cancelled, the lease still has to be returned
+	err := g.client.Release(context.Background(), decision.Request, decision.Result.LeaseID)
+	if err != nil && g.client.config.OnError != nil {
+		g.client.config.OnError(err)
+	}
+}
+
+func rejectionMessage(status int) string {
+	switch status {
+	case http.StatusTooManyRequests:
+		return "Rate limit exceeded"
+	case http.StatusForbidden:
+		return "Access denied"
+	}
+	return http.StatusText(status)
+}
+
+// Middleware rate limits net/http handlers. It also works with routers built on net/http such as chi,
+// use the chimw package to check chi routes by their pattern.
+func (c *Client) Middleware(config MiddlewareConfig) func(http.Handler) http.Handler {
+	guard := c.Guard(config)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			decision := guard.Check(r.Context(), HTTPRequestInfo(r))
+			ServeDecision(w, r, decision, next)
+			guard.Done(decision)
+		})
+	}
+}
+
+// ServeDecision writes the rate limit headers and either the rejection or the response of next
+func ServeDecision(w http.ResponseWriter, r *http.Request, decision Decision, next http.Handler) {
+	for name, value := range decision.Headers {
+		w.Header().Set(name, value)
+	}
+
+	if decision.Status != 0 {
+		http.Error(w, decision.Message, decision.Status)
+		return
+	}
+
+	next.ServeHTTP(w, r)
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/x-sushant-x/RateShield/models"
+)
+
+func runAlerts(c cli, args []string) error {
+	if len(args) == 0 {
+		return errors.New("usage: rsctl alerts <list|save|delete>")
+	}
+
+	switch args[0] {
+	case "list":
+		return alertsList(c)
+	case "save":
+		return alertsSave(c, args[1:])
+	case "delete":
+		return alertsDelete(c, args[1:])
+	}
+
+	return fmt.Errorf("unknown alerts command %q", args[0])
+}
+
+func alertsList(c cli) error {
+	var alerts []models.AlertRule
+	if err := c.api.get("/alerts/rules", nil, &alerts); err != nil {
+		return err
+	}
+
+	if c.output == outputJSON {
+		return printJSON(alerts)
+	}
+
+	if len(alerts) == 0 {
+		fmt.Println("No alert rules")
+		return nil
+	}
+
+	rows := [][]string{{"NAME", "TYPE", "ENDPOINT", "CONDITION", "SEVERITY", "CHANNELS", "COOLDOWN"}}
+	for _, alert := range alerts {
+		endpoint := alert.Endpoint
+		if endpoint == "" {
+			endpoint = "*"
+		}
+
+		channels := strings.Join(alert.Channels, ",")
+		if channels == "" {
+			channels = "all"
+		}
+
+		rows = append(rows, []string{
+			alert.Name,
+			alert.Type,
+			endpoint,
+			describeAlertCondition(alert),
+			alert.Severity,
+			channels,
+			strconv.Itoa(alert.Cooldown) + "s",
+		})
+	}
+
+	printTable(rows)
+	return nil
+}
+
+func describeAlertCondition(alert models.AlertRule) string {
+	switch alert.Type {
+	case models.AlertTypeDenyRate:
+		return fmt.Sprintf(">= %g%% denied in %ds, min %d requests", alert.Threshold, alert.Window, alert.MinRequests)
+	case models.AlertTypeThrottledClient:
+		return fmt.Sprintf("client denied for %ds", alert.Duration)
+	case models.AlertTypeQuotaExhaustion:
+		return fmt.Sprintf("%g%% of quota used", alert.Threshold)
+	case models.AlertTypeRedisFallback:
+		return "allow_on_error used"
+	}
+	return "-"
+}
+
+// alertsSave creates or replaces the alert rule in a JSON file
+func alertsSave(c cli, args []string) error {
+	flags := flag.NewFlagSet("alerts save", flag.ContinueOnError)
+	file := flags.String("f", "", "alert rule in JSON (required)")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if *file == "" {
+		return errors.New("usage: rsctl alerts save -f <file>")
+	}
+
+	data, err := os.ReadFile(*file)
+	if err != nil {
+		return err
+	}
+
+	var alert models.AlertRule
+	if err := json.Unmarshal(data, &alert); err != nil {
+		return fmt.Errorf("unable to parse %s: %w", *file, err)
+	}
+
+	var saved models.AlertRule
+	if err := c.api.post("/alerts/rules/save", nil, alert, &saved); err != nil {
+		return err
+	}
+
+	fmt.Printf("Alert rule %s saved\n", saved.Name)
+	return nil
+}
+
+func alertsDelete(c cli, args []string) error {
+	if len(args) != 1 {
+		return errors.New("usage: rsctl alerts delete <name>")
+	}
+
+	if err := c.api.post("/alerts/rules/delete", nil, models.AlertRuleNameDTO{Name: args[0]}, nil); err != nil {
+		return err
+	}
+
+	fmt.Printf("Alert rule %s deleted\n", args[0])
+	return nil
+}
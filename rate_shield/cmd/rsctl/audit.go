@@ -0,0 +1,128 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/x-sushant-x/RateShield/models"
+)
+
+func runAudit(c cli, args []string) error {
+	if len(args) == 0 || args[0] != "tail" {
+		return errors.New("usage: rsctl audit tail [-n 20] [-f]")
+	}
+
+	flags := flag.NewFlagSet("audit tail", flag.ContinueOnError)
+	count := flags.Int("n", 20, "number of recent entries to show")
+	follow := flags.Bool("f", false, "keep polling for new entries")
+	interval := flags.Duration("interval", 2*time.Second, "how often to poll when following")
+	if err := flags.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	if *count <= 0 {
+		return errors.New("-n must be greater than 0")
+	}
+
+	seen := map[string]bool{}
+	printHeader := c.output == outputTable
+
+	for {
+		logs, err := recentAuditLogs(c, *count)
+		if err != nil {
+			return err
+		}
+
+		// Logs come newest first, print them in the order they happened
+		newLogs := []models.AuditLog{}
+		for i := len(logs) - 1; i >= 0; i-- {
+			if seen[logs[i].ID] {
+				continue
+			}
+			seen[logs[i].ID] = true
+			newLogs = append(newLogs, logs[i])
+		}
+
+		if err := printAuditLogs(c, newLogs, printHeader); err != nil {
+			return err
+		}
+		if len(newLogs) > 0 {
+			printHeader = false
+		}
+
+		if !*follow {
+			return nil
+		}
+		time.Sleep(*interval)
+	}
+}
+
+func recentAuditLogs(c cli, count int) ([]models.AuditLog, error) {
+	var page models.PaginatedAuditLogs
+
+	query := url.Values{
+		"page":  {"1"},
+		"items": {strconv.Itoa(count)},
+	}
+
+	if err := c.api.get("/audit/logs", query, &page); err != nil {
+		return nil, err
+	}
+
+	return page.Logs, nil
+}
+
+func printAuditLogs(c cli, logs []models.AuditLog, header bool) error {
+	if c.output == outputJSON {
+		// One object per line so the output can be piped while following
+		for _, auditLog := range logs {
+			if err := printJSONLine(auditLog); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	rows := [][]string{}
+	if header {
+		rows = append(rows, []string{"TIME", "ACTOR", "ACTION", "ENDPOINT", "DETAILS"})
+	}
+
+	for _, auditLog := range logs {
+		rows = append(rows, []string{
+			formatTimestamp(auditLog.Timestamp),
+			auditLog.Actor,
+			auditLog.Action,
+			auditLog.Endpoint,
+			describeAuditLog(auditLog),
+		})
+	}
+
+	if len(rows) > 0 {
+		printTable(rows)
+	}
+	return nil
+}
+
+func describeAuditLog(auditLog models.AuditLog) string {
+	if auditLog.Details != "" && auditLog.ClientIP != "" {
+		return fmt.Sprintf("%s (client %s)", auditLog.Details, auditLog.ClientIP)
+	}
+	if auditLog.Details != "" {
+		return auditLog.Details
+	}
+
+	switch {
+	case auditLog.OldRule != nil && auditLog.NewRule != nil:
+		return fmt.Sprintf("%s -> %s", describeLimit(*auditLog.OldRule), describeLimit(*auditLog.NewRule))
+	case auditLog.NewRule != nil:
+		return describeLimit(*auditLog.NewRule)
+	case auditLog.OldRule != nil:
+		return "was " + describeLimit(*auditLog.OldRule)
+	}
+	return "-"
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// apiClient calls the RateShield HTTP API
+type apiClient struct {
+	baseURL string
+	actor   string
+	http    *http.Client
+}
+
+// apiResponse is the envelope every RateShield API response is wrapped in
+type apiResponse struct {
+	Status  string          `json:"status"`
+	Data    json.RawMessage `json:"data"`
+	Error   string          `json:"error"`
+	Message string          `json:"message"`
+	Details []struct {
+		Field   string `json:"field"`
+		Message string `json:"message"`
+	} `json:"details"`
+}
+
+func newAPIClient(baseURL, actor string) apiClient {
+	return apiClient{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		actor:   actor,
+		http:    &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+func (c apiClient) get(path string, query url.Values, out interface{}) error {
+	return c.call(http.MethodGet, path, query, nil, "", out)
+}
+
+func (c apiClient) post(path string, query url.Values, body interface{}, out interface{}) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	return c.call(http.MethodPost, path, query, strings.NewReader(string(data)), "application/json", out)
+}
+
+// call sends a request and decodes the data field of the response into out, which may be nil
+func (c apiClient) call(method, path string, query url.Values, body io.Reader, contentType string, out interface{}) error {
+	resp, err := c.send(method, path, query, body, contentType, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	var envelope apiResponse
+	if err := json.Unmarshal(raw, &envelope); err != nil {
+		return fmt.Errorf("unexpected response from server (HTTP %d): %s", resp.StatusCode, strings.TrimSpace(string(raw)))
+	}
+
+	if resp.StatusCode != http.StatusOK || envelope.Status != "success" {
+		return envelope.asError(resp.StatusCode)
+	}
+
+	if out == nil || len(envelope.Data) == 0 {
+		return nil
+	}
+
+	return json.Unmarshal(envelope.Data, out)
+}
+
+// send sends a request and returns the raw response, the caller must close its body
+func (c apiClient) send(method, path string, query url.Values, body io.Reader, contentType string, headers map[string]string) (*http.Response, error) {
+	target := c.baseURL + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequest(method, target, body)
+	if err != nil {
+		return nil, err
+	}
+
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if c.actor != "" {
+		req.Header.Set("X-User-ID", c.actor)
+	}
+	req.Header.Set("User-Agent", "rsctl")
+
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("unable to reach RateShield at %s: %w", c.baseURL, err)
+	}
+
+	return resp, nil
+}
+
+func (r apiResponse) asError(statusCode int) error {
+	message := r.Error
+	if message == "" {
+		message = http.StatusText(statusCode)
+	}
+	if r.Message != "" {
+		message += ": " + r.Message
+	}
+
+	for _, detail := range r.Details {
+		message += fmt.Sprintf("\n  %s: %s", detail.Field, detail.Message)
+	}
+
+	return fmt.Errorf("HTTP %d %s", statusCode, message)
+}
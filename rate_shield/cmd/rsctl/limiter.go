@@ -0,0 +1,291 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/x-sushant-x/RateShield/models"
+)
+
+func runLimiter(c cli, args []string) error {
+	if len(args) == 0 {
+		return errors.New("usage: rsctl limiter <state|reset|lift> --ip <ip> --endpoint <endpoint>")
+	}
+
+	if args[0] == "bans" {
+		return listBans(c, args[1:])
+	}
+
+	flags := flag.NewFlagSet("limiter "+args[0], flag.ContinueOnError)
+	ip := flags.String("ip", "", "client IP address (required)")
+	endpoint := flags.String("endpoint", "", "API endpoint (required)")
+	if err := flags.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	if *ip == "" || *endpoint == "" {
+		return errors.New("--ip and --endpoint are required")
+	}
+
+	switch args[0] {
+	case "state":
+		return limiterState(c, *ip, *endpoint)
+	case "reset":
+		dto := models.LimiterStateDTO{ClientIP: *ip, Endpoint: *endpoint}
+		if err := c.api.post("/limiter/reset", nil, dto, nil); err != nil {
+			return err
+		}
+		fmt.Printf("Limiter state of %s on %s reset\n", *ip, *endpoint)
+		return nil
+	case "lift":
+		dto := models.LimiterStateDTO{ClientIP: *ip, Endpoint: *endpoint}
+		if err := c.api.post("/limiter/bans/lift", nil, dto, nil); err != nil {
+			return err
+		}
+		fmt.Printf("Ban of %s on %s lifted\n", *ip, *endpoint)
+		return nil
+	}
+
+	return fmt.Errorf("unknown limiter command %q", args[0])
+}
+
+func limiterState(c cli, ip, endpoint string) error {
+	var state models.LimiterState
+	if err := c.api.get("/limiter/state", url.Values{"ip": {ip}, "endpoint": {endpoint}}, &state); err != nil {
+		return err
+	}
+
+	if c.output == outputJSON {
+		return printJSON(state)
+	}
+
+	rows := [][]string{{"STRATEGY", "USED", "LIMIT", "REMAINING", "DETAILS"}}
+
+	if bucket := state.TokenBucket; bucket != nil {
+		rows = append(rows, []string{
+			models.StrategyTokenBucket,
+			strconv.Itoa(max(bucket.Capacity-bucket.AvailableTokens, 0)), // Granted tokens go above the capacity
+			strconv.Itoa(bucket.Capacity),
+			strconv.Itoa(bucket.AvailableTokens),
+			"last refill " + bucket.LastRefill.Format("2006-01-02T15:04:05Z07:00"),
+		})
+	}
+
+	if window := state.FixedWindow; window != nil {
+		rows = append(rows, []string{
+			models.StrategyFixedWindowCounter,
+			strconv.FormatInt(max(window.CurrRequests, 0), 10), // Granted requests go below 0
+			strconv.FormatInt(window.MaxRequests, 10),
+			strconv.FormatInt(window.MaxRequests-window.CurrRequests, 10),
+			"window started " + formatTimestamp(window.CreatedAt),
+		})
+	}
+
+	if window := state.SlidingWindow; window != nil {
+		rows = append(rows, []string{
+			models.StrategySlidingWindowCounter,
+			strconv.FormatInt(window.ActiveRequests, 10),
+			strconv.FormatInt(window.MaxRequests, 10),
+			strconv.FormatInt(max(window.MaxRequests-window.ActiveRequests, 0), 10),
+			fmt.Sprintf("%ds window", window.WindowSize),
+		})
+	}
+
+	if quota := state.Quota; quota != nil {
+		rows = append(rows, []string{
+			models.StrategyQuota,
+			strconv.FormatInt(quota.Used, 10),
+			strconv.FormatInt(quota.Limit, 10),
+			strconv.FormatInt(quota.Remaining, 10),
+			"resets " + formatTimestamp(quota.ResetsAt),
+		})
+	}
+
+	if concurrency := state.Concurrency; concurrency != nil {
+		rows = append(rows, []string{
+			models.StrategyConcurrency,
+			strconv.FormatInt(concurrency.ActiveLeases, 10),
+			strconv.FormatInt(concurrency.MaxConcurrent, 10),
+			strconv.FormatInt(max(concurrency.MaxConcurrent-concurrency.ActiveLeases, 0), 10),
+			fmt.Sprintf("leases expire after %ds", concurrency.LeaseTimeout),
+		})
+	}
+
+	fmt.Printf("Client %s on %s (rule strategy: %s)\n\n", state.ClientIP, state.Endpoint, valueOrDash(state.Strategy))
+
+	if len(rows) == 1 {
+		fmt.Println("No limiter state stored for this client")
+		return nil
+	}
+
+	printTable(rows)
+	return nil
+}
+
+func listBans(c cli, args []string) error {
+	flags := flag.NewFlagSet("limiter bans", flag.ContinueOnError)
+	endpoint := flags.String("endpoint", "", "only list bans on this endpoint")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	bans := []models.PenaltyBan{}
+	cursor := ""
+
+	for {
+		query := url.Values{"count": {"1000"}}
+		if *endpoint != "" {
+			query.Set("endpoint", *endpoint)
+		}
+		if cursor != "" {
+			query.Set("cursor", cursor)
+		}
+
+		var page models.PaginatedPenaltyBans
+		if err := c.api.get("/limiter/bans", query, &page); err != nil {
+			return err
+		}
+
+		bans = append(bans, page.Bans...)
+		if !page.HasNextPage {
+			break
+		}
+		cursor = page.NextCursor
+	}
+
+	if c.output == outputJSON {
+		return printJSON(bans)
+	}
+
+	if len(bans) == 0 {
+		fmt.Println("No client is banned")
+		return nil
+	}
+
+	rows := [][]string{{"CLIENT", "ENDPOINT", "OFFENSE", "BANNED AT", "EXPIRES AT"}}
+	for _, ban := range bans {
+		rows = append(rows, []string{
+			ban.ClientIP,
+			ban.Endpoint,
+			strconv.FormatInt(ban.Offense, 10),
+			formatTimestamp(ban.BannedAt),
+			formatTimestamp(ban.ExpiresAt),
+		})
+	}
+
+	printTable(rows)
+	return nil
+}
+
+func runCheck(c cli, args []string) error {
+	flags := flag.NewFlagSet("check", flag.ContinueOnError)
+	ip := flags.String("ip", "", "client IP address (required)")
+	endpoint := flags.String("endpoint", "", "API endpoint (required)")
+	clientID := flags.String("client-id", "", "client ID to count the request by instead of the IP")
+	tier := flags.String("tier", "", "tier of the client, looked up on the server when empty")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if *ip == "" || *endpoint == "" {
+		return errors.New("usage: rsctl check --ip <ip> --endpoint <endpoint>")
+	}
+
+	headers := map[string]string{
+		"ip":       *ip,
+		"endpoint": *endpoint,
+	}
+	if *clientID != "" {
+		headers["client-id"] = *clientID
+	}
+	if *tier != "" {
+		headers["tier"] = *tier
+	}
+
+	resp, err := c.api.send(http.MethodGet, "/check-limit", nil, nil, "", headers)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	result := map[string]interface{}{
+		"status_code": resp.StatusCode,
+		"allowed":     resp.StatusCode == http.StatusOK,
+	}
+	if limit := resp.Header.Get("rate-limit"); limit != "" {
+		result["rate_limit"] = limit
+	}
+	if remaining := resp.Header.Get("rate-limit-remaining"); remaining != "" {
+		result["rate_limit_remaining"] = remaining
+	}
+	if reason := resp.Header.Get("rate-limit-reason"); reason != "" {
+		result["reason"] = reason
+	}
+	if leaseID := resp.Header.Get("rate-limit-lease-id"); leaseID != "" {
+		result["lease_id"] = leaseID
+	}
+	if retryAfter := resp.Header.Get("retry-after"); retryAfter != "" {
+		result["retry_after"] = retryAfter
+	}
+
+	if c.output == outputJSON {
+		return printJSON(result)
+	}
+
+	rows := [][]string{
+		{"FIELD", "VALUE"},
+		{"Status", fmt.Sprintf("%d %s", resp.StatusCode, http.StatusText(resp.StatusCode))},
+		{"Allowed", strconv.FormatBool(resp.StatusCode == http.StatusOK)},
+		{"Rate Limit", valueOrDash(resp.Header.Get("rate-limit"))},
+		{"Remaining", valueOrDash(resp.Header.Get("rate-limit-remaining"))},
+		{"Reason", valueOrDash(resp.Header.Get("rate-limit-reason"))},
+		{"Lease ID", valueOrDash(resp.Header.Get("rate-limit-lease-id"))},
+		{"Retry After", valueOrDash(resp.Header.Get("retry-after"))},
+	}
+	printTable(rows)
+	return nil
+}
+
+func runRelease(c cli, args []string) error {
+	flags := flag.NewFlagSet("release", flag.ContinueOnError)
+	ip := flags.String("ip", "", "client IP address (required)")
+	endpoint := flags.String("endpoint", "", "API endpoint (required)")
+	clientID := flags.String("client-id", "", "client ID the lease was acquired with")
+	leaseID := flags.String("lease-id", "", "lease to release (required)")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if *ip == "" || *endpoint == "" || *leaseID == "" {
+		return errors.New("usage: rsctl release --ip <ip> --endpoint <endpoint> --lease-id <lease id>")
+	}
+
+	headers := map[string]string{
+		"ip":       *ip,
+		"endpoint": *endpoint,
+		"lease-id": *leaseID,
+	}
+	if *clientID != "" {
+		headers["client-id"] = *clientID
+	}
+
+	resp, err := c.api.send(http.MethodPost, "/release-lease", nil, nil, "", headers)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		fmt.Printf("Lease %s released\n", *leaseID)
+		return nil
+	case http.StatusNotFound:
+		return errors.New("lease not found, it was already released or has expired")
+	}
+
+	return fmt.Errorf("unable to release lease: %d %s", resp.StatusCode, http.StatusText(resp.StatusCode))
+}
@@ -0,0 +1,147 @@
+// rsctl is a command line client for the RateShield HTTP API.
+//
+//	rsctl [--server URL] [-o table|json] [--user ID] <command> [arguments]
+//
+// The server defaults to $RSCTL_SERVER or http://localhost:8080 and the user recorded in the audit log to
+// $RSCTL_USER.
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+)
+
+const usage = `Usage: rsctl [--server URL] [-o table|json] [--user ID] <command> [arguments]
+
+Commands:
+  rules list [--search TEXT]                     List rules
+  rules get <endpoint>                           Show a rule
+  rules apply -f FILE [--mode upsert|replace]    Create, update (and with replace delete) rules to match a file
+              [--dry-run]
+  rules diff -f FILE [--mode upsert|replace]     Show what rules apply would change
+             [--exit-code]
+  rules delete <endpoint>                        Delete a rule
+  rules export [--format yaml|json]              Print every rule in the rules file layout
+  audit tail [-n 20] [-f] [--interval 2s]        Show the latest audit log entries, -f keeps following
+  limiter state --ip IP --endpoint ENDPOINT      Show the limiter state of a client
+  limiter reset --ip IP --endpoint ENDPOINT      Reset the limiter state of a client
+  limiter bans [--endpoint ENDPOINT]             List clients banned by penalty policies
+  limiter lift --ip IP --endpoint ENDPOINT       Lift the ban of a client
+  quota usage --identity ID                      Show the quota a client used this period
+              [--endpoint ENDPOINT] [--tier TIER]
+  alerts list                                    List alert rules
+  alerts save -f FILE                            Create or replace the alert rule in a JSON file
+  alerts delete <name>                           Delete an alert rule
+  webhooks list                                  List the webhooks told about rule changes
+  webhooks save -f FILE                          Create or replace the rule webhook in a JSON file
+  webhooks delete <name>                         Delete a rule webhook
+  webhooks deliveries [-n 20] [--webhook NAME]   Show the latest rule change deliveries
+                      [--status STATUS]
+  webhooks redeliver <delivery id>               Send the event of a delivery again
+  check --ip IP --endpoint ENDPOINT              Run a rate limit check as the client would
+        [--client-id ID] [--tier TIER]
+  release --ip IP --endpoint ENDPOINT            Release a lease acquired by a CONCURRENCY check
+          --lease-id ID [--client-id ID]
+
+Flags:
+`
+
+var (
+	errDifferencesFound = errors.New("rules differ from the server")
+)
+
+// cli holds the settings shared by every command
+type cli struct {
+	api    apiClient
+	output string
+}
+
+func main() {
+	os.Exit(run(os.Args[1:]))
+}
+
+func run(args []string) int {
+	flags := flag.NewFlagSet("rsctl", flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprint(flags.Output(), usage)
+		flags.PrintDefaults()
+	}
+
+	server := flags.String("server", envOrDefault("RSCTL_SERVER", "http://localhost:8080"), "RateShield API address")
+	output := flags.String("o", outputTable, "output format, table or json")
+	user := flags.String("user", os.Getenv("RSCTL_USER"), "user recorded in the audit log for changes")
+
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	if *output != outputTable && *output != outputJSON {
+		fmt.Fprintln(os.Stderr, "-o must be table or json")
+		return 2
+	}
+
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return 2
+	}
+
+	c := cli{
+		api:    newAPIClient(*server, *user),
+		output: *output,
+	}
+
+	command, commandArgs := flags.Arg(0), flags.Args()[1:]
+
+	var err error
+	switch command {
+	case "rules":
+		err = runRules(c, commandArgs)
+	case "audit":
+		err = runAudit(c, commandArgs)
+	case "limiter":
+		err = runLimiter(c, commandArgs)
+	case "quota":
+		err = runQuota(c, commandArgs)
+	case "alerts":
+		err = runAlerts(c, commandArgs)
+	case "webhooks":
+		err = runWebhooks(c, commandArgs)
+	case "check":
+		err = runCheck(c, commandArgs)
+	case "release":
+		err = runRelease(c, commandArgs)
+	case "help":
+		flags.Usage()
+		return 0
+	default:
+		err = fmt.Errorf("unknown command %q, run rsctl help", command)
+	}
+
+	if errors.Is(err, errDifferencesFound) {
+		return 1
+	}
+	if errors.Is(err, flag.ErrHelp) {
+		return 0
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		return 1
+	}
+
+	return 0
+}
+
+// printJSONLine writes a value as compact JSON on a single line
+func printJSONLine(value interface{}) error {
+	return json.NewEncoder(os.Stdout).Encode(value)
+}
+
+func envOrDefault(name, fallback string) string {
+	if value := os.Getenv(name); value != "" {
+		return value
+	}
+	return fallback
+}
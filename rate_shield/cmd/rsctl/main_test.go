@@ -0,0 +1,427 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/x-sushant-x/RateShield/models"
+)
+
+// fakeServer answers like the RateShield API and records the requests it got
+type fakeServer struct {
+	*httptest.Server
+	requests []*http.Request
+	bodies   []string
+}
+
+func newFakeServer(t *testing.T, handler func(w http.ResponseWriter, r *http.Request)) *fakeServer {
+	server := &fakeServer{}
+	server.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		server.requests = append(server.requests, r)
+		server.bodies = append(server.bodies, string(body))
+		handler(w, r)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func writeSuccess(w http.ResponseWriter, data interface{}) {
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{"status": "success", "data": data})
+}
+
+// runCLI runs rsctl against a server and returns its exit code, stdout and stderr
+func runCLI(t *testing.T, server string, args ...string) (int, string, string) {
+	stdout, err := os.CreateTemp(t.TempDir(), "stdout")
+	require.NoError(t, err)
+	stderr, err := os.CreateTemp(t.TempDir(), "stderr")
+	require.NoError(t, err)
+
+	originalStdout, originalStderr := os.Stdout, os.Stderr
+	os.Stdout, os.Stderr = stdout, stderr
+	defer func() {
+		os.Stdout, os.Stderr = originalStdout, originalStderr
+	}()
+
+	code := run(append([]string{"--server", server}, args...))
+
+	out, err := os.ReadFile(stdout.Name())
+	require.NoError(t, err)
+	errOut, err := os.ReadFile(stderr.Name())
+	require.NoError(t, err)
+
+	return code, string(out), string(errOut)
+}
+
+var testRules = []models.Rule{
+	{
+		APIEndpoint:            "/api/v1/search",
+		HTTPMethod:             "GET",
+		Strategy:               models.StrategyFixedWindowCounter,
+		FixedWindowCounterRule: &models.FixedWindowCounterRule{MaxRequests: 100, Window: 60},
+		Version:                3,
+	},
+	{
+		APIEndpoint:     "/api/v1/users",
+		Strategy:        models.StrategyTokenBucket,
+		TokenBucketRule: &models.TokenBucketRule{BucketCapacity: 10, TokenAddRate: 2},
+		Version:         1,
+		ManagedBy:       "rules-file",
+		ReadOnly:        true,
+	},
+}
+
+func TestRunArguments(t *testing.T) {
+	server := newFakeServer(t, func(w http.ResponseWriter, r *http.Request) {
+		writeSuccess(w, testRules)
+	})
+
+	tests := []struct {
+		name   string
+		args   []string
+		code   int
+		stderr string
+	}{
+		{"no command", nil, 2, "Usage: rsctl"},
+		{"help", []string{"help"}, 0, "Usage: rsctl"},
+		{"unknown flag", []string{"--verbose", "rules", "list"}, 2, "flag provided but not defined"},
+		{"invalid output", []string{"-o", "yaml", "rules", "list"}, 2, "-o must be table or json"},
+		{"unknown command", []string{"status"}, 1, `unknown command "status"`},
+		{"rules without command", []string{"rules"}, 1, "usage: rsctl rules"},
+		{"unknown rules command", []string{"rules", "rename"}, 1, `unknown rules command "rename"`},
+		{"rules get without endpoint", []string{"rules", "get"}, 1, "usage: rsctl rules get"},
+		{"rules delete with two endpoints", []string{"rules", "delete", "/a", "/b"}, 1, "usage: rsctl rules delete"},
+		{"rules apply without file", []string{"rules", "apply"}, 1, "usage: rsctl rules apply -f"},
+		{"rules apply with missing file", []string{"rules", "apply", "-f", "missing.yaml"}, 1, "no such file"},
+		{"subcommand help", []string{"rules", "list", "-h"}, 0, "-search"},
+		{"audit without tail", []string{"audit"}, 1, "usage: rsctl audit tail"},
+		{"audit tail with invalid count", []string{"audit", "tail", "-n", "0"}, 1, "-n must be greater than 0"},
+		{"limiter without command", []string{"limiter"}, 1, "usage: rsctl limiter"},
+		{"limiter state without ip", []string{"limiter", "state", "--endpoint", "/api"}, 1, "--ip and --endpoint are required"},
+		{"unknown limiter command", []string{"limiter", "purge", "--ip", "1.2.3.4", "--endpoint", "/api"}, 1, `unknown limiter command "purge"`},
+		{"check without endpoint", []string{"check", "--ip", "1.2.3.4"}, 1, "usage: rsctl check"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, _, stderr := runCLI(t, server.URL, tt.args...)
+
+			assert.Equal(t, tt.code, code)
+			assert.Contains(t, stderr, tt.stderr)
+		})
+	}
+}
+
+func TestRunServerErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler func(w http.ResponseWriter, r *http.Request)
+		stderr  string
+	}{
+		{
+			name: "error response",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusNotFound)
+				w.Write([]byte(`{"status": "fail", "error": "Rule not found"}`))
+			},
+			stderr: "Error: HTTP 404 Rule not found",
+		},
+		{
+			name: "validation details",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte(`{"status": "fail", "error": "Invalid Rule", "details": [{"field": "strategy", "message": "is required"}]}`))
+			},
+			stderr: "HTTP 400 Invalid Rule\n  strategy: is required",
+		},
+		{
+			name: "not json",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusBadGateway)
+				w.Write([]byte("bad gateway"))
+			},
+			stderr: "unexpected response from server (HTTP 502): bad gateway",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newFakeServer(t, tt.handler)
+
+			code, stdout, stderr := runCLI(t, server.URL, "rules", "get", "/api/v1/search")
+
+			assert.Equal(t, 1, code)
+			assert.Empty(t, stdout)
+			assert.Contains(t, stderr, tt.stderr)
+		})
+	}
+
+	t.Run("unreachable server", func(t *testing.T) {
+		server := newFakeServer(t, nil)
+		server.Close()
+
+		code, _, stderr := runCLI(t, server.URL, "rules", "list")
+
+		assert.Equal(t, 1, code)
+		assert.Contains(t, stderr, "unable to reach RateShield at "+server.URL)
+	})
+}
+
+func TestRulesList(t *testing.T) {
+	server := newFakeServer(t, func(w http.ResponseWriter, r *http.Request) {
+		writeSuccess(w, testRules)
+	})
+
+	t.Run("table", func(t *testing.T) {
+		code, stdout, _ := runCLI(t, server.URL, "rules", "list")
+		require.Equal(t, 0, code)
+
+		lines := strings.Split(strings.TrimSpace(stdout), "\n")
+		require.Len(t, lines, 3)
+		assert.Equal(t, []string{"ENDPOINT", "METHOD", "STRATEGY"}, strings.Fields(lines[0])[:3])
+		assert.Contains(t, lines[1], "100 per 60s")
+		assert.Contains(t, lines[2], "10 tokens, +2 per refill")
+		assert.Contains(t, lines[2], "rules-file (read only)")
+		assert.Equal(t, "/rule/list", server.requests[len(server.requests)-1].URL.Path)
+	})
+
+	t.Run("json", func(t *testing.T) {
+		code, stdout, _ := runCLI(t, server.URL, "-o", "json", "rules", "list")
+		require.Equal(t, 0, code)
+
+		var rules []models.Rule
+		require.NoError(t, json.Unmarshal([]byte(stdout), &rules))
+		assert.Equal(t, testRules, rules)
+	})
+
+	t.Run("search", func(t *testing.T) {
+		code, _, _ := runCLI(t, server.URL, "rules", "list", "--search", "users")
+		require.Equal(t, 0, code)
+
+		req := server.requests[len(server.requests)-1]
+		assert.Equal(t, "/rule/search", req.URL.Path)
+		assert.Equal(t, "users", req.URL.Query().Get("endpoint"))
+	})
+}
+
+func TestRulesDelete(t *testing.T) {
+	server := newFakeServer(t, func(w http.ResponseWriter, r *http.Request) {
+		writeSuccess(w, "Rule Deleted Successfully")
+	})
+
+	code, stdout, _ := runCLI(t, server.URL, "--user", "alice", "rules", "delete", "/api/v1/search")
+	require.Equal(t, 0, code)
+
+	assert.Equal(t, "Rule /api/v1/search deleted\n", stdout)
+	assert.Equal(t, "/rule/delete", server.requests[0].URL.Path)
+	assert.Equal(t, "alice", server.requests[0].Header.Get("X-User-ID"))
+	assert.JSONEq(t, `{"rule_key": "/api/v1/search"}`, server.bodies[0])
+}
+
+func TestRulesDiff(t *testing.T) {
+	rulesFile := filepath.Join(t.TempDir(), "rules.yaml")
+	require.NoError(t, os.WriteFile(rulesFile, []byte("rules: []\n"), 0o600))
+
+	changed := models.RulesImportResult{
+		Mode:   "replace",
+		DryRun: true,
+		RulesDiff: models.RulesDiff{
+			Created: []models.Rule{testRules[1]},
+			Updated: []models.RuleChange{{
+				Endpoint: testRules[0].APIEndpoint,
+				Old:      testRules[0],
+				New: models.Rule{
+					APIEndpoint:            testRules[0].APIEndpoint,
+					HTTPMethod:             "GET",
+					Strategy:               models.StrategyFixedWindowCounter,
+					FixedWindowCounterRule: &models.FixedWindowCounterRule{MaxRequests: 50, Window: 60},
+				},
+			}},
+			Deleted: []models.Rule{{APIEndpoint: "/api/v1/orders"}},
+		},
+	}
+	unchanged := models.RulesImportResult{Mode: "upsert", DryRun: true, RulesDiff: models.RulesDiff{Unchanged: 2}}
+
+	tests := []struct {
+		name   string
+		args   []string
+		result models.RulesImportResult
+		code   int
+		stdout []string
+	}{
+		{
+			name:   "changes",
+			args:   []string{"rules", "diff", "-f", rulesFile, "--mode", "replace"},
+			result: changed,
+			code:   0,
+			stdout: []string{
+				"+ /api/v1/users (TOKEN BUCKET, 10 tokens, +2 per refill)",
+				"~ /api/v1/search\n    limit: 100 per 60s -> 50 per 60s",
+				"- /api/v1/orders",
+				"1 to create, 1 to update, 1 to delete, 0 unchanged (replace, to apply)",
+			},
+		},
+		{
+			name:   "changes with exit code",
+			args:   []string{"rules", "diff", "-f", rulesFile, "--exit-code"},
+			result: changed,
+			code:   1,
+		},
+		{
+			name:   "no changes with exit code",
+			args:   []string{"rules", "diff", "-f", rulesFile, "--exit-code"},
+			result: unchanged,
+			code:   0,
+			stdout: []string{"0 to create, 0 to update, 0 to delete, 2 unchanged (upsert, to apply)"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newFakeServer(t, func(w http.ResponseWriter, r *http.Request) {
+				writeSuccess(w, tt.result)
+			})
+
+			code, stdout, stderr := runCLI(t, server.URL, tt.args...)
+
+			assert.Equal(t, tt.code, code)
+			assert.Empty(t, stderr)
+			for _, expected := range tt.stdout {
+				assert.Contains(t, stdout, expected)
+			}
+
+			req := server.requests[0]
+			assert.Equal(t, "/rule/import", req.URL.Path)
+			assert.Equal(t, "true", req.URL.Query().Get("dry_run"))
+			assert.Equal(t, "application/yaml", req.Header.Get("Content-Type"))
+		})
+	}
+}
+
+func TestRulesApply(t *testing.T) {
+	rulesFile := filepath.Join(t.TempDir(), "rules.json")
+	require.NoError(t, os.WriteFile(rulesFile, []byte(`{"rules": []}`), 0o600))
+
+	server := newFakeServer(t, func(w http.ResponseWriter, r *http.Request) {
+		writeSuccess(w, models.RulesImportResult{Mode: "upsert", RulesDiff: models.RulesDiff{Unchanged: 1}})
+	})
+
+	code, stdout, _ := runCLI(t, server.URL, "rules", "apply", "-f", rulesFile)
+	require.Equal(t, 0, code)
+
+	assert.Contains(t, stdout, "(upsert, applied)")
+	assert.Equal(t, "false", server.requests[0].URL.Query().Get("dry_run"))
+	assert.Equal(t, "application/json", server.requests[0].Header.Get("Content-Type"))
+	assert.Equal(t, `{"rules": []}`, server.bodies[0])
+}
+
+func TestLimiterState(t *testing.T) {
+	server := newFakeServer(t, func(w http.ResponseWriter, r *http.Request) {
+		writeSuccess(w, models.LimiterState{
+			ClientIP: r.URL.Query().Get("ip"),
+			Endpoint: r.URL.Query().Get("endpoint"),
+			Strategy: models.StrategyTokenBucket,
+			// A grant leaves more tokens than the bucket holds
+			TokenBucket: &models.Bucket{Capacity: 10, AvailableTokens: 15},
+		})
+	})
+
+	code, stdout, _ := runCLI(t, server.URL, "limiter", "state", "--ip", "1.2.3.4", "--endpoint", "/api/v1/search")
+	require.Equal(t, 0, code)
+
+	lines := strings.Split(stdout, "\n")
+	assert.Equal(t, "Client 1.2.3.4 on /api/v1/search (rule strategy: TOKEN BUCKET)", lines[0])
+	assert.Equal(t, []string{"TOKEN", "BUCKET", "0", "10", "15"}, strings.Fields(lines[3])[:5])
+
+	t.Run("no state", func(t *testing.T) {
+		server := newFakeServer(t, func(w http.ResponseWriter, r *http.Request) {
+			writeSuccess(w, models.LimiterState{ClientIP: "1.2.3.4", Endpoint: "/api/v1/search"})
+		})
+
+		code, stdout, _ := runCLI(t, server.URL, "limiter", "state", "--ip", "1.2.3.4", "--endpoint", "/api/v1/search")
+		require.Equal(t, 0, code)
+
+		assert.Contains(t, stdout, "(rule strategy: -)")
+		assert.Contains(t, stdout, "No limiter state stored for this client")
+	})
+}
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		output string
+		stdout []string
+	}{
+		{"allowed", http.StatusOK, outputTable, []string{"200 OK", "Allowed true"}},
+		{"limited", http.StatusTooManyRequests, outputTable, []string{"429 Too Many Requests", "Allowed false", "Remaining 0"}},
+		{"json", http.StatusTooManyRequests, outputJSON, []string{`"allowed": false`, `"status_code": 429`, `"rate_limit_remaining": "0"`}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newFakeServer(t, func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("rate-limit", "10")
+				w.Header().Set("rate-limit-remaining", "0")
+				w.WriteHeader(tt.status)
+			})
+
+			// A rate limited client is a result of the check, not an error
+			code, stdout, _ := runCLI(t, server.URL, "-o", tt.output, "check", "--ip", "1.2.3.4", "--endpoint", "/api/v1/search")
+			require.Equal(t, 0, code)
+
+			// Column widths change with the rows, compare with single spaces
+			stdout = strings.Join(strings.Fields(stdout), " ")
+			for _, expected := range tt.stdout {
+				assert.Contains(t, stdout, expected)
+			}
+			assert.Equal(t, "1.2.3.4", server.requests[0].Header.Get("ip"))
+			assert.Equal(t, "/api/v1/search", server.requests[0].Header.Get("endpoint"))
+		})
+	}
+}
+
+func TestAuditTail(t *testing.T) {
+	newRule := testRules[0]
+	server := newFakeServer(t, func(w http.ResponseWriter, r *http.Request) {
+		// Newest first, like the API
+		writeSuccess(w, models.PaginatedAuditLogs{Logs: []models.AuditLog{
+			{ID: "2", Timestamp: 1700000060, Actor: "bob", Action: models.AuditActionDelete, Endpoint: "/api/v1/users", OldRule: &testRules[1]},
+			{ID: "1", Timestamp: 1700000000, Actor: "alice", Action: models.AuditActionCreate, Endpoint: "/api/v1/search", NewRule: &newRule},
+		}})
+	})
+
+	t.Run("table", func(t *testing.T) {
+		code, stdout, _ := runCLI(t, server.URL, "audit", "tail", "-n", "2")
+		require.Equal(t, 0, code)
+
+		lines := strings.Split(strings.TrimSpace(stdout), "\n")
+		require.Len(t, lines, 3)
+		assert.True(t, strings.HasPrefix(lines[0], "TIME"))
+		assert.Contains(t, lines[1], "alice")
+		assert.Contains(t, lines[1], "100 per 60s")
+		assert.Contains(t, lines[2], "was 10 tokens, +2 per refill")
+		assert.Equal(t, "2", server.requests[len(server.requests)-1].URL.Query().Get("items"))
+	})
+
+	t.Run("json lines", func(t *testing.T) {
+		code, stdout, _ := runCLI(t, server.URL, "-o", "json", "audit", "tail")
+		require.Equal(t, 0, code)
+
+		lines := strings.Split(strings.TrimSpace(stdout), "\n")
+		require.Len(t, lines, 2)
+
+		var first models.AuditLog
+		require.NoError(t, json.Unmarshal([]byte(lines[0]), &first))
+		assert.Equal(t, "1", first.ID)
+	})
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/x-sushant-x/RateShield/models"
+)
+
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+)
+
+// printJSON writes a value as indented JSON to stdout
+func printJSON(value interface{}) error {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(value)
+}
+
+// printTable writes rows aligned in columns to stdout. The first row is the header.
+func printTable(rows [][]string) {
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+	for _, row := range rows {
+		fmt.Fprintln(writer, strings.Join(row, "\t"))
+	}
+	writer.Flush()
+}
+
+// describeLimit summarises the limit a rule enforces
+func describeLimit(rule models.Rule) string {
+	switch {
+	case rule.TokenBucketRule != nil:
+		return fmt.Sprintf("%d tokens, +%d per refill", rule.TokenBucketRule.BucketCapacity, rule.TokenBucketRule.TokenAddRate)
+	case rule.FixedWindowCounterRule != nil:
+		return fmt.Sprintf("%d per %ds", rule.FixedWindowCounterRule.MaxRequests, rule.FixedWindowCounterRule.Window)
+	case rule.SlidingWindowCounterRule != nil:
+		return fmt.Sprintf("%d per %ds", rule.SlidingWindowCounterRule.MaxRequests, rule.SlidingWindowCounterRule.WindowSize)
+	case rule.QuotaRule != nil:
+		return fmt.Sprintf("%d per %s", rule.QuotaRule.Limit, rule.QuotaRule.Period)
+	case rule.ConcurrencyRule != nil:
+		return fmt.Sprintf("%d in flight", rule.ConcurrencyRule.MaxConcurrent)
+	}
+	return "-"
+}
+
+func formatTimestamp(timestamp int64) string {
+	return time.Unix(timestamp, 0).Format(time.RFC3339)
+}
+
+func valueOrDash(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
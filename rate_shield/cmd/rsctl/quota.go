@@ -0,0 +1,66 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"net/url"
+	"strconv"
+
+	"github.com/x-sushant-x/RateShield/models"
+)
+
+func runQuota(c cli, args []string) error {
+	if len(args) == 0 || args[0] != "usage" {
+		return errors.New("usage: rsctl quota usage --identity <id> [--endpoint <endpoint>] [--tier <tier>]")
+	}
+
+	flags := flag.NewFlagSet("quota usage", flag.ContinueOnError)
+	identity := flags.String("identity", "", "client ID or IP (required)")
+	endpoint := flags.String("endpoint", "", "only show the quota of this endpoint")
+	tier := flags.String("tier", "", "tier of the client, looked up on the server when empty")
+	if err := flags.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	if *identity == "" {
+		return errors.New("--identity is required")
+	}
+
+	query := url.Values{"identity": {*identity}}
+	if *endpoint != "" {
+		query.Set("endpoint", *endpoint)
+	}
+	if *tier != "" {
+		query.Set("tier", *tier)
+	}
+
+	var usages []models.QuotaUsage
+	if err := c.api.get("/quota/usage", query, &usages); err != nil {
+		return err
+	}
+
+	if c.output == outputJSON {
+		return printJSON(usages)
+	}
+
+	if len(usages) == 0 {
+		fmt.Println("No endpoint has a quota")
+		return nil
+	}
+
+	rows := [][]string{{"ENDPOINT", "PERIOD", "USED", "LIMIT", "REMAINING", "RESETS AT"}}
+	for _, usage := range usages {
+		rows = append(rows, []string{
+			usage.Endpoint,
+			usage.Period,
+			strconv.FormatInt(usage.Used, 10),
+			strconv.FormatInt(usage.Limit, 10),
+			strconv.FormatInt(usage.Remaining, 10),
+			formatTimestamp(usage.ResetsAt),
+		})
+	}
+
+	printTable(rows)
+	return nil
+}
@@ -0,0 +1,236 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/x-sushant-x/RateShield/models"
+)
+
+func runRules(c cli, args []string) error {
+	if len(args) == 0 {
+		return errors.New("usage: rsctl rules <list|get|apply|diff|delete|export>")
+	}
+
+	switch args[0] {
+	case "list":
+		return rulesList(c, args[1:])
+	case "get":
+		return rulesGet(c, args[1:])
+	case "apply":
+		return rulesApply(c, args[1:], false)
+	case "diff":
+		return rulesApply(c, args[1:], true)
+	case "delete":
+		return rulesDelete(c, args[1:])
+	case "export":
+		return rulesExport(c, args[1:])
+	}
+
+	return fmt.Errorf("unknown rules command %q", args[0])
+}
+
+func rulesList(c cli, args []string) error {
+	flags := flag.NewFlagSet("rules list", flag.ContinueOnError)
+	search := flags.String("search", "", "only list rules whose endpoint contains this text")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	rules := []models.Rule{}
+
+	var err error
+	if *search != "" {
+		err = c.api.get("/rule/search", url.Values{"endpoint": {*search}}, &rules)
+	} else {
+		err = c.api.get("/rule/list", nil, &rules)
+	}
+	if err != nil {
+		return err
+	}
+
+	if c.output == outputJSON {
+		return printJSON(rules)
+	}
+
+	rows := [][]string{{"ENDPOINT", "METHOD", "STRATEGY", "LIMIT", "VERSION", "MANAGED BY"}}
+	for _, rule := range rules {
+		managedBy := valueOrDash(rule.ManagedBy)
+		if rule.ReadOnly {
+			managedBy += " (read only)"
+		}
+
+		rows = append(rows, []string{
+			rule.APIEndpoint,
+			valueOrDash(rule.HTTPMethod),
+			rule.Strategy,
+			describeLimit(rule),
+			strconv.FormatInt(rule.Version, 10),
+			managedBy,
+		})
+	}
+	printTable(rows)
+	return nil
+}
+
+func rulesGet(c cli, args []string) error {
+	if len(args) != 1 {
+		return errors.New("usage: rsctl rules get <endpoint>")
+	}
+
+	var rule models.Rule
+	if err := c.api.get("/rule/get", url.Values{"endpoint": {args[0]}}, &rule); err != nil {
+		return err
+	}
+
+	if c.output == outputJSON {
+		return printJSON(rule)
+	}
+
+	printTable([][]string{
+		{"FIELD", "VALUE"},
+		{"Endpoint", rule.APIEndpoint},
+		{"Method", valueOrDash(rule.HTTPMethod)},
+		{"Strategy", rule.Strategy},
+		{"Limit", describeLimit(rule)},
+		{"Allow On Error", strconv.FormatBool(rule.AllowOnError)},
+		{"Version", strconv.FormatInt(rule.Version, 10)},
+		{"Managed By", valueOrDash(rule.ManagedBy)},
+		{"Read Only", strconv.FormatBool(rule.ReadOnly)},
+	})
+	return nil
+}
+
+// rulesApply imports a local rules file. In diff mode the import is a dry run that only shows what would
+// change.
+func rulesApply(c cli, args []string, diffOnly bool) error {
+	name := "rules apply"
+	if diffOnly {
+		name = "rules diff"
+	}
+
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	file := flags.String("f", "", "rules file in YAML or JSON (required)")
+	mode := flags.String("mode", "upsert", "upsert keeps rules missing from the file, replace deletes them")
+	dryRun := flags.Bool("dry-run", false, "show what would change without applying it")
+	exitCode := flags.Bool("exit-code", false, "exit with status 1 when there are differences (diff only)")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if *file == "" {
+		return fmt.Errorf("usage: rsctl %s -f <file> [--mode upsert|replace]", name)
+	}
+
+	data, err := os.ReadFile(*file)
+	if err != nil {
+		return err
+	}
+
+	contentType := "application/json"
+	switch strings.ToLower(filepath.Ext(*file)) {
+	case ".yaml", ".yml":
+		contentType = "application/yaml"
+	}
+
+	query := url.Values{
+		"mode":    {*mode},
+		"dry_run": {strconv.FormatBool(diffOnly || *dryRun)},
+	}
+
+	var result models.RulesImportResult
+	if err := c.api.call("POST", "/rule/import", query, strings.NewReader(string(data)), contentType, &result); err != nil {
+		return err
+	}
+
+	if c.output == outputJSON {
+		if err := printJSON(result); err != nil {
+			return err
+		}
+	} else {
+		printRulesDiff(result)
+	}
+
+	if diffOnly && *exitCode && result.HasChanges() {
+		return errDifferencesFound
+	}
+	return nil
+}
+
+func printRulesDiff(result models.RulesImportResult) {
+	for _, rule := range result.Created {
+		fmt.Printf("+ %s (%s, %s)\n", rule.APIEndpoint, rule.Strategy, describeLimit(rule))
+	}
+
+	for _, change := range result.Updated {
+		fmt.Printf("~ %s\n", change.Endpoint)
+		if change.Old.Strategy != change.New.Strategy {
+			fmt.Printf("    strategy: %s -> %s\n", change.Old.Strategy, change.New.Strategy)
+		}
+		if oldLimit, newLimit := describeLimit(change.Old), describeLimit(change.New); oldLimit != newLimit {
+			fmt.Printf("    limit: %s -> %s\n", oldLimit, newLimit)
+		}
+		if change.Old.HTTPMethod != change.New.HTTPMethod {
+			fmt.Printf("    http_method: %s -> %s\n", valueOrDash(change.Old.HTTPMethod), valueOrDash(change.New.HTTPMethod))
+		}
+		if change.Old.AllowOnError != change.New.AllowOnError {
+			fmt.Printf("    allow_on_error: %t -> %t\n", change.Old.AllowOnError, change.New.AllowOnError)
+		}
+		if change.Old.ManagedBy != change.New.ManagedBy {
+			fmt.Printf("    managed_by: %s -> %s\n", valueOrDash(change.Old.ManagedBy), valueOrDash(change.New.ManagedBy))
+		}
+	}
+
+	for _, rule := range result.Deleted {
+		fmt.Printf("- %s\n", rule.APIEndpoint)
+	}
+
+	verb := "applied"
+	if result.DryRun {
+		verb = "to apply"
+	}
+
+	fmt.Printf("\n%d to create, %d to update, %d to delete, %d unchanged (%s, %s)\n",
+		len(result.Created), len(result.Updated), len(result.Deleted), result.Unchanged, result.Mode, verb)
+}
+
+func rulesDelete(c cli, args []string) error {
+	if len(args) != 1 {
+		return errors.New("usage: rsctl rules delete <endpoint>")
+	}
+
+	if err := c.api.post("/rule/delete", nil, models.DeleteRuleDTO{RuleKey: args[0]}, nil); err != nil {
+		return err
+	}
+
+	fmt.Printf("Rule %s deleted\n", args[0])
+	return nil
+}
+
+// rulesExport writes every rule to stdout in the layout read by rules apply
+func rulesExport(c cli, args []string) error {
+	flags := flag.NewFlagSet("rules export", flag.ContinueOnError)
+	format := flags.String("format", "yaml", "yaml or json")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	resp, err := c.api.send("GET", "/rule/export", url.Values{"format": {*format}}, nil, "", nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		return fmt.Errorf("HTTP %d exporting rules", resp.StatusCode)
+	}
+
+	_, err = os.Stdout.ReadFrom(resp.Body)
+	return err
+}
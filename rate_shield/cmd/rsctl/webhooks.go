@@ -0,0 +1,181 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/x-sushant-x/RateShield/models"
+)
+
+func runWebhooks(c cli, args []string) error {
+	if len(args) == 0 {
+		return errors.New("usage: rsctl webhooks <list|save|delete|deliveries|redeliver>")
+	}
+
+	switch args[0] {
+	case "list":
+		return webhooksList(c)
+	case "save":
+		return webhooksSave(c, args[1:])
+	case "delete":
+		return webhooksDelete(c, args[1:])
+	case "deliveries":
+		return webhooksDeliveries(c, args[1:])
+	case "redeliver":
+		return webhooksRedeliver(c, args[1:])
+	}
+
+	return fmt.Errorf("unknown webhooks command %q", args[0])
+}
+
+func webhooksList(c cli) error {
+	var webhooks []models.RuleWebhook
+	if err := c.api.get("/webhooks/rules", nil, &webhooks); err != nil {
+		return err
+	}
+
+	if c.output == outputJSON {
+		return printJSON(webhooks)
+	}
+
+	if len(webhooks) == 0 {
+		fmt.Println("No rule webhooks")
+		return nil
+	}
+
+	rows := [][]string{{"NAME", "URL", "ENDPOINTS", "ENABLED"}}
+	for _, webhook := range webhooks {
+		endpoints := strings.Join(webhook.Endpoints, ",")
+		if endpoints == "" {
+			endpoints = "*"
+		}
+
+		rows = append(rows, []string{
+			webhook.Name,
+			webhook.URL,
+			endpoints,
+			strconv.FormatBool(!webhook.Disabled),
+		})
+	}
+
+	printTable(rows)
+	return nil
+}
+
+// webhooksSave creates or replaces the rule webhook in a JSON file
+func webhooksSave(c cli, args []string) error {
+	flags := flag.NewFlagSet("webhooks save", flag.ContinueOnError)
+	file := flags.String("f", "", "rule webhook in JSON (required)")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if *file == "" {
+		return errors.New("usage: rsctl webhooks save -f <file>")
+	}
+
+	data, err := os.ReadFile(*file)
+	if err != nil {
+		return err
+	}
+
+	var webhook models.RuleWebhook
+	if err := json.Unmarshal(data, &webhook); err != nil {
+		return fmt.Errorf("unable to parse %s: %w", *file, err)
+	}
+
+	var saved models.RuleWebhook
+	if err := c.api.post("/webhooks/rules/save", nil, webhook, &saved); err != nil {
+		return err
+	}
+
+	fmt.Printf("Rule webhook %s saved\n", saved.Name)
+	return nil
+}
+
+func webhooksDelete(c cli, args []string) error {
+	if len(args) != 1 {
+		return errors.New("usage: rsctl webhooks delete <name>")
+	}
+
+	if err := c.api.post("/webhooks/rules/delete", nil, models.RuleWebhookNameDTO{Name: args[0]}, nil); err != nil {
+		return err
+	}
+
+	fmt.Printf("Rule webhook %s deleted\n", args[0])
+	return nil
+}
+
+func webhooksDeliveries(c cli, args []string) error {
+	flags := flag.NewFlagSet("webhooks deliveries", flag.ContinueOnError)
+	webhook := flags.String("webhook", "", "only list deliveries to this webhook")
+	status := flags.String("status", "", "only list deliveries with this status, PENDING, DELIVERED or FAILED")
+	limit := flags.Int("n", 20, "number of deliveries to show")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	query := url.Values{"limit": {strconv.Itoa(*limit)}}
+	if *webhook != "" {
+		query.Set("webhook", *webhook)
+	}
+	if *status != "" {
+		query.Set("status", strings.ToUpper(*status))
+	}
+
+	var deliveries []models.RuleWebhookDelivery
+	if err := c.api.get("/webhooks/deliveries", query, &deliveries); err != nil {
+		return err
+	}
+
+	if c.output == outputJSON {
+		return printJSON(deliveries)
+	}
+
+	if len(deliveries) == 0 {
+		fmt.Println("No deliveries")
+		return nil
+	}
+
+	rows := [][]string{{"ID", "WEBHOOK", "EVENT", "ENDPOINT", "STATUS", "ATTEMPTS", "LAST RESPONSE", "UPDATED AT"}}
+	for _, delivery := range deliveries {
+		response := delivery.Error
+		if response == "" && delivery.StatusCode != 0 {
+			response = strconv.Itoa(delivery.StatusCode)
+		}
+
+		rows = append(rows, []string{
+			delivery.ID,
+			delivery.Webhook,
+			delivery.Event,
+			delivery.Endpoint,
+			delivery.Status,
+			strconv.Itoa(delivery.Attempts),
+			valueOrDash(response),
+			formatTimestamp(delivery.UpdatedAt),
+		})
+	}
+
+	printTable(rows)
+	return nil
+}
+
+func webhooksRedeliver(c cli, args []string) error {
+	if len(args) != 1 {
+		return errors.New("usage: rsctl webhooks redeliver <delivery id>")
+	}
+
+	var delivery models.RuleWebhookDelivery
+	if err := c.api.post("/webhooks/deliveries/redeliver", nil, models.RuleWebhookDeliveryIDDTO{ID: args[0]}, &delivery); err != nil {
+		return err
+	}
+
+	fmt.Printf("Event %s queued for %s as delivery %s\n", delivery.EventID, delivery.Webhook, delivery.ID)
+	return nil
+}
@@ -1,44 +1,27 @@
 package middleware
 
 import (
-	"fmt"
-	"net/http"
+	"log"
+	"os"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/x-sushant-x/RateShield/client"
+	"github.com/x-sushant-x/RateShield/client/fibermw"
 )
 
+// RateLimiter checks every request against RateShield over gRPC. The address is read from
+// RATESHIELD_GRPC_ADDRESS and defaults to 127.0.0.1:50051.
 func RateLimiter() fiber.Handler {
-	fmt.Println("Here")
-	return func(c *fiber.Ctx) error {
-
-		ip := c.IP()         // Client IP
-		endpoint := c.Path() // Requested endpoint
-
-		req, err := http.NewRequest("GET", "http://127.0.0.1:8080/check-limit", nil)
-		if err != nil {
-			return fiber.ErrInternalServerError
-		}
-
-		// Set headers for Rate Shield
-		req.Header.Set("ip", ip)
-		req.Header.Set("endpoint", endpoint)
-
-		client := &http.Client{}
-		resp, err := client.Do(req)
-		if err != nil {
-			return fiber.ErrInternalServerError
-		}
-		defer resp.Body.Close()
-
-		// Handle Rate Shield response
-		switch resp.StatusCode {
-		case http.StatusOK:
-			return c.Next() // Allow request to proceed
-		case http.StatusTooManyRequests:
-			return c.Status(fiber.StatusTooManyRequests).SendString("Rate limit exceeded")
-		default:
-			return fiber.ErrInternalServerError
-		}
-
+	rs, err := client.New(client.Config{
+		Address:  os.Getenv("RATESHIELD_GRPC_ADDRESS"),
+		FailOpen: true,
+		OnError: func(err error) {
+			log.Printf("rate limit check failed: %v", err)
+		},
+	})
+	if err != nil {
+		log.Fatal(err)
 	}
+
+	return fibermw.Middleware(rs, client.MiddlewareConfig{})
 }
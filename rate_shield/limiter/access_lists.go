@@ -0,0 +1,107 @@
+package limiter
+
+import (
+	"net/netip"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/x-sushant-x/RateShield/models"
+	"github.com/x-sushant-x/RateShield/utils"
+)
+
+// ipMatchers are the compiled allow and deny lists of a rule or of every endpoint
+type ipMatchers struct {
+	allow *utils.IPMatcher
+	deny  *utils.IPMatcher
+}
+
+func newIPMatchers(allow, deny []string) (ipMatchers, error) {
+	allowMatcher, err := utils.NewIPMatcher(allow)
+	if err != nil {
+		return ipMatchers{}, err
+	}
+
+	denyMatcher, err := utils.NewIPMatcher(deny)
+	if err != nil {
+		return ipMatchers{}, err
+	}
+
+	return ipMatchers{
+		allow: allowMatcher,
+		deny:  denyMatcher,
+	}, nil
+}
+
+// checkAccessLists decides requests from listed IPs without going through a strategy. The global deny list
+// is checked first so a rule can't let a blocked IP back in, then the lists of the endpoint's rule (deny
+// before allow) and last the global allow list. Returns nil when the IP is on no list.
+func (l *Limiter) checkAccessLists(ip, endpoint string) *models.RateLimitResponse {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return nil
+	}
+
+	l.rulesMutex.RLock()
+	global := l.globalIPMatchers
+	ruleMatchers, hasRuleMatchers := l.ruleIPMatchers[endpoint]
+	var rule *models.Rule
+	if l.cachedRules != nil {
+		rule = (*l.cachedRules)[endpoint]
+	}
+	l.rulesMutex.RUnlock()
+
+	if entry, found := global.deny.Match(addr); found {
+		return utils.BuildRateLimitDeniedResponse("ip matches " + entry + " on the global deny list")
+	}
+
+	if hasRuleMatchers && rule != nil && !utils.IsRuleExpired(*rule, time.Now()) {
+		if entry, found := ruleMatchers.deny.Match(addr); found {
+			return utils.BuildRateLimitDeniedResponse("ip matches " + entry + " on the deny list of the rule")
+		}
+
+		if entry, found := ruleMatchers.allow.Match(addr); found {
+			return utils.BuildRateLimitAllowedResponse("ip matches " + entry + " on the allow list of the rule")
+		}
+	}
+
+	if entry, found := global.allow.Match(addr); found {
+		return utils.BuildRateLimitAllowedResponse("ip matches " + entry + " on the global allow list")
+	}
+
+	return nil
+}
+
+// buildIPMatchers compiles the global lists and the lists of every rule. The previous global lists are kept
+// if the current ones can't be loaded.
+func (l *Limiter) buildIPMatchers(rules map[string]*models.Rule, previousGlobal ipMatchers) (ipMatchers, map[string]ipMatchers) {
+	global := ipMatchers{}
+
+	if l.accessListSvc != nil {
+		lists, err := l.accessListSvc.GetAccessLists()
+		if err != nil {
+			log.Err(err).Msg("unable to load global access lists, keeping previous lists")
+			global = previousGlobal
+		} else if global, err = newIPMatchers(lists.Allow, lists.Deny); err != nil {
+			log.Err(err).Msg("invalid entry in global access lists, keeping previous lists")
+			global = previousGlobal
+		}
+	}
+
+	ruleMatchers := make(map[string]ipMatchers)
+
+	for endpoint, rule := range rules {
+		if len(rule.AllowList) == 0 && len(rule.DenyList) == 0 {
+			continue
+		}
+
+		// Rules are validated before they are cached so this only fails for corrupted rules
+		matchers, err := newIPMatchers(rule.AllowList, rule.DenyList)
+		if err != nil {
+			log.Err(err).Str("endpoint", endpoint).Msg("invalid access list on rule")
+			continue
+		}
+		ruleMatchers[endpoint] = matchers
+	}
+
+	return global, ruleMatchers
+}
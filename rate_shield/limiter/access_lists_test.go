@@ -0,0 +1,98 @@
+package limiter
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/x-sushant-x/RateShield/models"
+)
+
+func TestCheckAccessLists(t *testing.T) {
+	rules := map[string]*models.Rule{
+		"/api/v1/partner": {
+			APIEndpoint: "/api/v1/partner",
+			AllowList:   []string{"10.1.0.0/16"},
+			DenyList:    []string{"10.1.2.0/24"},
+		},
+	}
+
+	limiter := &Limiter{cachedRules: &rules}
+
+	global, err := newIPMatchers([]string{"10.0.0.0/8"}, []string{"10.1.0.0/16", "192.0.2.0/24"})
+	assert.NoError(t, err)
+
+	_, limiter.ruleIPMatchers = limiter.buildIPMatchers(rules, ipMatchers{})
+	limiter.globalIPMatchers = global
+
+	tests := []struct {
+		name     string
+		ip       string
+		endpoint string
+		status   int
+	}{
+		{"rule deny wins over rule allow", "10.1.2.3", "/api/v1/partner", http.StatusForbidden},
+		{"global deny", "10.1.9.9", "/api/v1/other", http.StatusForbidden},
+		{"global allow", "10.2.0.1", "/api/v1/other", http.StatusOK},
+		{"ipv6 not listed", "2001:db8::1", "/api/v1/other", 0},
+		{"invalid ip", "not-an-ip", "/api/v1/other", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := limiter.checkAccessLists(tt.ip, tt.endpoint)
+			if tt.status == 0 {
+				assert.Nil(t, resp)
+				return
+			}
+
+			assert.Equal(t, tt.status, resp.HTTPStatusCode)
+			assert.NotEmpty(t, resp.Reason)
+		})
+	}
+}
+
+func TestCheckAccessListsPrecedence(t *testing.T) {
+	rules := map[string]*models.Rule{
+		"/api/v1/partner": {
+			APIEndpoint: "/api/v1/partner",
+			AllowList:   []string{"192.0.2.10", "198.51.100.0/24"},
+			DenyList:    []string{"203.0.113.0/24"},
+		},
+	}
+
+	limiter := &Limiter{cachedRules: &rules}
+
+	global, err := newIPMatchers([]string{"203.0.113.0/24"}, []string{"192.0.2.0/24"})
+	assert.NoError(t, err)
+
+	_, limiter.ruleIPMatchers = limiter.buildIPMatchers(rules, ipMatchers{})
+	limiter.globalIPMatchers = global
+
+	tests := []struct {
+		name   string
+		ip     string
+		status int
+		reason string
+	}{
+		{"global deny wins over rule allow", "192.0.2.10", http.StatusForbidden, "on the global deny list"},
+		{"rule deny wins over global allow", "203.0.113.5", http.StatusForbidden, "on the deny list of the rule"},
+		{"rule allow", "198.51.100.7", http.StatusOK, "on the allow list of the rule"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := limiter.checkAccessLists(tt.ip, "/api/v1/partner")
+
+			assert.Equal(t, tt.status, resp.HTTPStatusCode)
+			assert.Contains(t, resp.Reason, tt.reason)
+		})
+	}
+
+	t.Run("global allow without a rule list", func(t *testing.T) {
+		resp := limiter.checkAccessLists("203.0.113.5", "/api/v1/other")
+
+		assert.Equal(t, http.StatusOK, resp.HTTPStatusCode)
+		assert.Contains(t, resp.Reason, "on the global allow list")
+	})
+}
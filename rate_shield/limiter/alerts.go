@@ -0,0 +1,404 @@
+package limiter
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/x-sushant-x/RateShield/models"
+	"github.com/x-sushant-x/RateShield/service"
+)
+
+const (
+	// A throttled client that was not denied for this long is no longer considered throttled
+	throttleGap = time.Minute
+
+	// How often finished deny rate windows of idle endpoints are evaluated and stale state is dropped
+	alertJobInterval = 10 * time.Second
+
+	// The alert state is split by endpoint so checks of different endpoints do not wait for each other
+	alertShards = 32
+)
+
+// alertStateKey identifies the state of one alert rule for one endpoint or client
+type alertStateKey struct {
+	alert   string
+	subject string
+}
+
+type denyWindow struct {
+	start  time.Time
+	total  int64
+	denied int64
+}
+
+type throttledClient struct {
+	since      time.Time
+	lastDenied time.Time
+}
+
+// AlertService evaluates the alert rules against the outcome of every rate limit check and routes alerts
+// through the notifiers. The state is kept per instance, an alert fires at most once per cooldown for
+// the same endpoint or client. With a shared dedup store the cooldown also applies across instances.
+type AlertService struct {
+	ruleSvc  service.AlertRuleService
+	notifier service.ChannelNotifier
+	dedup    service.NotificationDedupStore                            // Shares cooldowns between instances, nil keeps them per instance
+	send     func(notification models.Notification, channels []string) // Replaces the notifier in tests
+
+	hasRules atomic.Bool
+	rules    atomic.Pointer[alertRules] // Replaced as a whole on reload so checks read it without locking
+	shards   [alertShards]*alertShard
+}
+
+type alertRules struct {
+	list   []models.AlertRule
+	byName map[string]models.AlertRule
+}
+
+// alertShard holds the state of the alert rules for the endpoints hashed to it. Every state of a check,
+// including that of its client, belongs to the shard of the endpoint.
+type alertShard struct {
+	mutex       sync.Mutex
+	denyWindows map[alertStateKey]*denyWindow
+	throttled   map[alertStateKey]*throttledClient
+	lastFired   map[alertStateKey]time.Time
+}
+
+func NewAlertService(ruleSvc service.AlertRuleService, notifier service.ChannelNotifier, dedup service.NotificationDedupStore) AlertService {
+	shards := [alertShards]*alertShard{}
+	for i := range shards {
+		shards[i] = &alertShard{
+			denyWindows: make(map[alertStateKey]*denyWindow),
+			throttled:   make(map[alertStateKey]*throttledClient),
+			lastFired:   make(map[alertStateKey]time.Time),
+		}
+	}
+
+	return AlertService{
+		ruleSvc:  ruleSvc,
+		notifier: notifier,
+		dedup:    dedup,
+		shards:   shards,
+	}
+}
+
+// reload fetches the alert rules and drops the state of alert rules that no longer exist. The cooldowns
+// of the remaining ones are kept so saving an unrelated rule does not fire every alert again.
+func (a *AlertService) reload() {
+	if a == nil || a.ruleSvc == nil {
+		return
+	}
+
+	alerts, err := a.ruleSvc.ListAlertRules()
+	if err != nil {
+		log.Err(err).Msg("unable to load alert rules, keeping the previous ones")
+		return
+	}
+
+	a.setRules(alerts)
+}
+
+func (a *AlertService) setRules(alerts []models.AlertRule) {
+	rules := &alertRules{
+		list:   alerts,
+		byName: make(map[string]models.AlertRule, len(alerts)),
+	}
+	for _, alert := range alerts {
+		rules.byName[alert.Name] = alert
+	}
+
+	a.rules.Store(rules)
+
+	for _, shard := range a.shards {
+		shard.mutex.Lock()
+		shard.dropRemovedAlerts(rules.byName)
+		shard.mutex.Unlock()
+	}
+
+	a.hasRules.Store(len(alerts) > 0)
+}
+
+// dropRemovedAlerts drops the state of alert rules that no longer exist
+func (s *alertShard) dropRemovedAlerts(byName map[string]models.AlertRule) {
+	for key := range s.denyWindows {
+		if _, ok := byName[key.alert]; !ok {
+			delete(s.denyWindows, key)
+		}
+	}
+	for key := range s.throttled {
+		if _, ok := byName[key.alert]; !ok {
+			delete(s.throttled, key)
+		}
+	}
+	for key := range s.lastFired {
+		if _, ok := byName[key.alert]; !ok {
+			delete(s.lastFired, key)
+		}
+	}
+}
+
+// loadRules returns the current alert rules, never nil
+func (a *AlertService) loadRules() *alertRules {
+	if rules := a.rules.Load(); rules != nil {
+		return rules
+	}
+	return &alertRules{}
+}
+
+func (a *AlertService) shard(endpoint string) *alertShard {
+	hash := fnv.New32a()
+	hash.Write([]byte(endpoint))
+	return a.shards[hash.Sum32()%alertShards]
+}
+
+func (a *AlertService) startAlertJob() {
+	if a == nil {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(alertJobInterval)
+		defer ticker.Stop()
+
+		for now := range ticker.C {
+			a.evaluateIdle(now)
+		}
+	}()
+}
+
+// observe feeds the response of a rate limit check to the alert rules of its endpoint
+func (a *AlertService) observe(identity, endpoint string, rule *models.Rule, resp *models.RateLimitResponse) {
+	if a == nil || !a.hasRules.Load() {
+		return
+	}
+
+	a.observeAt(time.Now(), identity, endpoint, rule, resp)
+}
+
+func (a *AlertService) observeAt(now time.Time, identity, endpoint string, rule *models.Rule, resp *models.RateLimitResponse) {
+	denied := resp.HTTPStatusCode == http.StatusTooManyRequests || resp.HTTPStatusCode == http.StatusForbidden
+	shard := a.shard(endpoint)
+
+	for _, alert := range a.loadRules().list {
+		if alert.Endpoint != "" && alert.Endpoint != endpoint {
+			continue
+		}
+
+		switch alert.Type {
+		case models.AlertTypeDenyRate:
+			shard.mutex.Lock()
+			a.observeDenyRate(shard, now, alert, endpoint, denied)
+			shard.mutex.Unlock()
+		case models.AlertTypeThrottledClient:
+			shard.mutex.Lock()
+			a.observeThrottledClient(shard, now, alert, identity, endpoint, denied)
+			shard.mutex.Unlock()
+		case models.AlertTypeQuotaExhaustion:
+			if rule != nil && rule.Strategy == models.StrategyQuota {
+				shard.mutex.Lock()
+				a.observeQuota(shard, now, alert, identity, endpoint, resp)
+				shard.mutex.Unlock()
+			}
+		}
+	}
+}
+
+// observeFallback reports that a request to endpoint was allowed because the limiter failed and the rule
+// has allow_on_error
+func (a *AlertService) observeFallback(endpoint string) {
+	if a == nil || !a.hasRules.Load() {
+		return
+	}
+
+	a.observeFallbackAt(time.Now(), endpoint)
+}
+
+func (a *AlertService) observeFallbackAt(now time.Time, endpoint string) {
+	shard := a.shard(endpoint)
+
+	shard.mutex.Lock()
+	defer shard.mutex.Unlock()
+
+	for _, alert := range a.loadRules().list {
+		if alert.Type != models.AlertTypeRedisFallback || (alert.Endpoint != "" && alert.Endpoint != endpoint) {
+			continue
+		}
+
+		a.fire(shard, now, alert, endpoint, "Limiter failing open",
+			fmt.Sprintf("Requests to %s are allowed without rate limiting because the limiter failed and the rule has allow_on_error", endpoint),
+			map[string]string{"Endpoint": endpoint})
+	}
+}
+
+// observeDenyRate counts requests in fixed windows. A window is evaluated once it is over, by the first
+// request after it or by the alert job when the endpoint went quiet.
+func (a *AlertService) observeDenyRate(shard *alertShard, now time.Time, alert models.AlertRule, endpoint string, denied bool) {
+	key := alertStateKey{alert: alert.Name, subject: endpoint}
+
+	window, ok := shard.denyWindows[key]
+	if !ok {
+		window = &denyWindow{start: now}
+		shard.denyWindows[key] = window
+	} else if now.Sub(window.start) >= time.Duration(alert.Window)*time.Second {
+		a.evaluateDenyWindow(shard, now, alert, endpoint, window)
+		*window = denyWindow{start: now}
+	}
+
+	window.total++
+	if denied {
+		window.denied++
+	}
+}
+
+func (a *AlertService) evaluateDenyWindow(shard *alertShard, now time.Time, alert models.AlertRule, endpoint string, window *denyWindow) {
+	if window.total == 0 || window.total < alert.MinRequests {
+		return
+	}
+
+	rate := float64(window.denied) * 100 / float64(window.total)
+	if rate < alert.Threshold {
+		return
+	}
+
+	a.fire(shard, now, alert, endpoint, fmt.Sprintf("%.1f%% of requests denied", rate),
+		fmt.Sprintf("%d of %d requests to %s were denied in %d seconds", window.denied, window.total, endpoint, alert.Window),
+		map[string]string{"Endpoint": endpoint})
+}
+
+// observeThrottledClient tracks since when a client is denied. A successful request or a break longer than
+// throttleGap ends the throttling.
+func (a *AlertService) observeThrottledClient(shard *alertShard, now time.Time, alert models.AlertRule, identity, endpoint string, denied bool) {
+	key := alertStateKey{alert: alert.Name, subject: identity + ":" + endpoint}
+
+	if !denied {
+		delete(shard.throttled, key)
+		return
+	}
+
+	client, ok := shard.throttled[key]
+	if !ok || now.Sub(client.lastDenied) > throttleGap {
+		client = &throttledClient{since: now}
+		shard.throttled[key] = client
+	}
+	client.lastDenied = now
+
+	throttledFor := now.Sub(client.since)
+	if throttledFor < time.Duration(alert.Duration)*time.Second {
+		return
+	}
+
+	a.fire(shard, now, alert, key.subject, "Client throttled for "+throttledFor.Round(time.Second).String(),
+		fmt.Sprintf("%s has been denied on %s since %s", identity, endpoint, client.since.UTC().Format(time.RFC3339)),
+		map[string]string{"Client": identity, "Endpoint": endpoint})
+}
+
+// observeQuota fires when a request brings the usage to exactly the threshold. Quota counters are
+// incremented atomically so only one request per period sees that value.
+func (a *AlertService) observeQuota(shard *alertShard, now time.Time, alert models.AlertRule, identity, endpoint string, resp *models.RateLimitResponse) {
+	if !resp.Success || resp.RateLimit_Limit <= 0 {
+		return
+	}
+
+	used := resp.RateLimit_Limit - resp.RateLimit_Remaining
+	if used != quotaAlertCount(resp.RateLimit_Limit, alert.Threshold) {
+		return
+	}
+
+	a.fire(shard, now, alert, identity+":"+endpoint, fmt.Sprintf("Quota %g%% used", alert.Threshold),
+		fmt.Sprintf("%s used %d of %d requests of its quota on %s", identity, used, resp.RateLimit_Limit, endpoint),
+		map[string]string{"Client": identity, "Endpoint": endpoint})
+}
+
+// quotaAlertCount is the number of requests at which threshold percent of a quota is used, rounded up
+func quotaAlertCount(limit int64, threshold float64) int64 {
+	return int64(math.Ceil(float64(limit) * threshold / 100))
+}
+
+// evaluateIdle evaluates deny rate windows that ended without a request after them and drops the state of
+// clients that are no longer throttled and of cooldowns that are over
+func (a *AlertService) evaluateIdle(now time.Time) {
+	byName := a.loadRules().byName
+
+	for _, shard := range a.shards {
+		shard.mutex.Lock()
+
+		// State a check added with the rules from before a reload
+		shard.dropRemovedAlerts(byName)
+
+		for key, window := range shard.denyWindows {
+			alert := byName[key.alert]
+			if now.Sub(window.start) >= time.Duration(alert.Window)*time.Second {
+				a.evaluateDenyWindow(shard, now, alert, key.subject, window)
+				delete(shard.denyWindows, key)
+			}
+		}
+
+		for key, client := range shard.throttled {
+			if now.Sub(client.lastDenied) > throttleGap {
+				delete(shard.throttled, key)
+			}
+		}
+
+		for key, firedAt := range shard.lastFired {
+			if now.Sub(firedAt) >= time.Duration(byName[key.alert].Cooldown)*time.Second {
+				delete(shard.lastFired, key)
+			}
+		}
+
+		shard.mutex.Unlock()
+	}
+}
+
+// fire sends an alert unless it already fired for the same subject within the cooldown. The shard must be
+// locked.
+func (a *AlertService) fire(shard *alertShard, now time.Time, alert models.AlertRule, subject, title, text string, fields map[string]string) {
+	key := alertStateKey{alert: alert.Name, subject: subject}
+
+	cooldown := time.Duration(alert.Cooldown) * time.Second
+	if firedAt, ok := shard.lastFired[key]; ok && now.Sub(firedAt) < cooldown {
+		return
+	}
+	shard.lastFired[key] = now
+
+	if a.dedup != nil && cooldown > 0 {
+		// Another instance fired it within the cooldown. A failing store does not hold the alert back.
+		sent, err := a.dedup.MarkSent("alert:"+alert.Name+":"+subject, cooldown)
+		if err == nil && !sent {
+			return
+		}
+	}
+
+	severity := alert.Severity
+	if severity == "" {
+		severity = models.NotificationSeverityWarning
+	}
+
+	fields["Alert"] = alert.Name
+
+	notification := models.Notification{
+		Event:     alert.Type,
+		Severity:  severity,
+		Key:       alert.Name + ":" + subject,
+		Title:     fmt.Sprintf("[%s] %s", alert.Name, title),
+		Text:      text,
+		Fields:    fields,
+		Timestamp: now,
+	}
+
+	log.Warn().Str("alert", alert.Name).Str("subject", subject).Msg(title)
+
+	if a.send != nil {
+		a.send(notification, alert.Channels)
+		return
+	}
+
+	if a.notifier != nil {
+		a.notifier.NotifyChannels(notification, alert.Channels)
+	}
+}
@@ -0,0 +1,212 @@
+package limiter
+
+import (
+	"fmt"
+	"maps"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/x-sushant-x/RateShield/models"
+	"github.com/x-sushant-x/RateShield/service"
+	"github.com/x-sushant-x/RateShield/utils"
+)
+
+type sentAlert struct {
+	notification models.Notification
+	channels     []string
+}
+
+func newTestAlertService(alerts ...models.AlertRule) (*AlertService, *[]sentAlert) {
+	sent := []sentAlert{}
+
+	svc := NewAlertService(nil, nil, nil)
+	svc.send = func(notification models.Notification, channels []string) {
+		sent = append(sent, sentAlert{notification, channels})
+	}
+	svc.setRules(alerts)
+
+	return &svc, &sent
+}
+
+// alertState merges the state of every shard
+func alertState(svc *AlertService) (map[alertStateKey]*denyWindow, map[alertStateKey]*throttledClient, map[alertStateKey]time.Time) {
+	denyWindows := map[alertStateKey]*denyWindow{}
+	throttled := map[alertStateKey]*throttledClient{}
+	lastFired := map[alertStateKey]time.Time{}
+
+	for _, shard := range svc.shards {
+		shard.mutex.Lock()
+		maps.Copy(denyWindows, shard.denyWindows)
+		maps.Copy(throttled, shard.throttled)
+		maps.Copy(lastFired, shard.lastFired)
+		shard.mutex.Unlock()
+	}
+
+	return denyWindows, throttled, lastFired
+}
+
+func denyWindows(svc *AlertService) map[alertStateKey]*denyWindow {
+	windows, _, _ := alertState(svc)
+	return windows
+}
+
+func deniedResponse() *models.RateLimitResponse {
+	return utils.BuildRateLimitErrorResponse(http.StatusTooManyRequests)
+}
+
+func TestAlertDenyRate(t *testing.T) {
+	svc, sent := newTestAlertService(models.AlertRule{
+		Name: "denials", Type: models.AlertTypeDenyRate, Endpoint: "/api/v1/search",
+		Threshold: 50, Window: 60, MinRequests: 4, Cooldown: 300, Channels: []string{"pagerduty"},
+	})
+	start := time.Unix(1700000000, 0)
+
+	// 3 of 4 denied, evaluated by the first request after the window
+	for i := 0; i < 3; i++ {
+		svc.observeAt(start, "10.0.0.1", "/api/v1/search", nil, deniedResponse())
+	}
+	svc.observeAt(start, "10.0.0.2", "/api/v1/search", nil, utils.BuildRateLimitSuccessResponse(10, 9))
+	svc.observeAt(start, "10.0.0.1", "/api/v1/other", nil, deniedResponse())
+	assert.Empty(t, *sent)
+
+	svc.observeAt(start.Add(61*time.Second), "10.0.0.2", "/api/v1/search", nil, deniedResponse())
+	assert.Len(t, *sent, 1)
+	assert.Equal(t, models.AlertTypeDenyRate, (*sent)[0].notification.Event)
+	assert.Equal(t, models.NotificationSeverityWarning, (*sent)[0].notification.Severity)
+	assert.Equal(t, "denials:/api/v1/search", (*sent)[0].notification.Key)
+	assert.Equal(t, []string{"pagerduty"}, (*sent)[0].channels)
+
+	// The next window is also over the threshold but the cooldown is not over yet, the job evaluates it
+	svc.evaluateIdle(start.Add(122 * time.Second))
+	assert.Len(t, *sent, 1)
+	assert.Empty(t, denyWindows(svc))
+
+	// Windows below min_requests never fire
+	for i := 0; i < 3; i++ {
+		svc.observeAt(start.Add(400*time.Second), "10.0.0.1", "/api/v1/search", nil, deniedResponse())
+	}
+	svc.evaluateIdle(start.Add(461 * time.Second))
+	assert.Len(t, *sent, 1)
+}
+
+func TestAlertThrottledClient(t *testing.T) {
+	svc, sent := newTestAlertService(models.AlertRule{Name: "stuck", Type: models.AlertTypeThrottledClient, Duration: 120, Cooldown: 600})
+	start := time.Unix(1700000000, 0)
+
+	for i := 0; i <= 4; i++ {
+		svc.observeAt(start.Add(time.Duration(i)*30*time.Second), "customer-42", "/api/v1/search", nil, deniedResponse())
+	}
+	assert.Len(t, *sent, 1)
+	assert.Equal(t, map[string]string{"Alert": "stuck", "Client": "customer-42", "Endpoint": "/api/v1/search"}, (*sent)[0].notification.Fields)
+
+	// A successful request ends the throttling, a break longer than the gap too
+	svc.observeAt(start.Add(150*time.Second), "customer-7", "/api/v1/search", nil, deniedResponse())
+	svc.observeAt(start.Add(200*time.Second), "customer-7", "/api/v1/search", nil, utils.BuildRateLimitSuccessResponse(10, 0))
+	svc.observeAt(start.Add(250*time.Second), "customer-7", "/api/v1/search", nil, deniedResponse())
+	svc.observeAt(start.Add(340*time.Second), "customer-7", "/api/v1/search", nil, deniedResponse())
+	svc.observeAt(start.Add(380*time.Second), "customer-7", "/api/v1/search", nil, deniedResponse())
+	assert.Len(t, *sent, 1)
+
+	// A ban counts as a denial
+	svc.observeAt(start.Add(420*time.Second), "customer-7", "/api/v1/search", nil, utils.BuildRateLimitDeniedResponse("banned"))
+	svc.observeAt(start.Add(460*time.Second), "customer-7", "/api/v1/search", nil, utils.BuildRateLimitDeniedResponse("banned"))
+	assert.Len(t, *sent, 2)
+}
+
+func TestAlertQuotaExhaustion(t *testing.T) {
+	svc, sent := newTestAlertService(models.AlertRule{Name: "quota-90", Type: models.AlertTypeQuotaExhaustion, Threshold: 90, Cooldown: 300})
+	now := time.Unix(1700000000, 0)
+	quotaRule := &models.Rule{Strategy: models.StrategyQuota}
+	fixedWindowRule := &models.Rule{Strategy: models.StrategyFixedWindowCounter}
+
+	svc.observeAt(now, "customer-42", "/api/v1/search", quotaRule, utils.BuildRateLimitSuccessResponse(1000, 101))
+	svc.observeAt(now, "customer-42", "/api/v1/search", fixedWindowRule, utils.BuildRateLimitSuccessResponse(1000, 100))
+	assert.Empty(t, *sent)
+
+	svc.observeAt(now, "customer-42", "/api/v1/search", quotaRule, utils.BuildRateLimitSuccessResponse(1000, 100))
+	assert.Len(t, *sent, 1)
+	assert.Equal(t, "[quota-90] Quota 90% used", (*sent)[0].notification.Title)
+
+	assert.Equal(t, int64(1), quotaAlertCount(10, 5))
+	assert.Equal(t, int64(10), quotaAlertCount(10, 100))
+}
+
+func TestAlertRedisFallbackAndReload(t *testing.T) {
+	svc, sent := newTestAlertService(
+		models.AlertRule{Name: "fallback", Type: models.AlertTypeRedisFallback, Severity: models.NotificationSeverityCritical, Cooldown: 60},
+		models.AlertRule{Name: "denials", Type: models.AlertTypeDenyRate, Threshold: 50, Window: 60, Cooldown: 60},
+	)
+	now := time.Unix(1700000000, 0)
+
+	svc.observeFallbackAt(now, "/api/v1/search")
+	svc.observeFallbackAt(now.Add(30*time.Second), "/api/v1/search")
+	svc.observeFallbackAt(now.Add(30*time.Second), "/api/v1/create")
+	assert.Len(t, *sent, 2)
+	assert.Equal(t, models.NotificationSeverityCritical, (*sent)[0].notification.Severity)
+
+	svc.observeAt(now, "10.0.0.1", "/api/v1/search", nil, deniedResponse())
+	assert.Len(t, denyWindows(svc), 1)
+
+	// Removing an alert rule drops its state, the cooldowns of the others are kept
+	svc.setRules([]models.AlertRule{{Name: "fallback", Type: models.AlertTypeRedisFallback, Cooldown: 60}})
+	assert.Empty(t, denyWindows(svc))
+	svc.observeFallbackAt(now.Add(45*time.Second), "/api/v1/search")
+	assert.Len(t, *sent, 2)
+
+	svc.setRules(nil)
+	assert.False(t, svc.hasRules.Load())
+	_, _, lastFired := alertState(svc)
+	assert.Empty(t, lastFired)
+
+	// A limiter without alerts ignores every event
+	var noAlerts *AlertService
+	noAlerts.observe("10.0.0.1", "/api/v1/search", nil, deniedResponse())
+	noAlerts.observeFallback("/api/v1/search")
+	noAlerts.reload()
+}
+
+func TestAlertSharedDedup(t *testing.T) {
+	alert := models.AlertRule{Name: "fallback", Type: models.AlertTypeRedisFallback, Cooldown: 60}
+	first, sent := newTestAlertService(alert)
+	second, secondSent := newTestAlertService(alert)
+
+	store := service.NewMemoryNotificationDedup()
+	first.dedup = store
+	second.dedup = store
+
+	now := time.Unix(1700000000, 0)
+	first.observeFallbackAt(now, "/api/v1/search")
+	second.observeFallbackAt(now, "/api/v1/search")
+	second.observeFallbackAt(now, "/api/v1/create")
+
+	assert.Len(t, *sent, 1)
+	assert.Len(t, *secondSent, 1)
+	assert.Equal(t, "fallback:/api/v1/create", (*secondSent)[0].notification.Key)
+}
+
+func TestAlertConcurrentChecks(t *testing.T) {
+	svc, _ := newTestAlertService(models.AlertRule{Name: "denials", Type: models.AlertTypeDenyRate, Threshold: 50, Window: 60, Cooldown: 60})
+	now := time.Unix(1700000000, 0)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				svc.observeAt(now, "10.0.0.1", fmt.Sprintf("/api/v1/endpoint-%d", j%10), nil, deniedResponse())
+			}
+		}()
+	}
+	wg.Wait()
+
+	// Every endpoint has its own window, counting the checks of every goroutine
+	windows := denyWindows(svc)
+	assert.Len(t, windows, 10)
+	for _, window := range windows {
+		assert.Equal(t, int64(80), window.total)
+	}
+}
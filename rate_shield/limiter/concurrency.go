@@ -0,0 +1,137 @@
+package limiter
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog/log"
+	"github.com/x-sushant-x/RateShield/models"
+	"github.com/x-sushant-x/RateShield/utils"
+)
+
+const (
+	concurrencyKeyPrefix = "concurrency_"
+)
+
+var (
+	ErrLeaseNotFound      = errors.New("lease not found, it was already released or has expired")
+	ErrRuleNotConcurrency = errors.New("rule of the endpoint does not use the CONCURRENCY strategy")
+
+	// Drops expired leases and adds a new one unless the limit is reached. Leases are members of a sorted
+	// set scored by their expiry in milliseconds. Returns whether the lease was added and the active leases.
+	acquireLeaseScript = redis.NewScript(`
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', ARGV[1])
+local active = redis.call('ZCARD', KEYS[1])
+if active >= tonumber(ARGV[2]) then
+	return {0, active}
+end
+redis.call('ZADD', KEYS[1], ARGV[3], ARGV[4])
+redis.call('PEXPIREAT', KEYS[1], redis.call('ZRANGE', KEYS[1], -1, -1, 'WITHSCORES')[2])
+return {1, active + 1}
+`)
+
+	// Drops expired leases, then removes the lease. Returns 0 if the lease was not found, expired leases
+	// are gone already so a request that ran past its lease timeout can't release a place it no longer has.
+	releaseLeaseScript = redis.NewScript(`
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', ARGV[1])
+return redis.call('ZREM', KEYS[1], ARGV[2])
+`)
+)
+
+type ConcurrencyService struct {
+	redisClient *redis.ClusterClient
+}
+
+func NewConcurrencyService(redisClient *redis.ClusterClient) ConcurrencyService {
+	return ConcurrencyService{
+		redisClient: redisClient,
+	}
+}
+
+func (c *ConcurrencyService) processRequest(identity, endpoint string, rule *models.Rule) *models.RateLimitResponse {
+	concurrency := rule.ConcurrencyRule
+
+	leaseID, err := newLeaseID()
+	if err != nil {
+		log.Err(err).Msg("unable to create lease id")
+		return utils.BuildRateLimitErrorResponse(500)
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(time.Duration(concurrency.LeaseTimeout) * time.Second)
+	key := c.parseToKey(identity, endpoint, concurrency.Scope)
+
+	result, err := acquireLeaseScript.Run(ctx, c.redisClient, []string{key},
+		now.UnixMilli(), concurrency.MaxConcurrent, expiresAt.UnixMilli(), leaseID).Int64Slice()
+	if err != nil {
+		log.Err(err).Msg("unable to acquire lease")
+		return utils.BuildRateLimitErrorResponse(500)
+	}
+
+	acquired, active := result[0] == 1, result[1]
+	if !acquired {
+		return utils.BuildRateLimitErrorResponse(429)
+	}
+
+	resp := utils.BuildRateLimitSuccessResponse(concurrency.MaxConcurrent, concurrency.MaxConcurrent-active)
+	resp.LeaseID = leaseID
+	return resp
+}
+
+// release returns a lease so another request can take its place
+func (c *ConcurrencyService) release(identity, endpoint string, rule *models.Rule, leaseID string) error {
+	key := c.parseToKey(identity, endpoint, rule.ConcurrencyRule.Scope)
+
+	removed, err := releaseLeaseScript.Run(ctx, c.redisClient, []string{key}, time.Now().UnixMilli(), leaseID).Int64()
+	if err != nil {
+		return err
+	}
+
+	if removed == 0 {
+		return ErrLeaseNotFound
+	}
+	return nil
+}
+
+func (c *ConcurrencyService) getState(identity, endpoint string, rule *models.Rule) (*models.ConcurrencyState, bool, error) {
+	key := c.parseToKey(identity, endpoint, rule.ConcurrencyRule.Scope)
+
+	active, err := c.redisClient.ZCount(ctx, key, "("+strconv.FormatInt(time.Now().UnixMilli(), 10), "+inf").Result()
+	if err != nil {
+		return nil, false, err
+	}
+
+	if active == 0 {
+		return nil, false, nil
+	}
+
+	return &models.ConcurrencyState{
+		ActiveLeases:  active,
+		MaxConcurrent: rule.ConcurrencyRule.MaxConcurrent,
+		LeaseTimeout:  rule.ConcurrencyRule.LeaseTimeout,
+	}, true, nil
+}
+
+func (c *ConcurrencyService) reset(identity, endpoint string, rule *models.Rule) error {
+	return c.redisClient.Del(ctx, c.parseToKey(identity, endpoint, rule.ConcurrencyRule.Scope)).Err()
+}
+
+// parseToKey names the leases of a client, or of every client when the rule caps the endpoint as a whole
+func (c *ConcurrencyService) parseToKey(identity, endpoint, scope string) string {
+	if scope == models.ConcurrencyScopeEndpoint {
+		identity = ""
+	}
+	return concurrencyKeyPrefix + identity + ":" + endpoint
+}
+
+func newLeaseID() (string, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(id), nil
+}
@@ -0,0 +1,164 @@
+package limiter
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/x-sushant-x/RateShield/models"
+)
+
+func concurrencyRule(endpoint string, maxConcurrent int64, scope string) *models.Rule {
+	return &models.Rule{
+		APIEndpoint:     endpoint,
+		Strategy:        models.StrategyConcurrency,
+		ConcurrencyRule: &models.ConcurrencyRule{MaxConcurrent: maxConcurrent, LeaseTimeout: 30, Scope: scope},
+	}
+}
+
+func TestConcurrencyLeases(t *testing.T) {
+	endpoint := "/api/v1/export"
+	rule := concurrencyRule(endpoint, 2, "")
+
+	t.Run("leases up to the limit", func(t *testing.T) {
+		clusterClient, _ := newTestClusterClient(t)
+		svc := NewConcurrencyService(clusterClient)
+
+		first := svc.processRequest("10.0.0.1", endpoint, rule)
+		require.True(t, first.Success)
+		assert.NotEmpty(t, first.LeaseID)
+		assert.Equal(t, int64(1), first.RateLimit_Remaining)
+
+		second := svc.processRequest("10.0.0.1", endpoint, rule)
+		require.True(t, second.Success)
+		assert.NotEqual(t, first.LeaseID, second.LeaseID)
+		assert.Equal(t, int64(0), second.RateLimit_Remaining)
+
+		denied := svc.processRequest("10.0.0.1", endpoint, rule)
+		assert.False(t, denied.Success)
+		assert.Equal(t, http.StatusTooManyRequests, denied.HTTPStatusCode)
+
+		// Other clients have leases of their own
+		assert.True(t, svc.processRequest("10.0.0.2", endpoint, rule).Success)
+
+		// Releasing a lease frees its place
+		require.NoError(t, svc.release("10.0.0.1", endpoint, rule, first.LeaseID))
+		assert.True(t, svc.processRequest("10.0.0.1", endpoint, rule).Success)
+	})
+
+	t.Run("endpoint scope", func(t *testing.T) {
+		clusterClient, _ := newTestClusterClient(t)
+		svc := NewConcurrencyService(clusterClient)
+		endpointRule := concurrencyRule(endpoint, 1, models.ConcurrencyScopeEndpoint)
+
+		assert.True(t, svc.processRequest("10.0.0.1", endpoint, endpointRule).Success)
+		assert.Equal(t, http.StatusTooManyRequests, svc.processRequest("10.0.0.2", endpoint, endpointRule).HTTPStatusCode)
+	})
+
+	t.Run("expired leases are dropped", func(t *testing.T) {
+		clusterClient, mr := newTestClusterClient(t)
+		svc := NewConcurrencyService(clusterClient)
+		key := svc.parseToKey("10.0.0.1", endpoint, "")
+
+		// Leases of requests that never released them
+		expiredAt := float64(time.Now().Add(-time.Second).UnixMilli())
+		_, err := mr.ZAdd(key, expiredAt, "crashed-1")
+		require.NoError(t, err)
+		_, err = mr.ZAdd(key, expiredAt, "crashed-2")
+		require.NoError(t, err)
+
+		resp := svc.processRequest("10.0.0.1", endpoint, rule)
+		require.True(t, resp.Success)
+		assert.Equal(t, int64(1), resp.RateLimit_Remaining)
+
+		members, err := mr.ZMembers(key)
+		require.NoError(t, err)
+		assert.Equal(t, []string{resp.LeaseID}, members)
+
+		// The key expires with the last lease
+		assert.Greater(t, mr.TTL(key), time.Duration(0))
+	})
+
+	t.Run("release of an unknown lease", func(t *testing.T) {
+		clusterClient, _ := newTestClusterClient(t)
+		svc := NewConcurrencyService(clusterClient)
+
+		assert.ErrorIs(t, svc.release("10.0.0.1", endpoint, rule, "unknown"), ErrLeaseNotFound)
+
+		resp := svc.processRequest("10.0.0.1", endpoint, rule)
+		require.NoError(t, svc.release("10.0.0.1", endpoint, rule, resp.LeaseID))
+		assert.ErrorIs(t, svc.release("10.0.0.1", endpoint, rule, resp.LeaseID), ErrLeaseNotFound, "released twice")
+		assert.ErrorIs(t, svc.release("10.0.0.2", endpoint, rule, resp.LeaseID), ErrLeaseNotFound, "lease of another client")
+	})
+
+	t.Run("release of an expired lease", func(t *testing.T) {
+		clusterClient, mr := newTestClusterClient(t)
+		svc := NewConcurrencyService(clusterClient)
+		key := svc.parseToKey("10.0.0.1", endpoint, "")
+
+		// The request ran past its lease timeout and no other request purged the lease yet
+		_, err := mr.ZAdd(key, float64(time.Now().Add(-time.Second).UnixMilli()), "slow")
+		require.NoError(t, err)
+		active := svc.processRequest("10.0.0.2", endpoint, rule)
+		require.True(t, active.Success)
+
+		assert.ErrorIs(t, svc.release("10.0.0.1", endpoint, rule, "slow"), ErrLeaseNotFound)
+		assert.False(t, mr.Exists(key), "expired leases are purged")
+
+		require.NoError(t, svc.release("10.0.0.2", endpoint, rule, active.LeaseID))
+	})
+}
+
+func TestProcessConcurrencyReqAllowOnError(t *testing.T) {
+	endpoint := "/api/v1/export"
+	rule := concurrencyRule(endpoint, 1, "")
+	rule.AllowOnError = true
+
+	t.Run("requests over the limit stay rejected", func(t *testing.T) {
+		limiter := newTestStateLimiter(t, rule)
+
+		assert.True(t, limiter.processConcurrencyReq("10.0.0.1", endpoint, rule).Success)
+
+		resp := limiter.processConcurrencyReq("10.0.0.1", endpoint, rule)
+		assert.False(t, resp.Success)
+		assert.Equal(t, http.StatusTooManyRequests, resp.HTTPStatusCode)
+	})
+
+	t.Run("redis errors let requests through", func(t *testing.T) {
+		clusterClient, mr := newTestClusterClient(t)
+		concurrency := NewConcurrencyService(clusterClient)
+		limiter := &Limiter{concurrency: &concurrency}
+		mr.Close()
+
+		resp := limiter.processConcurrencyReq("10.0.0.1", endpoint, rule)
+		assert.True(t, resp.Success)
+		assert.Empty(t, resp.LeaseID)
+
+		rule := *rule
+		rule.AllowOnError = false
+		assert.Equal(t, http.StatusInternalServerError, limiter.processConcurrencyReq("10.0.0.1", endpoint, &rule).HTTPStatusCode)
+	})
+}
+
+func TestConcurrencyState(t *testing.T) {
+	clusterClient, mr := newTestClusterClient(t)
+	svc := NewConcurrencyService(clusterClient)
+	endpoint := "/api/v1/export"
+	rule := concurrencyRule(endpoint, 3, "")
+
+	_, found, err := svc.getState("10.0.0.1", endpoint, rule)
+	require.NoError(t, err)
+	assert.False(t, found)
+
+	svc.processRequest("10.0.0.1", endpoint, rule)
+	_, err = mr.ZAdd(svc.parseToKey("10.0.0.1", endpoint, ""), float64(time.Now().Add(-time.Second).UnixMilli()), "expired")
+	require.NoError(t, err)
+
+	// Expired leases are not counted
+	state, found, err := svc.getState("10.0.0.1", endpoint, rule)
+	require.NoError(t, err)
+	require.True(t, found)
+	assert.Equal(t, int64(1), state.ActiveLeases)
+}
@@ -9,6 +9,10 @@ import (
 	"github.com/x-sushant-x/RateShield/utils"
 )
 
+const (
+	fixedWindowKeyPrefix = "fixed_window_"
+)
+
 type FixedWindowService struct {
 	redisClient redisClient.RedisRateLimiterClient
 }
@@ -79,6 +83,7 @@ func (fw *FixedWindowService) handleError(err error, msg string) *models.RateLim
 }
 
 func (fw *FixedWindowService) ResetWindow(key string, currTime int64, fixedWindow *models.FixedWindowCounter) *models.RateLimitResponse {
+	fixedWindow.CreatedAt = currTime
 	fixedWindow.CurrRequests = 1
 	fixedWindow.LastAccessTime = currTime
 	return fw.saveFixedWindow(key, fixedWindow)
@@ -140,5 +145,39 @@ func (fw *FixedWindowService) save(key string, fixedWindow *models.FixedWindowCo
 }
 
 func (fw *FixedWindowService) parseToKey(ip, endpoint string) string {
-	return "fixed_window_" + ip + ":" + endpoint
+	return fixedWindowKeyPrefix + ip + ":" + endpoint
+}
+
+// grantRequests lowers the request count of the client's current window so it can make extra requests
+// before the window resets. A window is created if the client does not have one yet.
+func (fw *FixedWindowService) grantRequests(ip, endpoint string, rule *models.Rule, extra int64) (*models.FixedWindowCounter, error) {
+	key := fw.parseToKey(ip, endpoint)
+	fixedWindow, found, err := fw.getFixedWindowFromRedis(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if found {
+		fixedWindow.CurrRequests -= extra
+		return fixedWindow, fw.save(key, fixedWindow)
+	}
+
+	newWindow := fw.makeFixedWindowCounter(ip, endpoint, rule)
+	newWindow.CurrRequests = -extra
+
+	if err := fw.save(key, &newWindow); err != nil {
+		log.Err(err).Msg("unable to save fixed window to redis")
+		return nil, err
+	}
+
+	err = fw.redisClient.Expire(key, time.Duration(newWindow.Window)*time.Second)
+	if err != nil {
+		log.Err(err).Msg("unable to set expire time of fixed window in redis")
+		return nil, err
+	}
+	return &newWindow, nil
+}
+
+func (fw *FixedWindowService) reset(ip, endpoint string) error {
+	return fw.redisClient.Delete(fw.parseToKey(ip, endpoint))
 }
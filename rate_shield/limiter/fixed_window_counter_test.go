@@ -68,6 +68,7 @@ func TestProcessRequest(t *testing.T) {
 
 		fixedWindow := &models.FixedWindowCounter{
 			MaxRequests:    10,
+			CreatedAt:      time.Now().Unix() - 30,
 			CurrRequests:   5,
 			Window:         60,
 			LastAccessTime: time.Now().Unix() - 30,
@@ -98,6 +99,7 @@ func TestProcessRequest(t *testing.T) {
 
 		fixedWindow := &models.FixedWindowCounter{
 			MaxRequests:    10,
+			CreatedAt:      time.Now().Unix() - 30,
 			CurrRequests:   10,
 			Window:         60,
 			LastAccessTime: time.Now().Unix() - 30,
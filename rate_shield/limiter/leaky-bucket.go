@@ -1,5 +1,5 @@
 /*
-	Important - This strategy is not ready to be used by Rate Shield yet. Please ignore this if you are using Rate Shield as library.
+	Important - This strategy is not ready to be used by Rate Shield yet. To use Rate Shield as a library, see the ratelimit package.
 */
 
 package limiter
@@ -1,11 +1,13 @@
 package limiter
 
 import (
+	"net/http"
 	"sync"
 	"time"
 
 	"github.com/rs/zerolog/log"
 	"github.com/x-sushant-x/RateShield/models"
+	redisClient "github.com/x-sushant-x/RateShield/redis"
 	"github.com/x-sushant-x/RateShield/service"
 	"github.com/x-sushant-x/RateShield/utils"
 )
@@ -18,19 +20,42 @@ type Limiter struct {
 	tokenBucket   *TokenBucketService
 	fixedWindow   *FixedWindowService
 	slidingWindow *SlidingWindowService
+	quota         *QuotaService
+	concurrency   *ConcurrencyService
+	penalty       *PenaltyService
 	redisRuleSvc  service.RulesService
+	keyScanner    redisClient.RedisKeyScanner
+	accessListSvc service.AccessListService
+	tierSvc       service.TierService
+	alerts        *AlertService
 	cachedRules   *map[string]*models.Rule
+	rulesLoadErr  error // Error of the last rules reload, the cached rules are empty while it is set
 	rulesMutex    sync.RWMutex
+
+	globalIPMatchers ipMatchers
+	ruleIPMatchers   map[string]ipMatchers
+
+	tierCache   map[string]cachedTier
+	tierInserts int
+	tierMutex   sync.Mutex
 }
 
 func NewRateLimiterService(
-	tokenBucket *TokenBucketService, fixedWindow *FixedWindowService, slidingWindow *SlidingWindowService, redisRuleSvc service.RulesService) Limiter {
+	tokenBucket *TokenBucketService, fixedWindow *FixedWindowService, slidingWindow *SlidingWindowService, quota *QuotaService, concurrency *ConcurrencyService, penalty *PenaltyService, redisRuleSvc service.RulesService, keyScanner redisClient.RedisKeyScanner, accessListSvc service.AccessListService, tierSvc service.TierService, alerts *AlertService) Limiter {
 
 	return Limiter{
 		tokenBucket:   tokenBucket,
 		fixedWindow:   fixedWindow,
 		redisRuleSvc:  redisRuleSvc,
 		slidingWindow: slidingWindow,
+		quota:         quota,
+		concurrency:   concurrency,
+		penalty:       penalty,
+		keyScanner:    keyScanner,
+		accessListSvc: accessListSvc,
+		tierSvc:       tierSvc,
+		alerts:        alerts,
+		tierCache:     make(map[string]cachedTier),
 		// This is initialized later in StartRateLimiter() function
 		cachedRules: nil,
 		rulesMutex:  sync.RWMutex{},
@@ -38,25 +63,62 @@ func NewRateLimiterService(
 }
 
 func (l *Limiter) CheckLimit(ip, endpoint string) *models.RateLimitResponse {
-	key := ip + ":" + endpoint
+	return l.CheckLimitFor(models.RateLimitRequest{ClientIP: ip, Endpoint: endpoint})
+}
 
-	l.rulesMutex.RLock()
-	rulesMap := *l.cachedRules
-	l.rulesMutex.RUnlock()
+// CheckLimitFor checks the limit of a client described by its IP and optional client ID and tier. Clients
+// with a client ID are counted by it instead of their IP, access lists always match the IP.
+func (l *Limiter) CheckLimitFor(req models.RateLimitRequest) *models.RateLimitResponse {
+	identity := req.Identity()
+	endpoint := req.Endpoint
 
-	rule, found := rulesMap[endpoint]
+	if resp := l.checkAccessLists(req.ClientIP, endpoint); resp != nil {
+		return resp
+	}
+
+	rule := l.getCachedRule(endpoint)
+	if rule == nil {
+		return utils.BuildRateLimitSuccessResponse(0, 0)
+	}
 
-	if found {
-		switch rule.Strategy {
-		case "TOKEN BUCKET":
-			return l.processTokenBucketReq(key, rule)
-		case "FIXED WINDOW COUNTER":
-			return l.processFixedWindowReq(ip, endpoint, rule)
-		case "SLIDING WINDOW COUNTER":
-			return l.processSlidingWindowReq(ip, endpoint, rule)
+	// The penalty policy is set on the stored rule and also applies while a scheduled rule is active
+	var penalty *models.PenaltyPolicy
+	if baseRule := l.getBaseRule(endpoint); baseRule != nil {
+		penalty = baseRule.Penalty
+	}
+
+	var resp *models.RateLimitResponse
+	if penalty != nil {
+		resp = l.penalty.checkBan(identity, endpoint)
+	}
+
+	if resp == nil {
+		resp = l.processRuleReq(identity, endpoint, l.applyClientLimits(req, rule))
+
+		if penalty != nil && resp.HTTPStatusCode == http.StatusTooManyRequests {
+			l.penalty.recordDenial(identity, endpoint, *penalty)
 		}
 	}
 
+	l.alerts.observe(identity, endpoint, rule, resp)
+
+	return resp
+}
+
+func (l *Limiter) processRuleReq(identity, endpoint string, rule *models.Rule) *models.RateLimitResponse {
+	switch rule.Strategy {
+	case models.StrategyTokenBucket:
+		return l.processTokenBucketReq(identity+":"+endpoint, rule)
+	case models.StrategyFixedWindowCounter:
+		return l.processFixedWindowReq(identity, endpoint, rule)
+	case models.StrategySlidingWindowCounter:
+		return l.processSlidingWindowReq(identity, endpoint, rule)
+	case models.StrategyQuota:
+		return l.processQuotaReq(identity, endpoint, rule)
+	case models.StrategyConcurrency:
+		return l.processConcurrencyReq(identity, endpoint, rule)
+	}
+
 	return utils.BuildRateLimitSuccessResponse(0, 0)
 }
 
@@ -68,6 +130,7 @@ func (l *Limiter) processTokenBucketReq(key string, rule *models.Rule) *models.R
 	}
 
 	if rule.AllowOnError {
+		l.alerts.observeFallback(rule.APIEndpoint)
 		return utils.BuildRateLimitSuccessResponse(0, 0)
 	}
 
@@ -82,6 +145,7 @@ func (l *Limiter) processFixedWindowReq(ip, endpoint string, rule *models.Rule)
 	}
 
 	if rule.AllowOnError {
+		l.alerts.observeFallback(rule.APIEndpoint)
 		return utils.BuildRateLimitSuccessResponse(0, 0)
 	}
 
@@ -96,21 +160,85 @@ func (l *Limiter) processSlidingWindowReq(ip, endpoint string, rule *models.Rule
 	}
 
 	if rule.AllowOnError {
+		l.alerts.observeFallback(rule.APIEndpoint)
+		return utils.BuildRateLimitSuccessResponse(0, 0)
+	}
+
+	return resp
+}
+
+func (l *Limiter) processQuotaReq(identity, endpoint string, rule *models.Rule) *models.RateLimitResponse {
+	resp := l.quota.processRequest(identity, endpoint, rule)
+
+	if resp.Success {
+		return resp
+	}
+
+	// A used up quota is not an error, it stays rejected until the period ends
+	if rule.AllowOnError && resp.HTTPStatusCode != http.StatusTooManyRequests {
+		l.alerts.observeFallback(rule.APIEndpoint)
+		return utils.BuildRateLimitSuccessResponse(0, 0)
+	}
+
+	return resp
+}
+
+func (l *Limiter) processConcurrencyReq(identity, endpoint string, rule *models.Rule) *models.RateLimitResponse {
+	resp := l.concurrency.processRequest(identity, endpoint, rule)
+
+	if resp.Success {
+		return resp
+	}
+
+	// Requests over the limit stay rejected, letting them through would defeat the cap
+	if rule.AllowOnError && resp.HTTPStatusCode != http.StatusTooManyRequests {
+		l.alerts.observeFallback(rule.APIEndpoint)
 		return utils.BuildRateLimitSuccessResponse(0, 0)
 	}
 
 	return resp
 }
 
+// ReleaseLease returns a lease acquired by a CONCURRENCY check. The request must describe the same client
+// and endpoint as the check.
+func (l *Limiter) ReleaseLease(req models.RateLimitRequest, leaseID string) error {
+	if leaseID == "" {
+		return ErrLeaseNotFound
+	}
+
+	rule := l.concurrencyRule(req.Endpoint)
+	if rule == nil {
+		return ErrRuleNotConcurrency
+	}
+
+	return l.concurrency.release(req.Identity(), req.Endpoint, rule, leaseID)
+}
+
+// concurrencyRule returns the CONCURRENCY rule of an endpoint. The stored rule is used when a scheduled rule
+// with another strategy is active, so leases taken before it became active can still be released.
+func (l *Limiter) concurrencyRule(endpoint string) *models.Rule {
+	if rule := l.getCachedRule(endpoint); rule != nil && rule.Strategy == models.StrategyConcurrency {
+		return rule
+	}
+
+	if rule := l.getBaseRule(endpoint); rule != nil && rule.Strategy == models.StrategyConcurrency {
+		return rule
+	}
+
+	return nil
+}
+
 func (l *Limiter) GetRule(key string) (*models.Rule, bool, error) {
 	return l.redisRuleSvc.GetRule(key)
 }
 
 func (l *Limiter) StartRateLimiter() {
 	log.Info().Msg("Starting limiter service ✅")
-	l.cachedRules = l.redisRuleSvc.CacheRulesLocally()
+	l.reloadRules()
 	log.Info().Msgf("Total Rules: %d", len(*l.cachedRules))
 
+	l.alerts.startAlertJob()
+
 	// Not required for now.
 	//l.tokenBucket.startAddTokenJob()
 	go l.listenToRulesUpdate()
@@ -124,11 +252,48 @@ func (l *Limiter) listenToRulesUpdate() {
 		data := <-updatesChannel
 
 		if data == "UpdateRules" {
-			l.rulesMutex.Lock()
-			l.cachedRules = l.redisRuleSvc.CacheRulesLocally()
-			l.rulesMutex.Unlock()
-
+			l.reloadRules()
 			log.Info().Msg("Rules Updated Successfully")
 		}
 	}
 }
+
+// CheckRules returns the error of the last rules reload. A failed reload is retried first, so the rules
+// are cached again once the Redis rules instance is back without waiting for the next rule update.
+func (l *Limiter) CheckRules() error {
+	l.rulesMutex.RLock()
+	err := l.rulesLoadErr
+	l.rulesMutex.RUnlock()
+
+	if err == nil {
+		return nil
+	}
+
+	l.reloadRules()
+
+	l.rulesMutex.RLock()
+	defer l.rulesMutex.RUnlock()
+	return l.rulesLoadErr
+}
+
+// reloadRules caches the rules, compiles the allow and deny lists, drops the cached client tiers and
+// reloads the alert rules
+func (l *Limiter) reloadRules() {
+	rules, err := l.redisRuleSvc.CacheRulesLocally()
+
+	l.rulesMutex.RLock()
+	previousGlobalIPMatchers := l.globalIPMatchers
+	l.rulesMutex.RUnlock()
+
+	globalIPMatchers, ruleIPMatchers := l.buildIPMatchers(*rules, previousGlobalIPMatchers)
+
+	l.rulesMutex.Lock()
+	l.cachedRules = rules
+	l.rulesLoadErr = err
+	l.globalIPMatchers = globalIPMatchers
+	l.ruleIPMatchers = ruleIPMatchers
+	l.rulesMutex.Unlock()
+
+	l.clearTierCache()
+	l.alerts.reload()
+}
@@ -0,0 +1,316 @@
+package limiter
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/x-sushant-x/RateShield/models"
+	redisClient "github.com/x-sushant-x/RateShield/redis"
+	"github.com/x-sushant-x/RateShield/utils"
+)
+
+var (
+	ErrNoRuleForEndpoint       = errors.New("no rate limit rule defined for endpoint")
+	ErrInvalidExtraQuota       = errors.New("invalid extra quota. Must be greater than 0")
+	ErrStrategyNotSupportGrant = errors.New("strategy of the rule does not support granting extra quota")
+	ErrUnknownStrategy         = errors.New("unknown rate limit strategy")
+)
+
+// GetClientState returns the state held by every strategy for a client on an endpoint.
+func (l *Limiter) GetClientState(ip, endpoint string) (*models.LimiterState, error) {
+	rule := l.getCachedRule(endpoint)
+
+	state := &models.LimiterState{
+		ClientIP: ip,
+		Endpoint: endpoint,
+	}
+
+	if rule != nil {
+		state.Strategy = rule.Strategy
+	}
+
+	bucket, found, err := l.tokenBucket.getBucket(ip + ":" + endpoint)
+	if err != nil {
+		return nil, err
+	}
+	if found {
+		state.TokenBucket = bucket
+	}
+
+	fixedWindow, found, err := l.fixedWindow.getFixedWindowFromRedis(l.fixedWindow.parseToKey(ip, endpoint))
+	if err != nil {
+		return nil, err
+	}
+	if found {
+		state.FixedWindow = fixedWindow
+	}
+
+	slidingWindow, found, err := l.slidingWindow.getState(ip, endpoint, rule)
+	if err != nil {
+		return nil, err
+	}
+	if found {
+		state.SlidingWindow = slidingWindow
+	}
+
+	// Quota counters are per period, only the current period of the rule's quota is looked up
+	if rule != nil && rule.Strategy == models.StrategyQuota {
+		usage, err := l.quota.getUsage(ip, endpoint, *l.clientRule(ip, endpoint, rule).QuotaRule)
+		if err != nil {
+			return nil, err
+		}
+		state.Quota = usage
+	}
+
+	if rule := l.concurrencyRule(endpoint); rule != nil {
+		concurrency, found, err := l.concurrency.getState(ip, endpoint, l.clientRule(ip, endpoint, rule))
+		if err != nil {
+			return nil, err
+		}
+		if found {
+			state.Concurrency = concurrency
+		}
+	}
+
+	return state, nil
+}
+
+// ResetClientState removes the state of every strategy for a client on an endpoint, so its next
+// request starts with a full quota.
+func (l *Limiter) ResetClientState(ip, endpoint string) error {
+	if err := l.tokenBucket.reset(ip + ":" + endpoint); err != nil {
+		return err
+	}
+
+	if err := l.fixedWindow.reset(ip, endpoint); err != nil {
+		return err
+	}
+
+	if rule := l.getCachedRule(endpoint); rule != nil && rule.Strategy == models.StrategyQuota {
+		if err := l.quota.reset(ip, endpoint, *rule.QuotaRule); err != nil {
+			return err
+		}
+	}
+
+	if rule := l.concurrencyRule(endpoint); rule != nil {
+		if err := l.concurrency.reset(ip, endpoint, rule); err != nil {
+			return err
+		}
+	}
+
+	return l.slidingWindow.reset(ip, endpoint)
+}
+
+// GrantExtraQuota lets a client make extra requests on an endpoint. Token buckets and fixed windows get
+// them on top of what the endpoint's rule allows, a sliding window can only free requests the client
+// already made in it. The grant only applies to the current bucket or window and is gone once it resets.
+func (l *Limiter) GrantExtraQuota(ip, endpoint string, extra int64) (*models.LimiterState, error) {
+	if extra <= 0 {
+		return nil, ErrInvalidExtraQuota
+	}
+
+	rule := l.getCachedRule(endpoint)
+	if rule == nil {
+		return nil, ErrNoRuleForEndpoint
+	}
+
+	var err error
+
+	switch rule.Strategy {
+	case models.StrategyTokenBucket:
+		_, err = l.tokenBucket.grantTokens(ip+":"+endpoint, rule, int(extra))
+	case models.StrategyFixedWindowCounter:
+		_, err = l.fixedWindow.grantRequests(ip, endpoint, rule, extra)
+	case models.StrategySlidingWindowCounter:
+		err = l.slidingWindow.releaseRequests(ip, endpoint, extra)
+	case models.StrategyQuota:
+		err = l.quota.grantRequests(ip, endpoint, *rule.QuotaRule, extra)
+	default:
+		return nil, ErrStrategyNotSupportGrant
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return l.GetClientState(ip, endpoint)
+}
+
+// ListClientStates pages through the buckets or windows every client holds on an endpoint. When strategy
+// is empty the strategy of the endpoint's rule is used.
+func (l *Limiter) ListClientStates(endpoint, strategy, cursor string, count int64) (*models.PaginatedLimiterKeys, error) {
+	rule := l.getCachedRule(endpoint)
+
+	if strategy == "" {
+		if rule == nil {
+			return nil, ErrNoRuleForEndpoint
+		}
+		strategy = rule.Strategy
+	}
+
+	prefix, err := l.keyPrefixForStrategy(strategy, rule)
+	if err != nil {
+		return nil, err
+	}
+
+	suffix := ":" + endpoint
+	keys, nextCursor, err := l.keyScanner.ScanKeysPage(prefix+"*"+redisClient.EscapeMatchPattern(suffix), cursor, count)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]models.LimiterKeyEntry, 0, len(keys))
+
+	for _, key := range keys {
+		ip := strings.TrimSuffix(strings.TrimPrefix(key, prefix), suffix)
+
+		entry, found, err := l.getKeyEntry(strategy, ip, endpoint, rule)
+		if err != nil {
+			return nil, err
+		}
+
+		// Key expired between the scan and the lookup
+		if !found {
+			continue
+		}
+
+		entry.Key = key
+		entries = append(entries, *entry)
+	}
+
+	return &models.PaginatedLimiterKeys{
+		Endpoint:    endpoint,
+		Strategy:    strategy,
+		NextCursor:  nextCursor,
+		HasNextPage: nextCursor != "",
+		Entries:     entries,
+	}, nil
+}
+
+func (l *Limiter) getKeyEntry(strategy, ip, endpoint string, rule *models.Rule) (*models.LimiterKeyEntry, bool, error) {
+	switch strategy {
+	case models.StrategyTokenBucket:
+		bucket, found, err := l.tokenBucket.getBucket(ip + ":" + endpoint)
+		if err != nil || !found {
+			return nil, false, err
+		}
+
+		return &models.LimiterKeyEntry{
+			ClientIP:  ip,
+			Used:      max(int64(bucket.Capacity-bucket.AvailableTokens), 0), // Granted tokens go above the capacity
+			Limit:     int64(bucket.Capacity),
+			Remaining: int64(bucket.AvailableTokens),
+		}, true, nil
+	case models.StrategyFixedWindowCounter:
+		fixedWindow, found, err := l.fixedWindow.getFixedWindowFromRedis(l.fixedWindow.parseToKey(ip, endpoint))
+		if err != nil || !found {
+			return nil, false, err
+		}
+
+		return &models.LimiterKeyEntry{
+			ClientIP:  ip,
+			Used:      max(fixedWindow.CurrRequests, 0), // Granted requests go below 0
+			Limit:     fixedWindow.MaxRequests,
+			Remaining: fixedWindow.MaxRequests - fixedWindow.CurrRequests,
+		}, true, nil
+	case models.StrategySlidingWindowCounter:
+		slidingWindow, found, err := l.slidingWindow.getState(ip, endpoint, rule)
+		if err != nil || !found {
+			return nil, false, err
+		}
+
+		return &models.LimiterKeyEntry{
+			ClientIP:  ip,
+			Used:      slidingWindow.ActiveRequests,
+			Limit:     slidingWindow.MaxRequests,
+			Remaining: max(slidingWindow.MaxRequests-slidingWindow.ActiveRequests, 0),
+		}, true, nil
+	case models.StrategyQuota:
+		if rule == nil || rule.QuotaRule == nil {
+			return nil, false, ErrNoRuleForEndpoint
+		}
+
+		usage, err := l.quota.getUsage(ip, endpoint, *l.clientRule(ip, endpoint, rule).QuotaRule)
+		if err != nil {
+			return nil, false, err
+		}
+
+		return &models.LimiterKeyEntry{
+			ClientIP:  ip,
+			Used:      usage.Used,
+			Limit:     usage.Limit,
+			Remaining: usage.Remaining,
+		}, true, nil
+	case models.StrategyConcurrency:
+		rule = l.concurrencyRule(endpoint)
+		if rule == nil {
+			return nil, false, ErrNoRuleForEndpoint
+		}
+
+		concurrency, found, err := l.concurrency.getState(ip, endpoint, l.clientRule(ip, endpoint, rule))
+		if err != nil || !found {
+			return nil, false, err
+		}
+
+		return &models.LimiterKeyEntry{
+			ClientIP:  ip,
+			Used:      concurrency.ActiveLeases,
+			Limit:     concurrency.MaxConcurrent,
+			Remaining: max(concurrency.MaxConcurrent-concurrency.ActiveLeases, 0),
+		}, true, nil
+	}
+
+	return nil, false, ErrUnknownStrategy
+}
+
+func (l *Limiter) keyPrefixForStrategy(strategy string, rule *models.Rule) (string, error) {
+	switch strategy {
+	case models.StrategyTokenBucket:
+		return tokenBucketKeyPrefix, nil
+	case models.StrategyFixedWindowCounter:
+		return fixedWindowKeyPrefix, nil
+	case models.StrategySlidingWindowCounter:
+		return slidingWindowKeyPrefix, nil
+	case models.StrategyQuota:
+		// Only counters of the current period are listed, that needs the period of the rule's quota
+		if rule == nil || rule.QuotaRule == nil {
+			return "", ErrNoRuleForEndpoint
+		}
+		return l.quota.keyPrefix(*rule.QuotaRule)
+	case models.StrategyConcurrency:
+		return concurrencyKeyPrefix, nil
+	}
+
+	return "", ErrUnknownStrategy
+}
+
+// clientRule returns the rule with the limits of a client's override or tier
+func (l *Limiter) clientRule(identity, endpoint string, rule *models.Rule) *models.Rule {
+	return l.applyClientLimits(models.RateLimitRequest{ClientID: identity, Endpoint: endpoint}, rule)
+}
+
+// getCachedRule returns the rule that applies to an endpoint right now, taking the schedule and expiry of
+// the cached rule into account
+func (l *Limiter) getCachedRule(endpoint string) *models.Rule {
+	l.rulesMutex.RLock()
+	defer l.rulesMutex.RUnlock()
+
+	if l.cachedRules == nil {
+		return nil
+	}
+
+	return utils.SelectActiveRule((*l.cachedRules)[endpoint], time.Now())
+}
+
+// getBaseRule returns the rule stored for an endpoint, regardless of its schedule
+func (l *Limiter) getBaseRule(endpoint string) *models.Rule {
+	l.rulesMutex.RLock()
+	defer l.rulesMutex.RUnlock()
+
+	if l.cachedRules == nil {
+		return nil
+	}
+
+	return (*l.cachedRules)[endpoint]
+}
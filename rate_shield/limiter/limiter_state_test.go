@@ -0,0 +1,248 @@
+package limiter
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/x-sushant-x/RateShield/models"
+	"github.com/x-sushant-x/RateShield/service"
+)
+
+// memoryRateLimiterClient stores JSON documents in a map, like RedisJSON does for token buckets and windows
+type memoryRateLimiterClient struct {
+	docs map[string]string
+}
+
+func newMemoryRateLimiterClient() *memoryRateLimiterClient {
+	return &memoryRateLimiterClient{docs: map[string]string{}}
+}
+
+func (m *memoryRateLimiterClient) JSONSet(key string, val interface{}) error {
+	data, err := json.Marshal(val)
+	if err != nil {
+		return err
+	}
+	m.docs[key] = string(data)
+	return nil
+}
+
+func (m *memoryRateLimiterClient) JSONGet(key string) (string, bool, error) {
+	data, found := m.docs[key]
+	return data, found, nil
+}
+
+func (m *memoryRateLimiterClient) Expire(key string, expireTime time.Duration) error {
+	return nil
+}
+
+func (m *memoryRateLimiterClient) Delete(key string) error {
+	delete(m.docs, key)
+	return nil
+}
+
+// newTestClusterClient connects a cluster client to an in-memory Redis that runs the Lua scripts
+func newTestClusterClient(t *testing.T) (*redis.ClusterClient, *miniredis.Miniredis) {
+	mr := miniredis.RunT(t)
+
+	client := redis.NewClusterClient(&redis.ClusterOptions{Addrs: []string{mr.Addr()}})
+	t.Cleanup(func() { client.Close() })
+
+	return client, mr
+}
+
+func newTestStateLimiter(t *testing.T, rules ...*models.Rule) *Limiter {
+	cachedRules := map[string]*models.Rule{}
+	for _, rule := range rules {
+		cachedRules[rule.APIEndpoint] = rule
+	}
+
+	clusterClient, _ := newTestClusterClient(t)
+	rateLimitClient := newMemoryRateLimiterClient()
+
+	tokenBucket := NewTokenBucketService(rateLimitClient, service.NewErrorNotificationSVC(service.NewMultiNotifier(), nil, 0))
+	fixedWindow := NewFixedWindowService(rateLimitClient)
+	slidingWindow := NewSlidingWindowService(clusterClient)
+	concurrency := NewConcurrencyService(clusterClient)
+
+	return &Limiter{
+		cachedRules:   &cachedRules,
+		tokenBucket:   &tokenBucket,
+		fixedWindow:   &fixedWindow,
+		slidingWindow: &slidingWindow,
+		concurrency:   &concurrency,
+	}
+}
+
+func TestClientStateTokenBucket(t *testing.T) {
+	rule := &models.Rule{
+		APIEndpoint:     "/api/v1/search",
+		Strategy:        "TOKEN BUCKET",
+		TokenBucketRule: &models.TokenBucketRule{BucketCapacity: 3, TokenAddRate: 1, RetentionTime: 60},
+	}
+	limiter := newTestStateLimiter(t, rule)
+
+	state, err := limiter.GetClientState("10.0.0.1", rule.APIEndpoint)
+	require.NoError(t, err)
+	assert.Equal(t, "TOKEN BUCKET", state.Strategy)
+	assert.Nil(t, state.TokenBucket)
+
+	for range 3 {
+		assert.True(t, limiter.processTokenBucketReq("10.0.0.1:"+rule.APIEndpoint, rule).Success)
+	}
+	assert.Equal(t, http.StatusTooManyRequests, limiter.processTokenBucketReq("10.0.0.1:"+rule.APIEndpoint, rule).HTTPStatusCode)
+
+	state, err = limiter.GetClientState("10.0.0.1", rule.APIEndpoint)
+	require.NoError(t, err)
+	require.NotNil(t, state.TokenBucket)
+	assert.Equal(t, 0, state.TokenBucket.AvailableTokens)
+
+	// Granted tokens go on top of the capacity
+	state, err = limiter.GrantExtraQuota("10.0.0.1", rule.APIEndpoint, 5)
+	require.NoError(t, err)
+	assert.Equal(t, 5, state.TokenBucket.AvailableTokens)
+	assert.Equal(t, 3, state.TokenBucket.Capacity)
+
+	resp := limiter.processTokenBucketReq("10.0.0.1:"+rule.APIEndpoint, rule)
+	assert.True(t, resp.Success)
+	assert.Equal(t, int64(4), resp.RateLimit_Remaining)
+
+	// A client without a bucket gets a full one plus the grant
+	state, err = limiter.GrantExtraQuota("10.0.0.2", rule.APIEndpoint, 2)
+	require.NoError(t, err)
+	assert.Equal(t, 5, state.TokenBucket.AvailableTokens)
+
+	require.NoError(t, limiter.ResetClientState("10.0.0.1", rule.APIEndpoint))
+
+	state, err = limiter.GetClientState("10.0.0.1", rule.APIEndpoint)
+	require.NoError(t, err)
+	assert.Nil(t, state.TokenBucket)
+
+	resp = limiter.processTokenBucketReq("10.0.0.1:"+rule.APIEndpoint, rule)
+	assert.True(t, resp.Success)
+	assert.Equal(t, int64(2), resp.RateLimit_Remaining)
+}
+
+func TestClientStateFixedWindow(t *testing.T) {
+	rule := &models.Rule{
+		APIEndpoint:            "/api/v1/orders",
+		Strategy:               "FIXED WINDOW COUNTER",
+		FixedWindowCounterRule: &models.FixedWindowCounterRule{MaxRequests: 2, Window: 60},
+	}
+	limiter := newTestStateLimiter(t, rule)
+
+	for range 2 {
+		assert.True(t, limiter.processFixedWindowReq("10.0.0.1", rule.APIEndpoint, rule).Success)
+	}
+	assert.Equal(t, http.StatusTooManyRequests, limiter.processFixedWindowReq("10.0.0.1", rule.APIEndpoint, rule).HTTPStatusCode)
+
+	state, err := limiter.GetClientState("10.0.0.1", rule.APIEndpoint)
+	require.NoError(t, err)
+	require.NotNil(t, state.FixedWindow)
+	assert.Equal(t, int64(2), state.FixedWindow.CurrRequests)
+
+	state, err = limiter.GrantExtraQuota("10.0.0.1", rule.APIEndpoint, 3)
+	require.NoError(t, err)
+	assert.Equal(t, int64(-1), state.FixedWindow.CurrRequests)
+
+	for range 3 {
+		assert.True(t, limiter.processFixedWindowReq("10.0.0.1", rule.APIEndpoint, rule).Success)
+	}
+	assert.Equal(t, http.StatusTooManyRequests, limiter.processFixedWindowReq("10.0.0.1", rule.APIEndpoint, rule).HTTPStatusCode)
+
+	// A grant before the first request opens a window the client starts in with the extra requests
+	state, err = limiter.GrantExtraQuota("10.0.0.2", rule.APIEndpoint, 1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(-1), state.FixedWindow.CurrRequests)
+	assert.Equal(t, int64(2), state.FixedWindow.MaxRequests)
+
+	require.NoError(t, limiter.ResetClientState("10.0.0.1", rule.APIEndpoint))
+
+	state, err = limiter.GetClientState("10.0.0.1", rule.APIEndpoint)
+	require.NoError(t, err)
+	assert.Nil(t, state.FixedWindow)
+	assert.True(t, limiter.processFixedWindowReq("10.0.0.1", rule.APIEndpoint, rule).Success)
+}
+
+func TestClientStateSlidingWindow(t *testing.T) {
+	rule := &models.Rule{
+		APIEndpoint:              "/api/v1/feed",
+		Strategy:                 "SLIDING WINDOW COUNTER",
+		SlidingWindowCounterRule: &models.SlidingWindowCounterRule{MaxRequests: 5, WindowSize: 60},
+	}
+	limiter := newTestStateLimiter(t, rule)
+
+	assert.True(t, limiter.processSlidingWindowReq("10.0.0.1", rule.APIEndpoint, rule).Success)
+
+	state, err := limiter.GetClientState("10.0.0.1", rule.APIEndpoint)
+	require.NoError(t, err)
+	require.NotNil(t, state.SlidingWindow)
+	assert.Equal(t, int64(1), state.SlidingWindow.ActiveRequests)
+
+	// A grant only frees the requests already made, granting more than that leaves an empty window
+	state, err = limiter.GrantExtraQuota("10.0.0.1", rule.APIEndpoint, 3)
+	require.NoError(t, err)
+	assert.Nil(t, state.SlidingWindow)
+
+	assert.True(t, limiter.processSlidingWindowReq("10.0.0.1", rule.APIEndpoint, rule).Success)
+	require.NoError(t, limiter.ResetClientState("10.0.0.1", rule.APIEndpoint))
+
+	state, err = limiter.GetClientState("10.0.0.1", rule.APIEndpoint)
+	require.NoError(t, err)
+	assert.Nil(t, state.SlidingWindow)
+}
+
+func TestGrantExtraQuotaErrors(t *testing.T) {
+	rule := &models.Rule{
+		APIEndpoint:     "/api/v1/upload",
+		Strategy:        models.StrategyConcurrency,
+		ConcurrencyRule: &models.ConcurrencyRule{MaxConcurrent: 1, LeaseTimeout: 30},
+	}
+	limiter := newTestStateLimiter(t, rule)
+
+	_, err := limiter.GrantExtraQuota("10.0.0.1", rule.APIEndpoint, 0)
+	assert.ErrorIs(t, err, ErrInvalidExtraQuota)
+
+	_, err = limiter.GrantExtraQuota("10.0.0.1", "/api/v1/unknown", 1)
+	assert.ErrorIs(t, err, ErrNoRuleForEndpoint)
+
+	_, err = limiter.GrantExtraQuota("10.0.0.1", rule.APIEndpoint, 1)
+	assert.ErrorIs(t, err, ErrStrategyNotSupportGrant)
+}
+
+func TestSlidingWindowLegacyKey(t *testing.T) {
+	rule := &models.Rule{
+		APIEndpoint:              "/api/v1/feed",
+		Strategy:                 "SLIDING WINDOW COUNTER",
+		SlidingWindowCounterRule: &models.SlidingWindowCounterRule{MaxRequests: 5, WindowSize: 60},
+	}
+	clusterClient, mr := newTestClusterClient(t)
+	slidingWindow := NewSlidingWindowService(clusterClient)
+
+	// Requests made before the upgrade, stored under the key without the prefix
+	now := time.Now().Unix()
+	for i := int64(0); i < 3; i++ {
+		_, err := mr.ZAdd(legacySlidingWindowKey("10.0.0.1", rule.APIEndpoint), float64(now-i), strconv.FormatInt(now-i, 10))
+		require.NoError(t, err)
+	}
+	mr.SetTTL(legacySlidingWindowKey("10.0.0.1", rule.APIEndpoint), 60*time.Second)
+
+	resp := slidingWindow.processRequest("10.0.0.1", rule.APIEndpoint, rule)
+	assert.True(t, resp.Success)
+	assert.Equal(t, int64(2), resp.RateLimit_Remaining)
+
+	assert.False(t, mr.Exists(legacySlidingWindowKey("10.0.0.1", rule.APIEndpoint)))
+	assert.True(t, mr.Exists(slidingWindow.parseToKey("10.0.0.1", rule.APIEndpoint)))
+
+	// A reset also removes an old window that was not moved yet
+	_, err := mr.ZAdd(legacySlidingWindowKey("10.0.0.2", rule.APIEndpoint), float64(now), strconv.FormatInt(now, 10))
+	require.NoError(t, err)
+	require.NoError(t, slidingWindow.reset("10.0.0.2", rule.APIEndpoint))
+	assert.False(t, mr.Exists(legacySlidingWindowKey("10.0.0.2", rule.APIEndpoint)))
+}
@@ -0,0 +1,55 @@
+package limiter
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/x-sushant-x/RateShield/models"
+	"github.com/x-sushant-x/RateShield/service"
+)
+
+// fakeRulesCache fails to load the rules while err is set
+type fakeRulesCache struct {
+	service.RulesService
+
+	rules map[string]*models.Rule
+	err   error
+	loads int
+}
+
+func (f *fakeRulesCache) CacheRulesLocally() (*map[string]*models.Rule, error) {
+	f.loads++
+
+	rules := map[string]*models.Rule{}
+	if f.err != nil {
+		return &rules, f.err
+	}
+
+	for endpoint, rule := range f.rules {
+		rules[endpoint] = rule
+	}
+	return &rules, nil
+}
+
+func TestCheckRules(t *testing.T) {
+	rule := &models.Rule{APIEndpoint: "/api/v1/search", Strategy: models.StrategyFixedWindowCounter}
+	rulesSvc := &fakeRulesCache{rules: map[string]*models.Rule{rule.APIEndpoint: rule}, err: errors.New("connection refused")}
+	limiter := &Limiter{redisRuleSvc: rulesSvc}
+
+	limiter.reloadRules()
+	assert.Empty(t, *limiter.cachedRules)
+
+	// Every check retries the reload while it fails
+	assert.ErrorContains(t, limiter.CheckRules(), "connection refused")
+	assert.Equal(t, 2, rulesSvc.loads)
+
+	rulesSvc.err = nil
+	assert.NoError(t, limiter.CheckRules())
+	assert.Equal(t, 3, rulesSvc.loads)
+	assert.Contains(t, *limiter.cachedRules, rule.APIEndpoint)
+
+	// Loaded rules are not read again by the check, updates reload them
+	assert.NoError(t, limiter.CheckRules())
+	assert.Equal(t, 3, rulesSvc.loads)
+}
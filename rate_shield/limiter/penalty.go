@@ -0,0 +1,192 @@
+package limiter
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog/log"
+	"github.com/x-sushant-x/RateShield/models"
+	redisClient "github.com/x-sushant-x/RateShield/redis"
+	"github.com/x-sushant-x/RateShield/utils"
+)
+
+const (
+	penaltyDenialsKeyPrefix  = "penalty_denials_"
+	penaltyOffensesKeyPrefix = "penalty_offenses_"
+	penaltyBanKeyPrefix      = "penalty_ban_"
+
+	defaultMaxBanDuration = 24 * 60 * 60
+)
+
+var (
+	ErrBanNotFound = errors.New("client is not banned from the endpoint")
+
+	// Counts a denial and bans the client once it reaches the limit. Every further offense doubles the ban,
+	// up to the longest ban. Returns whether a ban started, the offense and the ban duration in seconds.
+	recordDenialScript = redis.NewScript(`
+local denials = redis.call('INCR', KEYS[1])
+if denials == 1 then
+	redis.call('EXPIRE', KEYS[1], ARGV[2])
+end
+if denials < tonumber(ARGV[1]) then
+	return {0, 0, 0}
+end
+redis.call('DEL', KEYS[1])
+local offense = redis.call('INCR', KEYS[2])
+local duration = math.floor(math.min(tonumber(ARGV[3]) * 2 ^ (offense - 1), tonumber(ARGV[4])))
+local now = tonumber(ARGV[5])
+redis.call('HSET', KEYS[3], 'client_ip', ARGV[6], 'endpoint', ARGV[7], 'offense', offense, 'banned_at', now, 'expires_at', now + duration)
+redis.call('EXPIRE', KEYS[3], duration)
+redis.call('EXPIRE', KEYS[2], duration + tonumber(ARGV[4]))
+return {1, offense, duration}
+`)
+)
+
+type PenaltyService struct {
+	redisClient *redis.ClusterClient
+	keyScanner  redisClient.RedisKeyScanner
+}
+
+func NewPenaltyService(redisClient *redis.ClusterClient, keyScanner redisClient.RedisKeyScanner) PenaltyService {
+	return PenaltyService{
+		redisClient: redisClient,
+		keyScanner:  keyScanner,
+	}
+}
+
+// checkBan rejects the request if the client is banned from the endpoint. Returns nil when it is not.
+func (p *PenaltyService) checkBan(identity, endpoint string) *models.RateLimitResponse {
+	ban, found, err := p.getBan(p.parseToKey(penaltyBanKeyPrefix, identity, endpoint))
+	if err != nil {
+		// Let the strategy decide rather than rejecting everyone while Redis has trouble
+		log.Err(err).Msg("unable to check penalty ban")
+		return nil
+	}
+
+	now := time.Now().Unix()
+	if !found || ban.ExpiresAt <= now {
+		return nil
+	}
+
+	resp := utils.BuildRateLimitDeniedResponse(fmt.Sprintf("banned until %s after repeatedly exceeding the rate limit (offense %d)",
+		time.Unix(ban.ExpiresAt, 0).UTC().Format(time.RFC3339), ban.Offense))
+	resp.RetryAfter = ban.ExpiresAt - now
+	return resp
+}
+
+// recordDenial counts a rate limited request towards the penalty policy
+func (p *PenaltyService) recordDenial(identity, endpoint string, policy models.PenaltyPolicy) {
+	maxBanDuration := policy.MaxBanDuration
+	if maxBanDuration == 0 {
+		maxBanDuration = max(defaultMaxBanDuration, policy.BanDuration)
+	}
+
+	keys := []string{
+		p.parseToKey(penaltyDenialsKeyPrefix, identity, endpoint),
+		p.parseToKey(penaltyOffensesKeyPrefix, identity, endpoint),
+		p.parseToKey(penaltyBanKeyPrefix, identity, endpoint),
+	}
+
+	result, err := recordDenialScript.Run(ctx, p.redisClient, keys, policy.MaxDenials, policy.Window,
+		policy.BanDuration, maxBanDuration, time.Now().Unix(), identity, endpoint).Int64Slice()
+	if err != nil {
+		log.Err(err).Msg("unable to record denial for penalty policy")
+		return
+	}
+
+	if result[0] == 1 {
+		log.Warn().Str("client", identity).Str("endpoint", endpoint).Int64("offense", result[1]).
+			Msgf("client banned for %ds after repeatedly exceeding the rate limit", result[2])
+	}
+}
+
+// listBans pages through active bans, on every endpoint when endpoint is empty
+func (p *PenaltyService) listBans(endpoint, cursor string, count int64) (*models.PaginatedPenaltyBans, error) {
+	match := penaltyBanKeyPrefix + "*"
+	if endpoint != "" {
+		match = penaltyBanKeyPrefix + "*" + redisClient.EscapeMatchPattern(":"+endpoint+"}")
+	}
+
+	keys, nextCursor, err := p.keyScanner.ScanKeysPage(match, cursor, count)
+	if err != nil {
+		return nil, err
+	}
+
+	bans := make([]models.PenaltyBan, 0, len(keys))
+
+	for _, key := range keys {
+		ban, found, err := p.getBan(key)
+		if err != nil {
+			return nil, err
+		}
+
+		// Ban expired between the scan and the lookup
+		if !found || (endpoint != "" && ban.Endpoint != endpoint) {
+			continue
+		}
+		bans = append(bans, *ban)
+	}
+
+	return &models.PaginatedPenaltyBans{
+		NextCursor:  nextCursor,
+		HasNextPage: nextCursor != "",
+		Bans:        bans,
+	}, nil
+}
+
+// liftBan ends a ban and forgets the client's denials and offenses, so its next ban starts short again
+func (p *PenaltyService) liftBan(identity, endpoint string) error {
+	removed, err := p.redisClient.Del(ctx,
+		p.parseToKey(penaltyBanKeyPrefix, identity, endpoint),
+		p.parseToKey(penaltyOffensesKeyPrefix, identity, endpoint),
+		p.parseToKey(penaltyDenialsKeyPrefix, identity, endpoint),
+	).Result()
+	if err != nil {
+		return err
+	}
+
+	if removed == 0 {
+		return ErrBanNotFound
+	}
+	return nil
+}
+
+func (p *PenaltyService) getBan(key string) (*models.PenaltyBan, bool, error) {
+	fields, err := p.redisClient.HGetAll(ctx, key).Result()
+	if err != nil {
+		return nil, false, err
+	}
+
+	if len(fields) == 0 {
+		return nil, false, nil
+	}
+
+	ban := &models.PenaltyBan{
+		ClientIP: fields["client_ip"],
+		Endpoint: fields["endpoint"],
+	}
+	ban.Offense, _ = strconv.ParseInt(fields["offense"], 10, 64)
+	ban.BannedAt, _ = strconv.ParseInt(fields["banned_at"], 10, 64)
+	ban.ExpiresAt, _ = strconv.ParseInt(fields["expires_at"], 10, 64)
+
+	return ban, true, nil
+}
+
+// parseToKey names the penalty state of a client. The hash tag keeps the keys of a client in one cluster
+// slot so they can be updated by one script.
+func (p *PenaltyService) parseToKey(prefix, identity, endpoint string) string {
+	return prefix + "{" + identity + ":" + endpoint + "}"
+}
+
+// ListBans pages through the clients banned by penalty policies, on every endpoint when endpoint is empty
+func (l *Limiter) ListBans(endpoint, cursor string, count int64) (*models.PaginatedPenaltyBans, error) {
+	return l.penalty.listBans(endpoint, cursor, count)
+}
+
+// LiftBan ends the ban of a client on an endpoint and resets its offenses
+func (l *Limiter) LiftBan(identity, endpoint string) error {
+	return l.penalty.liftBan(identity, endpoint)
+}
@@ -0,0 +1,141 @@
+package limiter
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/x-sushant-x/RateShield/models"
+	redisClient "github.com/x-sushant-x/RateShield/redis"
+)
+
+func newTestPenaltyService(t *testing.T) (PenaltyService, *miniredis.Miniredis) {
+	clusterClient, mr := newTestClusterClient(t)
+	return NewPenaltyService(clusterClient, redisClient.NewRedisKeyScanner(clusterClient)), mr
+}
+
+// banClient records denials until the client is banned
+func banClient(p *PenaltyService, identity, endpoint string, policy models.PenaltyPolicy) {
+	for i := int64(0); i < policy.MaxDenials; i++ {
+		p.recordDenial(identity, endpoint, policy)
+	}
+}
+
+func TestPenaltyKeys(t *testing.T) {
+	p, _ := newTestPenaltyService(t)
+
+	// Keys of a client share a hash tag so the script can update them in one cluster slot
+	assert.Equal(t, "penalty_ban_{10.0.0.1:/api/v1/login}", p.parseToKey(penaltyBanKeyPrefix, "10.0.0.1", "/api/v1/login"))
+	assert.Equal(t, "penalty_offenses_{10.0.0.1:/api/v1/login}", p.parseToKey(penaltyOffensesKeyPrefix, "10.0.0.1", "/api/v1/login"))
+	assert.Equal(t, "penalty_denials_{10.0.0.1:/api/v1/login}", p.parseToKey(penaltyDenialsKeyPrefix, "10.0.0.1", "/api/v1/login"))
+}
+
+func TestPenaltyBans(t *testing.T) {
+	endpoint := "/api/v1/login"
+	policy := models.PenaltyPolicy{MaxDenials: 3, Window: 60, BanDuration: 60, MaxBanDuration: 200}
+
+	t.Run("ban after max denials", func(t *testing.T) {
+		p, mr := newTestPenaltyService(t)
+		banKey := p.parseToKey(penaltyBanKeyPrefix, "10.0.0.1", endpoint)
+
+		p.recordDenial("10.0.0.1", endpoint, policy)
+		p.recordDenial("10.0.0.1", endpoint, policy)
+		assert.Nil(t, p.checkBan("10.0.0.1", endpoint))
+		assert.Equal(t, time.Duration(policy.Window)*time.Second, mr.TTL(p.parseToKey(penaltyDenialsKeyPrefix, "10.0.0.1", endpoint)))
+
+		p.recordDenial("10.0.0.1", endpoint, policy)
+
+		resp := p.checkBan("10.0.0.1", endpoint)
+		require.NotNil(t, resp)
+		assert.False(t, resp.Success)
+		assert.Equal(t, http.StatusForbidden, resp.HTTPStatusCode)
+		assert.InDelta(t, policy.BanDuration, resp.RetryAfter, 1)
+		assert.Contains(t, resp.Reason, "offense 1")
+
+		assert.Equal(t, 60*time.Second, mr.TTL(banKey))
+		assert.False(t, mr.Exists(p.parseToKey(penaltyDenialsKeyPrefix, "10.0.0.1", endpoint)), "denials start over after a ban")
+
+		// Other clients and endpoints are not banned
+		assert.Nil(t, p.checkBan("10.0.0.2", endpoint))
+		assert.Nil(t, p.checkBan("10.0.0.1", "/api/v1/search"))
+
+		// The ban ends when its key expires
+		mr.FastForward(61 * time.Second)
+		assert.Nil(t, p.checkBan("10.0.0.1", endpoint))
+	})
+
+	t.Run("bans double up to the longest ban", func(t *testing.T) {
+		p, mr := newTestPenaltyService(t)
+		banKey := p.parseToKey(penaltyBanKeyPrefix, "10.0.0.1", endpoint)
+
+		for _, expected := range []time.Duration{60, 120, 200, 200} {
+			banClient(&p, "10.0.0.1", endpoint, policy)
+			assert.Equal(t, expected*time.Second, mr.TTL(banKey))
+		}
+
+		ban, found, err := p.getBan(banKey)
+		require.NoError(t, err)
+		require.True(t, found)
+		assert.Equal(t, int64(4), ban.Offense)
+		assert.Equal(t, "10.0.0.1", ban.ClientIP)
+		assert.Equal(t, endpoint, ban.Endpoint)
+		assert.Equal(t, int64(200), ban.ExpiresAt-ban.BannedAt)
+
+		// Offenses are remembered for the longest ban after the last one
+		assert.Equal(t, 400*time.Second, mr.TTL(p.parseToKey(penaltyOffensesKeyPrefix, "10.0.0.1", endpoint)))
+	})
+
+	t.Run("default longest ban", func(t *testing.T) {
+		p, mr := newTestPenaltyService(t)
+		banKey := p.parseToKey(penaltyBanKeyPrefix, "10.0.0.1", endpoint)
+		policy := models.PenaltyPolicy{MaxDenials: 1, Window: 60, BanDuration: 12 * 60 * 60}
+
+		banClient(&p, "10.0.0.1", endpoint, policy)
+		banClient(&p, "10.0.0.1", endpoint, policy)
+		assert.Equal(t, defaultMaxBanDuration*time.Second, mr.TTL(banKey))
+	})
+}
+
+func TestLiftBan(t *testing.T) {
+	p, mr := newTestPenaltyService(t)
+	endpoint := "/api/v1/login"
+	policy := models.PenaltyPolicy{MaxDenials: 1, Window: 60, BanDuration: 60, MaxBanDuration: 600}
+
+	assert.ErrorIs(t, p.liftBan("10.0.0.1", endpoint), ErrBanNotFound)
+
+	banClient(&p, "10.0.0.1", endpoint, policy)
+	banClient(&p, "10.0.0.1", endpoint, policy)
+	require.NotNil(t, p.checkBan("10.0.0.1", endpoint))
+
+	require.NoError(t, p.liftBan("10.0.0.1", endpoint))
+	assert.Nil(t, p.checkBan("10.0.0.1", endpoint))
+	assert.False(t, mr.Exists(p.parseToKey(penaltyOffensesKeyPrefix, "10.0.0.1", endpoint)))
+
+	// The next ban starts short again
+	banClient(&p, "10.0.0.1", endpoint, policy)
+	assert.Equal(t, 60*time.Second, mr.TTL(p.parseToKey(penaltyBanKeyPrefix, "10.0.0.1", endpoint)))
+}
+
+func TestListBans(t *testing.T) {
+	p, _ := newTestPenaltyService(t)
+	policy := models.PenaltyPolicy{MaxDenials: 1, Window: 60, BanDuration: 60}
+
+	banClient(&p, "10.0.0.1", "/api/v1/login", policy)
+	banClient(&p, "10.0.0.2", "/api/v1/login", policy)
+	banClient(&p, "10.0.0.1", "/api/v1/search", policy)
+
+	bans, err := p.listBans("", "", 100)
+	require.NoError(t, err)
+	assert.Len(t, bans.Bans, 3)
+	assert.False(t, bans.HasNextPage)
+
+	bans, err = p.listBans("/api/v1/login", "", 100)
+	require.NoError(t, err)
+	require.Len(t, bans.Bans, 2)
+	for _, ban := range bans.Bans {
+		assert.Equal(t, "/api/v1/login", ban.Endpoint)
+	}
+}
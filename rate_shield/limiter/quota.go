@@ -0,0 +1,170 @@
+package limiter
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog/log"
+	"github.com/x-sushant-x/RateShield/models"
+	"github.com/x-sushant-x/RateShield/service"
+	"github.com/x-sushant-x/RateShield/utils"
+)
+
+const (
+	quotaKeyPrefix = "quota_"
+
+	// Counters are kept a little past the end of their period so instances with a slow clock still find them
+	quotaKeyGracePeriod = time.Hour
+)
+
+var (
+	// Share of the quota at which a notification is sent, in percent
+	quotaNotificationThresholds = []int{100, 80}
+
+	// Counts a request unless the quota is used up. Returns whether the request was counted and the usage.
+	// Counters are plain integers with an absolute expiry so they survive an AOF replay unchanged.
+	consumeQuotaScript = redis.NewScript(`
+local used = tonumber(redis.call('GET', KEYS[1]) or '0')
+if used >= tonumber(ARGV[1]) then
+	return {0, used}
+end
+used = redis.call('INCR', KEYS[1])
+redis.call('EXPIREAT', KEYS[1], ARGV[2])
+return {1, used}
+`)
+)
+
+type QuotaService struct {
+	redisClient     *redis.ClusterClient
+	notificationSVC *service.QuotaNotificationSVC
+}
+
+func NewQuotaService(redisClient *redis.ClusterClient, notificationSVC *service.QuotaNotificationSVC) QuotaService {
+	return QuotaService{
+		redisClient:     redisClient,
+		notificationSVC: notificationSVC,
+	}
+}
+
+func (q *QuotaService) processRequest(identity, endpoint string, rule *models.Rule) *models.RateLimitResponse {
+	quota := *rule.QuotaRule
+	start, end, err := utils.QuotaPeriodBounds(quota, time.Now())
+	if err != nil {
+		log.Err(err).Str("endpoint", endpoint).Msg("invalid quota rule")
+		return utils.BuildRateLimitErrorResponse(500)
+	}
+
+	key := q.parseToKey(identity, endpoint, quota, start)
+	expireAt := end.Add(quotaKeyGracePeriod).Unix()
+
+	result, err := consumeQuotaScript.Run(ctx, q.redisClient, []string{key}, quota.Limit, expireAt).Int64Slice()
+	if err != nil {
+		log.Err(err).Msg("unable to consume quota")
+		return utils.BuildRateLimitErrorResponse(500)
+	}
+
+	counted, used := result[0] == 1, result[1]
+	if !counted {
+		return utils.BuildRateLimitErrorResponse(429)
+	}
+
+	q.notifyThresholds(models.QuotaUsage{
+		Identity:    identity,
+		Endpoint:    endpoint,
+		Period:      quota.Period,
+		Limit:       quota.Limit,
+		Used:        used,
+		Remaining:   quota.Limit - used,
+		PeriodStart: start.Unix(),
+		ResetsAt:    end.Unix(),
+	})
+
+	return utils.BuildRateLimitSuccessResponse(quota.Limit, quota.Limit-used)
+}
+
+// notifyThresholds notifies when a request brings the usage to exactly a threshold. Counters are
+// incremented atomically so only one request per period sees that value, even across instances.
+func (q *QuotaService) notifyThresholds(usage models.QuotaUsage) {
+	if q.notificationSVC == nil {
+		return
+	}
+
+	for _, threshold := range quotaNotificationThresholds {
+		if usage.Used == quotaThresholdCount(usage.Limit, threshold) {
+			q.notificationSVC.SendThresholdNotification(usage, threshold)
+			return
+		}
+	}
+}
+
+// quotaThresholdCount returns the first request count at or above threshold percent of the limit
+func quotaThresholdCount(limit int64, threshold int) int64 {
+	return (limit*int64(threshold) + 99) / 100
+}
+
+// getUsage returns the usage of the current period, a client without requests in it has used nothing
+func (q *QuotaService) getUsage(identity, endpoint string, quota models.QuotaRule) (*models.QuotaUsage, error) {
+	start, end, err := utils.QuotaPeriodBounds(quota, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	used, err := q.redisClient.Get(ctx, q.parseToKey(identity, endpoint, quota, start)).Int64()
+	if err != nil && err != redis.Nil {
+		return nil, err
+	}
+
+	return &models.QuotaUsage{
+		Identity:    identity,
+		Endpoint:    endpoint,
+		Period:      quota.Period,
+		Limit:       quota.Limit,
+		Used:        max(used, 0), // Granted requests go below 0
+		Remaining:   max(quota.Limit-used, 0),
+		PeriodStart: start.Unix(),
+		ResetsAt:    end.Unix(),
+	}, nil
+}
+
+// grantRequests lowers the usage of the current period so the client can make extra requests before the
+// period ends
+func (q *QuotaService) grantRequests(identity, endpoint string, quota models.QuotaRule, extra int64) error {
+	start, end, err := utils.QuotaPeriodBounds(quota, time.Now())
+	if err != nil {
+		return err
+	}
+
+	key := q.parseToKey(identity, endpoint, quota, start)
+
+	pipe := q.redisClient.TxPipeline()
+	pipe.DecrBy(ctx, key, extra)
+	pipe.ExpireAt(ctx, key, end.Add(quotaKeyGracePeriod))
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+func (q *QuotaService) reset(identity, endpoint string, quota models.QuotaRule) error {
+	start, _, err := utils.QuotaPeriodBounds(quota, time.Now())
+	if err != nil {
+		return err
+	}
+
+	return q.redisClient.Del(ctx, q.parseToKey(identity, endpoint, quota, start)).Err()
+}
+
+// keyPrefix returns the prefix of every counter in the current period
+func (q *QuotaService) keyPrefix(quota models.QuotaRule) (string, error) {
+	start, _, err := utils.QuotaPeriodBounds(quota, time.Now())
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%s%s_", quotaKeyPrefix, utils.QuotaPeriodID(quota, start)), nil
+}
+
+// parseToKey names the counter of a period. The period is part of the key so a new period starts from zero
+// without a reset, and counters of past periods simply expire.
+func (q *QuotaService) parseToKey(identity, endpoint string, quota models.QuotaRule, start time.Time) string {
+	return quotaKeyPrefix + utils.QuotaPeriodID(quota, start) + "_" + identity + ":" + endpoint
+}
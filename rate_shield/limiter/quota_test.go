@@ -0,0 +1,151 @@
+package limiter
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/x-sushant-x/RateShield/models"
+	"github.com/x-sushant-x/RateShield/utils"
+)
+
+func TestQuotaThresholdCount(t *testing.T) {
+	assert.Equal(t, int64(8000), quotaThresholdCount(10000, 80))
+	assert.Equal(t, int64(10000), quotaThresholdCount(10000, 100))
+	assert.Equal(t, int64(3), quotaThresholdCount(3, 80))
+	assert.Equal(t, int64(1), quotaThresholdCount(1, 80))
+}
+
+func newTestQuotaRule(limit int64, period string) *models.Rule {
+	return &models.Rule{
+		APIEndpoint: "/api/v1/search",
+		Strategy:    models.StrategyQuota,
+		QuotaRule:   &models.QuotaRule{Limit: limit, Period: period},
+	}
+}
+
+func TestQuotaProcessRequest(t *testing.T) {
+	clusterClient, mr := newTestClusterClient(t)
+	quotaSvc := NewQuotaService(clusterClient, nil)
+	rule := newTestQuotaRule(3, models.QuotaPeriodDay)
+
+	for remaining := int64(2); remaining >= 0; remaining-- {
+		resp := quotaSvc.processRequest("customer-42", rule.APIEndpoint, rule)
+		require.Equal(t, http.StatusOK, resp.HTTPStatusCode)
+		assert.Equal(t, int64(3), resp.RateLimit_Limit)
+		assert.Equal(t, remaining, resp.RateLimit_Remaining)
+	}
+
+	// Rejected requests are not counted
+	for range 2 {
+		resp := quotaSvc.processRequest("customer-42", rule.APIEndpoint, rule)
+		assert.Equal(t, http.StatusTooManyRequests, resp.HTTPStatusCode)
+	}
+
+	start, end, err := utils.QuotaPeriodBounds(*rule.QuotaRule, time.Now())
+	require.NoError(t, err)
+
+	key := quotaSvc.parseToKey("customer-42", rule.APIEndpoint, *rule.QuotaRule, start)
+	used, err := mr.Get(key)
+	require.NoError(t, err)
+	assert.Equal(t, "3", used)
+
+	// The counter expires a grace period after the end of the day
+	ttl := mr.TTL(key)
+	expectedTTL := time.Until(end.Add(quotaKeyGracePeriod))
+	assert.InDelta(t, expectedTTL.Seconds(), ttl.Seconds(), 2)
+
+	// Other clients have their own counter
+	resp := quotaSvc.processRequest("customer-7", rule.APIEndpoint, rule)
+	assert.Equal(t, http.StatusOK, resp.HTTPStatusCode)
+	assert.Equal(t, int64(2), resp.RateLimit_Remaining)
+}
+
+func TestQuotaPeriodRollover(t *testing.T) {
+	clusterClient, mr := newTestClusterClient(t)
+	quotaSvc := NewQuotaService(clusterClient, nil)
+	rule := newTestQuotaRule(5, models.QuotaPeriodHour)
+
+	start, _, err := utils.QuotaPeriodBounds(*rule.QuotaRule, time.Now())
+	require.NoError(t, err)
+
+	// The previous hour was used up, its counter is still around during the grace period
+	previousStart, _, err := utils.QuotaPeriodBounds(*rule.QuotaRule, start.Add(-time.Minute))
+	require.NoError(t, err)
+	previousKey := quotaSvc.parseToKey("customer-42", rule.APIEndpoint, *rule.QuotaRule, previousStart)
+	require.NoError(t, mr.Set(previousKey, "5"))
+
+	currentKey := quotaSvc.parseToKey("customer-42", rule.APIEndpoint, *rule.QuotaRule, start)
+	require.NotEqual(t, previousKey, currentKey)
+
+	resp := quotaSvc.processRequest("customer-42", rule.APIEndpoint, rule)
+	require.Equal(t, http.StatusOK, resp.HTTPStatusCode)
+	assert.Equal(t, int64(4), resp.RateLimit_Remaining)
+
+	used, err := mr.Get(previousKey)
+	require.NoError(t, err)
+	assert.Equal(t, "5", used)
+
+	used, err = mr.Get(currentKey)
+	require.NoError(t, err)
+	assert.Equal(t, "1", used)
+}
+
+func TestQuotaUsageAndGrant(t *testing.T) {
+	clusterClient, mr := newTestClusterClient(t)
+	quotaSvc := NewQuotaService(clusterClient, nil)
+	rule := newTestQuotaRule(2, models.QuotaPeriodMonth)
+	quota := *rule.QuotaRule
+
+	// A client without requests has used nothing
+	usage, err := quotaSvc.getUsage("customer-42", rule.APIEndpoint, quota)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), usage.Used)
+	assert.Equal(t, int64(2), usage.Remaining)
+
+	start, end, err := utils.QuotaPeriodBounds(quota, time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, start.Unix(), usage.PeriodStart)
+	assert.Equal(t, end.Unix(), usage.ResetsAt)
+
+	for range 3 {
+		quotaSvc.processRequest("customer-42", rule.APIEndpoint, rule)
+	}
+
+	usage, err = quotaSvc.getUsage("customer-42", rule.APIEndpoint, quota)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), usage.Used)
+	assert.Equal(t, int64(0), usage.Remaining)
+
+	// A grant lowers the usage, so the client can go past its limit this period
+	require.NoError(t, quotaSvc.grantRequests("customer-42", rule.APIEndpoint, quota, 3))
+
+	usage, err = quotaSvc.getUsage("customer-42", rule.APIEndpoint, quota)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), usage.Used)
+	assert.Equal(t, int64(3), usage.Remaining)
+
+	for range 3 {
+		resp := quotaSvc.processRequest("customer-42", rule.APIEndpoint, rule)
+		assert.Equal(t, http.StatusOK, resp.HTTPStatusCode)
+	}
+	resp := quotaSvc.processRequest("customer-42", rule.APIEndpoint, rule)
+	assert.Equal(t, http.StatusTooManyRequests, resp.HTTPStatusCode)
+
+	// A grant to a client without requests creates the counter with an expiry
+	require.NoError(t, quotaSvc.grantRequests("customer-7", rule.APIEndpoint, quota, 4))
+
+	key := quotaSvc.parseToKey("customer-7", rule.APIEndpoint, quota, start)
+	used, err := mr.Get(key)
+	require.NoError(t, err)
+	assert.Equal(t, "-4", used)
+	assert.Positive(t, mr.TTL(key))
+
+	require.NoError(t, quotaSvc.reset("customer-42", rule.APIEndpoint, quota))
+
+	usage, err = quotaSvc.getUsage("customer-42", rule.APIEndpoint, quota)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), usage.Used)
+}
@@ -0,0 +1,65 @@
+package limiter
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/x-sushant-x/RateShield/models"
+)
+
+var (
+	ErrRuleNotQuota = errors.New("rule of the endpoint does not use the QUOTA strategy")
+)
+
+// GetQuotaUsage returns the usage of the current period on every endpoint with a quota, or only on the given
+// endpoint. Limits of the client's override or tier are taken into account, tier is optional.
+func (l *Limiter) GetQuotaUsage(identity, endpoint, tier string) ([]models.QuotaUsage, error) {
+	endpoints := []string{endpoint}
+	if endpoint == "" {
+		endpoints = l.quotaEndpoints()
+	}
+
+	usages := make([]models.QuotaUsage, 0, len(endpoints))
+
+	for _, quotaEndpoint := range endpoints {
+		rule := l.getCachedRule(quotaEndpoint)
+		if rule == nil {
+			return nil, ErrNoRuleForEndpoint
+		}
+
+		if rule.Strategy != models.StrategyQuota {
+			return nil, ErrRuleNotQuota
+		}
+
+		req := models.RateLimitRequest{ClientID: identity, Endpoint: quotaEndpoint, Tier: tier}
+		usage, err := l.quota.getUsage(identity, quotaEndpoint, *l.applyClientLimits(req, rule).QuotaRule)
+		if err != nil {
+			return nil, err
+		}
+		usages = append(usages, *usage)
+	}
+
+	return usages, nil
+}
+
+// quotaEndpoints returns the endpoints whose active rule uses the QUOTA strategy
+func (l *Limiter) quotaEndpoints() []string {
+	l.rulesMutex.RLock()
+	endpoints := []string{}
+	if l.cachedRules != nil {
+		for endpoint := range *l.cachedRules {
+			endpoints = append(endpoints, endpoint)
+		}
+	}
+	l.rulesMutex.RUnlock()
+
+	quotaEndpoints := []string{}
+	for _, endpoint := range endpoints {
+		if rule := l.getCachedRule(endpoint); rule != nil && rule.Strategy == models.StrategyQuota {
+			quotaEndpoints = append(quotaEndpoints, endpoint)
+		}
+	}
+
+	sort.Strings(quotaEndpoints)
+	return quotaEndpoints
+}
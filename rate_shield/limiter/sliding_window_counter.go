@@ -10,6 +10,10 @@ import (
 	"github.com/x-sushant-x/RateShield/utils"
 )
 
+const (
+	slidingWindowKeyPrefix = "sliding_window_"
+)
+
 var (
 	ctx = context.Background()
 )
@@ -25,7 +29,7 @@ func NewSlidingWindowService(redisClient *redis.ClusterClient) SlidingWindowServ
 }
 
 func (s *SlidingWindowService) processRequest(ip, endpoint string, rule *models.Rule) *models.RateLimitResponse {
-	key := ip + ":" + endpoint
+	key := s.parseToKey(ip, endpoint)
 
 	now := time.Now().Unix()
 	windowSize := time.Duration(rule.SlidingWindowCounterRule.WindowSize) * time.Second
@@ -35,6 +39,21 @@ func (s *SlidingWindowService) processRequest(ip, endpoint string, rule *models.
 		return utils.BuildRateLimitErrorResponse(500)
 	}
 
+	// The first request of a client after upgrading carries over the requests of its old window
+	if count == 0 {
+		moved, err := s.moveLegacyWindow(ip, endpoint)
+		if err != nil {
+			return utils.BuildRateLimitErrorResponse(500)
+		}
+
+		if moved > 0 {
+			count, err = s.removeOldRequestsAndCountActiveRequests(key, now, windowSize)
+			if err != nil {
+				return utils.BuildRateLimitErrorResponse(500)
+			}
+		}
+	}
+
 	if count > rule.SlidingWindowCounterRule.MaxRequests {
 		return utils.BuildRateLimitErrorResponse(429)
 	}
@@ -84,3 +103,93 @@ func (s *SlidingWindowService) updateWindow(key string, now int64, windowSize ti
 
 	return nil
 }
+
+func (s *SlidingWindowService) getState(ip, endpoint string, rule *models.Rule) (*models.SlidingWindowState, bool, error) {
+	key := s.parseToKey(ip, endpoint)
+
+	if _, err := s.moveLegacyWindow(ip, endpoint); err != nil {
+		return nil, false, err
+	}
+
+	state := &models.SlidingWindowState{}
+	var count int64
+	var err error
+
+	if rule != nil && rule.SlidingWindowCounterRule != nil {
+		state.MaxRequests = rule.SlidingWindowCounterRule.MaxRequests
+		state.WindowSize = rule.SlidingWindowCounterRule.WindowSize
+
+		then := time.Now().Unix() - int64(rule.SlidingWindowCounterRule.WindowSize)
+		count, err = s.redisClient.ZCount(ctx, key, fmt.Sprintf("%d", then), "+inf").Result()
+	} else {
+		count, err = s.redisClient.ZCard(ctx, key).Result()
+	}
+
+	if err != nil {
+		return nil, false, err
+	}
+
+	if count == 0 {
+		return nil, false, nil
+	}
+
+	state.ActiveRequests = count
+	return state, true, nil
+}
+
+// releaseRequests frees up to n slots of the current window by dropping the oldest recorded requests
+func (s *SlidingWindowService) releaseRequests(ip, endpoint string, n int64) error {
+	if _, err := s.moveLegacyWindow(ip, endpoint); err != nil {
+		return err
+	}
+
+	return s.redisClient.ZPopMin(ctx, s.parseToKey(ip, endpoint), n).Err()
+}
+
+func (s *SlidingWindowService) reset(ip, endpoint string) error {
+	if err := s.redisClient.Del(ctx, s.parseToKey(ip, endpoint)).Err(); err != nil {
+		return err
+	}
+
+	return s.redisClient.Del(ctx, legacySlidingWindowKey(ip, endpoint)).Err()
+}
+
+// moveLegacyWindow moves the requests stored under the key used before the sliding_window_ prefix into the
+// current key and returns how many were moved. The keys are in different cluster slots so the move is not
+// atomic, a check running at the same time may miss the moved requests.
+func (s *SlidingWindowService) moveLegacyWindow(ip, endpoint string) (int64, error) {
+	legacyKey := legacySlidingWindowKey(ip, endpoint)
+
+	requests, err := s.redisClient.ZRangeWithScores(ctx, legacyKey, 0, -1).Result()
+	if err != nil || len(requests) == 0 {
+		return 0, err
+	}
+
+	ttl, err := s.redisClient.TTL(ctx, legacyKey).Result()
+	if err != nil {
+		return 0, err
+	}
+
+	key := s.parseToKey(ip, endpoint)
+
+	pipe := s.redisClient.TxPipeline()
+	pipe.ZAdd(ctx, key, requests...)
+	if ttl > 0 {
+		pipe.Expire(ctx, key, ttl)
+	}
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, err
+	}
+
+	return int64(len(requests)), s.redisClient.Del(ctx, legacyKey).Err()
+}
+
+func (s *SlidingWindowService) parseToKey(ip, endpoint string) string {
+	return slidingWindowKeyPrefix + ip + ":" + endpoint
+}
+
+// legacySlidingWindowKey is the key of a client's window before keys got the sliding_window_ prefix
+func legacySlidingWindowKey(ip, endpoint string) string {
+	return ip + ":" + endpoint
+}
@@ -0,0 +1,111 @@
+package limiter
+
+import (
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/x-sushant-x/RateShield/models"
+	"github.com/x-sushant-x/RateShield/utils"
+)
+
+const (
+	// How long the tier of a client is cached before it is looked up again
+	tierCacheTTL = 30 * time.Second
+
+	// Every tierCacheSweepEvery cached lookups the expired tiers are removed
+	tierCacheSweepEvery = 1024
+)
+
+type cachedTier struct {
+	tier      string
+	expiresAt time.Time
+}
+
+// applyClientLimits returns the rule with the limits of the client's override or tier. Overrides and tiers
+// are defined on the endpoint's rule and only apply if they have limits for the strategy of the active rule.
+func (l *Limiter) applyClientLimits(req models.RateLimitRequest, rule *models.Rule) *models.Rule {
+	baseRule := l.getBaseRule(req.Endpoint)
+	if baseRule == nil {
+		return rule
+	}
+
+	identity := req.Identity()
+	for _, override := range baseRule.Overrides {
+		if override.Identity == identity {
+			if limited, ok := utils.WithRuleLimits(rule, override.RuleLimits); ok {
+				return limited
+			}
+			break
+		}
+	}
+
+	if len(baseRule.Tiers) == 0 {
+		return rule
+	}
+
+	tierName := req.Tier
+	if tierName == "" {
+		tierName = l.lookupClientTier(identity)
+	}
+
+	for _, tier := range baseRule.Tiers {
+		if tierName != "" && tier.Name == tierName {
+			if limited, ok := utils.WithRuleLimits(rule, tier.RuleLimits); ok {
+				return limited
+			}
+			break
+		}
+	}
+
+	return rule
+}
+
+// lookupClientTier returns the tier of a client from the tier mapping, empty if it has none. Lookups are
+// cached so only the first request of a client in tierCacheTTL goes to Redis.
+func (l *Limiter) lookupClientTier(identity string) string {
+	if l.tierSvc == nil {
+		return ""
+	}
+
+	now := time.Now()
+
+	l.tierMutex.Lock()
+	cached, found := l.tierCache[identity]
+	l.tierMutex.Unlock()
+
+	if found && now.Before(cached.expiresAt) {
+		return cached.tier
+	}
+
+	tier, _, err := l.tierSvc.GetClientTier(identity)
+	if err != nil {
+		// Fall back to the default limits rather than failing the request
+		log.Err(err).Str("identity", identity).Msg("unable to look up client tier")
+		return ""
+	}
+
+	l.tierMutex.Lock()
+	if l.tierCache == nil {
+		l.tierCache = make(map[string]cachedTier)
+	}
+	l.tierCache[identity] = cachedTier{tier: tier, expiresAt: now.Add(tierCacheTTL)}
+
+	l.tierInserts++
+	if l.tierInserts%tierCacheSweepEvery == 0 {
+		for cachedIdentity, entry := range l.tierCache {
+			if !now.Before(entry.expiresAt) {
+				delete(l.tierCache, cachedIdentity)
+			}
+		}
+	}
+	l.tierMutex.Unlock()
+
+	return tier
+}
+
+// clearTierCache drops the cached tiers so tier changes apply right away
+func (l *Limiter) clearTierCache() {
+	l.tierMutex.Lock()
+	l.tierCache = make(map[string]cachedTier)
+	l.tierMutex.Unlock()
+}
@@ -0,0 +1,113 @@
+package limiter
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/x-sushant-x/RateShield/models"
+)
+
+type fakeTierService struct {
+	tiers   map[string]string
+	lookups int
+}
+
+func (f *fakeTierService) GetClientTier(identity string) (string, bool, error) {
+	f.lookups++
+	tier, found := f.tiers[identity]
+	return tier, found, nil
+}
+
+func (f *fakeTierService) AssignTier(identity, tier, actor, ipAddress, userAgent string) error {
+	return nil
+}
+
+func (f *fakeTierService) UnassignTier(identity, actor, ipAddress, userAgent string) (bool, error) {
+	return false, nil
+}
+
+func fixedWindowLimits(maxRequests int64) models.RuleLimits {
+	return models.RuleLimits{FixedWindowCounterRule: &models.FixedWindowCounterRule{MaxRequests: maxRequests, Window: 60}}
+}
+
+func TestApplyClientLimits(t *testing.T) {
+	rule := &models.Rule{
+		APIEndpoint:            "/api/v1/get-data",
+		Strategy:               models.StrategyFixedWindowCounter,
+		FixedWindowCounterRule: &models.FixedWindowCounterRule{MaxRequests: 100, Window: 60},
+		Overrides: []models.RuleOverride{
+			{Identity: "customer-42", RuleLimits: fixedWindowLimits(5000)},
+		},
+		Tiers: []models.RuleTier{
+			{Name: "free", RuleLimits: fixedWindowLimits(10)},
+			{Name: "pro", RuleLimits: fixedWindowLimits(1000)},
+		},
+	}
+	rules := map[string]*models.Rule{rule.APIEndpoint: rule}
+
+	tierSvc := &fakeTierService{tiers: map[string]string{"customer-7": "pro", "10.0.0.1": "free"}}
+	limiter := &Limiter{cachedRules: &rules, tierSvc: tierSvc}
+
+	tests := []struct {
+		name        string
+		req         models.RateLimitRequest
+		maxRequests int64
+	}{
+		{"override wins over tier", models.RateLimitRequest{ClientID: "customer-42", Tier: "free"}, 5000},
+		{"tier descriptor", models.RateLimitRequest{ClientID: "customer-7", Tier: "free"}, 10},
+		{"tier from mapping by client id", models.RateLimitRequest{ClientIP: "10.0.0.1", ClientID: "customer-7"}, 1000},
+		{"tier from mapping by ip", models.RateLimitRequest{ClientIP: "10.0.0.1"}, 10},
+		{"unknown tier", models.RateLimitRequest{ClientIP: "10.0.0.2", Tier: "enterprise"}, 100},
+		{"no tier", models.RateLimitRequest{ClientIP: "10.0.0.2"}, 100},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.req.Endpoint = rule.APIEndpoint
+			limited := limiter.applyClientLimits(tt.req, rule)
+			assert.Equal(t, tt.maxRequests, limited.FixedWindowCounterRule.MaxRequests)
+		})
+	}
+
+	assert.Equal(t, int64(100), rule.FixedWindowCounterRule.MaxRequests, "rule must not be modified")
+
+	t.Run("tier lookups are cached", func(t *testing.T) {
+		limiter.clearTierCache()
+		tierSvc.lookups = 0
+
+		req := models.RateLimitRequest{ClientIP: "10.0.0.1", Endpoint: rule.APIEndpoint}
+		limiter.applyClientLimits(req, rule)
+		limiter.applyClientLimits(req, rule)
+		assert.Equal(t, 1, tierSvc.lookups)
+	})
+
+	t.Run("limits of another strategy are ignored", func(t *testing.T) {
+		scheduled := &models.Rule{
+			APIEndpoint:              rule.APIEndpoint,
+			Strategy:                 models.StrategySlidingWindowCounter,
+			SlidingWindowCounterRule: &models.SlidingWindowCounterRule{MaxRequests: 50, WindowSize: 60},
+		}
+
+		limited := limiter.applyClientLimits(models.RateLimitRequest{ClientID: "customer-42", Endpoint: rule.APIEndpoint}, scheduled)
+		assert.Same(t, scheduled, limited)
+	})
+}
+
+func TestTierCacheSweep(t *testing.T) {
+	limiter := &Limiter{tierSvc: &fakeTierService{tiers: map[string]string{}}}
+
+	// Tiers cached long ago are removed by the next sweep
+	expiresAt := time.Now().Add(-time.Minute)
+	limiter.tierCache = map[string]cachedTier{}
+	for i := 0; i < tierCacheSweepEvery-1; i++ {
+		limiter.tierCache[fmt.Sprintf("client-%d", i)] = cachedTier{tier: "free", expiresAt: expiresAt}
+		limiter.tierInserts++
+	}
+
+	limiter.lookupClientTier("10.0.0.1")
+
+	assert.Len(t, limiter.tierCache, 1)
+	assert.Contains(t, limiter.tierCache, "10.0.0.1")
+}
@@ -1,7 +1,6 @@
 package limiter
 
 import (
-	"context"
 	"errors"
 	"fmt"
 	"net/http"
@@ -17,7 +16,8 @@ import (
 )
 
 const (
-	BucketExpireTime = time.Second * 60
+	BucketExpireTime     = time.Second * 60
+	tokenBucketKeyPrefix = "token_bucket_"
 )
 
 type TokenBucketService struct {
@@ -121,7 +121,7 @@ func (t *TokenBucketService) spawnNewBucket(key string, rule *models.Rule) (*mod
 }
 
 func (t *TokenBucketService) getBucket(key string) (*models.Bucket, bool, error) {
-	key = "token_bucket_" + key
+	key = tokenBucketKeyPrefix + key
 	data, found, err := t.redisClient.JSONGet(key)
 	if err != nil {
 		log.Error().Err(err).Msg("Error fetching bucket from Redis")
@@ -141,8 +141,7 @@ func (t *TokenBucketService) getBucket(key string) (*models.Bucket, bool, error)
 }
 
 func (t *TokenBucketService) addTokens() {
-	ctx := context.TODO()
-	keys, err := redisClient.TokenBucketClient.Keys(ctx, "token_bucket_*").Result()
+	keys, err := redisClient.NewRedisKeyScanner(redisClient.TokenBucketClient).ScanKeys(tokenBucketKeyPrefix + "*")
 	if err != nil {
 		log.Error().Err(err).Msg("Unable to get Redis keys")
 		return
@@ -159,7 +158,7 @@ func (t *TokenBucketService) addTokens() {
 func (t *TokenBucketService) processRequest(key string, rule *models.Rule) *models.RateLimitResponse {
 	bucket, found, err := t.getBucket(key)
 	if err != nil {
-		log.Error().Msgf("error while getting bucket %s" + err.Error())
+		log.Error().Err(err).Msg("error while getting bucket")
 		return utils.BuildRateLimitErrorResponse(500)
 	}
 
@@ -190,7 +189,7 @@ func (t *TokenBucketService) processRequest(key string, rule *models.Rule) *mode
 }
 
 func (t *TokenBucketService) saveBucket(bucket *models.Bucket, isNewBucket bool) error {
-	key := "token_bucket_" + bucket.ClientIP + ":" + bucket.Endpoint
+	key := tokenBucketKeyPrefix + bucket.ClientIP + ":" + bucket.Endpoint
 	if err := t.redisClient.JSONSet(key, bucket); err != nil {
 		log.Error().Err(err).Msg("Error saving new bucket to Redis")
 		return err
@@ -207,6 +206,34 @@ func (t *TokenBucketService) saveBucket(bucket *models.Bucket, isNewBucket bool)
 	return nil
 }
 
+// grantTokens adds extra tokens to the client's bucket on top of its capacity. The extra tokens are not
+// refilled once consumed. A bucket is created if the client does not have one yet.
+func (t *TokenBucketService) grantTokens(key string, rule *models.Rule, extra int) (*models.Bucket, error) {
+	bucket, found, err := t.getBucket(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if !found {
+		bucket, err = t.spawnNewBucket(key, rule)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	bucket.AvailableTokens += extra
+
+	if err := t.saveBucket(bucket, false); err != nil {
+		return nil, err
+	}
+
+	return bucket, nil
+}
+
+func (t *TokenBucketService) reset(key string) error {
+	return t.redisClient.Delete(tokenBucketKeyPrefix + key)
+}
+
 func (t *TokenBucketService) startAddTokenJob() {
 	s, err := gocron.NewScheduler()
 	if err != nil {
@@ -35,11 +35,22 @@ func (m *MockRedisRateLimiterClient) Delete(key string) error {
 	return args.Error(0)
 }
 
+// matchBucket ignores LastRefill since it is stamped with time.Now() when a bucket is created
+func matchBucket(expected *models.Bucket) interface{} {
+	return mock.MatchedBy(func(b *models.Bucket) bool {
+		return b.ClientIP == expected.ClientIP &&
+			b.Endpoint == expected.Endpoint &&
+			b.Capacity == expected.Capacity &&
+			b.TokenAddRate == expected.TokenAddRate &&
+			b.AvailableTokens == expected.AvailableTokens &&
+			b.RetentionTime == expected.RetentionTime
+	})
+}
+
 func TestTokenBucketService(t *testing.T) {
 	mockRedis := new(MockRedisRateLimiterClient)
 
-	slackSVC := service.NewSlackService("", "")
-	errorNotificationSVC := service.NewErrorNotificationSVC(*slackSVC)
+	errorNotificationSVC := service.NewErrorNotificationSVC(service.NewMultiNotifier(), nil, 0)
 
 	svc := NewTokenBucketService(mockRedis, errorNotificationSVC)
 
@@ -109,6 +120,7 @@ func TestTokenBucketService(t *testing.T) {
 			ClientIP:        "192.168.1.23",
 			CreatedAt:       time.Now().Unix(),
 			AvailableTokens: 100,
+			RetentionTime:   60,
 		}
 
 		mockRedis.On("JSONSet", "token_bucket_192.168.1.23:/api/v1/get-data", bucket).Return(nil)
@@ -129,6 +141,7 @@ func TestTokenBucketService(t *testing.T) {
 			ClientIP:        "192.168.1.23",
 			CreatedAt:       time.Now().Unix(),
 			AvailableTokens: 100,
+			RetentionTime:   60,
 		}
 
 		mockRedis.On("JSONSet", "token_bucket_192.168.1.23:/api/v1/get-data", bucket).Return(nil)
@@ -149,6 +162,7 @@ func TestTokenBucketService(t *testing.T) {
 			ClientIP:        "192.168.1.23",
 			CreatedAt:       time.Now().Unix(),
 			AvailableTokens: 100,
+			RetentionTime:   60,
 		}
 
 		mockRedis.On("JSONSet", "token_bucket_192.168.1.23:/api/v1/get-data", bucket).Return(errors.New("redis-error"))
@@ -169,12 +183,13 @@ func TestTokenBucketService(t *testing.T) {
 			ClientIP:        "192.168.1.23",
 			CreatedAt:       time.Now().Unix(),
 			AvailableTokens: 100,
+			RetentionTime:   60,
 		}
 
 		mockRedis.On("JSONSet", "token_bucket_192.168.1.23:/api/v1/get-data", bucket).Return(nil)
 		mockRedis.On("Expire", "token_bucket_192.168.1.23:/api/v1/get-data", time.Second*60).Return(errors.New("redis-error"))
 
-		err := svc.saveBucket(bucket, false)
+		err := svc.saveBucket(bucket, true)
 		assert.Error(t, err)
 
 		mockRedis.ExpectedCalls = nil
@@ -199,6 +214,7 @@ func TestTokenBucketService(t *testing.T) {
 			TokenBucketRule: &models.TokenBucketRule{
 				BucketCapacity: 10,
 				TokenAddRate:   10,
+				RetentionTime:  60,
 			},
 		}
 
@@ -213,9 +229,10 @@ func TestTokenBucketService(t *testing.T) {
 			ClientIP:        "192.168.12.1",
 			CreatedAt:       time.Now().Unix(),
 			AvailableTokens: 10,
+			RetentionTime:   60,
 		}
 
-		mockRedis.On("JSONSet", key, bucket).Return(nil)
+		mockRedis.On("JSONSet", key, matchBucket(bucket)).Return(nil)
 		mockRedis.On("Expire", key, time.Second*60).Return(nil)
 
 		_, err := svc.createBucketFromRule(ip, "/api/v1/get-data", rule)
@@ -233,6 +250,7 @@ func TestTokenBucketService(t *testing.T) {
 			TokenBucketRule: &models.TokenBucketRule{
 				BucketCapacity: 10,
 				TokenAddRate:   10,
+				RetentionTime:  60,
 			},
 		}
 
@@ -247,9 +265,10 @@ func TestTokenBucketService(t *testing.T) {
 			ClientIP:        "192.168.12.1",
 			CreatedAt:       time.Now().Unix(),
 			AvailableTokens: 10,
+			RetentionTime:   60,
 		}
 
-		mockRedis.On("JSONSet", key, bucket).Return(errors.New("redis-error"))
+		mockRedis.On("JSONSet", key, matchBucket(bucket)).Return(errors.New("redis-error"))
 		mockRedis.On("Expire", key, time.Second*60).Return(nil)
 
 		_, err := svc.createBucketFromRule(ip, "/api/v1/get-data", rule)
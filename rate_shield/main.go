@@ -1,21 +1,27 @@
 package main
 
 import (
+	"context"
 	"os"
+	"os/signal"
+	"strings"
+	"syscall"
 	"time"
 
 	"github.com/joho/godotenv"
+	"github.com/redis/go-redis/v9"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 	"github.com/x-sushant-x/RateShield/api"
 	"github.com/x-sushant-x/RateShield/limiter"
 	redisClient "github.com/x-sushant-x/RateShield/redis"
 	"github.com/x-sushant-x/RateShield/service"
+	"github.com/x-sushant-x/RateShield/utils"
 )
 
-var (
-	slackToken     string
-	slackChannelID string
+const (
+	notificationDrainTimeout = 10 * time.Second
+	grpcShutdownTimeout      = 10 * time.Second
 )
 
 func init() {
@@ -23,7 +29,6 @@ func init() {
 	zerolog.SetGlobalLevel(zerolog.DebugLevel)
 
 	loadENVFile()
-	setSlackCredentials()
 }
 
 func main() {
@@ -33,13 +38,32 @@ func main() {
 		log.Fatal().Err(err)
 	}
 
-	// Create audit client and service
-	auditClient := redisClient.NewAuditClient(redisRulesClient.(redisClient.RedisRules).GetClient())
-	auditSvc := service.NewAuditService(auditClient)
+	migratedRules, err := redisRulesClient.MigrateLegacyRules()
+	if err != nil {
+		log.Fatal().Err(err).Msg("unable to migrate rules to the rules namespace")
+	}
+	if migratedRules > 0 {
+		log.Info().Msgf("Migrated %d rules to the rules namespace ✅", migratedRules)
+	}
 
-	slackSvc := service.NewSlackService(slackToken, slackChannelID)
+	// Create audit client and service, rule changes are also sent to the rule webhooks
+	auditClient := redisClient.NewAuditClient(redisRulesClient.(redisClient.RedisRules).GetClient())
+	ruleWebhookClient := redisClient.NewRuleWebhookClient(redisRulesClient.(redisClient.RedisRules).GetClient())
+	ruleWebhookSvc := service.NewRuleWebhookService(ruleWebhookClient, service.NewAuditService(auditClient, nil), utils.GetRuleWebhookMaxRetries())
+	auditSvc := service.NewAuditService(auditClient, ruleWebhookSvc)
+
+	notificationWorkers, notificationQueueSize, notificationMaxRetries := utils.GetNotificationDispatcherDetails()
+	notifier := service.NewNotificationDispatcher(buildNotifier(), notificationWorkers, notificationQueueSize, notificationMaxRetries)
+
+	// Without a shared store every instance keeps its own cooldowns and sends its own notifications
+	var notificationDedup service.NotificationDedupStore
+	sharedNotificationDedup, notificationCooldown := utils.GetNotificationDedupDetails()
+	if sharedNotificationDedup {
+		notificationDedup = redisClient.NewNotificationDedupClient(redisRulesClient.(redisClient.RedisRules).GetClient())
+	}
 
-	errorNotificationSvc := service.NewErrorNotificationSVC(*slackSvc)
+	errorNotificationSvc := service.NewErrorNotificationSVC(notifier, notificationDedup, notificationCooldown)
+	errorNotificationSvc.StartDigestJob()
 
 	redisRateLimiter, clusterClient, err := redisClient.NewRedisRateLimitClient()
 	if err != nil {
@@ -52,21 +76,106 @@ func main() {
 
 	redisRulesSvc := service.NewRedisRulesService(redisRulesClient, auditSvc)
 
+	rulesFilePath, rulesFileReadOnly, rulesFileSyncInterval := utils.GetRulesFileDetails()
+	if len(rulesFilePath) != 0 {
+		rulesFileSyncer := service.NewRulesFileSyncer(redisRulesSvc, rulesFilePath, rulesFileReadOnly, rulesFileSyncInterval)
+
+		diff, err := rulesFileSyncer.Sync()
+		if err != nil {
+			log.Fatal().Err(err).Msg("unable to sync rules file")
+		}
+		log.Info().Msgf("Rules file synced: %d created, %d updated, %d deleted ✅", len(diff.Created), len(diff.Updated), len(diff.Deleted))
+
+		go rulesFileSyncer.Watch()
+	}
+
+	// Expired rules already stop applying in the limiter, the job only cleans them up
+	redisRulesSvc.StartRuleExpiryJob(30 * time.Second)
+
 	slidingWindowSvc := limiter.NewSlidingWindowService(clusterClient)
 
-	limiter := limiter.NewRateLimiterService(&tokenBucketSvc, &fixedWindowSvc, &slidingWindowSvc, redisRulesSvc)
+	quotaNotificationSvc := service.NewQuotaNotificationSVC(notifier)
+	quotaSvc := limiter.NewQuotaService(clusterClient, quotaNotificationSvc)
+
+	concurrencySvc := limiter.NewConcurrencyService(clusterClient)
+
+	keyScanner := redisClient.NewRedisKeyScanner(clusterClient)
+
+	penaltySvc := limiter.NewPenaltyService(clusterClient, keyScanner)
+
+	accessListClient := redisClient.NewAccessListClient(redisRulesClient.(redisClient.RedisRules).GetClient())
+	accessListSvc := service.NewAccessListService(accessListClient, auditSvc)
+
+	tierClient := redisClient.NewTierClient(redisRulesClient.(redisClient.RedisRules).GetClient())
+	tierSvc := service.NewTierService(tierClient, auditSvc)
+
+	alertRuleClient := redisClient.NewAlertRuleClient(redisRulesClient.(redisClient.RedisRules).GetClient())
+	alertRuleSvc := service.NewAlertRuleService(alertRuleClient, auditSvc)
+	alertSvc := limiter.NewAlertService(alertRuleSvc, notifier, notificationDedup)
+
+	limiter := limiter.NewRateLimiterService(&tokenBucketSvc, &fixedWindowSvc, &slidingWindowSvc, &quotaSvc, &concurrencySvc, &penaltySvc, redisRulesSvc, keyScanner, accessListSvc, tierSvc, &alertSvc)
 	limiter.StartRateLimiter()
 
 	go func() {
-		server := api.NewServer(&limiter)
+		server := api.NewServer(&limiter, notifier, ruleWebhookSvc)
 		log.Fatal().Err(server.StartServer())
 	}()
 
+	// The gRPC server only reports itself as serving while both Redis deployments answer and the rules are
+	// cached. A cluster ping only reaches one node, so every master is pinged.
+	rulesRedis := redisRulesClient.(redisClient.RedisRules).GetClient()
+	grpcServer, err := api.NewGRPCServer(utils.GetGRPCServerDetails(), &limiter, redisRulesSvc, auditSvc, map[string]api.HealthCheck{
+		"redis rules instance": func(ctx context.Context) error {
+			return rulesRedis.Ping(ctx).Err()
+		},
+		"redis cluster": func(ctx context.Context) error {
+			return clusterClient.ForEachMaster(ctx, func(ctx context.Context, master *redis.Client) error {
+				return master.Ping(ctx).Err()
+			})
+		},
+		"rules": func(ctx context.Context) error {
+			return limiter.CheckRules()
+		},
+	})
+	if err != nil {
+		log.Fatal().Err(err).Msg("unable to set up gRPC server")
+	}
+
 	go func() {
-		api.StartGRPCServer(&limiter, "50051")
+		if err := grpcServer.Start(); err != nil {
+			log.Fatal().Err(err).Msg("unable to start gRPC server")
+		}
 	}()
 
-	select {}
+	// Running gRPC calls finish and queued notifications and rule change events are delivered before exiting
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	<-ctx.Done()
+
+	log.Info().Msg("Shutting down, finishing running gRPC calls")
+
+	grpcCtx, cancelGRPC := context.WithTimeout(context.Background(), grpcShutdownTimeout)
+	defer cancelGRPC()
+
+	if err := grpcServer.Shutdown(grpcCtx); err != nil {
+		log.Warn().Err(err).Msg("cancelled gRPC calls that were still running")
+	}
+
+	log.Info().Msg("Delivering queued notifications")
+
+	drainCtx, cancel := context.WithTimeout(context.Background(), notificationDrainTimeout)
+	defer cancel()
+
+	if err := notifier.Shutdown(drainCtx); err != nil {
+		stats := notifier.Stats()
+		log.Warn().Err(err).Msgf("stopped before every notification was delivered, %d still queued", stats.Queued)
+	}
+
+	if err := ruleWebhookSvc.Shutdown(drainCtx); err != nil {
+		log.Warn().Err(err).Msg("stopped before every rule change event was delivered, see the delivery log for failed deliveries")
+	}
+
+	log.Info().Msg("RateShield stopped")
 }
 
 func loadENVFile() {
@@ -76,16 +185,33 @@ func loadENVFile() {
 	}
 }
 
-func setSlackCredentials() {
-	sToken := os.Getenv("SLACK_TOKEN")
-	if len(sToken) == 0 {
-		log.Panic().Msg("SLACK_TOKEN not available in env file")
+// buildNotifier sets up every notification channel configured in the environment. RateShield also runs
+// without any channel, notifications are then dropped.
+func buildNotifier() *service.MultiNotifier {
+	var notifiers []service.Notifier
+
+	if token, channel := utils.GetSlackDetails(); len(token) != 0 {
+		notifiers = append(notifiers, service.NewSlackService(token, channel))
+	}
+
+	if url, secret := utils.GetWebhookNotifierDetails(); len(url) != 0 {
+		notifiers = append(notifiers, service.NewWebhookNotifier(url, secret))
 	}
-	slackToken = sToken
 
-	sChannel := os.Getenv("SLACK_CHANNEL")
-	if len(sChannel) == 0 {
-		log.Panic().Msg("SLACK_CHANNEL not available in env file")
+	if smtpConfig := utils.GetSMTPDetails(); len(smtpConfig.Host) != 0 {
+		notifiers = append(notifiers, service.NewEmailNotifier(smtpConfig))
 	}
-	slackChannelID = sChannel
+
+	if routingKey, eventsURL := utils.GetPagerDutyDetails(); len(routingKey) != 0 {
+		notifiers = append(notifiers, service.NewPagerDutyNotifier(routingKey, eventsURL))
+	}
+
+	notifier := service.NewMultiNotifier(notifiers...)
+	if notifier.Enabled() {
+		log.Info().Msgf("Notification channels: %s ✅", strings.Join(notifier.Channels(), ", "))
+	} else {
+		log.Warn().Msg("No notification channels configured, notifications are disabled")
+	}
+
+	return notifier
 }
@@ -0,0 +1,17 @@
+package models
+
+const (
+	AccessListAllow = "allow"
+	AccessListDeny  = "deny"
+)
+
+// AccessLists are the IPs and CIDR ranges that skip rate limiting or are rejected on every endpoint
+type AccessLists struct {
+	Allow []string `json:"allow"`
+	Deny  []string `json:"deny"`
+}
+
+type AccessListEntriesDTO struct {
+	List    string   `json:"list"` // allow or deny
+	Entries []string `json:"entries"`
+}
@@ -0,0 +1,31 @@
+package models
+
+const (
+	AlertTypeDenyRate        = "DENY_RATE"
+	AlertTypeThrottledClient = "THROTTLED_CLIENT"
+	AlertTypeQuotaExhaustion = "QUOTA_EXHAUSTION"
+	AlertTypeRedisFallback   = "REDIS_FALLBACK"
+)
+
+// AlertRule describes a rate limit event worth a notification. Which fields are used depends on the type:
+//   - DENY_RATE: Threshold percent of the requests to an endpoint were denied within Window seconds, once
+//     at least MinRequests were seen
+//   - THROTTLED_CLIENT: a single client was denied without a break for Duration seconds
+//   - QUOTA_EXHAUSTION: a client used Threshold percent of its quota
+//   - REDIS_FALLBACK: a request was allowed because its rule has allow_on_error and the limiter failed
+type AlertRule struct {
+	Name        string   `json:"name"`
+	Type        string   `json:"type"`
+	Endpoint    string   `json:"endpoint,omitempty"` // Empty matches every endpoint
+	Threshold   float64  `json:"threshold,omitempty"`
+	Window      int      `json:"window,omitempty"`
+	MinRequests int64    `json:"min_requests,omitempty"`
+	Duration    int      `json:"duration,omitempty"`
+	Severity    string   `json:"severity,omitempty"`
+	Channels    []string `json:"channels,omitempty"` // Empty sends to every configured channel
+	Cooldown    int      `json:"cooldown,omitempty"` // Seconds before the same alert fires again for the same endpoint or client
+}
+
+type AlertRuleNameDTO struct {
+	Name string `json:"name"`
+}
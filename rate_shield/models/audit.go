@@ -2,15 +2,17 @@ package models
 
 // AuditLog represents an audit trail entry for rule modifications
 type AuditLog struct {
-	ID        string `json:"id"`         // Unique identifier (UUID)
-	Timestamp int64  `json:"timestamp"`  // Unix timestamp
-	Actor     string `json:"actor"`      // User ID/email who performed the action
-	Action    string `json:"action"`     // CREATE, UPDATE, or DELETE
-	Endpoint  string `json:"endpoint"`   // The API endpoint affected by the rule
-	OldRule   *Rule  `json:"old_rule"`   // State before change (null for CREATE)
-	NewRule   *Rule  `json:"new_rule"`   // State after change (null for DELETE)
-	IPAddress string `json:"ip_address"` // IP address of the requester
-	UserAgent string `json:"user_agent"` // User agent of the requester
+	ID        string `json:"id"`                  // Unique identifier (UUID)
+	Timestamp int64  `json:"timestamp"`           // Unix timestamp
+	Actor     string `json:"actor"`               // User ID/email who performed the action
+	Action    string `json:"action"`              // One of the AuditAction constants
+	Endpoint  string `json:"endpoint"`            // The API endpoint affected by the rule
+	OldRule   *Rule  `json:"old_rule"`            // State before change (null for CREATE)
+	NewRule   *Rule  `json:"new_rule"`            // State after change (null for DELETE)
+	ClientIP  string `json:"client_ip,omitempty"` // Client whose limiter state or tier was changed (limiter and tier actions only)
+	Details   string `json:"details,omitempty"`   // Free form description of the change (limiter, access list and tier actions only)
+	IPAddress string `json:"ip_address"`          // IP address of the requester
+	UserAgent string `json:"user_agent"`          // User agent of the requester
 }
 
 // AuditAction constants for different types of actions
@@ -18,12 +20,28 @@ const (
 	AuditActionCreate = "CREATE"
 	AuditActionUpdate = "UPDATE"
 	AuditActionDelete = "DELETE"
+
+	AuditActionResetLimiter = "RESET_LIMITER"
+	AuditActionGrantQuota   = "GRANT_QUOTA"
+	AuditActionLiftBan      = "LIFT_BAN"
+
+	AuditActionAccessListAdd    = "ACCESS_LIST_ADD"
+	AuditActionAccessListRemove = "ACCESS_LIST_REMOVE"
+
+	AuditActionAssignTier   = "ASSIGN_TIER"
+	AuditActionUnassignTier = "UNASSIGN_TIER"
+
+	AuditActionSaveAlertRule   = "SAVE_ALERT_RULE"
+	AuditActionDeleteAlertRule = "DELETE_ALERT_RULE"
+
+	AuditActionSaveRuleWebhook   = "SAVE_RULE_WEBHOOK"
+	AuditActionDeleteRuleWebhook = "DELETE_RULE_WEBHOOK"
 )
 
 // PaginatedAuditLogs represents a paginated response of audit logs
 type PaginatedAuditLogs struct {
-	PageNumber  int         `json:"page_number"`
-	TotalItems  int         `json:"total_items"`
-	HasNextPage bool        `json:"has_next_page"`
-	Logs        []AuditLog  `json:"logs"`
+	PageNumber  int        `json:"page_number"`
+	TotalItems  int        `json:"total_items"`
+	HasNextPage bool       `json:"has_next_page"`
+	Logs        []AuditLog `json:"logs"`
 }
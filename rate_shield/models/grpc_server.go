@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// GRPCServerConfig configures the gRPC server
+type GRPCServerConfig struct {
+	Port                 string
+	AdminTokens          map[string]string // Token -> actor of calls to the admin services, disabled when empty
+	MaxConcurrentStreams uint32            // Concurrent calls per connection, 0 keeps the gRPC default
+	StreamMaxInFlight    int               // Checks running at once per CheckRateLimitStream stream
+	KeepaliveTime        time.Duration     // Idle connections are pinged after this long
+	KeepaliveTimeout     time.Duration     // Connections that don't answer a ping within this are closed
+	KeepaliveMinTime     time.Duration     // Clients pinging more often than this are disconnected
+	TLSCertFile          string            // Serves TLS when set together with TLSKeyFile
+	TLSKeyFile           string
+	TLSClientCAFile      string // Requires client certificates signed by this CA (mTLS)
+	Reflection           bool   // Lets clients list the services, off unless GRPC_REFLECTION is true
+}
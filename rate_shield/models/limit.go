@@ -1,8 +1,27 @@
 package models
 
+// RateLimitRequest describes the client a limit is checked for. ClientID and Tier are optional descriptors.
+type RateLimitRequest struct {
+	ClientIP string
+	Endpoint string
+	ClientID string // Identifies the client instead of its IP, e.g. an API key or account ID
+	Tier     string // Tier of the client, looked up from the tier mapping when empty
+}
+
+// Identity returns what the client is limited by, its client ID if it has one and else its IP
+func (r RateLimitRequest) Identity() string {
+	if r.ClientID != "" {
+		return r.ClientID
+	}
+	return r.ClientIP
+}
+
 type RateLimitResponse struct {
 	RateLimit_Limit     int64
 	RateLimit_Remaining int64
 	Success             bool
 	HTTPStatusCode      int
+	Reason              string // Why the request skipped the strategy, set for allow and deny list matches
+	LeaseID             string // Lease held by the request, set for the CONCURRENCY strategy
+	RetryAfter          int64  // Seconds until the client may retry, set for penalty bans
 }
@@ -0,0 +1,69 @@
+package models
+
+// LimiterState is a snapshot of the state every strategy holds for a client on an endpoint.
+// Strategies with nothing stored for the client are left nil.
+type LimiterState struct {
+	ClientIP      string              `json:"client_ip"`
+	Endpoint      string              `json:"endpoint"`
+	Strategy      string              `json:"strategy"` // Strategy of the rule currently applied to the endpoint (empty if no rule)
+	TokenBucket   *Bucket             `json:"token_bucket"`
+	FixedWindow   *FixedWindowCounter `json:"fixed_window"`
+	SlidingWindow *SlidingWindowState `json:"sliding_window"`
+	Quota         *QuotaUsage         `json:"quota"`
+	Concurrency   *ConcurrencyState   `json:"concurrency"`
+}
+
+type ConcurrencyState struct {
+	ActiveLeases  int64 `json:"active_leases"`
+	MaxConcurrent int64 `json:"max_concurrent"`
+	LeaseTimeout  int   `json:"lease_timeout"`
+}
+
+type SlidingWindowState struct {
+	ActiveRequests int64 `json:"active_requests"`
+	MaxRequests    int64 `json:"max_requests"`
+	WindowSize     int   `json:"window"`
+}
+
+type LimiterStateDTO struct {
+	ClientIP string `json:"client_ip"`
+	Endpoint string `json:"endpoint"`
+}
+
+type GrantQuotaDTO struct {
+	ClientIP      string `json:"client_ip"`
+	Endpoint      string `json:"endpoint"`
+	ExtraRequests int64  `json:"extra_requests"`
+}
+
+// LimiterKeyEntry is the state of a single client's bucket or window on an endpoint
+type LimiterKeyEntry struct {
+	Key       string `json:"key"`
+	ClientIP  string `json:"client_ip"`
+	Used      int64  `json:"used"`
+	Limit     int64  `json:"limit"`
+	Remaining int64  `json:"remaining"`
+}
+
+type PaginatedLimiterKeys struct {
+	Endpoint    string            `json:"endpoint"`
+	Strategy    string            `json:"strategy"`
+	NextCursor  string            `json:"next_cursor"`
+	HasNextPage bool              `json:"has_next_page"`
+	Entries     []LimiterKeyEntry `json:"entries"`
+}
+
+// PenaltyBan is a client banned from an endpoint by the penalty policy of its rule
+type PenaltyBan struct {
+	ClientIP  string `json:"client_ip"`
+	Endpoint  string `json:"endpoint"`
+	Offense   int64  `json:"offense"` // How many times the client was banned, the ban duration doubles with each
+	BannedAt  int64  `json:"banned_at"`
+	ExpiresAt int64  `json:"expires_at"`
+}
+
+type PaginatedPenaltyBans struct {
+	NextCursor  string       `json:"next_cursor"`
+	HasNextPage bool         `json:"has_next_page"`
+	Bans        []PenaltyBan `json:"bans"`
+}
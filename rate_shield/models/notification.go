@@ -0,0 +1,76 @@
+package models
+
+import "time"
+
+const (
+	NotificationSeverityCritical = "critical"
+	NotificationSeverityError    = "error"
+	NotificationSeverityWarning  = "warning"
+	NotificationSeverityInfo     = "info"
+)
+
+// Names of the notification channels notifications can be routed to
+const (
+	NotificationChannelSlack     = "slack"
+	NotificationChannelWebhook   = "webhook"
+	NotificationChannelEmail     = "email"
+	NotificationChannelPagerDuty = "pagerduty"
+)
+
+const (
+	NotificationEventSystemError    = "SYSTEM_ERROR"
+	NotificationEventErrorDigest    = "SYSTEM_ERROR_DIGEST"
+	NotificationEventQuotaThreshold = "QUOTA_THRESHOLD"
+)
+
+// Notification is sent to every configured notification channel, each channel renders it in its own format.
+// Key identifies what the notification is about, e.g. the client and endpoint, so repeats can be grouped.
+type Notification struct {
+	Event     string            `json:"event"`
+	Severity  string            `json:"severity"`
+	Key       string            `json:"key,omitempty"`
+	Title     string            `json:"title"`
+	Text      string            `json:"text"`
+	Fields    map[string]string `json:"fields,omitempty"`
+	Timestamp time.Time         `json:"timestamp"`
+}
+
+// PagerDutyEvent is an event in the PagerDuty Events API v2 format
+type PagerDutyEvent struct {
+	RoutingKey  string           `json:"routing_key"`
+	EventAction string           `json:"event_action"`
+	DedupKey    string           `json:"dedup_key,omitempty"`
+	Payload     PagerDutyPayload `json:"payload"`
+}
+
+type PagerDutyPayload struct {
+	Summary       string            `json:"summary"`
+	Source        string            `json:"source"`
+	Severity      string            `json:"severity"`
+	Timestamp     string            `json:"timestamp"`
+	Class         string            `json:"class,omitempty"`
+	CustomDetails map[string]string `json:"custom_details,omitempty"`
+}
+
+// SMTPConfig holds the mail server and recipients of email notifications
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+	To       []string
+}
+
+// NotificationStats describes the background delivery of notifications since start. Every notification
+// counts once per channel it is sent to.
+type NotificationStats struct {
+	Workers       int    `json:"workers"`
+	QueueCapacity int    `json:"queue_capacity"`
+	Queued        int    `json:"queued"`
+	Enqueued      uint64 `json:"enqueued"`
+	Delivered     uint64 `json:"delivered"`
+	Retried       uint64 `json:"retried"`
+	Failed        uint64 `json:"failed"`  // Given up after every retry
+	Dropped       uint64 `json:"dropped"` // Not queued because the queue was full or delivery was shutting down
+}
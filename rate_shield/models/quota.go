@@ -0,0 +1,13 @@
+package models
+
+// QuotaUsage is how much of its quota a client used on an endpoint in the current period
+type QuotaUsage struct {
+	Identity    string `json:"identity"`
+	Endpoint    string `json:"endpoint"`
+	Period      string `json:"period"`
+	Limit       int64  `json:"limit"`
+	Used        int64  `json:"used"`
+	Remaining   int64  `json:"remaining"`
+	PeriodStart int64  `json:"period_start"` // Unix timestamp the current period started at
+	ResetsAt    int64  `json:"resets_at"`    // Unix timestamp the next period starts at
+}
@@ -0,0 +1,70 @@
+package models
+
+import "encoding/json"
+
+// Events sent to rule webhooks
+const (
+	RuleEventCreated = "rule.created"
+	RuleEventUpdated = "rule.updated"
+	RuleEventDeleted = "rule.deleted"
+)
+
+const (
+	RuleWebhookDeliveryPending   = "PENDING"
+	RuleWebhookDeliveryDelivered = "DELIVERED"
+	RuleWebhookDeliveryFailed    = "FAILED"
+)
+
+// RuleWebhook receives an event for every rule change. The secret is never returned by the API.
+type RuleWebhook struct {
+	Name      string   `json:"name"`
+	URL       string   `json:"url"`
+	Secret    string   `json:"secret,omitempty"`    // Signs the body with HMAC-SHA256, saving without a secret keeps the current one
+	Endpoints []string `json:"endpoints,omitempty"` // Only changes of these endpoints are sent, empty sends every change
+	Disabled  bool     `json:"disabled,omitempty"`
+}
+
+type RuleWebhookNameDTO struct {
+	Name string `json:"name"`
+}
+
+// RuleChangeEvent is the body posted to rule webhooks
+type RuleChangeEvent struct {
+	ID        string            `json:"id"`    // ID of the audit log entry of the change
+	Event     string            `json:"event"` // One of the RuleEvent constants
+	Timestamp int64             `json:"timestamp"`
+	Actor     string            `json:"actor"`
+	Endpoint  string            `json:"endpoint"`
+	OldRule   *Rule             `json:"old_rule"` // null for rule.created
+	NewRule   *Rule             `json:"new_rule"` // null for rule.deleted
+	Diff      []RuleFieldChange `json:"diff"`
+}
+
+// RuleFieldChange is a single changed field of a rule. Field is the JSON path of the field such as
+// "token_bucket_rule.bucket_capacity" or "overrides[0].identity", a value missing on one side is null.
+type RuleFieldChange struct {
+	Field string      `json:"field"`
+	Old   interface{} `json:"old"`
+	New   interface{} `json:"new"`
+}
+
+// RuleWebhookDelivery records the delivery of an event to one webhook
+type RuleWebhookDelivery struct {
+	ID         string          `json:"id"`
+	Webhook    string          `json:"webhook"`
+	URL        string          `json:"url"`
+	EventID    string          `json:"event_id"`
+	Event      string          `json:"event"`
+	Endpoint   string          `json:"endpoint"`
+	Status     string          `json:"status"` // One of the RuleWebhookDelivery constants
+	Attempts   int             `json:"attempts"`
+	StatusCode int             `json:"status_code,omitempty"` // HTTP status of the last attempt
+	Error      string          `json:"error,omitempty"`       // Error of the last failed attempt
+	CreatedAt  int64           `json:"created_at"`
+	UpdatedAt  int64           `json:"updated_at"`
+	Payload    json.RawMessage `json:"payload"`
+}
+
+type RuleWebhookDeliveryIDDTO struct {
+	ID string `json:"id"`
+}
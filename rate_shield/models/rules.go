@@ -1,13 +1,79 @@
 package models
 
+const (
+	StrategyTokenBucket          = "TOKEN BUCKET"
+	StrategyFixedWindowCounter   = "FIXED WINDOW COUNTER"
+	StrategySlidingWindowCounter = "SLIDING WINDOW COUNTER"
+	StrategyQuota                = "QUOTA"
+	StrategyConcurrency          = "CONCURRENCY"
+)
+
+const (
+	QuotaPeriodHour  = "hour"
+	QuotaPeriodDay   = "day"
+	QuotaPeriodMonth = "month"
+)
+
+const (
+	ConcurrencyScopeClient   = "client"
+	ConcurrencyScopeEndpoint = "endpoint"
+)
+
+const (
+	// RuleManagedByFile marks rules that were loaded from the rules file
+	RuleManagedByFile = "rules-file"
+)
+
 type Rule struct {
 	Strategy                 string                    `json:"strategy"`
 	APIEndpoint              string                    `json:"endpoint"`
 	HTTPMethod               string                    `json:"http_method"`
 	AllowOnError             bool                      `json:"allow_on_error"`
+	Version                  int64                     `json:"version"` // Assigned by RateShield on every write, starts at 1
+	ManagedBy                string                    `json:"managed_by,omitempty"`
+	ReadOnly                 bool                      `json:"read_only,omitempty"` // Set on file managed rules when edits through the API are not allowed
+	TokenBucketRule          *TokenBucketRule          `json:"token_bucket_rule,omitempty"`
+	FixedWindowCounterRule   *FixedWindowCounterRule   `json:"fixed_window_counter_rule,omitempty"`
+	SlidingWindowCounterRule *SlidingWindowCounterRule `json:"sliding_window_counter_rule,omitempty"`
+	QuotaRule                *QuotaRule                `json:"quota_rule,omitempty"`
+	ConcurrencyRule          *ConcurrencyRule          `json:"concurrency_rule,omitempty"`
+	Schedule                 *RuleSchedule             `json:"schedule,omitempty"`        // The rule only applies while one of its windows is open
+	ExpiresAt                int64                     `json:"expires_at,omitempty"`      // Unix timestamp after which the rule no longer applies and is deleted
+	ScheduledRules           []Rule                    `json:"scheduled_rules,omitempty"` // Replace the rule while active, the first active one wins
+	AllowList                []string                  `json:"allow_list,omitempty"`      // IPs and CIDR ranges that are never limited on the endpoint
+	DenyList                 []string                  `json:"deny_list,omitempty"`       // IPs and CIDR ranges that are always rejected on the endpoint
+	Overrides                []RuleOverride            `json:"overrides,omitempty"`       // Limits for specific clients, take precedence over tiers
+	Tiers                    []RuleTier                `json:"tiers,omitempty"`           // Limits for clients on a plan such as free, pro or enterprise
+	Penalty                  *PenaltyPolicy            `json:"penalty,omitempty"`         // Bans clients that keep getting rate limited
+}
+
+// RuleLimits replaces the limits of a rule's strategy for some clients. Only the sub-rule of the rule's
+// strategy is set.
+type RuleLimits struct {
 	TokenBucketRule          *TokenBucketRule          `json:"token_bucket_rule,omitempty"`
 	FixedWindowCounterRule   *FixedWindowCounterRule   `json:"fixed_window_counter_rule,omitempty"`
 	SlidingWindowCounterRule *SlidingWindowCounterRule `json:"sliding_window_counter_rule,omitempty"`
+	QuotaRule                *QuotaRule                `json:"quota_rule,omitempty"`
+	ConcurrencyRule          *ConcurrencyRule          `json:"concurrency_rule,omitempty"`
+}
+
+// RuleOverride holds the limits of a single client, identified by its client ID or else its IP
+type RuleOverride struct {
+	Identity string `json:"identity"`
+	RuleLimits
+}
+
+// RuleTier holds the limits of every client on a tier
+type RuleTier struct {
+	Name string `json:"name"`
+	RuleLimits
+}
+
+// RuleSchedule opens a window of Duration seconds every time the cron expression fires
+type RuleSchedule struct {
+	Cron     string `json:"cron"`               // Standard 5 field cron expression, e.g. "0 9 * * MON-FRI"
+	Duration int    `json:"duration"`           // Length of each window in seconds
+	Timezone string `json:"timezone,omitempty"` // IANA time zone the cron expression is evaluated in, defaults to UTC
 }
 
 type TokenBucketRule struct {
@@ -21,6 +87,45 @@ type FixedWindowCounterRule struct {
 	Window      int   `json:"window"`
 }
 
+// RuleHistoryEntry is a stored version of a rule
+type RuleHistoryEntry struct {
+	Version   int64  `json:"version"`
+	Timestamp int64  `json:"timestamp"`
+	Actor     string `json:"actor"`
+	Rule      Rule   `json:"rule"`
+}
+
+type RollbackRuleDTO struct {
+	Endpoint string `json:"endpoint"`
+	Version  int64  `json:"version"`
+}
+
+// RuleChange is a rule whose stored and desired definitions differ
+type RuleChange struct {
+	Endpoint string `json:"endpoint"`
+	Old      Rule   `json:"old"`
+	New      Rule   `json:"new"`
+}
+
+// RulesDiff lists what has to change to turn the stored rules into the desired rules
+type RulesDiff struct {
+	Created   []Rule       `json:"created"`
+	Updated   []RuleChange `json:"updated"`
+	Deleted   []Rule       `json:"deleted"`
+	Unchanged int          `json:"unchanged"`
+}
+
+func (d RulesDiff) HasChanges() bool {
+	return len(d.Created) > 0 || len(d.Updated) > 0 || len(d.Deleted) > 0
+}
+
+// RulesImportResult is what an import changed, or would change when it is a dry run
+type RulesImportResult struct {
+	Mode   string `json:"mode"`
+	DryRun bool   `json:"dry_run"`
+	RulesDiff
+}
+
 type DeleteRuleDTO struct {
 	RuleKey string `json:"rule_key"`
 }
@@ -36,3 +141,29 @@ type SlidingWindowCounterRule struct {
 	MaxRequests int64 `json:"max_requests"`
 	WindowSize  int   `json:"window"`
 }
+
+// QuotaRule allows Limit requests per calendar period, e.g. 10000 per month. Periods start on the hour, at
+// midnight or on the first of the month in Timezone.
+type QuotaRule struct {
+	Limit    int64  `json:"limit"`
+	Period   string `json:"period"`             // hour, day or month
+	Timezone string `json:"timezone,omitempty"` // IANA time zone periods are aligned to, defaults to UTC
+}
+
+// PenaltyPolicy bans a client from an endpoint once it was rate limited MaxDenials times within Window
+// seconds. The first ban lasts BanDuration seconds and every further offense doubles it, up to
+// MaxBanDuration. Offenses are forgotten once a client stays out of trouble for MaxBanDuration after a ban.
+type PenaltyPolicy struct {
+	MaxDenials     int64 `json:"max_denials"`
+	Window         int   `json:"window"`
+	BanDuration    int   `json:"ban_duration"`
+	MaxBanDuration int   `json:"max_ban_duration,omitempty"` // Defaults to 24 hours
+}
+
+// ConcurrencyRule caps the requests in flight. Every allowed request holds a lease until it is released or
+// LeaseTimeout seconds pass, so leases of crashed callers free up on their own.
+type ConcurrencyRule struct {
+	MaxConcurrent int64  `json:"max_concurrent"`
+	LeaseTimeout  int    `json:"lease_timeout"`
+	Scope         string `json:"scope,omitempty"` // client (default) caps each client, endpoint caps all clients together
+}
@@ -0,0 +1,7 @@
+package models
+
+// ClientTierDTO assigns a client to a tier, an empty tier means the client has none
+type ClientTierDTO struct {
+	Identity string `json:"identity"`
+	Tier     string `json:"tier"`
+}
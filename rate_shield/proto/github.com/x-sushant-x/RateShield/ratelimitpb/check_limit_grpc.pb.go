@@ -19,7 +19,9 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	RateLimitService_CheckRateLimit_FullMethodName = "/ratelimit.RateLimitService/CheckRateLimit"
+	RateLimitService_CheckRateLimit_FullMethodName       = "/ratelimit.RateLimitService/CheckRateLimit"
+	RateLimitService_ReleaseLease_FullMethodName         = "/ratelimit.RateLimitService/ReleaseLease"
+	RateLimitService_CheckRateLimitStream_FullMethodName = "/ratelimit.RateLimitService/CheckRateLimitStream"
 )
 
 // RateLimitServiceClient is the client API for RateLimitService service.
@@ -27,6 +29,10 @@ const (
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type RateLimitServiceClient interface {
 	CheckRateLimit(ctx context.Context, in *RateLimitRequest, opts ...grpc.CallOption) (*RateLimitResponse, error)
+	ReleaseLease(ctx context.Context, in *ReleaseLeaseRequest, opts ...grpc.CallOption) (*ReleaseLeaseResponse, error)
+	// Checks many requests over one stream. Responses are sent as soon as their check is done, so they can
+	// arrive in another order than the requests, match them by id.
+	CheckRateLimitStream(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[RateLimitStreamRequest, RateLimitStreamResponse], error)
 }
 
 type rateLimitServiceClient struct {
@@ -47,11 +53,38 @@ func (c *rateLimitServiceClient) CheckRateLimit(ctx context.Context, in *RateLim
 	return out, nil
 }
 
+func (c *rateLimitServiceClient) ReleaseLease(ctx context.Context, in *ReleaseLeaseRequest, opts ...grpc.CallOption) (*ReleaseLeaseResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ReleaseLeaseResponse)
+	err := c.cc.Invoke(ctx, RateLimitService_ReleaseLease_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rateLimitServiceClient) CheckRateLimitStream(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[RateLimitStreamRequest, RateLimitStreamResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &RateLimitService_ServiceDesc.Streams[0], RateLimitService_CheckRateLimitStream_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[RateLimitStreamRequest, RateLimitStreamResponse]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type RateLimitService_CheckRateLimitStreamClient = grpc.BidiStreamingClient[RateLimitStreamRequest, RateLimitStreamResponse]
+
 // RateLimitServiceServer is the server API for RateLimitService service.
 // All implementations must embed UnimplementedRateLimitServiceServer
 // for forward compatibility.
 type RateLimitServiceServer interface {
 	CheckRateLimit(context.Context, *RateLimitRequest) (*RateLimitResponse, error)
+	ReleaseLease(context.Context, *ReleaseLeaseRequest) (*ReleaseLeaseResponse, error)
+	// Checks many requests over one stream. Responses are sent as soon as their check is done, so they can
+	// arrive in another order than the requests, match them by id.
+	CheckRateLimitStream(grpc.BidiStreamingServer[RateLimitStreamRequest, RateLimitStreamResponse]) error
 	mustEmbedUnimplementedRateLimitServiceServer()
 }
 
@@ -65,6 +98,12 @@ type UnimplementedRateLimitServiceServer struct{}
 func (UnimplementedRateLimitServiceServer) CheckRateLimit(context.Context, *RateLimitRequest) (*RateLimitResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method CheckRateLimit not implemented")
 }
+func (UnimplementedRateLimitServiceServer) ReleaseLease(context.Context, *ReleaseLeaseRequest) (*ReleaseLeaseResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReleaseLease not implemented")
+}
+func (UnimplementedRateLimitServiceServer) CheckRateLimitStream(grpc.BidiStreamingServer[RateLimitStreamRequest, RateLimitStreamResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method CheckRateLimitStream not implemented")
+}
 func (UnimplementedRateLimitServiceServer) mustEmbedUnimplementedRateLimitServiceServer() {}
 func (UnimplementedRateLimitServiceServer) testEmbeddedByValue()                          {}
 
@@ -104,6 +143,31 @@ func _RateLimitService_CheckRateLimit_Handler(srv interface{}, ctx context.Conte
 	return interceptor(ctx, in, info, handler)
 }
 
+func _RateLimitService_ReleaseLease_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReleaseLeaseRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RateLimitServiceServer).ReleaseLease(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RateLimitService_ReleaseLease_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RateLimitServiceServer).ReleaseLease(ctx, req.(*ReleaseLeaseRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RateLimitService_CheckRateLimitStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(RateLimitServiceServer).CheckRateLimitStream(&grpc.GenericServerStream[RateLimitStreamRequest, RateLimitStreamResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type RateLimitService_CheckRateLimitStreamServer = grpc.BidiStreamingServer[RateLimitStreamRequest, RateLimitStreamResponse]
+
 // RateLimitService_ServiceDesc is the grpc.ServiceDesc for RateLimitService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -115,7 +179,18 @@ var RateLimitService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "CheckRateLimit",
 			Handler:    _RateLimitService_CheckRateLimit_Handler,
 		},
+		{
+			MethodName: "ReleaseLease",
+			Handler:    _RateLimitService_ReleaseLease_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "CheckRateLimitStream",
+			Handler:       _RateLimitService_CheckRateLimitStream_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "check_limit.proto",
 }
@@ -0,0 +1,235 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             v5.29.2
+// source: limiter_admin.proto
+
+package ratelimitpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	LimiterAdminService_GetLimiterState_FullMethodName   = "/ratelimit.LimiterAdminService/GetLimiterState"
+	LimiterAdminService_ResetLimiterState_FullMethodName = "/ratelimit.LimiterAdminService/ResetLimiterState"
+	LimiterAdminService_GrantExtraQuota_FullMethodName   = "/ratelimit.LimiterAdminService/GrantExtraQuota"
+	LimiterAdminService_ListLimiterKeys_FullMethodName   = "/ratelimit.LimiterAdminService/ListLimiterKeys"
+)
+
+// LimiterAdminServiceClient is the client API for LimiterAdminService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type LimiterAdminServiceClient interface {
+	GetLimiterState(ctx context.Context, in *LimiterStateRequest, opts ...grpc.CallOption) (*LimiterStateResponse, error)
+	ResetLimiterState(ctx context.Context, in *LimiterStateRequest, opts ...grpc.CallOption) (*ResetLimiterStateResponse, error)
+	GrantExtraQuota(ctx context.Context, in *GrantExtraQuotaRequest, opts ...grpc.CallOption) (*LimiterStateResponse, error)
+	ListLimiterKeys(ctx context.Context, in *ListLimiterKeysRequest, opts ...grpc.CallOption) (*ListLimiterKeysResponse, error)
+}
+
+type limiterAdminServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewLimiterAdminServiceClient(cc grpc.ClientConnInterface) LimiterAdminServiceClient {
+	return &limiterAdminServiceClient{cc}
+}
+
+func (c *limiterAdminServiceClient) GetLimiterState(ctx context.Context, in *LimiterStateRequest, opts ...grpc.CallOption) (*LimiterStateResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(LimiterStateResponse)
+	err := c.cc.Invoke(ctx, LimiterAdminService_GetLimiterState_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *limiterAdminServiceClient) ResetLimiterState(ctx context.Context, in *LimiterStateRequest, opts ...grpc.CallOption) (*ResetLimiterStateResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ResetLimiterStateResponse)
+	err := c.cc.Invoke(ctx, LimiterAdminService_ResetLimiterState_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *limiterAdminServiceClient) GrantExtraQuota(ctx context.Context, in *GrantExtraQuotaRequest, opts ...grpc.CallOption) (*LimiterStateResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(LimiterStateResponse)
+	err := c.cc.Invoke(ctx, LimiterAdminService_GrantExtraQuota_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *limiterAdminServiceClient) ListLimiterKeys(ctx context.Context, in *ListLimiterKeysRequest, opts ...grpc.CallOption) (*ListLimiterKeysResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListLimiterKeysResponse)
+	err := c.cc.Invoke(ctx, LimiterAdminService_ListLimiterKeys_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// LimiterAdminServiceServer is the server API for LimiterAdminService service.
+// All implementations must embed UnimplementedLimiterAdminServiceServer
+// for forward compatibility.
+type LimiterAdminServiceServer interface {
+	GetLimiterState(context.Context, *LimiterStateRequest) (*LimiterStateResponse, error)
+	ResetLimiterState(context.Context, *LimiterStateRequest) (*ResetLimiterStateResponse, error)
+	GrantExtraQuota(context.Context, *GrantExtraQuotaRequest) (*LimiterStateResponse, error)
+	ListLimiterKeys(context.Context, *ListLimiterKeysRequest) (*ListLimiterKeysResponse, error)
+	mustEmbedUnimplementedLimiterAdminServiceServer()
+}
+
+// UnimplementedLimiterAdminServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedLimiterAdminServiceServer struct{}
+
+func (UnimplementedLimiterAdminServiceServer) GetLimiterState(context.Context, *LimiterStateRequest) (*LimiterStateResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetLimiterState not implemented")
+}
+func (UnimplementedLimiterAdminServiceServer) ResetLimiterState(context.Context, *LimiterStateRequest) (*ResetLimiterStateResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ResetLimiterState not implemented")
+}
+func (UnimplementedLimiterAdminServiceServer) GrantExtraQuota(context.Context, *GrantExtraQuotaRequest) (*LimiterStateResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GrantExtraQuota not implemented")
+}
+func (UnimplementedLimiterAdminServiceServer) ListLimiterKeys(context.Context, *ListLimiterKeysRequest) (*ListLimiterKeysResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListLimiterKeys not implemented")
+}
+func (UnimplementedLimiterAdminServiceServer) mustEmbedUnimplementedLimiterAdminServiceServer() {}
+func (UnimplementedLimiterAdminServiceServer) testEmbeddedByValue()                             {}
+
+// UnsafeLimiterAdminServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to LimiterAdminServiceServer will
+// result in compilation errors.
+type UnsafeLimiterAdminServiceServer interface {
+	mustEmbedUnimplementedLimiterAdminServiceServer()
+}
+
+func RegisterLimiterAdminServiceServer(s grpc.ServiceRegistrar, srv LimiterAdminServiceServer) {
+	// If the following call pancis, it indicates UnimplementedLimiterAdminServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&LimiterAdminService_ServiceDesc, srv)
+}
+
+func _LimiterAdminService_GetLimiterState_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LimiterStateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LimiterAdminServiceServer).GetLimiterState(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: LimiterAdminService_GetLimiterState_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LimiterAdminServiceServer).GetLimiterState(ctx, req.(*LimiterStateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LimiterAdminService_ResetLimiterState_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LimiterStateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LimiterAdminServiceServer).ResetLimiterState(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: LimiterAdminService_ResetLimiterState_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LimiterAdminServiceServer).ResetLimiterState(ctx, req.(*LimiterStateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LimiterAdminService_GrantExtraQuota_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GrantExtraQuotaRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LimiterAdminServiceServer).GrantExtraQuota(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: LimiterAdminService_GrantExtraQuota_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LimiterAdminServiceServer).GrantExtraQuota(ctx, req.(*GrantExtraQuotaRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LimiterAdminService_ListLimiterKeys_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListLimiterKeysRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LimiterAdminServiceServer).ListLimiterKeys(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: LimiterAdminService_ListLimiterKeys_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LimiterAdminServiceServer).ListLimiterKeys(ctx, req.(*ListLimiterKeysRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// LimiterAdminService_ServiceDesc is the grpc.ServiceDesc for LimiterAdminService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var LimiterAdminService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "ratelimit.LimiterAdminService",
+	HandlerType: (*LimiterAdminServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetLimiterState",
+			Handler:    _LimiterAdminService_GetLimiterState_Handler,
+		},
+		{
+			MethodName: "ResetLimiterState",
+			Handler:    _LimiterAdminService_ResetLimiterState_Handler,
+		},
+		{
+			MethodName: "GrantExtraQuota",
+			Handler:    _LimiterAdminService_GrantExtraQuota_Handler,
+		},
+		{
+			MethodName: "ListLimiterKeys",
+			Handler:    _LimiterAdminService_ListLimiterKeys_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "limiter_admin.proto",
+}
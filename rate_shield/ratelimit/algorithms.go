@@ -0,0 +1,390 @@
+package ratelimit
+
+import (
+	"encoding/json"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/x-sushant-x/RateShield/models"
+	"github.com/x-sushant-x/RateShield/utils"
+)
+
+const (
+	// Token buckets gain token_add_rate tokens per tokenRefillInterval, spread evenly over it. The server adds
+	// them all at once at the end of every interval.
+	tokenRefillInterval = 10 * time.Second
+)
+
+// outcome is what an algorithm decided for a request
+type outcome struct {
+	allowed    bool
+	delay      time.Duration // How long a reservation has to wait, 0 if it is allowed right away
+	takenAt    time.Time     // When the reserved units count against the limit
+	limit      int64
+	remaining  int64
+	retryAfter time.Duration
+	resetAt    time.Time
+}
+
+// algorithm applies the strategy of a rule to the stored state of a client
+type algorithm interface {
+	// take uses n units at now. With reserve, units that are not available yet are booked ahead if the
+	// limit can ever grant them.
+	take(state []byte, now time.Time, n int64, reserve bool) ([]byte, time.Duration, outcome, error)
+
+	// give returns n units that were taken at takenAt
+	give(state []byte, now, takenAt time.Time, n int64) ([]byte, time.Duration, error)
+}
+
+func newAlgorithm(rule *models.Rule) (algorithm, error) {
+	switch rule.Strategy {
+	case models.StrategyTokenBucket:
+		return tokenBucket{capacity: rule.TokenBucketRule.BucketCapacity, rate: rule.TokenBucketRule.TokenAddRate}, nil
+	case models.StrategyFixedWindowCounter:
+		window := time.Duration(rule.FixedWindowCounterRule.Window) * time.Second
+		return windowCounter{
+			max: rule.FixedWindowCounterRule.MaxRequests,
+			period: func(now time.Time, current windowState) (time.Time, time.Time) {
+				end := time.Unix(0, current.End)
+				switch {
+				case current.End != 0 && now.Before(end):
+					return time.Unix(0, current.Start), end
+				case current.Next > 0 && now.Before(end.Add(window)):
+					return end, end.Add(window)
+				}
+				// Like on the server a window starts with the first request after the previous one
+				return now, now.Add(window)
+			},
+			next: func(end time.Time) (time.Time, time.Time) {
+				return end, end.Add(window)
+			},
+		}, nil
+	case models.StrategyQuota:
+		quota := *rule.QuotaRule
+		if _, _, err := utils.QuotaPeriodBounds(quota, time.Now()); err != nil {
+			return nil, err
+		}
+		return windowCounter{
+			max: quota.Limit,
+			period: func(now time.Time, _ windowState) (time.Time, time.Time) {
+				start, end, _ := utils.QuotaPeriodBounds(quota, now)
+				return start, end
+			},
+			next: func(end time.Time) (time.Time, time.Time) {
+				start, nextEnd, _ := utils.QuotaPeriodBounds(quota, end)
+				return start, nextEnd
+			},
+		}, nil
+	case models.StrategySlidingWindowCounter:
+		return slidingLog{
+			max:    rule.SlidingWindowCounterRule.MaxRequests,
+			window: time.Duration(rule.SlidingWindowCounterRule.WindowSize) * time.Second,
+		}, nil
+	}
+
+	return nil, ErrUnsupportedStrategy
+}
+
+// tokenBucket refills continuously, a reservation may borrow tokens that are added later
+type tokenBucket struct {
+	capacity int64
+	rate     int64 // Tokens per tokenRefillInterval
+}
+
+type tokenBucketState struct {
+	Tokens  float64 `json:"tokens"`
+	Updated int64   `json:"updated"` // Unix nanoseconds of the last refill
+}
+
+func (b tokenBucket) perSecond() float64 {
+	return float64(b.rate) / tokenRefillInterval.Seconds()
+}
+
+func (b tokenBucket) load(state []byte, now time.Time) (tokenBucketState, error) {
+	bucket := tokenBucketState{Tokens: float64(b.capacity), Updated: now.UnixNano()}
+	if state == nil {
+		return bucket, nil
+	}
+
+	if err := json.Unmarshal(state, &bucket); err != nil {
+		return bucket, err
+	}
+
+	if elapsed := now.Sub(time.Unix(0, bucket.Updated)); elapsed > 0 {
+		bucket.Tokens = math.Min(float64(b.capacity), bucket.Tokens+elapsed.Seconds()*b.perSecond())
+		bucket.Updated = now.UnixNano()
+	}
+	return bucket, nil
+}
+
+// wait returns how long it takes until the bucket holds tokens
+func (b tokenBucket) wait(bucket tokenBucketState, tokens float64) time.Duration {
+	missing := tokens - bucket.Tokens
+	if missing <= 0 {
+		return 0
+	}
+	return time.Duration(math.Ceil(missing / b.perSecond() * float64(time.Second)))
+}
+
+func (b tokenBucket) save(bucket tokenBucketState) ([]byte, time.Duration, error) {
+	// A full bucket is the same as a missing one
+	ttl := b.wait(bucket, float64(b.capacity))
+	if ttl <= 0 {
+		return nil, 0, nil
+	}
+
+	state, err := json.Marshal(bucket)
+	return state, ttl, err
+}
+
+func (b tokenBucket) take(state []byte, now time.Time, n int64, reserve bool) ([]byte, time.Duration, outcome, error) {
+	bucket, err := b.load(state, now)
+	if err != nil {
+		return nil, 0, outcome{}, err
+	}
+
+	out := outcome{limit: b.capacity, takenAt: now}
+
+	switch {
+	case bucket.Tokens >= float64(n):
+		out.allowed = true
+		bucket.Tokens -= float64(n)
+	case reserve && n <= b.capacity:
+		out.allowed = true
+		out.delay = b.wait(bucket, float64(n))
+		out.takenAt = now.Add(out.delay)
+		bucket.Tokens -= float64(n)
+	case n <= b.capacity:
+		out.retryAfter = b.wait(bucket, float64(n))
+	}
+
+	out.remaining = int64(math.Max(0, math.Floor(bucket.Tokens)))
+	out.resetAt = now.Add(b.wait(bucket, float64(b.capacity)))
+
+	newState, ttl, err := b.save(bucket)
+	return newState, ttl, out, err
+}
+
+func (b tokenBucket) give(state []byte, now, _ time.Time, n int64) ([]byte, time.Duration, error) {
+	bucket, err := b.load(state, now)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	bucket.Tokens = math.Min(float64(b.capacity), bucket.Tokens+float64(n))
+	return b.save(bucket)
+}
+
+// windowCounter counts requests per window, reservations are booked into the following window. Fixed
+// windows and quota periods only differ in where their windows start.
+type windowCounter struct {
+	max    int64
+	period func(now time.Time, current windowState) (time.Time, time.Time)
+	next   func(end time.Time) (time.Time, time.Time)
+}
+
+type windowState struct {
+	Start int64 `json:"start"` // Unix nanoseconds
+	End   int64 `json:"end"`
+	Count int64 `json:"count"`
+	Next  int64 `json:"next"` // Requests reserved in the window starting at End
+}
+
+func (w windowCounter) load(state []byte, now time.Time) (windowState, error) {
+	var current windowState
+	if state != nil {
+		if err := json.Unmarshal(state, &current); err != nil {
+			return current, err
+		}
+	}
+
+	start, end := w.period(now, current)
+
+	switch {
+	case start.UnixNano() == current.Start && current.End != 0:
+		return current, nil
+	case start.UnixNano() == current.End && current.End != 0:
+		return windowState{Start: start.UnixNano(), End: end.UnixNano(), Count: current.Next}, nil
+	}
+
+	return windowState{Start: start.UnixNano(), End: end.UnixNano()}, nil
+}
+
+func (w windowCounter) save(current windowState, now time.Time) ([]byte, time.Duration, error) {
+	expiresAt := time.Unix(0, current.End)
+	if current.Next > 0 {
+		_, expiresAt = w.next(expiresAt)
+	}
+
+	state, err := json.Marshal(current)
+	return state, expiresAt.Sub(now), err
+}
+
+func (w windowCounter) take(state []byte, now time.Time, n int64, reserve bool) ([]byte, time.Duration, outcome, error) {
+	current, err := w.load(state, now)
+	if err != nil {
+		return nil, 0, outcome{}, err
+	}
+
+	end := time.Unix(0, current.End)
+	out := outcome{limit: w.max, takenAt: now, resetAt: end}
+
+	switch {
+	case current.Count+n <= w.max:
+		out.allowed = true
+		current.Count += n
+	case reserve && current.Next+n <= w.max:
+		out.allowed = true
+		out.delay = end.Sub(now)
+		out.takenAt = end
+		current.Next += n
+	case n <= w.max:
+		out.retryAfter = end.Sub(now)
+	}
+
+	out.remaining = max(0, w.max-current.Count)
+
+	newState, ttl, err := w.save(current, now)
+	return newState, ttl, out, err
+}
+
+func (w windowCounter) give(state []byte, now, takenAt time.Time, n int64) ([]byte, time.Duration, error) {
+	current, err := w.load(state, now)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	switch {
+	case takenAt.UnixNano() >= current.End:
+		current.Next = max(0, current.Next-n)
+	case takenAt.UnixNano() >= current.Start:
+		current.Count = max(0, current.Count-n)
+	}
+
+	return w.save(current, now)
+}
+
+// slidingLog keeps a log of the requests in the last window, like the sorted sets of the server. A
+// reservation is booked at the time enough of the logged requests left the window.
+type slidingLog struct {
+	max    int64
+	window time.Duration
+}
+
+type slidingLogEntry struct {
+	At    int64 `json:"at"` // Unix nanoseconds
+	Count int64 `json:"count"`
+}
+
+func (s slidingLog) load(state []byte, now time.Time) ([]slidingLogEntry, error) {
+	var entries []slidingLogEntry
+	if state != nil {
+		if err := json.Unmarshal(state, &entries); err != nil {
+			return nil, err
+		}
+	}
+
+	windowStart := now.Add(-s.window).UnixNano()
+	kept := entries[:0]
+	for _, entry := range entries {
+		if entry.At > windowStart {
+			kept = append(kept, entry)
+		}
+	}
+	return kept, nil
+}
+
+func (s slidingLog) save(entries []slidingLogEntry, now time.Time) ([]byte, time.Duration, error) {
+	if len(entries) == 0 {
+		return nil, 0, nil
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].At < entries[j].At
+	})
+
+	state, err := json.Marshal(entries)
+	return state, time.Unix(0, entries[len(entries)-1].At).Add(s.window).Sub(now), err
+}
+
+// availableAt returns when n more requests fit into the window
+func (s slidingLog) availableAt(entries []slidingLogEntry, now time.Time, n int64) time.Time {
+	used := int64(0)
+	for _, entry := range entries {
+		used += entry.Count
+	}
+
+	for _, entry := range entries {
+		if used+n <= s.max {
+			break
+		}
+		used -= entry.Count
+		if at := time.Unix(0, entry.At).Add(s.window); at.After(now) {
+			now = at
+		}
+	}
+	return now
+}
+
+func (s slidingLog) take(state []byte, now time.Time, n int64, reserve bool) ([]byte, time.Duration, outcome, error) {
+	entries, err := s.load(state, now)
+	if err != nil {
+		return nil, 0, outcome{}, err
+	}
+
+	out := outcome{limit: s.max, takenAt: now}
+
+	if n <= s.max {
+		at := s.availableAt(entries, now, n)
+		switch {
+		case !at.After(now):
+			out.allowed = true
+			entries = append(entries, slidingLogEntry{At: now.UnixNano(), Count: n})
+		case reserve:
+			out.allowed = true
+			out.delay = at.Sub(now)
+			out.takenAt = at
+			entries = append(entries, slidingLogEntry{At: at.UnixNano(), Count: n})
+		default:
+			out.retryAfter = at.Sub(now)
+		}
+	}
+
+	// Reserved requests count as well, they are inside the window once it gets to them
+	used := int64(0)
+	for _, entry := range entries {
+		used += entry.Count
+	}
+	out.remaining = max(0, s.max-used)
+
+	newState, ttl, err := s.save(entries, now)
+	if len(entries) > 0 {
+		out.resetAt = now.Add(ttl)
+	}
+	return newState, ttl, out, err
+}
+
+func (s slidingLog) give(state []byte, now, takenAt time.Time, n int64) ([]byte, time.Duration, error) {
+	entries, err := s.load(state, now)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	for i := range entries {
+		if entries[i].At == takenAt.UnixNano() && n > 0 {
+			returned := min(n, entries[i].Count)
+			entries[i].Count -= returned
+			n -= returned
+		}
+	}
+
+	kept := entries[:0]
+	for _, entry := range entries {
+		if entry.Count > 0 {
+			kept = append(kept, entry)
+		}
+	}
+
+	return s.save(kept, now)
+}
@@ -0,0 +1,333 @@
+// Package ratelimit runs the rate limiting strategies of RateShield inside an application, without the
+// HTTP and gRPC servers. Rules are the same as on the server and are passed in code, the state of the
+// limiters is kept in a Store: in memory for a single instance or in Redis to share it between instances.
+//
+//	limiter, err := ratelimit.New(ratelimit.Config{
+//		Rules: []models.Rule{{
+//			APIEndpoint:            "/api/v1/orders",
+//			Strategy:               models.StrategyFixedWindowCounter,
+//			FixedWindowCounterRule: &models.FixedWindowCounterRule{MaxRequests: 100, Window: 60},
+//		}},
+//	})
+//
+//	result, err := limiter.Allow(ctx, "/api/v1/orders", clientIP)
+//	if err == nil && !result.Allowed {
+//		// Reject, result.RetryAfter says when to try again
+//	}
+//
+// The library does not share code with the limiter of the server and differs from it in a few ways:
+//
+//   - Token buckets refill continuously at token_add_rate tokens per 10 seconds. The server adds all
+//     token_add_rate tokens at once every 10 seconds, so a client waiting for a token gets it earlier here.
+//   - CONCURRENCY rules, penalties and access lists are rejected by New and SetRules. They need the
+//     lease, ban and list state that only the server keeps.
+//   - Alerts, notifications, the audit log and rule webhooks are not available.
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/x-sushant-x/RateShield/models"
+	"github.com/x-sushant-x/RateShield/utils"
+)
+
+const (
+	defaultKeyPrefix = "ratelimit:"
+)
+
+var (
+	ErrUnsupportedStrategy   = errors.New("strategy is not supported in library mode, use token bucket, fixed window counter, sliding window counter or quota")
+	ErrUnsupportedPenalty    = errors.New("penalties are not supported in library mode")
+	ErrUnsupportedAccessList = errors.New("allow and deny lists are not supported in library mode")
+	ErrDuplicateEndpoint     = errors.New("endpoint has more than one rule")
+	ErrInvalidCount          = errors.New("count must be at least 1")
+)
+
+// Config of a Limiter
+type Config struct {
+	Rules []models.Rule // Validated like rules sent to the server, overrides, tiers and schedules apply
+
+	// Keeps the state of the limiters, defaults to a MemoryStore
+	Store Store
+
+	// Returns the tier of a client for the tier limits of the rules, optional
+	Tier func(key string) string
+
+	// Prepended to every key in the store, defaults to "ratelimit:"
+	KeyPrefix string
+}
+
+// Limiter checks requests of clients against the rules of their endpoints
+type Limiter struct {
+	mutex sync.RWMutex
+	rules map[string]models.Rule
+
+	store     Store
+	tier      func(key string) string
+	keyPrefix string
+	now       func() time.Time
+}
+
+// Result is the decision for a request
+type Result struct {
+	Allowed    bool
+	Endpoint   string
+	Key        string
+	Strategy   string        // Strategy of the applied rule, empty if the endpoint has no active rule
+	Limit      int64         // Requests allowed by the applied rule, 0 if the endpoint has no active rule
+	Remaining  int64         // Requests left right now
+	RetryAfter time.Duration // How long to wait before the request can be allowed, 0 if it was allowed
+	ResetAt    time.Time     // When the full limit is available again, zero if it already is
+	FailedOpen bool          // The store failed and the rule allows requests on errors
+}
+
+// Reservation holds requests that were booked ahead. The caller has to wait for Delay before acting on it,
+// or Cancel it to give the requests back.
+type Reservation struct {
+	OK     bool          // False if the limit can never grant the requests, e.g. more than its capacity
+	Delay  time.Duration // How long to wait until the requests may be made
+	Result Result
+
+	limiter  *Limiter
+	alg      algorithm
+	storeKey string
+	takenAt  time.Time
+	n        int64
+}
+
+func New(config Config) (*Limiter, error) {
+	l := &Limiter{
+		store:     config.Store,
+		tier:      config.Tier,
+		keyPrefix: config.KeyPrefix,
+		now:       time.Now,
+	}
+
+	if l.store == nil {
+		l.store = NewMemoryStore()
+	}
+
+	if l.keyPrefix == "" {
+		l.keyPrefix = defaultKeyPrefix
+	}
+
+	if err := l.SetRules(config.Rules); err != nil {
+		return nil, err
+	}
+
+	return l, nil
+}
+
+// SetRules replaces the rules of the limiter. The state of clients is kept for rules whose strategy did not
+// change.
+func (l *Limiter) SetRules(rules []models.Rule) error {
+	byEndpoint := make(map[string]models.Rule, len(rules))
+
+	for _, rule := range rules {
+		if err := utils.ValidateRule(rule); err != nil {
+			return fmt.Errorf("%s: %w", rule.APIEndpoint, err)
+		}
+
+		if err := checkSupported(rule); err != nil {
+			return fmt.Errorf("%s: %w", rule.APIEndpoint, err)
+		}
+
+		if _, found := byEndpoint[rule.APIEndpoint]; found {
+			return fmt.Errorf("%s: %w", rule.APIEndpoint, ErrDuplicateEndpoint)
+		}
+		byEndpoint[rule.APIEndpoint] = rule
+	}
+
+	l.mutex.Lock()
+	l.rules = byEndpoint
+	l.mutex.Unlock()
+
+	return nil
+}
+
+// checkSupported rejects rules that use a strategy or feature the library can't enforce, also in their
+// scheduled rules. Ignoring them would let through requests the server rejects.
+func checkSupported(rule models.Rule) error {
+	switch {
+	case rule.Strategy == models.StrategyConcurrency:
+		return ErrUnsupportedStrategy
+	case rule.Penalty != nil:
+		return ErrUnsupportedPenalty
+	case len(rule.AllowList) > 0 || len(rule.DenyList) > 0:
+		return ErrUnsupportedAccessList
+	}
+
+	if _, err := newAlgorithm(&rule); err != nil {
+		return err
+	}
+
+	for _, scheduledRule := range rule.ScheduledRules {
+		if err := checkSupported(scheduledRule); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Allow checks a single request of the client identified by key, e.g. its IP or client ID
+func (l *Limiter) Allow(ctx context.Context, endpoint, key string) (Result, error) {
+	return l.AllowN(ctx, endpoint, key, 1)
+}
+
+// AllowN checks n requests at once, they are only counted if all of them are allowed
+func (l *Limiter) AllowN(ctx context.Context, endpoint, key string, n int64) (Result, error) {
+	reservation, err := l.take(ctx, endpoint, key, n, false)
+	if err != nil {
+		return Result{}, err
+	}
+	return reservation.Result, nil
+}
+
+// Reserve books a single request, see ReserveN
+func (l *Limiter) Reserve(ctx context.Context, endpoint, key string) (*Reservation, error) {
+	return l.ReserveN(ctx, endpoint, key, 1)
+}
+
+// ReserveN books n requests even if the limit is reached right now. Token buckets lend tokens that are added
+// later, window counters book the next window and sliding windows the time enough requests left the window.
+func (l *Limiter) ReserveN(ctx context.Context, endpoint, key string, n int64) (*Reservation, error) {
+	return l.take(ctx, endpoint, key, n, true)
+}
+
+func (l *Limiter) take(ctx context.Context, endpoint, key string, n int64, reserve bool) (*Reservation, error) {
+	if n < 1 {
+		return nil, ErrInvalidCount
+	}
+
+	now := l.now()
+	reservation := &Reservation{
+		Result:  Result{Endpoint: endpoint, Key: key},
+		limiter: l,
+		takenAt: now,
+	}
+
+	rule := l.activeRule(endpoint, key, now)
+	if rule == nil {
+		reservation.OK = true
+		reservation.Result.Allowed = true
+		return reservation, nil
+	}
+
+	alg, err := newAlgorithm(rule)
+	if err != nil {
+		return nil, err
+	}
+
+	storeKey := l.storeKey(rule, key)
+	var out outcome
+
+	err = l.store.Update(ctx, storeKey, func(state []byte) ([]byte, time.Duration, error) {
+		newState, ttl, taken, err := alg.take(state, now, n, reserve)
+		out = taken
+		return newState, ttl, err
+	})
+
+	reservation.Result.Strategy = rule.Strategy
+
+	if err != nil {
+		if !rule.AllowOnError {
+			return nil, err
+		}
+		reservation.OK = true
+		reservation.Result.Allowed = true
+		reservation.Result.FailedOpen = true
+		return reservation, nil
+	}
+
+	reservation.Result.Allowed = out.allowed && out.delay == 0
+	reservation.Result.Limit = out.limit
+	reservation.Result.Remaining = out.remaining
+	reservation.Result.RetryAfter = out.retryAfter
+	reservation.Result.ResetAt = out.resetAt
+	if reservation.Result.Allowed {
+		reservation.Result.RetryAfter = 0
+	} else if out.allowed {
+		reservation.Result.RetryAfter = out.delay
+	}
+
+	reservation.OK = out.allowed
+	reservation.Delay = out.delay
+
+	if out.allowed {
+		reservation.alg = alg
+		reservation.storeKey = storeKey
+		reservation.takenAt = out.takenAt
+		reservation.n = n
+	}
+
+	return reservation, nil
+}
+
+// Cancel gives the booked requests back so other requests can use them. It should only be called before the
+// requests were made.
+func (r *Reservation) Cancel(ctx context.Context) error {
+	if r == nil || r.alg == nil {
+		return nil
+	}
+
+	alg, takenAt, n := r.alg, r.takenAt, r.n
+	r.alg = nil
+
+	now := r.limiter.now()
+	return r.limiter.store.Update(ctx, r.storeKey, func(state []byte) ([]byte, time.Duration, error) {
+		return alg.give(state, now, takenAt, n)
+	})
+}
+
+// activeRule returns the rule to apply to a client right now with the limits of its override or tier, nil
+// if the endpoint has no active rule
+func (l *Limiter) activeRule(endpoint, key string, now time.Time) *models.Rule {
+	l.mutex.RLock()
+	baseRule, found := l.rules[endpoint]
+	l.mutex.RUnlock()
+
+	if !found {
+		return nil
+	}
+
+	rule := utils.SelectActiveRule(&baseRule, now)
+	if rule == nil {
+		return nil
+	}
+
+	for _, override := range baseRule.Overrides {
+		if override.Identity == key {
+			if limited, ok := utils.WithRuleLimits(rule, override.RuleLimits); ok {
+				return limited
+			}
+			break
+		}
+	}
+
+	if len(baseRule.Tiers) == 0 || l.tier == nil {
+		return rule
+	}
+
+	tierName := l.tier(key)
+	for _, tier := range baseRule.Tiers {
+		if tierName != "" && tier.Name == tierName {
+			if limited, ok := utils.WithRuleLimits(rule, tier.RuleLimits); ok {
+				return limited
+			}
+			break
+		}
+	}
+
+	return rule
+}
+
+// storeKey names the state of a client on an endpoint. The strategy is part of the key so a changed rule
+// never reads state written by another strategy.
+func (l *Limiter) storeKey(rule *models.Rule, key string) string {
+	return fmt.Sprintf("%s%s:%s:%s", l.keyPrefix, rule.Strategy, rule.APIEndpoint, key)
+}
@@ -0,0 +1,400 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/x-sushant-x/RateShield/models"
+	"github.com/x-sushant-x/RateShield/utils"
+)
+
+type testClock struct {
+	mutex sync.Mutex
+	now   time.Time
+}
+
+func (c *testClock) Now() time.Time {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.now
+}
+
+func (c *testClock) Advance(d time.Duration) {
+	c.mutex.Lock()
+	c.now = c.now.Add(d)
+	c.mutex.Unlock()
+}
+
+func newTestLimiter(t *testing.T, config Config) (*Limiter, *testClock) {
+	t.Helper()
+
+	clock := &testClock{now: time.Date(2024, 9, 30, 23, 59, 0, 0, time.UTC)}
+	store := NewMemoryStore()
+	store.now = clock.Now
+	config.Store = store
+
+	l, err := New(config)
+	require.NoError(t, err)
+	l.now = clock.Now
+
+	return l, clock
+}
+
+func allowN(t *testing.T, l *Limiter, endpoint, key string, n int64) Result {
+	t.Helper()
+
+	result, err := l.AllowN(context.Background(), endpoint, key, n)
+	require.NoError(t, err)
+	return result
+}
+
+func TestTokenBucket(t *testing.T) {
+	l, clock := newTestLimiter(t, Config{Rules: []models.Rule{{
+		APIEndpoint:     "/api/v1/orders",
+		Strategy:        models.StrategyTokenBucket,
+		TokenBucketRule: &models.TokenBucketRule{BucketCapacity: 5, TokenAddRate: 10, RetentionTime: 60},
+	}}})
+
+	result := allowN(t, l, "/api/v1/orders", "1.1.1.1", 5)
+	assert.True(t, result.Allowed)
+	assert.Equal(t, int64(5), result.Limit)
+	assert.Equal(t, int64(0), result.Remaining)
+	assert.Equal(t, models.StrategyTokenBucket, result.Strategy)
+
+	result = allowN(t, l, "/api/v1/orders", "1.1.1.1", 1)
+	assert.False(t, result.Allowed)
+	assert.Equal(t, time.Second, result.RetryAfter)
+
+	// Clients have their own buckets
+	assert.True(t, allowN(t, l, "/api/v1/orders", "2.2.2.2", 1).Allowed)
+
+	// One token is added every second
+	clock.Advance(2 * time.Second)
+	result = allowN(t, l, "/api/v1/orders", "1.1.1.1", 1)
+	assert.True(t, result.Allowed)
+	assert.Equal(t, int64(1), result.Remaining)
+
+	// More than the capacity is never allowed
+	result = allowN(t, l, "/api/v1/orders", "1.1.1.1", 6)
+	assert.False(t, result.Allowed)
+	assert.Zero(t, result.RetryAfter)
+}
+
+func TestFixedWindowCounter(t *testing.T) {
+	l, clock := newTestLimiter(t, Config{Rules: []models.Rule{{
+		APIEndpoint:            "/api/v1/orders",
+		Strategy:               models.StrategyFixedWindowCounter,
+		FixedWindowCounterRule: &models.FixedWindowCounterRule{MaxRequests: 3, Window: 60},
+	}}})
+
+	for i := 0; i < 3; i++ {
+		assert.True(t, allowN(t, l, "/api/v1/orders", "1.1.1.1", 1).Allowed)
+	}
+
+	clock.Advance(20 * time.Second)
+	result := allowN(t, l, "/api/v1/orders", "1.1.1.1", 1)
+	assert.False(t, result.Allowed)
+	assert.Equal(t, 40*time.Second, result.RetryAfter)
+	assert.True(t, clock.Now().Add(40*time.Second).Equal(result.ResetAt))
+
+	clock.Advance(40 * time.Second)
+	result = allowN(t, l, "/api/v1/orders", "1.1.1.1", 1)
+	assert.True(t, result.Allowed)
+	assert.Equal(t, int64(2), result.Remaining)
+}
+
+func TestSlidingWindowCounter(t *testing.T) {
+	l, clock := newTestLimiter(t, Config{Rules: []models.Rule{{
+		APIEndpoint:              "/api/v1/orders",
+		Strategy:                 models.StrategySlidingWindowCounter,
+		SlidingWindowCounterRule: &models.SlidingWindowCounterRule{MaxRequests: 3, WindowSize: 60},
+	}}})
+
+	assert.True(t, allowN(t, l, "/api/v1/orders", "1.1.1.1", 2).Allowed)
+	clock.Advance(30 * time.Second)
+	assert.True(t, allowN(t, l, "/api/v1/orders", "1.1.1.1", 1).Allowed)
+
+	result := allowN(t, l, "/api/v1/orders", "1.1.1.1", 2)
+	assert.False(t, result.Allowed)
+	assert.Equal(t, 30*time.Second, result.RetryAfter)
+
+	// The first two requests left the window, the third is still in it
+	clock.Advance(30 * time.Second)
+	result = allowN(t, l, "/api/v1/orders", "1.1.1.1", 2)
+	assert.True(t, result.Allowed)
+	assert.Equal(t, int64(0), result.Remaining)
+	assert.False(t, allowN(t, l, "/api/v1/orders", "1.1.1.1", 1).Allowed)
+}
+
+func TestQuota(t *testing.T) {
+	l, clock := newTestLimiter(t, Config{Rules: []models.Rule{{
+		APIEndpoint: "/api/v1/orders",
+		Strategy:    models.StrategyQuota,
+		QuotaRule:   &models.QuotaRule{Limit: 2, Period: models.QuotaPeriodMonth},
+	}}})
+
+	assert.True(t, allowN(t, l, "/api/v1/orders", "customer-42", 2).Allowed)
+
+	result := allowN(t, l, "/api/v1/orders", "customer-42", 1)
+	assert.False(t, result.Allowed)
+	assert.Equal(t, time.Minute, result.RetryAfter)
+	assert.True(t, time.Date(2024, 10, 1, 0, 0, 0, 0, time.UTC).Equal(result.ResetAt))
+
+	// A new month starts from zero
+	clock.Advance(time.Minute)
+	assert.True(t, allowN(t, l, "/api/v1/orders", "customer-42", 2).Allowed)
+}
+
+func TestReserve(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("token bucket lends tokens", func(t *testing.T) {
+		l, _ := newTestLimiter(t, Config{Rules: []models.Rule{{
+			APIEndpoint:     "/api/v1/orders",
+			Strategy:        models.StrategyTokenBucket,
+			TokenBucketRule: &models.TokenBucketRule{BucketCapacity: 2, TokenAddRate: 10, RetentionTime: 60},
+		}}})
+
+		first, err := l.ReserveN(ctx, "/api/v1/orders", "1.1.1.1", 2)
+		require.NoError(t, err)
+		assert.True(t, first.OK)
+		assert.Zero(t, first.Delay)
+		assert.True(t, first.Result.Allowed)
+
+		second, err := l.ReserveN(ctx, "/api/v1/orders", "1.1.1.1", 2)
+		require.NoError(t, err)
+		assert.True(t, second.OK)
+		assert.Equal(t, 2*time.Second, second.Delay)
+		assert.False(t, second.Result.Allowed)
+
+		third, err := l.Reserve(ctx, "/api/v1/orders", "1.1.1.1")
+		require.NoError(t, err)
+		assert.Equal(t, 3*time.Second, third.Delay)
+
+		// Cancelling gives the tokens back to the next reservation, only once
+		require.NoError(t, second.Cancel(ctx))
+		require.NoError(t, second.Cancel(ctx))
+		fourth, err := l.Reserve(ctx, "/api/v1/orders", "1.1.1.1")
+		require.NoError(t, err)
+		assert.Equal(t, 2*time.Second, fourth.Delay)
+
+		tooMany, err := l.ReserveN(ctx, "/api/v1/orders", "1.1.1.1", 3)
+		require.NoError(t, err)
+		assert.False(t, tooMany.OK)
+	})
+
+	t.Run("fixed window books the next window", func(t *testing.T) {
+		l, clock := newTestLimiter(t, Config{Rules: []models.Rule{{
+			APIEndpoint:            "/api/v1/orders",
+			Strategy:               models.StrategyFixedWindowCounter,
+			FixedWindowCounterRule: &models.FixedWindowCounterRule{MaxRequests: 2, Window: 60},
+		}}})
+
+		assert.True(t, allowN(t, l, "/api/v1/orders", "1.1.1.1", 2).Allowed)
+		clock.Advance(10 * time.Second)
+
+		reservation, err := l.ReserveN(ctx, "/api/v1/orders", "1.1.1.1", 2)
+		require.NoError(t, err)
+		assert.True(t, reservation.OK)
+		assert.Equal(t, 50*time.Second, reservation.Delay)
+
+		full, err := l.Reserve(ctx, "/api/v1/orders", "1.1.1.1")
+		require.NoError(t, err)
+		assert.False(t, full.OK)
+
+		// The reserved requests use up the next window
+		clock.Advance(50 * time.Second)
+		assert.False(t, allowN(t, l, "/api/v1/orders", "1.1.1.1", 1).Allowed)
+
+		require.NoError(t, reservation.Cancel(ctx))
+		assert.True(t, allowN(t, l, "/api/v1/orders", "1.1.1.1", 2).Allowed)
+	})
+
+	t.Run("sliding window books when requests leave the window", func(t *testing.T) {
+		l, clock := newTestLimiter(t, Config{Rules: []models.Rule{{
+			APIEndpoint:              "/api/v1/orders",
+			Strategy:                 models.StrategySlidingWindowCounter,
+			SlidingWindowCounterRule: &models.SlidingWindowCounterRule{MaxRequests: 2, WindowSize: 60},
+		}}})
+
+		assert.True(t, allowN(t, l, "/api/v1/orders", "1.1.1.1", 1).Allowed)
+		clock.Advance(20 * time.Second)
+		assert.True(t, allowN(t, l, "/api/v1/orders", "1.1.1.1", 1).Allowed)
+
+		first, err := l.Reserve(ctx, "/api/v1/orders", "1.1.1.1")
+		require.NoError(t, err)
+		assert.Equal(t, 40*time.Second, first.Delay)
+
+		second, err := l.Reserve(ctx, "/api/v1/orders", "1.1.1.1")
+		require.NoError(t, err)
+		assert.Equal(t, 60*time.Second, second.Delay)
+	})
+}
+
+func TestOverridesAndTiers(t *testing.T) {
+	l, _ := newTestLimiter(t, Config{
+		Rules: []models.Rule{{
+			APIEndpoint:            "/api/v1/orders",
+			Strategy:               models.StrategyFixedWindowCounter,
+			FixedWindowCounterRule: &models.FixedWindowCounterRule{MaxRequests: 1, Window: 60},
+			Overrides: []models.RuleOverride{{
+				Identity:   "customer-42",
+				RuleLimits: models.RuleLimits{FixedWindowCounterRule: &models.FixedWindowCounterRule{MaxRequests: 10, Window: 60}},
+			}},
+			Tiers: []models.RuleTier{{
+				Name:       "pro",
+				RuleLimits: models.RuleLimits{FixedWindowCounterRule: &models.FixedWindowCounterRule{MaxRequests: 5, Window: 60}},
+			}},
+		}},
+		Tier: func(key string) string {
+			if key == "customer-7" || key == "customer-42" {
+				return "pro"
+			}
+			return ""
+		},
+	})
+
+	assert.Equal(t, int64(1), allowN(t, l, "/api/v1/orders", "1.1.1.1", 1).Limit)
+	assert.Equal(t, int64(5), allowN(t, l, "/api/v1/orders", "customer-7", 1).Limit)
+	assert.Equal(t, int64(10), allowN(t, l, "/api/v1/orders", "customer-42", 1).Limit)
+}
+
+func TestUnlimitedEndpoints(t *testing.T) {
+	l, clock := newTestLimiter(t, Config{Rules: []models.Rule{{
+		APIEndpoint:            "/api/v1/orders",
+		Strategy:               models.StrategyFixedWindowCounter,
+		FixedWindowCounterRule: &models.FixedWindowCounterRule{MaxRequests: 1, Window: 60},
+		ExpiresAt:              time.Date(2024, 10, 1, 0, 0, 0, 0, time.UTC).Unix(),
+	}}})
+
+	result := allowN(t, l, "/api/v1/users", "1.1.1.1", 100)
+	assert.True(t, result.Allowed)
+	assert.Empty(t, result.Strategy)
+
+	assert.True(t, allowN(t, l, "/api/v1/orders", "1.1.1.1", 1).Allowed)
+	assert.False(t, allowN(t, l, "/api/v1/orders", "1.1.1.1", 1).Allowed)
+
+	// Expired rules no longer apply
+	clock.Advance(time.Minute)
+	assert.True(t, allowN(t, l, "/api/v1/orders", "1.1.1.1", 1).Allowed)
+	assert.True(t, allowN(t, l, "/api/v1/orders", "1.1.1.1", 1).Allowed)
+
+	_, err := l.AllowN(context.Background(), "/api/v1/orders", "1.1.1.1", 0)
+	assert.ErrorIs(t, err, ErrInvalidCount)
+}
+
+type failingStore struct{}
+
+func (failingStore) Update(context.Context, string, UpdateFunc) error {
+	return errors.New("store unavailable")
+}
+
+func TestStoreErrors(t *testing.T) {
+	rule := models.Rule{
+		APIEndpoint:            "/api/v1/orders",
+		Strategy:               models.StrategyFixedWindowCounter,
+		FixedWindowCounterRule: &models.FixedWindowCounterRule{MaxRequests: 1, Window: 60},
+	}
+
+	l, err := New(Config{Rules: []models.Rule{rule}, Store: failingStore{}})
+	require.NoError(t, err)
+	_, err = l.Allow(context.Background(), "/api/v1/orders", "1.1.1.1")
+	assert.Error(t, err)
+
+	rule.AllowOnError = true
+	require.NoError(t, l.SetRules([]models.Rule{rule}))
+	result, err := l.Allow(context.Background(), "/api/v1/orders", "1.1.1.1")
+	require.NoError(t, err)
+	assert.True(t, result.Allowed)
+	assert.True(t, result.FailedOpen)
+}
+
+func TestInvalidRules(t *testing.T) {
+	valid := models.Rule{
+		APIEndpoint:            "/api/v1/orders",
+		Strategy:               models.StrategyFixedWindowCounter,
+		FixedWindowCounterRule: &models.FixedWindowCounterRule{MaxRequests: 1, Window: 60},
+	}
+
+	_, err := New(Config{Rules: []models.Rule{valid, valid}})
+	assert.ErrorIs(t, err, ErrDuplicateEndpoint)
+
+	_, err = New(Config{Rules: []models.Rule{{
+		APIEndpoint:     "/api/v1/orders",
+		Strategy:        models.StrategyConcurrency,
+		ConcurrencyRule: &models.ConcurrencyRule{MaxConcurrent: 1, LeaseTimeout: 30},
+	}}})
+	assert.ErrorIs(t, err, ErrUnsupportedStrategy)
+
+	scheduled := valid
+	scheduled.ScheduledRules = []models.Rule{{
+		Strategy:        models.StrategyConcurrency,
+		ConcurrencyRule: &models.ConcurrencyRule{MaxConcurrent: 1, LeaseTimeout: 30},
+		Schedule:        &models.RuleSchedule{Cron: "0 9 * * *", Duration: 3600},
+	}}
+	_, err = New(Config{Rules: []models.Rule{scheduled}})
+	assert.ErrorIs(t, err, ErrUnsupportedStrategy)
+
+	penalty := valid
+	penalty.Penalty = &models.PenaltyPolicy{MaxDenials: 10, Window: 60, BanDuration: 300}
+	_, err = New(Config{Rules: []models.Rule{penalty}})
+	assert.ErrorIs(t, err, ErrUnsupportedPenalty)
+
+	accessList := valid
+	accessList.DenyList = []string{"10.0.0.0/8"}
+	_, err = New(Config{Rules: []models.Rule{accessList}})
+	assert.ErrorIs(t, err, ErrUnsupportedAccessList)
+
+	var validationErr *utils.RuleValidationError
+	_, err = New(Config{Rules: []models.Rule{{APIEndpoint: "/api/v1/orders", Strategy: "LEAKY BUCKET"}}})
+	assert.ErrorAs(t, err, &validationErr)
+}
+
+func TestMemoryStoreExpiry(t *testing.T) {
+	clock := &testClock{now: time.Unix(1700000000, 0)}
+	store := NewMemoryStore()
+	store.now = clock.Now
+
+	write := func(key string, state []byte, ttl time.Duration) {
+		require.NoError(t, store.Update(context.Background(), key, func([]byte) ([]byte, time.Duration, error) {
+			return state, ttl, nil
+		}))
+	}
+
+	read := func(key string) []byte {
+		var got []byte
+		require.NoError(t, store.Update(context.Background(), key, func(state []byte) ([]byte, time.Duration, error) {
+			got = state
+			return state, time.Minute, nil
+		}))
+		return got
+	}
+
+	write("a", []byte("1"), time.Second)
+	write("b", []byte("2"), 0)
+	assert.Equal(t, []byte("1"), read("a"))
+	assert.Nil(t, read("b"))
+	assert.Equal(t, 1, store.Len())
+
+	clock.Advance(time.Minute)
+	assert.Nil(t, read("a"))
+
+	// Expired keys are swept while other keys of their shard are updated
+	write("c", []byte("3"), time.Second)
+	clock.Advance(time.Minute)
+
+	other := "d"
+	for i := 0; store.shard(other) != store.shard("c"); i++ {
+		other = fmt.Sprintf("d%d", i)
+	}
+	for i := 0; i < memoryStoreSweepEvery; i++ {
+		write(other, []byte("4"), 0)
+	}
+	assert.Equal(t, 0, store.Len())
+}
@@ -0,0 +1,61 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// How often an update is retried when the key changed between reading and writing it
+	redisStoreMaxAttempts = 20
+)
+
+var (
+	ErrStoreConflict = errors.New("state kept changing while it was updated, try again")
+)
+
+// RedisStore keeps the state in Redis so every instance using the same Redis shares the limits. Updates use
+// WATCH transactions, so a Redis Cluster works as well since every update touches a single key.
+type RedisStore struct {
+	client redis.UniversalClient
+}
+
+func NewRedisStore(client redis.UniversalClient) *RedisStore {
+	return &RedisStore{client: client}
+}
+
+func (s *RedisStore) Update(ctx context.Context, key string, fn UpdateFunc) error {
+	for attempt := 0; attempt < redisStoreMaxAttempts; attempt++ {
+		err := s.client.Watch(ctx, func(tx *redis.Tx) error {
+			state, err := tx.Get(ctx, key).Bytes()
+			if err == redis.Nil {
+				state = nil
+			} else if err != nil {
+				return err
+			}
+
+			newState, ttl, err := fn(state)
+			if err != nil {
+				return err
+			}
+
+			_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+				if newState == nil || ttl <= 0 {
+					pipe.Del(ctx, key)
+				} else {
+					pipe.Set(ctx, key, newState, ttl)
+				}
+				return nil
+			})
+			return err
+		}, key)
+
+		if err != redis.TxFailedErr {
+			return err
+		}
+	}
+
+	return ErrStoreConflict
+}
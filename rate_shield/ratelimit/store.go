@@ -0,0 +1,107 @@
+package ratelimit
+
+import (
+	"context"
+	"hash/fnv"
+	"sync"
+	"time"
+)
+
+const (
+	memoryStoreShards = 32
+
+	// Every memoryStoreSweepEvery updates of a shard its expired keys are removed
+	memoryStoreSweepEvery = 1024
+)
+
+// UpdateFunc turns the stored state of a key into its new state. The state is nil for a missing key, a nil
+// new state or a ttl of zero or less deletes the key. Stores may call it again if the state changed
+// concurrently, so it must not have side effects.
+type UpdateFunc func(state []byte) (newState []byte, ttl time.Duration, err error)
+
+// Store keeps the state of the limiters, one value per client and endpoint
+type Store interface {
+	// Update atomically replaces the state of a key with the result of fn
+	Update(ctx context.Context, key string, fn UpdateFunc) error
+}
+
+// MemoryStore keeps the state in the process, limits are not shared with other instances
+type MemoryStore struct {
+	shards [memoryStoreShards]memoryShard
+	now    func() time.Time
+}
+
+type memoryShard struct {
+	mutex   sync.Mutex
+	entries map[string]memoryEntry
+	updates int
+}
+
+type memoryEntry struct {
+	state     []byte
+	expiresAt time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	s := &MemoryStore{now: time.Now}
+	for i := range s.shards {
+		s.shards[i].entries = make(map[string]memoryEntry)
+	}
+	return s
+}
+
+func (s *MemoryStore) Update(ctx context.Context, key string, fn UpdateFunc) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	shard := s.shard(key)
+	now := s.now()
+
+	shard.mutex.Lock()
+	defer shard.mutex.Unlock()
+
+	var state []byte
+	if entry, found := shard.entries[key]; found && now.Before(entry.expiresAt) {
+		state = entry.state
+	}
+
+	newState, ttl, err := fn(state)
+	if err != nil {
+		return err
+	}
+
+	if newState == nil || ttl <= 0 {
+		delete(shard.entries, key)
+	} else {
+		shard.entries[key] = memoryEntry{state: newState, expiresAt: now.Add(ttl)}
+	}
+
+	shard.updates++
+	if shard.updates%memoryStoreSweepEvery == 0 {
+		for key, entry := range shard.entries {
+			if !now.Before(entry.expiresAt) {
+				delete(shard.entries, key)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Len returns the number of stored keys, including expired keys that were not removed yet
+func (s *MemoryStore) Len() int {
+	count := 0
+	for i := range s.shards {
+		s.shards[i].mutex.Lock()
+		count += len(s.shards[i].entries)
+		s.shards[i].mutex.Unlock()
+	}
+	return count
+}
+
+func (s *MemoryStore) shard(key string) *memoryShard {
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	return &s.shards[h.Sum32()%memoryStoreShards]
+}
@@ -0,0 +1,43 @@
+package redisClient
+
+import (
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// Global allow and deny lists, stored as sets of IPs and CIDR ranges
+	accessListKeyPrefix = "access:"
+)
+
+// RedisAccessLists implements the RedisAccessListClient interface
+type RedisAccessLists struct {
+	client *redis.Client
+}
+
+// NewAccessListClient creates a new Redis access list client using the existing rules client connection
+func NewAccessListClient(client *redis.Client) RedisAccessListClient {
+	return RedisAccessLists{
+		client: client,
+	}
+}
+
+// GetAccessList returns every entry of a list
+func (r RedisAccessLists) GetAccessList(list string) ([]string, error) {
+	return r.client.SMembers(ctx, accessListKeyPrefix+list).Result()
+}
+
+// AddToAccessList adds an entry to a list. Returns false if the entry was already on it.
+func (r RedisAccessLists) AddToAccessList(list, entry string) (bool, error) {
+	added, err := r.client.SAdd(ctx, accessListKeyPrefix+list, entry).Result()
+	return added == 1, err
+}
+
+// RemoveFromAccessList removes an entry from a list. Returns false if the entry was not on it.
+func (r RedisAccessLists) RemoveFromAccessList(list, entry string) (bool, error) {
+	removed, err := r.client.SRem(ctx, accessListKeyPrefix+list, entry).Result()
+	return removed == 1, err
+}
+
+func (r RedisAccessLists) PublishMessage(channel, msg string) error {
+	return r.client.Publish(ctx, channel, msg).Err()
+}
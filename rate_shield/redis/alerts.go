@@ -0,0 +1,89 @@
+package redisClient
+
+import (
+	"encoding/json"
+	"errors"
+	"sort"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/x-sushant-x/RateShield/models"
+)
+
+const (
+	// Alert rules, a hash of alert rule name to the JSON encoded alert rule
+	alertRulesKey = "alerts:rules"
+)
+
+// RedisAlertRules implements the RedisAlertRuleClient interface
+type RedisAlertRules struct {
+	client *redis.Client
+}
+
+// NewAlertRuleClient creates a new Redis alert rule client using the existing rules client connection
+func NewAlertRuleClient(client *redis.Client) RedisAlertRuleClient {
+	return RedisAlertRules{
+		client: client,
+	}
+}
+
+// GetAlertRules returns every alert rule sorted by name
+func (r RedisAlertRules) GetAlertRules() ([]models.AlertRule, error) {
+	values, err := r.client.HGetAll(ctx, alertRulesKey).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	alerts := make([]models.AlertRule, 0, len(values))
+	for _, value := range values {
+		var alert models.AlertRule
+		if err := json.Unmarshal([]byte(value), &alert); err != nil {
+			return nil, err
+		}
+		alerts = append(alerts, alert)
+	}
+
+	sort.Slice(alerts, func(i, j int) bool {
+		return alerts[i].Name < alerts[j].Name
+	})
+
+	return alerts, nil
+}
+
+// GetAlertRule returns an alert rule by name. Returns false if there is no alert rule with that name.
+func (r RedisAlertRules) GetAlertRule(name string) (*models.AlertRule, bool, error) {
+	value, err := r.client.HGet(ctx, alertRulesKey, name).Result()
+	if errors.Is(err, redis.Nil) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	var alert models.AlertRule
+	if err := json.Unmarshal([]byte(value), &alert); err != nil {
+		return nil, false, err
+	}
+	return &alert, true, nil
+}
+
+// SetAlertRule creates or replaces the alert rule with the same name
+func (r RedisAlertRules) SetAlertRule(alert models.AlertRule) error {
+	value, err := json.Marshal(alert)
+	if err != nil {
+		return err
+	}
+	return r.client.HSet(ctx, alertRulesKey, alert.Name, value).Err()
+}
+
+// DeleteAlertRule removes an alert rule. Returns false if there was no alert rule with that name.
+func (r RedisAlertRules) DeleteAlertRule(name string) (bool, error) {
+	deleted, err := r.client.HDel(ctx, alertRulesKey, name).Result()
+	if err != nil {
+		return false, err
+	}
+	return deleted > 0, nil
+}
+
+func (r RedisAlertRules) PublishMessage(channel, msg string) error {
+	return r.client.Publish(ctx, channel, msg).Err()
+}
@@ -10,7 +10,14 @@ type RedisRuleClient interface {
 	GetRule(key string) (*models.Rule, bool, error)
 	GetAllRuleKeys() ([]string, bool, error)
 	SetRule(key string, val interface{}) error
+	SetRuleIfVersion(key string, rule models.Rule, expectedVersion int64) error
+	ApplyRules(rules []models.Rule, deleteKeys []string, expectedVersions map[string]int64) error
+	NextRuleVersion(key string) (int64, error)
+	AppendRuleHistory(key string, entry models.RuleHistoryEntry) error
+	GetRuleHistory(key string) ([]models.RuleHistoryEntry, error)
 	DeleteRule(key string) error
+	DeleteRuleIfVersion(key string, expectedVersion int64) error
+	MigrateLegacyRules() (int, error)
 	PublishMessage(channel, msg string) error
 	ListenToRulesUpdate(udpatesChannel chan string)
 }
@@ -22,6 +29,50 @@ type RedisRateLimiterClient interface {
 	Delete(key string) error
 }
 
+type RedisKeyScanner interface {
+	ScanKeys(match string) ([]string, error)
+	ScanKeysPage(match, cursor string, count int64) ([]string, string, error)
+}
+
+type RedisAccessListClient interface {
+	GetAccessList(list string) ([]string, error)
+	AddToAccessList(list, entry string) (bool, error)
+	RemoveFromAccessList(list, entry string) (bool, error)
+	PublishMessage(channel, msg string) error
+}
+
+type RedisTierClient interface {
+	GetClientTier(identity string) (string, bool, error)
+	SetClientTier(identity, tier string) (string, error)
+	DeleteClientTier(identity string) (string, bool, error)
+	PublishMessage(channel, msg string) error
+}
+
+type RedisAlertRuleClient interface {
+	GetAlertRules() ([]models.AlertRule, error)
+	GetAlertRule(name string) (*models.AlertRule, bool, error)
+	SetAlertRule(alert models.AlertRule) error
+	DeleteAlertRule(name string) (bool, error)
+	PublishMessage(channel, msg string) error
+}
+
+type RedisRuleWebhookClient interface {
+	GetRuleWebhooks() ([]models.RuleWebhook, error)
+	GetRuleWebhook(name string) (*models.RuleWebhook, bool, error)
+	SetRuleWebhook(webhook models.RuleWebhook) error
+	DeleteRuleWebhook(name string) (bool, error)
+	AddDelivery(delivery models.RuleWebhookDelivery) error
+	UpdateDelivery(delivery models.RuleWebhookDelivery) error
+	GetDelivery(id string) (*models.RuleWebhookDelivery, bool, error)
+	GetDeliveries() ([]models.RuleWebhookDelivery, error)
+}
+
+type RedisNotificationDedupClient interface {
+	MarkSent(key string, cooldown time.Duration) (bool, error)
+	AddSuppressed(key string) error
+	TakeSuppressed() (map[string]int64, error)
+}
+
 type RedisAuditClient interface {
 	AppendAuditLog(auditLog models.AuditLog) error
 	GetAuditLogs(start, end int64) ([]models.AuditLog, error)
@@ -0,0 +1,65 @@
+package redisClient
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// Marks a notification as sent until its cooldown ends, followed by the notification key
+	notificationSentKeyPrefix = "notifications:sent:"
+
+	// Notifications suppressed since the last digest, a hash of notification key to count
+	notificationSuppressedKey = "notifications:suppressed"
+)
+
+// RedisNotificationDedup implements the RedisNotificationDedupClient interface
+type RedisNotificationDedup struct {
+	client *redis.Client
+}
+
+// NewNotificationDedupClient creates a new Redis notification dedup client using the existing rules client connection
+func NewNotificationDedupClient(client *redis.Client) RedisNotificationDedupClient {
+	return RedisNotificationDedup{
+		client: client,
+	}
+}
+
+// MarkSent marks a notification as sent for the cooldown. Returns false if it was already sent within
+// the cooldown, by this or any other instance.
+func (r RedisNotificationDedup) MarkSent(key string, cooldown time.Duration) (bool, error) {
+	return r.client.SetNX(ctx, notificationSentKeyPrefix+key, time.Now().Unix(), cooldown).Result()
+}
+
+// AddSuppressed counts a notification that was not sent because of its cooldown
+func (r RedisNotificationDedup) AddSuppressed(key string) error {
+	return r.client.HIncrBy(ctx, notificationSuppressedKey, key, 1).Err()
+}
+
+// TakeSuppressed returns and clears the suppressed counts in one transaction, so every count ends up in
+// exactly one digest even when several instances send digests
+func (r RedisNotificationDedup) TakeSuppressed() (map[string]int64, error) {
+	var values *redis.MapStringStringCmd
+
+	_, err := r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		values = pipe.HGetAll(ctx, notificationSuppressedKey)
+		pipe.Del(ctx, notificationSuppressedKey)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int64, len(values.Val()))
+	for key, value := range values.Val() {
+		count, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		counts[key] = count
+	}
+
+	return counts, nil
+}
@@ -0,0 +1,185 @@
+package redisClient
+
+import (
+	"encoding/json"
+	"errors"
+	"sort"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/x-sushant-x/RateShield/models"
+)
+
+const (
+	// Rule webhooks, a hash of webhook name to the JSON encoded webhook
+	ruleWebhooksKey = "webhooks:rules"
+
+	// Deliveries of rule change events, a hash of delivery ID to the JSON encoded delivery and a list of
+	// the delivery IDs, newest first
+	ruleWebhookDeliveriesKey   = "webhooks:deliveries"
+	ruleWebhookDeliveryListKey = "webhooks:deliveries:log"
+
+	// Older deliveries are dropped from the log
+	maxRuleWebhookDeliveries = 1000
+)
+
+// RedisRuleWebhooks implements the RedisRuleWebhookClient interface
+type RedisRuleWebhooks struct {
+	client *redis.Client
+}
+
+// NewRuleWebhookClient creates a new Redis rule webhook client using the existing rules client connection
+func NewRuleWebhookClient(client *redis.Client) RedisRuleWebhookClient {
+	return RedisRuleWebhooks{
+		client: client,
+	}
+}
+
+// GetRuleWebhooks returns every rule webhook sorted by name
+func (r RedisRuleWebhooks) GetRuleWebhooks() ([]models.RuleWebhook, error) {
+	values, err := r.client.HGetAll(ctx, ruleWebhooksKey).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	webhooks := make([]models.RuleWebhook, 0, len(values))
+	for _, value := range values {
+		var webhook models.RuleWebhook
+		if err := json.Unmarshal([]byte(value), &webhook); err != nil {
+			return nil, err
+		}
+		webhooks = append(webhooks, webhook)
+	}
+
+	sort.Slice(webhooks, func(i, j int) bool {
+		return webhooks[i].Name < webhooks[j].Name
+	})
+
+	return webhooks, nil
+}
+
+// GetRuleWebhook returns a rule webhook by name. Returns false if there is no webhook with that name.
+func (r RedisRuleWebhooks) GetRuleWebhook(name string) (*models.RuleWebhook, bool, error) {
+	value, err := r.client.HGet(ctx, ruleWebhooksKey, name).Result()
+	if errors.Is(err, redis.Nil) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	var webhook models.RuleWebhook
+	if err := json.Unmarshal([]byte(value), &webhook); err != nil {
+		return nil, false, err
+	}
+	return &webhook, true, nil
+}
+
+// SetRuleWebhook creates or replaces the rule webhook with the same name
+func (r RedisRuleWebhooks) SetRuleWebhook(webhook models.RuleWebhook) error {
+	value, err := json.Marshal(webhook)
+	if err != nil {
+		return err
+	}
+	return r.client.HSet(ctx, ruleWebhooksKey, webhook.Name, value).Err()
+}
+
+// DeleteRuleWebhook removes a rule webhook. Returns false if there was no webhook with that name.
+func (r RedisRuleWebhooks) DeleteRuleWebhook(name string) (bool, error) {
+	deleted, err := r.client.HDel(ctx, ruleWebhooksKey, name).Result()
+	if err != nil {
+		return false, err
+	}
+	return deleted > 0, nil
+}
+
+// AddDelivery stores a new delivery at the head of the log and drops the deliveries past the newest 1000
+func (r RedisRuleWebhooks) AddDelivery(delivery models.RuleWebhookDelivery) error {
+	value, err := json.Marshal(delivery)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HSet(ctx, ruleWebhookDeliveriesKey, delivery.ID, value)
+		pipe.LPush(ctx, ruleWebhookDeliveryListKey, delivery.ID)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	expired, err := r.client.LRange(ctx, ruleWebhookDeliveryListKey, maxRuleWebhookDeliveries, -1).Result()
+	if err != nil || len(expired) == 0 {
+		return err
+	}
+
+	_, err = r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.LTrim(ctx, ruleWebhookDeliveryListKey, 0, maxRuleWebhookDeliveries-1)
+		pipe.HDel(ctx, ruleWebhookDeliveriesKey, expired...)
+		return nil
+	})
+	return err
+}
+
+// UpdateDelivery replaces a delivery still in the log, deliveries dropped from the log in the meantime
+// are not stored again
+func (r RedisRuleWebhooks) UpdateDelivery(delivery models.RuleWebhookDelivery) error {
+	value, err := json.Marshal(delivery)
+	if err != nil {
+		return err
+	}
+
+	exists, err := r.client.HExists(ctx, ruleWebhookDeliveriesKey, delivery.ID).Result()
+	if err != nil || !exists {
+		return err
+	}
+
+	return r.client.HSet(ctx, ruleWebhookDeliveriesKey, delivery.ID, value).Err()
+}
+
+// GetDelivery returns a delivery by ID. Returns false if it is not in the log.
+func (r RedisRuleWebhooks) GetDelivery(id string) (*models.RuleWebhookDelivery, bool, error) {
+	value, err := r.client.HGet(ctx, ruleWebhookDeliveriesKey, id).Result()
+	if errors.Is(err, redis.Nil) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	var delivery models.RuleWebhookDelivery
+	if err := json.Unmarshal([]byte(value), &delivery); err != nil {
+		return nil, false, err
+	}
+	return &delivery, true, nil
+}
+
+// GetDeliveries returns every delivery in the log, newest first
+func (r RedisRuleWebhooks) GetDeliveries() ([]models.RuleWebhookDelivery, error) {
+	ids, err := r.client.LRange(ctx, ruleWebhookDeliveryListKey, 0, -1).Result()
+	if err != nil || len(ids) == 0 {
+		return []models.RuleWebhookDelivery{}, err
+	}
+
+	values, err := r.client.HMGet(ctx, ruleWebhookDeliveriesKey, ids...).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	deliveries := make([]models.RuleWebhookDelivery, 0, len(values))
+	for _, value := range values {
+		// A delivery dropped between reading the list and the hash
+		value, ok := value.(string)
+		if !ok {
+			continue
+		}
+
+		var delivery models.RuleWebhookDelivery
+		if err := json.Unmarshal([]byte(value), &delivery); err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, delivery)
+	}
+
+	return deliveries, nil
+}
@@ -2,6 +2,9 @@ package redisClient
 
 import (
 	"encoding/json"
+	"errors"
+	"strings"
+	"time"
 
 	"github.com/redis/go-redis/v9"
 	"github.com/rs/zerolog/log"
@@ -10,6 +13,23 @@ import (
 
 const (
 	redisRuleUpdateChannel = "rules-update"
+
+	// Every rule is stored as a JSON document under ruleKeyPrefix + endpoint and its endpoint is added to
+	// rulesIndexKey, so rules never get mixed up with other data kept in the rules instance.
+	ruleKeyPrefix     = "rule:"
+	rulesIndexKey     = "rules:index"
+	rulesMigrationKey = "rules:migrated"
+	rulesVersionedKey = "rules:versioned"
+
+	// Per endpoint version counter and list of previous versions. Both are kept when a rule is deleted so
+	// versions keep increasing and a deleted rule can be restored.
+	ruleVersionKeyPrefix = "rules:version:"
+	ruleHistoryKeyPrefix = "rules:history:"
+	maxRuleHistory       = 100
+)
+
+var (
+	ErrRuleVersionConflict = errors.New("rule was modified by someone else")
 )
 
 type RedisRules struct {
@@ -22,25 +42,26 @@ func (r RedisRules) GetClient() *redis.Client {
 }
 
 func (r RedisRules) DeleteRule(key string) error {
-	err := r.client.Del(ctx, key).Err()
-	if err != nil {
-		return err
-	}
-	return nil
+	pipe := r.client.TxPipeline()
+	pipe.Del(ctx, ruleKeyPrefix+key)
+	pipe.SRem(ctx, rulesIndexKey, key)
+
+	_, err := pipe.Exec(ctx)
+	return err
 }
 
 func (r RedisRules) GetAllRuleKeys() ([]string, bool, error) {
-	res, err := r.client.Keys(ctx, "*").Result()
+	res, err := r.client.SMembers(ctx, rulesIndexKey).Result()
 	if err != nil {
-		return nil, false, nil
+		return nil, false, err
 	}
 
 	return res, true, nil
 }
 
 func (r RedisRules) GetRule(key string) (*models.Rule, bool, error) {
-	res, err := r.client.JSONGet(ctx, key).Result()
-	if err == redis.Nil {
+	res, err := r.client.JSONGet(ctx, ruleKeyPrefix+key).Result()
+	if err == redis.Nil || (err == nil && len(res) == 0) {
 		return nil, false, nil
 	} else if err != nil {
 		return nil, false, err
@@ -56,11 +77,317 @@ func (r RedisRules) GetRule(key string) (*models.Rule, bool, error) {
 }
 
 func (r RedisRules) SetRule(key string, val interface{}) error {
-	err := r.client.JSONSet(ctx, key, ".", val).Err()
+	pipe := r.client.TxPipeline()
+	pipe.JSONSet(ctx, ruleKeyPrefix+key, ".", val)
+	pipe.SAdd(ctx, rulesIndexKey, key)
+
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// SetRuleIfVersion stores a rule only if the currently stored rule has the expected version, or if no rule
+// is stored when the expected version is 0. The check and write happen in a WATCH transaction, so a
+// concurrent write between them also fails with ErrRuleVersionConflict.
+func (r RedisRules) SetRuleIfVersion(key string, rule models.Rule, expectedVersion int64) error {
+	return r.setRuleIf(key, rule, func(current *models.Rule) bool {
+		return ruleHasVersion(current, expectedVersion)
+	})
+}
+
+// setRuleIf stores a rule only if matches accepts the stored rule, nil if there is none
+func (r RedisRules) setRuleIf(key string, rule models.Rule, matches func(current *models.Rule) bool) error {
+	ruleKey := ruleKeyPrefix + key
+
+	err := r.client.Watch(ctx, func(tx *redis.Tx) error {
+		current, err := getWatchedRule(tx, key)
+		if err != nil {
+			return err
+		}
+
+		if !matches(current) {
+			return ErrRuleVersionConflict
+		}
+
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.JSONSet(ctx, ruleKey, ".", rule)
+			pipe.SAdd(ctx, rulesIndexKey, key)
+			return nil
+		})
+		return err
+	}, ruleKey)
+
+	if err == redis.TxFailedErr {
+		return ErrRuleVersionConflict
+	}
+	return err
+}
+
+// DeleteRuleIfVersion deletes a rule only if it still has expectedVersion. Returns ErrRuleVersionConflict if
+// the rule was changed or already deleted, by another instance for example.
+func (r RedisRules) DeleteRuleIfVersion(key string, expectedVersion int64) error {
+	ruleKey := ruleKeyPrefix + key
+
+	err := r.client.Watch(ctx, func(tx *redis.Tx) error {
+		current, err := getWatchedRule(tx, key)
+		if err != nil {
+			return err
+		}
+
+		if current == nil || !ruleHasVersion(current, expectedVersion) {
+			return ErrRuleVersionConflict
+		}
+
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.Del(ctx, ruleKey)
+			pipe.SRem(ctx, rulesIndexKey, key)
+			return nil
+		})
+		return err
+	}, ruleKey)
+
+	if err == redis.TxFailedErr {
+		return ErrRuleVersionConflict
+	}
+	return err
+}
+
+// ApplyRules stores and deletes several rules in one transaction. expectedVersions holds the version every
+// stored or deleted rule had when the changes were planned (0 if it did not exist); if any of them changed,
+// or was created since, nothing is written and ErrRuleVersionConflict is returned.
+func (r RedisRules) ApplyRules(rules []models.Rule, deleteKeys []string, expectedVersions map[string]int64) error {
+	watchedKeys := make([]string, 0, len(rules)+len(deleteKeys))
+	for _, rule := range rules {
+		watchedKeys = append(watchedKeys, ruleKeyPrefix+rule.APIEndpoint)
+	}
+	for _, key := range deleteKeys {
+		watchedKeys = append(watchedKeys, ruleKeyPrefix+key)
+	}
+
+	if len(watchedKeys) == 0 {
+		return nil
+	}
+
+	err := r.client.Watch(ctx, func(tx *redis.Tx) error {
+		for key, expectedVersion := range expectedVersions {
+			current, err := getWatchedRule(tx, key)
+			if err != nil {
+				return err
+			}
+
+			if !ruleHasVersion(current, expectedVersion) {
+				return ErrRuleVersionConflict
+			}
+		}
+
+		_, err := tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			for _, rule := range rules {
+				pipe.JSONSet(ctx, ruleKeyPrefix+rule.APIEndpoint, ".", rule)
+				pipe.SAdd(ctx, rulesIndexKey, rule.APIEndpoint)
+			}
+
+			for _, key := range deleteKeys {
+				pipe.Del(ctx, ruleKeyPrefix+key)
+				pipe.SRem(ctx, rulesIndexKey, key)
+			}
+			return nil
+		})
+		return err
+	}, watchedKeys...)
+
+	if err == redis.TxFailedErr {
+		return ErrRuleVersionConflict
+	}
+	return err
+}
+
+// getWatchedRule reads a rule inside a WATCH transaction, nil if it does not exist
+func getWatchedRule(tx *redis.Tx, key string) (*models.Rule, error) {
+	res, err := tx.JSONGet(ctx, ruleKeyPrefix+key).Result()
+	if err == redis.Nil || (err == nil && len(res) == 0) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var rule models.Rule
+	if err := json.Unmarshal([]byte(res), &rule); err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+// ruleHasVersion reports whether a stored rule has the expected version. Version 0 stands for a rule that
+// does not exist, an existing rule never matches it.
+func ruleHasVersion(current *models.Rule, expectedVersion int64) bool {
+	if current == nil {
+		return expectedVersion == 0
+	}
+	return expectedVersion != 0 && current.Version == expectedVersion
+}
+
+// NextRuleVersion returns the version to assign to the next write of a rule
+func (r RedisRules) NextRuleVersion(key string) (int64, error) {
+	return r.client.Incr(ctx, ruleVersionKeyPrefix+key).Result()
+}
+
+// AppendRuleHistory stores a version of a rule, keeping only the latest maxRuleHistory versions
+func (r RedisRules) AppendRuleHistory(key string, entry models.RuleHistoryEntry) error {
+	entryJSON, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	pipe := r.client.TxPipeline()
+	pipe.RPush(ctx, ruleHistoryKeyPrefix+key, string(entryJSON))
+	pipe.LTrim(ctx, ruleHistoryKeyPrefix+key, -maxRuleHistory, -1)
+
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+// GetRuleHistory returns the stored versions of a rule, oldest first
+func (r RedisRules) GetRuleHistory(key string) ([]models.RuleHistoryEntry, error) {
+	results, err := r.client.LRange(ctx, ruleHistoryKeyPrefix+key, 0, -1).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	history := make([]models.RuleHistoryEntry, 0, len(results))
+	for _, result := range results {
+		var entry models.RuleHistoryEntry
+		if err := json.Unmarshal([]byte(result), &entry); err != nil {
+			log.Error().Err(err).Str("entry", result).Msg("failed to unmarshal rule history entry")
+			continue
+		}
+		history = append(history, entry)
+	}
+
+	return history, nil
+}
+
+// MigrateLegacyRules moves rules stored by older versions (one JSON document per endpoint at the top level
+// of the rules instance) under the rule namespace, and gives rules stored before rules had versions their
+// first version. Each step runs once per deployment; a marker key is set when it completes and later calls
+// skip it. Returns the number of moved rules.
+func (r RedisRules) MigrateLegacyRules() (int, error) {
+	migrated, err := r.client.Exists(ctx, rulesMigrationKey).Result()
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	if migrated == 0 {
+		count, err = r.moveLegacyRules()
+		if err != nil {
+			return count, err
+		}
+	}
+
+	return count, r.versionLegacyRules()
+}
+
+func (r RedisRules) moveLegacyRules() (int, error) {
+	keys, err := NewRedisKeyScanner(r.client).ScanKeys("*")
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+
+	for _, key := range keys {
+		rule, isRule := r.getLegacyRule(key)
+		if !isRule {
+			continue
+		}
+
+		pipe := r.client.TxPipeline()
+		pipe.JSONSet(ctx, ruleKeyPrefix+key, ".", rule)
+		pipe.SAdd(ctx, rulesIndexKey, key)
+		pipe.Del(ctx, key)
+
+		if _, err := pipe.Exec(ctx); err != nil {
+			log.Err(err).Str("key", key).Msg("unable to migrate legacy rule")
+			return count, err
+		}
+
+		count++
+	}
+
+	err = r.client.Set(ctx, rulesMigrationKey, time.Now().Unix(), 0).Err()
+	if err != nil {
+		return count, err
+	}
+
+	return count, nil
+}
+
+// versionLegacyRules gives every rule without a version its first one, so version 0 only ever stands for a
+// rule that does not exist and every rule has an ETag that can be sent in If-Match
+func (r RedisRules) versionLegacyRules() error {
+	versioned, err := r.client.Exists(ctx, rulesVersionedKey).Result()
 	if err != nil {
 		return err
 	}
-	return nil
+
+	if versioned == 1 {
+		return nil
+	}
+
+	keys, _, err := r.GetAllRuleKeys()
+	if err != nil {
+		return err
+	}
+
+	for _, key := range keys {
+		rule, found, err := r.GetRule(key)
+		if err != nil {
+			return err
+		}
+
+		if !found || rule.Version != 0 {
+			continue
+		}
+
+		rule.Version, err = r.NextRuleVersion(key)
+		if err != nil {
+			return err
+		}
+
+		// Another instance or an admin gave the rule a version in the meantime
+		err = r.setRuleIf(key, *rule, func(current *models.Rule) bool {
+			return current != nil && current.Version == 0
+		})
+		if err != nil && err != ErrRuleVersionConflict {
+			log.Err(err).Str("key", key).Msg("unable to version legacy rule")
+			return err
+		}
+	}
+
+	return r.client.Set(ctx, rulesVersionedKey, time.Now().Unix(), 0).Err()
+}
+
+// getLegacyRule returns the rule stored at a top level key, if the key holds a rule for its own endpoint
+func (r RedisRules) getLegacyRule(key string) (*models.Rule, bool) {
+	if strings.HasPrefix(key, ruleKeyPrefix) || strings.HasPrefix(key, "rules:") || strings.HasPrefix(key, "audit:") {
+		return nil, false
+	}
+
+	keyType, err := r.client.Type(ctx, key).Result()
+	if err != nil || keyType != "ReJSON-RL" {
+		return nil, false
+	}
+
+	res, err := r.client.JSONGet(ctx, key).Result()
+	if err != nil {
+		return nil, false
+	}
+
+	var rule models.Rule
+	if err := json.Unmarshal([]byte(res), &rule); err != nil || rule.APIEndpoint != key {
+		return nil, false
+	}
+
+	return &rule, true
 }
 
 func (r RedisRules) PublishMessage(channel, msg string) error {
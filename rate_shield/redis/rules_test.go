@@ -0,0 +1,231 @@
+package redisClient
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/x-sushant-x/RateShield/models"
+)
+
+// jsonAsStringHook stands in for RedisJSON, which the in-memory Redis does not have. Documents are stored as
+// plain strings and string keys report the RedisJSON type.
+type jsonAsStringHook struct{}
+
+func (jsonAsStringHook) DialHook(next redis.DialHook) redis.DialHook {
+	return next
+}
+
+func (jsonAsStringHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		rewriteJSONCmd(cmd)
+		err := next(ctx, cmd)
+
+		if statusCmd, ok := cmd.(*redis.StatusCmd); ok && cmd.Name() == "type" && statusCmd.Val() == "string" {
+			statusCmd.SetVal("ReJSON-RL")
+		}
+		return err
+	}
+}
+
+func (jsonAsStringHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		for _, cmd := range cmds {
+			rewriteJSONCmd(cmd)
+		}
+		return next(ctx, cmds)
+	}
+}
+
+func rewriteJSONCmd(cmd redis.Cmder) {
+	args := cmd.Args()
+
+	switch cmd.Name() {
+	case "json.set":
+		args[0], args[2], args[3] = "SET", args[3], "KEEPTTL"
+	case "json.get":
+		args[0] = "GET"
+	}
+}
+
+func newTestRedisRules(t *testing.T) (RedisRules, *miniredis.Miniredis) {
+	mr := miniredis.RunT(t)
+
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	client.AddHook(jsonAsStringHook{})
+	t.Cleanup(func() { client.Close() })
+
+	return RedisRules{client: client}, mr
+}
+
+func TestMigrateLegacyRules(t *testing.T) {
+	rules, mr := newTestRedisRules(t)
+
+	legacy := models.Rule{APIEndpoint: "/api/v1/users", Strategy: "TOKEN BUCKET"}
+	require.NoError(t, rules.client.JSONSet(ctx, legacy.APIEndpoint, ".", legacy).Err())
+
+	// Keys that are not rules of their own endpoint stay where they are
+	require.NoError(t, rules.client.JSONSet(ctx, "/api/v1/orders", ".", models.Rule{APIEndpoint: "/api/v1/other"}).Err())
+	require.NoError(t, rules.client.JSONSet(ctx, "settings", ".", `{"theme":"dark"}`).Err())
+	mr.SAdd("other-set", "a")
+
+	current := models.Rule{APIEndpoint: "/api/v1/search", HTTPMethod: "GET"}
+	require.NoError(t, rules.SetRule(current.APIEndpoint, current))
+
+	count, err := rules.MigrateLegacyRules()
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+
+	migrated, found, err := rules.GetRule(legacy.APIEndpoint)
+	require.NoError(t, err)
+	require.True(t, found)
+	assert.Equal(t, legacy.Strategy, migrated.Strategy)
+	assert.Equal(t, int64(1), migrated.Version, "migrated rules get their first version")
+
+	keys, _, err := rules.GetAllRuleKeys()
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"/api/v1/users", "/api/v1/search"}, keys)
+
+	assert.False(t, mr.Exists(legacy.APIEndpoint))
+	assert.True(t, mr.Exists("/api/v1/orders"))
+	assert.True(t, mr.Exists("settings"))
+	assert.True(t, mr.Exists(rulesMigrationKey))
+
+	t.Run("runs only once", func(t *testing.T) {
+		require.NoError(t, rules.client.JSONSet(ctx, "/api/v1/late", ".", models.Rule{APIEndpoint: "/api/v1/late"}).Err())
+
+		count, err := rules.MigrateLegacyRules()
+		require.NoError(t, err)
+		assert.Equal(t, 0, count)
+		assert.True(t, mr.Exists("/api/v1/late"))
+	})
+
+	t.Run("running it again leaves migrated rules alone", func(t *testing.T) {
+		mr.Del(rulesMigrationKey)
+		mr.Del("/api/v1/late")
+
+		count, err := rules.MigrateLegacyRules()
+		require.NoError(t, err)
+		assert.Equal(t, 0, count)
+
+		keys, _, err := rules.GetAllRuleKeys()
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{"/api/v1/users", "/api/v1/search"}, keys)
+
+		rule, found, err := rules.GetRule(current.APIEndpoint)
+		require.NoError(t, err)
+		require.True(t, found)
+		assert.Equal(t, "GET", rule.HTTPMethod)
+	})
+}
+
+func TestVersionLegacyRules(t *testing.T) {
+	rules, mr := newTestRedisRules(t)
+
+	// Stored in the namespace before rules had versions
+	require.NoError(t, rules.SetRule("/api/v1/users", models.Rule{APIEndpoint: "/api/v1/users"}))
+	require.NoError(t, rules.SetRule("/api/v1/search", models.Rule{APIEndpoint: "/api/v1/search", Version: 4}))
+	mr.Set(rulesMigrationKey, "1")
+
+	_, err := rules.MigrateLegacyRules()
+	require.NoError(t, err)
+
+	rule, _, err := rules.GetRule("/api/v1/users")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), rule.Version)
+
+	rule, _, err = rules.GetRule("/api/v1/search")
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), rule.Version)
+
+	assert.True(t, mr.Exists(rulesVersionedKey))
+}
+
+func TestSetRuleIfVersion(t *testing.T) {
+	rules, _ := newTestRedisRules(t)
+	endpoint := "/api/v1/search"
+
+	require.NoError(t, rules.SetRuleIfVersion(endpoint, models.Rule{APIEndpoint: endpoint, Version: 1}, 0))
+	assert.ErrorIs(t, rules.SetRuleIfVersion(endpoint, models.Rule{APIEndpoint: endpoint, Version: 2}, 0), ErrRuleVersionConflict)
+	assert.ErrorIs(t, rules.SetRuleIfVersion(endpoint, models.Rule{APIEndpoint: endpoint, Version: 2}, 5), ErrRuleVersionConflict)
+	require.NoError(t, rules.SetRuleIfVersion(endpoint, models.Rule{APIEndpoint: endpoint, Version: 2}, 1))
+
+	rule, _, err := rules.GetRule(endpoint)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), rule.Version)
+
+	t.Run("create only with a rule stored before versioning", func(t *testing.T) {
+		require.NoError(t, rules.SetRule("/api/v1/users", models.Rule{APIEndpoint: "/api/v1/users"}))
+
+		err := rules.SetRuleIfVersion("/api/v1/users", models.Rule{APIEndpoint: "/api/v1/users", Version: 1}, 0)
+		assert.ErrorIs(t, err, ErrRuleVersionConflict)
+	})
+
+	t.Run("version of a rule that does not exist", func(t *testing.T) {
+		err := rules.SetRuleIfVersion("/api/v1/orders", models.Rule{APIEndpoint: "/api/v1/orders", Version: 4}, 3)
+		assert.ErrorIs(t, err, ErrRuleVersionConflict)
+	})
+}
+
+func TestDeleteRuleIfVersion(t *testing.T) {
+	rules, _ := newTestRedisRules(t)
+	endpoint := "/api/v1/search"
+
+	require.NoError(t, rules.SetRule(endpoint, models.Rule{APIEndpoint: endpoint, Version: 2}))
+
+	assert.ErrorIs(t, rules.DeleteRuleIfVersion(endpoint, 1), ErrRuleVersionConflict)
+	assert.ErrorIs(t, rules.DeleteRuleIfVersion(endpoint, 0), ErrRuleVersionConflict)
+	require.NoError(t, rules.DeleteRuleIfVersion(endpoint, 2))
+
+	keys, _, err := rules.GetAllRuleKeys()
+	require.NoError(t, err)
+	assert.Empty(t, keys)
+
+	// Already deleted, by another instance for example
+	assert.ErrorIs(t, rules.DeleteRuleIfVersion(endpoint, 2), ErrRuleVersionConflict)
+}
+
+func TestApplyRulesVersionConflict(t *testing.T) {
+	rules, _ := newTestRedisRules(t)
+
+	require.NoError(t, rules.SetRule("/api/v1/users", models.Rule{APIEndpoint: "/api/v1/users", Version: 2}))
+
+	// Creating a rule that exists fails and writes nothing
+	err := rules.ApplyRules(
+		[]models.Rule{{APIEndpoint: "/api/v1/users", Version: 3}, {APIEndpoint: "/api/v1/search", Version: 1}},
+		nil, map[string]int64{"/api/v1/users": 0, "/api/v1/search": 0})
+	assert.ErrorIs(t, err, ErrRuleVersionConflict)
+
+	_, found, err := rules.GetRule("/api/v1/search")
+	require.NoError(t, err)
+	assert.False(t, found)
+
+	require.NoError(t, rules.ApplyRules(
+		[]models.Rule{{APIEndpoint: "/api/v1/search", Version: 1}},
+		[]string{"/api/v1/users"}, map[string]int64{"/api/v1/users": 2, "/api/v1/search": 0}))
+
+	keys, _, err := rules.GetAllRuleKeys()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"/api/v1/search"}, keys)
+}
+
+func TestSetRuleIfVersionConcurrentWrite(t *testing.T) {
+	rules, _ := newTestRedisRules(t)
+	endpoint := "/api/v1/search"
+
+	require.NoError(t, rules.SetRule(endpoint, models.Rule{APIEndpoint: endpoint, Version: 1}))
+
+	// Another instance saves the rule between the version check and the write
+	err := rules.setRuleIf(endpoint, models.Rule{APIEndpoint: endpoint, Version: 2}, func(current *models.Rule) bool {
+		require.NoError(t, rules.SetRule(endpoint, models.Rule{APIEndpoint: endpoint, Version: 3}))
+		return ruleHasVersion(current, 1)
+	})
+	assert.ErrorIs(t, err, ErrRuleVersionConflict)
+
+	rule, _, err := rules.GetRule(endpoint)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), rule.Version)
+}
@@ -0,0 +1,156 @@
+package redisClient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	scanBatchSize = 500
+)
+
+var (
+	ErrInvalidScanCursor      = errors.New("invalid scan cursor")
+	ErrUnsupportedRedisClient = errors.New("unsupported redis client for key scanning")
+)
+
+// keyScanner enumerates keys with SCAN instead of KEYS so Redis is never blocked. On a cluster
+// every master node is scanned one after another.
+type keyScanner struct {
+	client redis.UniversalClient
+}
+
+func NewRedisKeyScanner(client redis.UniversalClient) RedisKeyScanner {
+	return keyScanner{
+		client: client,
+	}
+}
+
+// ScanKeys returns every key matching the pattern
+func (s keyScanner) ScanKeys(match string) ([]string, error) {
+	keys := []string{}
+	cursor := ""
+
+	for {
+		page, next, err := s.ScanKeysPage(match, cursor, scanBatchSize)
+		if err != nil {
+			return nil, err
+		}
+
+		keys = append(keys, page...)
+
+		if next == "" {
+			return keys, nil
+		}
+		cursor = next
+	}
+}
+
+// ScanKeysPage returns at least count keys matching the pattern (fewer on the last page) and the cursor
+// to pass to get the next page. An empty cursor starts a new iteration and an empty next cursor means
+// the iteration is complete. The cursor has the form <node index>:<node cursor>; like SCAN itself a key
+// may be returned more than once if the cluster topology changes during an iteration.
+func (s keyScanner) ScanKeysPage(match, cursor string, count int64) ([]string, string, error) {
+	nodes, err := s.nodes()
+	if err != nil {
+		return nil, "", err
+	}
+
+	nodeIdx, nodeCursor, err := parseScanCursor(cursor)
+	if err != nil {
+		return nil, "", err
+	}
+
+	keys := []string{}
+
+	for nodeIdx < len(nodes) {
+		page, next, err := nodes[nodeIdx].Scan(ctx, nodeCursor, match, count).Result()
+		if err != nil {
+			return nil, "", err
+		}
+
+		keys = append(keys, page...)
+
+		if next == 0 {
+			nodeIdx++
+			nodeCursor = 0
+		} else {
+			nodeCursor = next
+		}
+
+		if int64(len(keys)) >= count {
+			break
+		}
+	}
+
+	if nodeIdx >= len(nodes) {
+		return keys, "", nil
+	}
+
+	return keys, fmt.Sprintf("%d:%d", nodeIdx, nodeCursor), nil
+}
+
+func (s keyScanner) nodes() ([]*redis.Client, error) {
+	switch client := s.client.(type) {
+	case *redis.Client:
+		return []*redis.Client{client}, nil
+	case *redis.ClusterClient:
+		var mutex sync.Mutex
+		nodes := []*redis.Client{}
+
+		err := client.ForEachMaster(ctx, func(ctx context.Context, node *redis.Client) error {
+			mutex.Lock()
+			nodes = append(nodes, node)
+			mutex.Unlock()
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		// ForEachMaster visits nodes concurrently, sort them so cursors stay stable between pages
+		sort.Slice(nodes, func(i, j int) bool {
+			return nodes[i].Options().Addr < nodes[j].Options().Addr
+		})
+
+		return nodes, nil
+	}
+
+	return nil, ErrUnsupportedRedisClient
+}
+
+func parseScanCursor(cursor string) (int, uint64, error) {
+	if cursor == "" {
+		return 0, 0, nil
+	}
+
+	parts := strings.Split(cursor, ":")
+	if len(parts) != 2 {
+		return 0, 0, ErrInvalidScanCursor
+	}
+
+	nodeIdx, err := strconv.Atoi(parts[0])
+	if err != nil || nodeIdx < 0 {
+		return 0, 0, ErrInvalidScanCursor
+	}
+
+	nodeCursor, err := strconv.ParseUint(parts[1], 10, 64)
+	if err != nil {
+		return 0, 0, ErrInvalidScanCursor
+	}
+
+	return nodeIdx, nodeCursor, nil
+}
+
+// EscapeMatchPattern escapes glob characters so a value can be used literally inside a SCAN MATCH pattern
+func EscapeMatchPattern(value string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, `*`, `\*`, `?`, `\?`, `[`, `\[`, `]`, `\]`)
+	return replacer.Replace(value)
+}
@@ -0,0 +1,36 @@
+package redisClient
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseScanCursor(t *testing.T) {
+	t.Run("empty cursor starts a new iteration", func(t *testing.T) {
+		nodeIdx, nodeCursor, err := parseScanCursor("")
+		assert.NoError(t, err)
+		assert.Equal(t, 0, nodeIdx)
+		assert.Equal(t, uint64(0), nodeCursor)
+	})
+
+	t.Run("valid cursor", func(t *testing.T) {
+		nodeIdx, nodeCursor, err := parseScanCursor("2:1536")
+		assert.NoError(t, err)
+		assert.Equal(t, 2, nodeIdx)
+		assert.Equal(t, uint64(1536), nodeCursor)
+	})
+
+	t.Run("invalid cursors", func(t *testing.T) {
+		for _, cursor := range []string{"12", "a:1", "1:b", "-1:0", "1:2:3"} {
+			_, _, err := parseScanCursor(cursor)
+			assert.ErrorIs(t, err, ErrInvalidScanCursor, cursor)
+		}
+	})
+}
+
+func TestEscapeMatchPattern(t *testing.T) {
+	assert.Equal(t, "/api/v1/users", EscapeMatchPattern("/api/v1/users"))
+	assert.Equal(t, `/api/\*/items\?id=\[1\]`, EscapeMatchPattern("/api/*/items?id=[1]"))
+	assert.Equal(t, `a\\b`, EscapeMatchPattern(`a\b`))
+}
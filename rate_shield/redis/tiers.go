@@ -0,0 +1,58 @@
+package redisClient
+
+import (
+	"errors"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// Tier of each client, a hash of client identity to tier name
+	clientTiersKey = "tiers:clients"
+)
+
+// RedisTiers implements the RedisTierClient interface
+type RedisTiers struct {
+	client *redis.Client
+}
+
+// NewTierClient creates a new Redis tier client using the existing rules client connection
+func NewTierClient(client *redis.Client) RedisTierClient {
+	return RedisTiers{
+		client: client,
+	}
+}
+
+// GetClientTier returns the tier of a client. Returns false if the client has no tier.
+func (r RedisTiers) GetClientTier(identity string) (string, bool, error) {
+	tier, err := r.client.HGet(ctx, clientTiersKey, identity).Result()
+	if errors.Is(err, redis.Nil) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	return tier, true, nil
+}
+
+// SetClientTier assigns a client to a tier and returns its previous tier, empty if it had none
+func (r RedisTiers) SetClientTier(identity, tier string) (string, error) {
+	previous, _, err := r.GetClientTier(identity)
+	if err != nil {
+		return "", err
+	}
+	return previous, r.client.HSet(ctx, clientTiersKey, identity, tier).Err()
+}
+
+// DeleteClientTier removes the tier of a client and returns it. Returns false if the client had no tier.
+func (r RedisTiers) DeleteClientTier(identity string) (string, bool, error) {
+	previous, found, err := r.GetClientTier(identity)
+	if err != nil || !found {
+		return "", false, err
+	}
+	return previous, true, r.client.HDel(ctx, clientTiersKey, identity).Err()
+}
+
+func (r RedisTiers) PublishMessage(channel, msg string) error {
+	return r.client.Publish(ctx, channel, msg).Err()
+}
@@ -0,0 +1,140 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/rs/zerolog/log"
+	"github.com/x-sushant-x/RateShield/models"
+	redisClient "github.com/x-sushant-x/RateShield/redis"
+	"github.com/x-sushant-x/RateShield/utils"
+)
+
+var (
+	ErrUnknownAccessList   = errors.New("unknown access list, use allow or deny")
+	ErrNoAccessListEntries = errors.New("no entries given")
+)
+
+// AccessListService manages the global allow and deny lists
+type AccessListService interface {
+	GetAccessLists() (models.AccessLists, error)
+	AddEntries(list string, entries []string, actor, ipAddress, userAgent string) ([]string, error)
+	RemoveEntries(list string, entries []string, actor, ipAddress, userAgent string) ([]string, error)
+}
+
+type AccessListServiceRedis struct {
+	redisClient redisClient.RedisAccessListClient
+	auditSvc    AuditService
+}
+
+func NewAccessListService(client redisClient.RedisAccessListClient, auditSvc AuditService) AccessListServiceRedis {
+	return AccessListServiceRedis{
+		redisClient: client,
+		auditSvc:    auditSvc,
+	}
+}
+
+func (s AccessListServiceRedis) GetAccessLists() (models.AccessLists, error) {
+	allow, err := s.redisClient.GetAccessList(models.AccessListAllow)
+	if err != nil {
+		return models.AccessLists{}, err
+	}
+
+	deny, err := s.redisClient.GetAccessList(models.AccessListDeny)
+	if err != nil {
+		return models.AccessLists{}, err
+	}
+
+	return models.AccessLists{
+		Allow: allow,
+		Deny:  deny,
+	}, nil
+}
+
+// AddEntries adds IPs or CIDR ranges to a list. Entries are stored in canonical form and every entry that
+// was not on the list yet is audited. Returns the added entries.
+func (s AccessListServiceRedis) AddEntries(list string, entries []string, actor, ipAddress, userAgent string) ([]string, error) {
+	return s.changeEntries(list, entries, models.AuditActionAccessListAdd, actor, ipAddress, userAgent)
+}
+
+// RemoveEntries removes IPs or CIDR ranges from a list. Returns the removed entries.
+func (s AccessListServiceRedis) RemoveEntries(list string, entries []string, actor, ipAddress, userAgent string) ([]string, error) {
+	return s.changeEntries(list, entries, models.AuditActionAccessListRemove, actor, ipAddress, userAgent)
+}
+
+func (s AccessListServiceRedis) changeEntries(list string, entries []string, action, actor, ipAddress, userAgent string) ([]string, error) {
+	if list != models.AccessListAllow && list != models.AccessListDeny {
+		return nil, ErrUnknownAccessList
+	}
+
+	// Validate every entry first so a bad entry does not leave the list half changed
+	normalized, err := normalizeIPEntries(entries)
+	if err != nil {
+		return nil, err
+	}
+
+	changed := []string{}
+
+	for _, entry := range normalized {
+		var done bool
+		if action == models.AuditActionAccessListAdd {
+			done, err = s.redisClient.AddToAccessList(list, entry)
+		} else {
+			done, err = s.redisClient.RemoveFromAccessList(list, entry)
+		}
+
+		if err != nil {
+			log.Err(err).Msg("unable to update access list")
+			return changed, err
+		}
+
+		if !done {
+			continue
+		}
+
+		changed = append(changed, entry)
+		s.logAccessListChange(action, list, entry, actor, ipAddress, userAgent)
+	}
+
+	if len(changed) == 0 {
+		return changed, nil
+	}
+
+	// Limiters reload the access lists together with the rules
+	return changed, s.redisClient.PublishMessage(redisChannel, "access-lists-updated")
+}
+
+func (s AccessListServiceRedis) logAccessListChange(action, list, entry, actor, ipAddress, userAgent string) {
+	if s.auditSvc == nil {
+		return
+	}
+
+	details := fmt.Sprintf("added %s to the %s list", entry, list)
+	if action == models.AuditActionAccessListRemove {
+		details = fmt.Sprintf("removed %s from the %s list", entry, list)
+	}
+
+	err := s.auditSvc.LogLimiterAction(actor, action, "", "", details, ipAddress, userAgent)
+	if err != nil {
+		// Don't fail the operation if audit logging fails
+		log.Warn().Err(err).Msg("failed to log audit event for access list change")
+	}
+}
+
+func normalizeIPEntries(entries []string) ([]string, error) {
+	if len(entries) == 0 {
+		return nil, ErrNoAccessListEntries
+	}
+
+	normalized := make([]string, 0, len(entries))
+
+	for _, entry := range entries {
+		normalizedEntry, err := utils.NormalizeIPEntry(entry)
+		if err != nil {
+			return nil, fmt.Errorf("%q %w", entry, err)
+		}
+		normalized = append(normalized, normalizedEntry)
+	}
+
+	return normalized, nil
+}
@@ -0,0 +1,114 @@
+package service
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+	"github.com/x-sushant-x/RateShield/models"
+	redisClient "github.com/x-sushant-x/RateShield/redis"
+	"github.com/x-sushant-x/RateShield/utils"
+)
+
+const (
+	defaultAlertCooldown = 300
+)
+
+var (
+	ErrNoAlertRuleName = errors.New("name must not be empty")
+)
+
+// AlertRuleService manages the alert rules evaluated by the limiters
+type AlertRuleService interface {
+	ListAlertRules() ([]models.AlertRule, error)
+	SaveAlertRule(alert models.AlertRule, actor, ipAddress, userAgent string) (models.AlertRule, error)
+	DeleteAlertRule(name, actor, ipAddress, userAgent string) (bool, error)
+}
+
+type AlertRuleServiceRedis struct {
+	redisClient redisClient.RedisAlertRuleClient
+	auditSvc    AuditService
+}
+
+func NewAlertRuleService(client redisClient.RedisAlertRuleClient, auditSvc AuditService) AlertRuleServiceRedis {
+	return AlertRuleServiceRedis{
+		redisClient: client,
+		auditSvc:    auditSvc,
+	}
+}
+
+func (s AlertRuleServiceRedis) ListAlertRules() ([]models.AlertRule, error) {
+	return s.redisClient.GetAlertRules()
+}
+
+// SaveAlertRule creates or replaces the alert rule with the same name. A missing severity defaults to
+// warning and a missing cooldown to 5 minutes.
+func (s AlertRuleServiceRedis) SaveAlertRule(alert models.AlertRule, actor, ipAddress, userAgent string) (models.AlertRule, error) {
+	if err := utils.ValidateAlertRule(alert); err != nil {
+		return models.AlertRule{}, err
+	}
+
+	if alert.Severity == "" {
+		alert.Severity = models.NotificationSeverityWarning
+	}
+	if alert.Cooldown == 0 {
+		alert.Cooldown = defaultAlertCooldown
+	}
+
+	_, existed, err := s.redisClient.GetAlertRule(alert.Name)
+	if err != nil {
+		log.Err(err).Msg("unable to get alert rule")
+		return models.AlertRule{}, err
+	}
+
+	if err := s.redisClient.SetAlertRule(alert); err != nil {
+		log.Err(err).Msg("unable to save alert rule")
+		return models.AlertRule{}, err
+	}
+
+	details := "created " + strings.ToLower(alert.Type) + " alert rule " + alert.Name
+	if existed {
+		details = "updated " + strings.ToLower(alert.Type) + " alert rule " + alert.Name
+	}
+	s.logAlertRuleChange(models.AuditActionSaveAlertRule, details, actor, ipAddress, userAgent)
+
+	return alert, s.publishAlertRulesUpdate()
+}
+
+// DeleteAlertRule removes an alert rule. Returns false if there was no alert rule with that name.
+func (s AlertRuleServiceRedis) DeleteAlertRule(name, actor, ipAddress, userAgent string) (bool, error) {
+	if strings.TrimSpace(name) == "" {
+		return false, ErrNoAlertRuleName
+	}
+
+	deleted, err := s.redisClient.DeleteAlertRule(name)
+	if err != nil {
+		log.Err(err).Msg("unable to delete alert rule")
+		return false, err
+	}
+
+	if !deleted {
+		return false, nil
+	}
+
+	s.logAlertRuleChange(models.AuditActionDeleteAlertRule, "deleted alert rule "+name, actor, ipAddress, userAgent)
+
+	return true, s.publishAlertRulesUpdate()
+}
+
+// Limiters reload the alert rules together with the rules
+func (s AlertRuleServiceRedis) publishAlertRulesUpdate() error {
+	return s.redisClient.PublishMessage(redisChannel, "alerts-updated")
+}
+
+func (s AlertRuleServiceRedis) logAlertRuleChange(action, details, actor, ipAddress, userAgent string) {
+	if s.auditSvc == nil {
+		return
+	}
+
+	err := s.auditSvc.LogLimiterAction(actor, action, "", "", details, ipAddress, userAgent)
+	if err != nil {
+		// Don't fail the operation if audit logging fails
+		log.Warn().Err(err).Msg("failed to log audit event for alert rule change")
+	}
+}
@@ -10,9 +10,25 @@ import (
 	redisClient "github.com/x-sushant-x/RateShield/redis"
 )
 
+// Actions recorded by LogLimiterAction, every admin action that is not a rule change
+var limiterAuditActions = map[string]bool{
+	models.AuditActionResetLimiter:      true,
+	models.AuditActionGrantQuota:        true,
+	models.AuditActionLiftBan:           true,
+	models.AuditActionAccessListAdd:     true,
+	models.AuditActionAccessListRemove:  true,
+	models.AuditActionAssignTier:        true,
+	models.AuditActionUnassignTier:      true,
+	models.AuditActionSaveAlertRule:     true,
+	models.AuditActionDeleteAlertRule:   true,
+	models.AuditActionSaveRuleWebhook:   true,
+	models.AuditActionDeleteRuleWebhook: true,
+}
+
 // AuditService defines the interface for audit logging operations
 type AuditService interface {
 	LogRuleChange(actor, action, endpoint string, oldRule, newRule *models.Rule, ipAddress, userAgent string) error
+	LogLimiterAction(actor, action, endpoint, clientIP, details, ipAddress, userAgent string) error
 	GetAuditLogs(page, items int) (models.PaginatedAuditLogs, error)
 	GetAllAuditLogs() ([]models.AuditLog, error)
 	GetAuditLogsByEndpoint(endpoint string) ([]models.AuditLog, error)
@@ -20,15 +36,23 @@ type AuditService interface {
 	GetAuditLogsByAction(action string) ([]models.AuditLog, error)
 }
 
+// RuleChangeEmitter is told about every rule change recorded in the audit log
+type RuleChangeEmitter interface {
+	EmitRuleChange(auditLog models.AuditLog)
+}
+
 // AuditServiceRedis implements the AuditService interface using Redis
 type AuditServiceRedis struct {
 	auditClient redisClient.RedisAuditClient
+	ruleChanges RuleChangeEmitter
 }
 
-// NewAuditService creates a new audit service instance
-func NewAuditService(auditClient redisClient.RedisAuditClient) AuditService {
+// NewAuditService creates a new audit service instance. Rule changes are also passed to ruleChanges,
+// pass nil to only store them.
+func NewAuditService(auditClient redisClient.RedisAuditClient, ruleChanges RuleChangeEmitter) AuditService {
 	return &AuditServiceRedis{
 		auditClient: auditClient,
+		ruleChanges: ruleChanges,
 	}
 }
 
@@ -76,6 +100,51 @@ func (s *AuditServiceRedis) LogRuleChange(
 		Str("endpoint", endpoint).
 		Msg("audit event logged successfully")
 
+	if s.ruleChanges != nil {
+		s.ruleChanges.EmitRuleChange(auditLog)
+	}
+
+	return nil
+}
+
+// LogLimiterAction logs an admin action performed on a client's limiter state, an access list, a tier or
+// an alert rule
+func (s *AuditServiceRedis) LogLimiterAction(actor, action, endpoint, clientIP, details, ipAddress, userAgent string) error {
+	if !limiterAuditActions[action] {
+		return errors.New("invalid audit action")
+	}
+
+	auditLog := models.AuditLog{
+		ID:        uuid.New().String(),
+		Timestamp: time.Now().Unix(),
+		Actor:     actor,
+		Action:    action,
+		Endpoint:  endpoint,
+		ClientIP:  clientIP,
+		Details:   details,
+		IPAddress: ipAddress,
+		UserAgent: userAgent,
+	}
+
+	err := s.auditClient.AppendAuditLog(auditLog)
+	if err != nil {
+		log.Error().Err(err).
+			Str("action", action).
+			Str("actor", actor).
+			Str("endpoint", endpoint).
+			Str("client_ip", clientIP).
+			Msg("failed to log limiter audit event")
+		return err
+	}
+
+	log.Info().
+		Str("id", auditLog.ID).
+		Str("action", action).
+		Str("actor", actor).
+		Str("endpoint", endpoint).
+		Str("client_ip", clientIP).
+		Msg("limiter audit event logged successfully")
+
 	return nil
 }
 
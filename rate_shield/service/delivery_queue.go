@@ -0,0 +1,156 @@
+package service
+
+import (
+	"context"
+	"math/rand/v2"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// deliveryQueue runs jobs on a pool of workers and retries failed ones with exponential backoff. Jobs are
+// never waited for by the caller, when the queue is full they are dropped and counted instead.
+type deliveryQueue[T any] struct {
+	deliver func(job T) error
+	giveUp  func(job T, err error, attempts int)
+
+	queue       chan T
+	workers     int
+	maxRetries  int
+	baseBackoff time.Duration
+	maxBackoff  time.Duration
+
+	// closeMutex makes sure nothing is sent on the queue after it was closed
+	closeMutex sync.RWMutex
+	closed     bool
+	abort      chan struct{}
+	abortOnce  sync.Once
+	wg         sync.WaitGroup
+
+	enqueued  atomic.Uint64
+	delivered atomic.Uint64
+	retried   atomic.Uint64
+	failed    atomic.Uint64
+	dropped   atomic.Uint64
+}
+
+// newDeliveryQueue starts the workers. giveUp is called when a job failed on every attempt.
+func newDeliveryQueue[T any](workers, queueSize, maxRetries int, baseBackoff, maxBackoff time.Duration, deliver func(job T) error, giveUp func(job T, err error, attempts int)) *deliveryQueue[T] {
+	q := &deliveryQueue[T]{
+		deliver:     deliver,
+		giveUp:      giveUp,
+		queue:       make(chan T, queueSize),
+		workers:     workers,
+		maxRetries:  maxRetries,
+		baseBackoff: baseBackoff,
+		maxBackoff:  maxBackoff,
+		abort:       make(chan struct{}),
+	}
+
+	for i := 0; i < workers; i++ {
+		q.wg.Add(1)
+		go q.work()
+	}
+
+	return q
+}
+
+// enqueue queues a job without blocking. Returns false if it was dropped because the queue is full or
+// shutting down.
+func (q *deliveryQueue[T]) enqueue(job T) bool {
+	q.closeMutex.RLock()
+	defer q.closeMutex.RUnlock()
+
+	if q.closed {
+		q.dropped.Add(1)
+		return false
+	}
+
+	select {
+	case q.queue <- job:
+		q.enqueued.Add(1)
+		return true
+	default:
+		q.dropped.Add(1)
+		return false
+	}
+}
+
+// shutdown stops accepting jobs and waits until the queued ones are done. When ctx ends first, pending
+// retries are abandoned and ctx's error is returned.
+func (q *deliveryQueue[T]) shutdown(ctx context.Context) error {
+	q.closeMutex.Lock()
+	if !q.closed {
+		q.closed = true
+		close(q.queue)
+	}
+	q.closeMutex.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		q.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		q.abortOnce.Do(func() { close(q.abort) })
+		<-done
+		return ctx.Err()
+	}
+}
+
+func (q *deliveryQueue[T]) work() {
+	defer q.wg.Done()
+
+	for job := range q.queue {
+		q.run(job)
+	}
+}
+
+func (q *deliveryQueue[T]) run(job T) {
+	for attempt := 0; ; attempt++ {
+		err := q.deliver(job)
+		if err == nil {
+			q.delivered.Add(1)
+			return
+		}
+
+		if attempt >= q.maxRetries || !q.wait(q.backoff(attempt)) {
+			q.failed.Add(1)
+			if q.giveUp != nil {
+				q.giveUp(job, err, attempt+1)
+			}
+			return
+		}
+
+		q.retried.Add(1)
+	}
+}
+
+// wait sleeps for the backoff, returns false if the shutdown deadline passed in the meantime
+func (q *deliveryQueue[T]) wait(backoff time.Duration) bool {
+	timer := time.NewTimer(backoff)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return true
+	case <-q.abort:
+		return false
+	}
+}
+
+// backoff doubles with every attempt up to the maximum. Half of it is random so jobs that failed
+// together do not all retry at the same moment.
+func (q *deliveryQueue[T]) backoff(attempt int) time.Duration {
+	backoff := q.maxBackoff
+	if attempt < 30 && q.baseBackoff<<attempt < q.maxBackoff {
+		backoff = q.baseBackoff << attempt
+	}
+
+	half := backoff / 2
+	return half + rand.N(half+1)
+}
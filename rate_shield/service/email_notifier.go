@@ -0,0 +1,113 @@
+package service
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/x-sushant-x/RateShield/models"
+)
+
+const (
+	smtpTimeout = 30 * time.Second
+)
+
+// EmailNotifier sends notifications as plain text mails through an SMTP server. SMTP authentication is
+// only used when a username is set, the connection is upgraded to TLS when the server supports STARTTLS.
+type EmailNotifier struct {
+	config models.SMTPConfig
+}
+
+func NewEmailNotifier(config models.SMTPConfig) *EmailNotifier {
+	return &EmailNotifier{
+		config: config,
+	}
+}
+
+func (e *EmailNotifier) Name() string {
+	return models.NotificationChannelEmail
+}
+
+func (e *EmailNotifier) Notify(notification models.Notification) error {
+	if err := e.send(e.buildMessage(notification)); err != nil {
+		return fmt.Errorf("unable to send email: %w", err)
+	}
+
+	return nil
+}
+
+// send delivers a message like smtp.SendMail does, but within smtpTimeout so a hanging mail server can
+// not block a notification worker
+func (e *EmailNotifier) send(message []byte) error {
+	addr := net.JoinHostPort(e.config.Host, strconv.Itoa(e.config.Port))
+
+	conn, err := net.DialTimeout("tcp", addr, smtpTimeout)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if err := conn.SetDeadline(time.Now().Add(smtpTimeout)); err != nil {
+		return err
+	}
+
+	client, err := smtp.NewClient(conn, e.config.Host)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: e.config.Host}); err != nil {
+			return err
+		}
+	}
+
+	if e.config.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", e.config.Username, e.config.Password, e.config.Host)); err != nil {
+			return err
+		}
+	}
+
+	if err := client.Mail(e.config.From); err != nil {
+		return err
+	}
+	for _, to := range e.config.To {
+		if err := client.Rcpt(to); err != nil {
+			return err
+		}
+	}
+
+	writer, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := writer.Write(message); err != nil {
+		return err
+	}
+	if err := writer.Close(); err != nil {
+		return err
+	}
+
+	return client.Quit()
+}
+
+func (e *EmailNotifier) buildMessage(notification models.Notification) []byte {
+	var builder strings.Builder
+
+	builder.WriteString("From: " + e.config.From + "\r\n")
+	builder.WriteString("To: " + strings.Join(e.config.To, ", ") + "\r\n")
+	builder.WriteString(fmt.Sprintf("Subject: [RateShield] [%s] %s\r\n", strings.ToUpper(notification.Severity), notification.Title))
+	builder.WriteString("Date: " + time.Now().Format(time.RFC1123Z) + "\r\n")
+	builder.WriteString("MIME-Version: 1.0\r\n")
+	builder.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	builder.WriteString("\r\n")
+	builder.WriteString(strings.ReplaceAll(formatNotificationText(notification), "\n", "\r\n"))
+	builder.WriteString("\r\n")
+
+	return []byte(builder.String())
+}
@@ -0,0 +1,139 @@
+package service
+
+import (
+	"net"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/x-sushant-x/RateShield/models"
+)
+
+// smtpMail is a mail received by the test SMTP server
+type smtpMail struct {
+	from string
+	to   []string
+	data string
+}
+
+// startTestSMTPServer accepts one plain SMTP session without extensions and sends the mail it received
+func startTestSMTPServer(t *testing.T) (string, int, <-chan smtpMail) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	mails := make(chan smtpMail, 1)
+
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		text := textproto.NewConn(conn)
+		mail := smtpMail{}
+
+		text.PrintfLine("220 localhost ready")
+		for {
+			line, err := text.ReadLine()
+			if err != nil {
+				return
+			}
+
+			command := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+			switch command {
+			case "EHLO", "HELO":
+				text.PrintfLine("250 localhost")
+			case "MAIL":
+				mail.from = strings.Trim(strings.TrimPrefix(line, "MAIL FROM:"), "<>")
+				text.PrintfLine("250 OK")
+			case "RCPT":
+				mail.to = append(mail.to, strings.Trim(strings.TrimPrefix(line, "RCPT TO:"), "<>"))
+				text.PrintfLine("250 OK")
+			case "DATA":
+				text.PrintfLine("354 Send the message")
+				data, err := text.ReadDotBytes()
+				if err != nil {
+					return
+				}
+				mail.data = string(data)
+				text.PrintfLine("250 OK")
+			case "QUIT":
+				text.PrintfLine("221 Bye")
+				mails <- mail
+				return
+			default:
+				text.PrintfLine("502 Not implemented")
+			}
+		}
+	}()
+
+	host, port, err := net.SplitHostPort(listener.Addr().String())
+	require.NoError(t, err)
+	portNumber, err := strconv.Atoi(port)
+	require.NoError(t, err)
+
+	return host, portNumber, mails
+}
+
+func testEmailNotification() models.Notification {
+	return models.Notification{
+		Event:     models.NotificationEventSystemError,
+		Severity:  models.NotificationSeverityCritical,
+		Title:     "Redis unavailable",
+		Text:      "Rate limits fall back to allow on error",
+		Fields:    map[string]string{"Endpoint": "/api/v1/search", "Client": "10.0.0.1"},
+		Timestamp: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+	}
+}
+
+func TestEmailMessage(t *testing.T) {
+	notifier := NewEmailNotifier(models.SMTPConfig{From: "rate-shield@example.com", To: []string{"ops@example.com", "oncall@example.com"}})
+
+	message := string(notifier.buildMessage(testEmailNotification()))
+
+	headers, body, found := strings.Cut(message, "\r\n\r\n")
+	require.True(t, found)
+
+	assert.Contains(t, headers, "From: rate-shield@example.com\r\n")
+	assert.Contains(t, headers, "To: ops@example.com, oncall@example.com\r\n")
+	assert.Contains(t, headers, "Subject: [RateShield] [CRITICAL] Redis unavailable\r\n")
+	assert.Contains(t, headers, "Content-Type: text/plain; charset=UTF-8")
+	assert.Contains(t, headers, "Date: ")
+
+	// Lines end in CRLF and fields are sorted
+	assert.Equal(t, "Redis unavailable\r\nRate limits fall back to allow on error\r\n Client: 10.0.0.1\r\n Endpoint: /api/v1/search\r\n Timestamp: 2024-05-01T12:00:00Z\r\n", body)
+	assert.NotContains(t, strings.ReplaceAll(message, "\r\n", ""), "\n")
+}
+
+func TestEmailNotifier(t *testing.T) {
+	host, port, mails := startTestSMTPServer(t)
+
+	notifier := NewEmailNotifier(models.SMTPConfig{
+		Host: host,
+		Port: port,
+		From: "rate-shield@example.com",
+		To:   []string{"ops@example.com", "oncall@example.com"},
+	})
+	require.NoError(t, notifier.Notify(testEmailNotification()))
+
+	mail := <-mails
+	assert.Equal(t, "rate-shield@example.com", mail.from)
+	assert.Equal(t, []string{"ops@example.com", "oncall@example.com"}, mail.to)
+	assert.Contains(t, mail.data, "Subject: [RateShield] [CRITICAL] Redis unavailable")
+
+	t.Run("unreachable server", func(t *testing.T) {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		addr := listener.Addr().(*net.TCPAddr)
+		listener.Close()
+
+		notifier := NewEmailNotifier(models.SMTPConfig{Host: "127.0.0.1", Port: addr.Port, From: "rate-shield@example.com"})
+		assert.ErrorContains(t, notifier.Notify(testEmailNotification()), "unable to send email")
+	})
+}
@@ -2,50 +2,185 @@ package service
 
 import (
 	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
 	"time"
 
+	"github.com/rs/zerolog/log"
 	"github.com/x-sushant-x/RateShield/models"
 	"github.com/x-sushant-x/RateShield/utils"
 )
 
+const (
+	defaultErrorNotificationCooldown = 30 * time.Second
+
+	// Groups listed one by one in a digest, the rest are summed up
+	maxDigestGroups = 20
+)
+
+var (
+	// Numbers in errors are mostly addresses, ports and durations, errors that only differ in them are grouped
+	errorNumbersPattern = regexp.MustCompile(`\d+`)
+)
+
+// ErrorNotificationSVC notifies about limiter errors. Similar errors on the same endpoint are grouped and
+// notified at most once per cooldown, the ones suppressed in between are sent together in a digest.
 type ErrorNotificationSVC struct {
-	slackSVC            SlackService
-	notificationHistory map[string]time.Time
+	notifier Notifier
+	dedup    NotificationDedupStore
+	// Used when the shared dedup store fails so a Redis outage does not turn into a notification storm
+	fallbackDedup *MemoryNotificationDedup
+	cooldown      time.Duration
 }
 
-func NewErrorNotificationSVC(slackService SlackService) ErrorNotificationSVC {
+// NewErrorNotificationSVC creates the service. A nil dedup store keeps the state in memory and a cooldown
+// of 0 defaults to 30 seconds.
+func NewErrorNotificationSVC(notifier Notifier, dedup NotificationDedupStore, cooldown time.Duration) ErrorNotificationSVC {
+	fallbackDedup := NewMemoryNotificationDedup()
+	if dedup == nil {
+		dedup = fallbackDedup
+	}
+
+	if cooldown <= 0 {
+		cooldown = defaultErrorNotificationCooldown
+	}
+
 	return ErrorNotificationSVC{
-		slackSVC:            slackService,
-		notificationHistory: make(map[string]time.Time),
+		notifier:      notifier,
+		dedup:         dedup,
+		fallbackDedup: fallbackDedup,
+		cooldown:      cooldown,
 	}
 }
 
 func (e *ErrorNotificationSVC) SendErrorNotification(systemError string, timestamp time.Time, ip string, endpoint string, rule models.Rule) {
-	if !e.canSendNotification(ip, endpoint) {
+	group := errorGroupKey(systemError, endpoint)
+
+	if !e.markSent(group) {
+		e.addSuppressed(group)
 		return
 	}
 
 	ruleString, _ := utils.MarshalJSON(rule)
 
-	notificationString := fmt.Sprintf("Error: %s,\n IP: %s,\n Endpoint: %s,\n Rule: %s,\n Timestamp: %s", systemError, ip, endpoint, ruleString, timestamp)
+	notification := models.Notification{
+		Event:    models.NotificationEventSystemError,
+		Severity: models.NotificationSeverityError,
+		Key:      group,
+		Title:    "RateShield Error",
+		Text:     systemError,
+		Fields: map[string]string{
+			"IP":       ip,
+			"Endpoint": endpoint,
+			"Rule":     string(ruleString),
+		},
+		Timestamp: timestamp,
+	}
+
+	e.sendNotification(notification)
+}
 
-	e.sendNotification(notificationString)
-	e.notificationHistory[ip+":"+endpoint] = time.Now()
+// StartDigestJob sends the errors suppressed by the cooldown as one digest every cooldown. With a shared
+// dedup store every instance runs the job, the counts are taken atomically so each is sent once.
+func (e *ErrorNotificationSVC) StartDigestJob() {
+	go func() {
+		ticker := time.NewTicker(e.cooldown)
+		defer ticker.Stop()
 
+		for range ticker.C {
+			e.sendDigest()
+		}
+	}()
 }
 
-func (e *ErrorNotificationSVC) canSendNotification(ip, endpoint string) bool {
-	key := ip + ":" + endpoint
+func (e *ErrorNotificationSVC) sendDigest() {
+	counts, err := e.dedup.TakeSuppressed()
+	if err != nil {
+		log.Warn().Err(err).Msg("unable to take suppressed error notifications")
+		counts = map[string]int64{}
+	}
 
-	lastNotifiedTime, ok := e.notificationHistory[key]
-	if !ok {
-		return true
+	// Counts that went to the fallback while the shared store was failing
+	if e.dedup != e.fallbackDedup {
+		fallbackCounts, _ := e.fallbackDedup.TakeSuppressed()
+		for group, count := range fallbackCounts {
+			counts[group] += count
+		}
 	}
 
-	sinceTime := time.Since(lastNotifiedTime)
-	return sinceTime.Seconds() >= 30
+	if len(counts) == 0 {
+		return
+	}
+
+	notification := buildErrorDigest(counts, e.cooldown, time.Now())
+	e.sendNotification(notification)
 }
 
-func (e *ErrorNotificationSVC) sendNotification(notification string) {
-	e.slackSVC.SendSlackMessage(notification)
+// buildErrorDigest lists the groups with the most suppressed errors first
+func buildErrorDigest(counts map[string]int64, cooldown time.Duration, now time.Time) models.Notification {
+	groups := make([]string, 0, len(counts))
+	var total int64
+	for group, count := range counts {
+		groups = append(groups, group)
+		total += count
+	}
+
+	sort.Slice(groups, func(i, j int) bool {
+		if counts[groups[i]] != counts[groups[j]] {
+			return counts[groups[i]] > counts[groups[j]]
+		}
+		return groups[i] < groups[j]
+	})
+
+	fields := make(map[string]string, min(len(groups), maxDigestGroups)+1)
+	var others int64
+	for i, group := range groups {
+		if i < maxDigestGroups {
+			fields[group] = strconv.FormatInt(counts[group], 10)
+		} else {
+			others += counts[group]
+		}
+	}
+	if others > 0 {
+		fields["Other Errors"] = strconv.FormatInt(others, 10)
+	}
+
+	return models.Notification{
+		Event:     models.NotificationEventErrorDigest,
+		Severity:  models.NotificationSeverityError,
+		Key:       "digest",
+		Title:     "RateShield Error Digest",
+		Text:      fmt.Sprintf("%d similar errors were not notified one by one in the last %s", total, cooldown),
+		Fields:    fields,
+		Timestamp: now,
+	}
+}
+
+// errorGroupKey identifies similar errors on the same endpoint
+func errorGroupKey(systemError, endpoint string) string {
+	return errorNumbersPattern.ReplaceAllString(systemError, "#") + " on " + endpoint
+}
+
+func (e *ErrorNotificationSVC) markSent(group string) bool {
+	sent, err := e.dedup.MarkSent(group, e.cooldown)
+	if err != nil {
+		log.Warn().Err(err).Msg("unable to check notification cooldown, using the local one")
+		sent, _ = e.fallbackDedup.MarkSent(group, e.cooldown)
+	}
+	return sent
+}
+
+func (e *ErrorNotificationSVC) addSuppressed(group string) {
+	if err := e.dedup.AddSuppressed(group); err != nil {
+		e.fallbackDedup.AddSuppressed(group)
+	}
+}
+
+func (e *ErrorNotificationSVC) sendNotification(notification models.Notification) {
+	if e.notifier == nil {
+		return
+	}
+
+	e.notifier.Notify(notification)
 }
@@ -0,0 +1,165 @@
+package service
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/x-sushant-x/RateShield/models"
+)
+
+type recordingNotifier struct {
+	mutex         sync.Mutex
+	notifications []models.Notification
+}
+
+func (r *recordingNotifier) Name() string {
+	return "recording"
+}
+
+func (r *recordingNotifier) Notify(notification models.Notification) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.notifications = append(r.notifications, notification)
+	return nil
+}
+
+func (r *recordingNotifier) sent() []models.Notification {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	return append([]models.Notification{}, r.notifications...)
+}
+
+type failingDedupStore struct{}
+
+var errStoreDown = errors.New("store down")
+
+func (failingDedupStore) MarkSent(key string, cooldown time.Duration) (bool, error) {
+	return false, errStoreDown
+}
+
+func (failingDedupStore) AddSuppressed(key string) error {
+	return errStoreDown
+}
+
+func (failingDedupStore) TakeSuppressed() (map[string]int64, error) {
+	return nil, errStoreDown
+}
+
+func sendConcurrently(svc *ErrorNotificationSVC, count int, systemError string) {
+	var wg sync.WaitGroup
+	for i := 0; i < count; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			svc.SendErrorNotification(systemError, time.Now(), "10.0.0.1", "/api/v1/search", models.Rule{})
+		}()
+	}
+	wg.Wait()
+}
+
+func TestErrorNotificationDedup(t *testing.T) {
+	notifier := &recordingNotifier{}
+	svc := NewErrorNotificationSVC(notifier, nil, time.Minute)
+
+	sendConcurrently(&svc, 50, "dial tcp 10.0.0.7:7000: connection refused")
+	sendConcurrently(&svc, 10, "dial tcp 10.0.0.8:7001: connection refused")
+	sendConcurrently(&svc, 5, "redis: nil")
+
+	sent := notifier.sent()
+	assert.Len(t, sent, 2)
+
+	svc.sendDigest()
+	sent = notifier.sent()
+	assert.Len(t, sent, 3)
+
+	digest := sent[2]
+	assert.Equal(t, models.NotificationEventErrorDigest, digest.Event)
+	assert.Equal(t, map[string]string{
+		"dial tcp #.#.#.#:#: connection refused on /api/v1/search": "59",
+		"redis: nil on /api/v1/search":                             "4",
+	}, digest.Fields)
+
+	// Nothing was suppressed since the last digest
+	svc.sendDigest()
+	assert.Len(t, notifier.sent(), 3)
+}
+
+func TestErrorNotificationSharedDedup(t *testing.T) {
+	// Two instances sharing a store, as with NOTIFICATION_DEDUP=redis
+	store := NewMemoryNotificationDedup()
+	notifier := &recordingNotifier{}
+	first := NewErrorNotificationSVC(notifier, store, time.Minute)
+	second := NewErrorNotificationSVC(notifier, store, time.Minute)
+
+	var wg sync.WaitGroup
+	for _, svc := range []*ErrorNotificationSVC{&first, &second} {
+		wg.Add(1)
+		go func(svc *ErrorNotificationSVC) {
+			defer wg.Done()
+			sendConcurrently(svc, 20, "connection refused")
+		}(svc)
+	}
+	wg.Wait()
+
+	assert.Len(t, notifier.sent(), 1)
+
+	// Either instance takes the counts of both, the other one has nothing left to send
+	first.sendDigest()
+	second.sendDigest()
+	sent := notifier.sent()
+	assert.Len(t, sent, 2)
+	assert.Equal(t, "39", sent[1].Fields["connection refused on /api/v1/search"])
+}
+
+func TestErrorNotificationFallbackDedup(t *testing.T) {
+	notifier := &recordingNotifier{}
+	svc := NewErrorNotificationSVC(notifier, failingDedupStore{}, time.Minute)
+
+	sendConcurrently(&svc, 10, "connection refused")
+	assert.Len(t, notifier.sent(), 1)
+
+	svc.sendDigest()
+	sent := notifier.sent()
+	assert.Len(t, sent, 2)
+	assert.Equal(t, "9", sent[1].Fields["connection refused on /api/v1/search"])
+}
+
+func TestMemoryNotificationDedup(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	store := NewMemoryNotificationDedup()
+	store.now = func() time.Time { return now }
+
+	sent, _ := store.MarkSent("error", 30*time.Second)
+	assert.True(t, sent)
+	sent, _ = store.MarkSent("error", 30*time.Second)
+	assert.False(t, sent)
+	sent, _ = store.MarkSent("other", 30*time.Second)
+	assert.True(t, sent)
+
+	now = now.Add(30 * time.Second)
+	sent, _ = store.MarkSent("error", 30*time.Second)
+	assert.True(t, sent)
+
+	// Taking the counts drops cooldowns that are over
+	now = now.Add(time.Second)
+	counts, _ := store.TakeSuppressed()
+	assert.Empty(t, counts)
+	assert.Len(t, store.sentUntil, 1)
+}
+
+func TestBuildErrorDigest(t *testing.T) {
+	counts := map[string]int64{}
+	for i := 0; i < maxDigestGroups+3; i++ {
+		counts[string(rune('a'+i))] = int64(i + 1)
+	}
+
+	digest := buildErrorDigest(counts, 30*time.Second, time.Unix(1700000000, 0))
+	assert.Len(t, digest.Fields, maxDigestGroups+1)
+	assert.Equal(t, "6", digest.Fields["Other Errors"]) // a, b and c have the fewest errors
+	assert.Equal(t, "276 similar errors were not notified one by one in the last 30s", digest.Text)
+}
@@ -0,0 +1,73 @@
+package service
+
+import (
+	"sync"
+	"time"
+)
+
+// NotificationDedupStore remembers which notifications were sent recently and counts the ones suppressed
+// since the last digest. The Redis client in redisClient.RedisNotificationDedupClient shares the state
+// between instances, MemoryNotificationDedup keeps it per instance.
+type NotificationDedupStore interface {
+	// MarkSent returns false if a notification with the key was already sent within the cooldown,
+	// otherwise it marks the key as sent and returns true
+	MarkSent(key string, cooldown time.Duration) (bool, error)
+	AddSuppressed(key string) error
+	TakeSuppressed() (map[string]int64, error)
+}
+
+// MemoryNotificationDedup is a NotificationDedupStore for a single instance, safe for concurrent use
+type MemoryNotificationDedup struct {
+	mutex      sync.Mutex
+	sentUntil  map[string]time.Time
+	suppressed map[string]int64
+	now        func() time.Time
+}
+
+func NewMemoryNotificationDedup() *MemoryNotificationDedup {
+	return &MemoryNotificationDedup{
+		sentUntil:  make(map[string]time.Time),
+		suppressed: make(map[string]int64),
+		now:        time.Now,
+	}
+}
+
+func (m *MemoryNotificationDedup) MarkSent(key string, cooldown time.Duration) (bool, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	now := m.now()
+	if until, ok := m.sentUntil[key]; ok && now.Before(until) {
+		return false, nil
+	}
+
+	m.sentUntil[key] = now.Add(cooldown)
+	return true, nil
+}
+
+func (m *MemoryNotificationDedup) AddSuppressed(key string) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.suppressed[key]++
+	return nil
+}
+
+// TakeSuppressed returns and clears the suppressed counts. Cooldowns that are over are dropped as well so
+// the store does not grow with every key ever seen.
+func (m *MemoryNotificationDedup) TakeSuppressed() (map[string]int64, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	counts := m.suppressed
+	m.suppressed = make(map[string]int64)
+
+	now := m.now()
+	for key, until := range m.sentUntil {
+		if !now.Before(until) {
+			delete(m.sentUntil, key)
+		}
+	}
+
+	return counts, nil
+}
@@ -0,0 +1,109 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/x-sushant-x/RateShield/models"
+)
+
+const (
+	defaultNotificationWorkers    = 4
+	defaultNotificationQueueSize  = 1000
+	defaultNotificationMaxRetries = 3
+
+	notificationBaseBackoff = time.Second
+	notificationMaxBackoff  = 30 * time.Second
+)
+
+type notificationJob struct {
+	notifier     Notifier
+	notification models.Notification
+}
+
+// NotificationDispatcher delivers notifications in the background so callers on the request path never
+// wait for a notification channel. Every channel gets its own job, a failing channel is retried with
+// exponential backoff without sending the notification again to the channels that got it. When the
+// queue is full new notifications are dropped and counted instead of blocking.
+type NotificationDispatcher struct {
+	notifier *MultiNotifier
+	queue    *deliveryQueue[notificationJob]
+}
+
+// NewNotificationDispatcher creates a dispatcher for the channels of notifier and starts its workers.
+// Workers and queue sizes of 0 or less use the defaults of 4 workers and 1000 queued notifications, a
+// negative maxRetries uses the default of 3 retries and 0 turns retries off.
+func NewNotificationDispatcher(notifier *MultiNotifier, workers, queueSize, maxRetries int) *NotificationDispatcher {
+	if workers <= 0 {
+		workers = defaultNotificationWorkers
+	}
+	if queueSize <= 0 {
+		queueSize = defaultNotificationQueueSize
+	}
+	if maxRetries < 0 {
+		maxRetries = defaultNotificationMaxRetries
+	}
+
+	return &NotificationDispatcher{
+		notifier: notifier,
+		queue: newDeliveryQueue(workers, queueSize, maxRetries, notificationBaseBackoff, notificationMaxBackoff,
+			func(job notificationJob) error {
+				return job.notifier.Notify(job.notification)
+			},
+			func(job notificationJob, err error, attempts int) {
+				log.Warn().Err(err).Str("channel", job.notifier.Name()).Str("event", job.notification.Event).Int("attempts", attempts).Msg("giving up on notification")
+			}),
+	}
+}
+
+func (d *NotificationDispatcher) Name() string {
+	return d.notifier.Name()
+}
+
+// Notify queues the notification for every channel
+func (d *NotificationDispatcher) Notify(notification models.Notification) error {
+	return d.NotifyChannels(notification, nil)
+}
+
+// NotifyChannels queues the notification for the named channels, every channel when none are named. It
+// never blocks and never fails, notifications that do not fit in the queue are dropped.
+func (d *NotificationDispatcher) NotifyChannels(notification models.Notification, channels []string) error {
+	notifiers := d.notifier.Select(channels)
+	if len(notifiers) == 0 && len(channels) > 0 {
+		log.Warn().Strs("channels", channels).Str("event", notification.Event).Msg("none of the notification channels are configured")
+	}
+
+	for _, notifier := range notifiers {
+		if d.queue.enqueue(notificationJob{notifier: notifier, notification: notification}) {
+			continue
+		}
+
+		// Logging every drop would flood the log exactly when notifications are piling up
+		if dropped := d.queue.dropped.Load(); dropped == 1 || dropped%100 == 0 {
+			log.Warn().Str("channel", notifier.Name()).Str("event", notification.Event).Uint64("dropped", dropped).Msg("notification queue is full or shutting down, dropping notification")
+		}
+	}
+
+	return nil
+}
+
+// Stats returns the delivery counters
+func (d *NotificationDispatcher) Stats() models.NotificationStats {
+	return models.NotificationStats{
+		Workers:       d.queue.workers,
+		QueueCapacity: cap(d.queue.queue),
+		Queued:        len(d.queue.queue),
+		Enqueued:      d.queue.enqueued.Load(),
+		Delivered:     d.queue.delivered.Load(),
+		Retried:       d.queue.retried.Load(),
+		Failed:        d.queue.failed.Load(),
+		Dropped:       d.queue.dropped.Load(),
+	}
+}
+
+// Shutdown stops accepting notifications and waits until the queued ones are delivered. When ctx ends
+// first, pending retries are abandoned and ctx's error is returned.
+func (d *NotificationDispatcher) Shutdown(ctx context.Context) error {
+	return d.queue.shutdown(ctx)
+}
@@ -0,0 +1,143 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/x-sushant-x/RateShield/models"
+)
+
+// flakyNotifier fails the first failures calls and blocks every call until release is closed, if set
+type flakyNotifier struct {
+	name     string
+	failures int32
+	calls    atomic.Int32
+	release  chan struct{}
+
+	recordingNotifier
+}
+
+func (f *flakyNotifier) Name() string {
+	return f.name
+}
+
+func (f *flakyNotifier) Notify(notification models.Notification) error {
+	if f.release != nil {
+		<-f.release
+	}
+
+	if f.calls.Add(1) <= f.failures {
+		return errors.New("channel unavailable")
+	}
+
+	return f.recordingNotifier.Notify(notification)
+}
+
+func newTestDispatcher(workers, queueSize, maxRetries int, notifiers ...Notifier) *NotificationDispatcher {
+	d := NewNotificationDispatcher(NewMultiNotifier(notifiers...), workers, queueSize, maxRetries)
+	d.queue.baseBackoff = time.Millisecond
+	d.queue.maxBackoff = 4 * time.Millisecond
+	return d
+}
+
+func TestNotificationDispatcherRetries(t *testing.T) {
+	slack := &flakyNotifier{name: models.NotificationChannelSlack, failures: 2}
+	webhook := &flakyNotifier{name: models.NotificationChannelWebhook}
+	email := &flakyNotifier{name: models.NotificationChannelEmail, failures: 10}
+	d := newTestDispatcher(2, 10, 3, slack, webhook, email)
+
+	assert.NoError(t, d.Notify(models.Notification{Event: models.NotificationEventSystemError}))
+	assert.NoError(t, d.Shutdown(context.Background()))
+
+	// The webhook got it once although the other channels were retried
+	assert.Len(t, slack.sent(), 1)
+	assert.Len(t, webhook.sent(), 1)
+	assert.Empty(t, email.sent())
+	assert.Equal(t, int32(4), email.calls.Load())
+
+	stats := d.Stats()
+	assert.Equal(t, uint64(3), stats.Enqueued)
+	assert.Equal(t, uint64(2), stats.Delivered)
+	assert.Equal(t, uint64(5), stats.Retried)
+	assert.Equal(t, uint64(1), stats.Failed)
+	assert.Equal(t, uint64(0), stats.Dropped)
+}
+
+func TestNotificationDispatcherChannels(t *testing.T) {
+	slack := &flakyNotifier{name: models.NotificationChannelSlack}
+	pagerDuty := &flakyNotifier{name: models.NotificationChannelPagerDuty}
+	d := newTestDispatcher(1, 10, 0, slack, pagerDuty)
+
+	d.NotifyChannels(models.Notification{Event: models.AlertTypeDenyRate}, []string{models.NotificationChannelPagerDuty, models.NotificationChannelEmail})
+	d.NotifyChannels(models.Notification{Event: models.AlertTypeDenyRate}, nil)
+	assert.NoError(t, d.Shutdown(context.Background()))
+
+	assert.Len(t, slack.sent(), 1)
+	assert.Len(t, pagerDuty.sent(), 2)
+}
+
+func TestNotificationDispatcherDropsOnOverflow(t *testing.T) {
+	release := make(chan struct{})
+	slack := &flakyNotifier{name: models.NotificationChannelSlack, release: release}
+	d := newTestDispatcher(1, 2, 0, slack)
+
+	// The worker holds one notification and the queue two more, the callers never block
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			d.Notify(models.Notification{Event: models.NotificationEventSystemError})
+		}()
+	}
+	wg.Wait()
+
+	stats := d.Stats()
+	assert.Equal(t, uint64(10), stats.Enqueued+stats.Dropped)
+	assert.GreaterOrEqual(t, stats.Dropped, uint64(7))
+
+	close(release)
+	assert.NoError(t, d.Shutdown(context.Background()))
+	assert.Equal(t, stats.Enqueued, d.Stats().Delivered)
+
+	// Nothing is accepted after the shutdown
+	d.Notify(models.Notification{Event: models.NotificationEventSystemError})
+	assert.Equal(t, stats.Dropped+1, d.Stats().Dropped)
+}
+
+func TestNotificationDispatcherShutdownDeadline(t *testing.T) {
+	slack := &flakyNotifier{name: models.NotificationChannelSlack, failures: 100}
+	d := newTestDispatcher(1, 10, 100, slack)
+	d.queue.baseBackoff = time.Hour
+	d.queue.maxBackoff = time.Hour
+
+	d.Notify(models.Notification{Event: models.NotificationEventSystemError})
+	d.Notify(models.Notification{Event: models.NotificationEventSystemError})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	// Waiting for the retries would take hours, the deadline abandons them
+	assert.ErrorIs(t, d.Shutdown(ctx), context.DeadlineExceeded)
+	assert.Equal(t, uint64(2), d.Stats().Failed)
+	assert.NoError(t, d.Shutdown(context.Background()))
+}
+
+func TestNotificationDispatcherBackoff(t *testing.T) {
+	d := newTestDispatcher(1, 1, 0)
+	d.queue.baseBackoff = time.Second
+	d.queue.maxBackoff = 30 * time.Second
+	defer d.Shutdown(context.Background())
+
+	for attempt, max := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second, 16 * time.Second, 30 * time.Second, 30 * time.Second} {
+		backoff := d.queue.backoff(attempt)
+		assert.GreaterOrEqual(t, backoff, max/2)
+		assert.LessOrEqual(t, backoff, max)
+	}
+	assert.LessOrEqual(t, d.queue.backoff(100), 30*time.Second)
+}
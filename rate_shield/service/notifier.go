@@ -0,0 +1,131 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/x-sushant-x/RateShield/models"
+)
+
+// notifierHTTPClient is shared by the channels that deliver over HTTP so a slow receiver can not block
+// the caller forever
+var notifierHTTPClient = &http.Client{Timeout: 10 * time.Second}
+
+// Notifier delivers notifications to one channel such as Slack, a webhook, email or PagerDuty
+type Notifier interface {
+	Name() string
+	Notify(notification models.Notification) error
+}
+
+// ChannelNotifier delivers notifications to a choice of channels, every channel when none are named
+type ChannelNotifier interface {
+	Notifier
+	NotifyChannels(notification models.Notification, channels []string) error
+}
+
+// MultiNotifier sends every notification to all configured channels. Without any channel notifications
+// are dropped, so RateShield runs fine with no notifier set up.
+type MultiNotifier struct {
+	notifiers []Notifier
+}
+
+func NewMultiNotifier(notifiers ...Notifier) *MultiNotifier {
+	return &MultiNotifier{
+		notifiers: notifiers,
+	}
+}
+
+func (m *MultiNotifier) Name() string {
+	return "multi"
+}
+
+// Channels returns the names of the configured channels
+func (m *MultiNotifier) Channels() []string {
+	names := make([]string, 0, len(m.notifiers))
+	for _, notifier := range m.notifiers {
+		names = append(names, notifier.Name())
+	}
+
+	return names
+}
+
+func (m *MultiNotifier) Enabled() bool {
+	return len(m.notifiers) > 0
+}
+
+// Notify sends the notification to every channel. A failing channel does not stop the others, the
+// errors of all failed channels are returned together.
+func (m *MultiNotifier) Notify(notification models.Notification) error {
+	return m.notify(m.notifiers, notification)
+}
+
+// NotifyChannels sends the notification to the named channels only, every channel when none are named.
+// Named channels that are not configured are skipped.
+func (m *MultiNotifier) NotifyChannels(notification models.Notification, channels []string) error {
+	notifiers := m.Select(channels)
+
+	if len(notifiers) == 0 && len(channels) > 0 {
+		log.Warn().Strs("channels", channels).Str("event", notification.Event).Msg("none of the notification channels are configured")
+	}
+
+	return m.notify(notifiers, notification)
+}
+
+// Select returns the configured notifiers of the named channels, every notifier when none are named
+func (m *MultiNotifier) Select(channels []string) []Notifier {
+	if len(channels) == 0 {
+		return m.notifiers
+	}
+
+	notifiers := []Notifier{}
+	for _, notifier := range m.notifiers {
+		if slices.Contains(channels, notifier.Name()) {
+			notifiers = append(notifiers, notifier)
+		}
+	}
+
+	return notifiers
+}
+
+func (m *MultiNotifier) notify(notifiers []Notifier, notification models.Notification) error {
+	var errs []error
+
+	for _, notifier := range notifiers {
+		if err := notifier.Notify(notification); err != nil {
+			log.Warn().Err(err).Str("channel", notifier.Name()).Str("event", notification.Event).Msg("failed to send notification")
+			errs = append(errs, fmt.Errorf("%s: %w", notifier.Name(), err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// formatNotificationText renders a notification as plain text for chat and email channels
+func formatNotificationText(notification models.Notification) string {
+	var builder strings.Builder
+
+	builder.WriteString(notification.Title)
+	if notification.Text != "" {
+		builder.WriteString("\n" + notification.Text)
+	}
+
+	keys := make([]string, 0, len(notification.Fields))
+	for key := range notification.Fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		builder.WriteString(fmt.Sprintf("\n %s: %s", key, notification.Fields[key]))
+	}
+
+	builder.WriteString("\n Timestamp: " + notification.Timestamp.UTC().Format(time.RFC3339))
+
+	return builder.String()
+}
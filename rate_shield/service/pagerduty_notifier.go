@@ -0,0 +1,111 @@
+package service
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/x-sushant-x/RateShield/models"
+	"github.com/x-sushant-x/RateShield/utils"
+)
+
+const (
+	PAGERDUTY_EVENTS_ENDPOINT = "https://events.pagerduty.com/v2/enqueue"
+)
+
+// PagerDutyNotifier triggers events through the PagerDuty Events API v2. Any service accepting the same
+// format can be used by changing the URL. Notifications with the same event and key share a dedup key so
+// repeats are grouped into one incident.
+type PagerDutyNotifier struct {
+	routingKey string
+	url        string
+	source     string
+}
+
+func NewPagerDutyNotifier(routingKey, url string) *PagerDutyNotifier {
+	if url == "" {
+		url = PAGERDUTY_EVENTS_ENDPOINT
+	}
+
+	source, err := os.Hostname()
+	if err != nil || source == "" {
+		source = "rate-shield"
+	}
+
+	return &PagerDutyNotifier{
+		routingKey: routingKey,
+		url:        url,
+		source:     source,
+	}
+}
+
+func (p *PagerDutyNotifier) Name() string {
+	return models.NotificationChannelPagerDuty
+}
+
+func (p *PagerDutyNotifier) Notify(notification models.Notification) error {
+	body, err := utils.MarshalJSON(p.buildEvent(notification))
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, p.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := notifierHTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("received non-2xx response from PagerDuty: %s", resp.Status)
+	}
+
+	return nil
+}
+
+func (p *PagerDutyNotifier) buildEvent(notification models.Notification) models.PagerDutyEvent {
+	summary := notification.Title
+	if notification.Text != "" {
+		summary += ": " + notification.Text
+	}
+
+	// The Events API rejects summaries longer than 1024 characters
+	if len(summary) > 1024 {
+		summary = summary[:1021] + "..."
+	}
+
+	event := models.PagerDutyEvent{
+		RoutingKey:  p.routingKey,
+		EventAction: "trigger",
+		Payload: models.PagerDutyPayload{
+			Summary:       summary,
+			Source:        p.source,
+			Severity:      pagerDutySeverity(notification.Severity),
+			Timestamp:     notification.Timestamp.UTC().Format(time.RFC3339),
+			Class:         notification.Event,
+			CustomDetails: notification.Fields,
+		},
+	}
+
+	if notification.Key != "" {
+		event.DedupKey = notification.Event + ":" + notification.Key
+	}
+
+	return event
+}
+
+func pagerDutySeverity(severity string) string {
+	switch severity {
+	case models.NotificationSeverityCritical, models.NotificationSeverityError, models.NotificationSeverityWarning, models.NotificationSeverityInfo:
+		return severity
+	}
+
+	return models.NotificationSeverityError
+}
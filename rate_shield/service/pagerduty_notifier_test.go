@@ -0,0 +1,96 @@
+package service
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/x-sushant-x/RateShield/models"
+)
+
+func TestPagerDutyNotifier(t *testing.T) {
+	events := make(chan models.PagerDutyEvent, 1)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+
+		var event models.PagerDutyEvent
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&event))
+		events <- event
+
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	notifier := NewPagerDutyNotifier("routing-key", server.URL)
+	timestamp := time.Date(2024, 5, 1, 12, 0, 0, 0, time.FixedZone("CEST", 2*60*60))
+
+	err := notifier.Notify(models.Notification{
+		Event:     models.NotificationEventQuotaThreshold,
+		Severity:  models.NotificationSeverityWarning,
+		Key:       "10.0.0.1:/api/v1/search",
+		Title:     "Quota almost used up",
+		Text:      "90% of the quota is used",
+		Fields:    map[string]string{"Endpoint": "/api/v1/search"},
+		Timestamp: timestamp,
+	})
+	require.NoError(t, err)
+
+	event := <-events
+	assert.Equal(t, "routing-key", event.RoutingKey)
+	assert.Equal(t, "trigger", event.EventAction)
+	assert.Equal(t, "QUOTA_THRESHOLD:10.0.0.1:/api/v1/search", event.DedupKey)
+	assert.Equal(t, "Quota almost used up: 90% of the quota is used", event.Payload.Summary)
+	assert.Equal(t, models.NotificationSeverityWarning, event.Payload.Severity)
+	assert.Equal(t, "2024-05-01T10:00:00Z", event.Payload.Timestamp)
+	assert.Equal(t, models.NotificationEventQuotaThreshold, event.Payload.Class)
+	assert.Equal(t, map[string]string{"Endpoint": "/api/v1/search"}, event.Payload.CustomDetails)
+	assert.NotEmpty(t, event.Payload.Source)
+
+	t.Run("non-2xx response", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadRequest)
+		}))
+		defer server.Close()
+
+		err := NewPagerDutyNotifier("routing-key", server.URL).Notify(models.Notification{Title: "test"})
+		assert.ErrorContains(t, err, "400")
+	})
+}
+
+func TestPagerDutyEvent(t *testing.T) {
+	notifier := NewPagerDutyNotifier("routing-key", "")
+	assert.Equal(t, PAGERDUTY_EVENTS_ENDPOINT, notifier.url)
+
+	t.Run("without key", func(t *testing.T) {
+		event := notifier.buildEvent(models.Notification{Event: models.NotificationEventSystemError, Title: "Redis unavailable"})
+		assert.Empty(t, event.DedupKey, "only notifications with a key are grouped")
+		assert.Equal(t, "Redis unavailable", event.Payload.Summary)
+	})
+
+	t.Run("same event and key share a dedup key", func(t *testing.T) {
+		first := notifier.buildEvent(models.Notification{Event: models.NotificationEventSystemError, Key: "redis", Title: "first"})
+		second := notifier.buildEvent(models.Notification{Event: models.NotificationEventSystemError, Key: "redis", Title: "second"})
+		other := notifier.buildEvent(models.Notification{Event: models.NotificationEventErrorDigest, Key: "redis", Title: "other"})
+
+		assert.Equal(t, first.DedupKey, second.DedupKey)
+		assert.NotEqual(t, first.DedupKey, other.DedupKey)
+	})
+
+	t.Run("unknown severity", func(t *testing.T) {
+		event := notifier.buildEvent(models.Notification{Severity: "fatal", Title: "test"})
+		assert.Equal(t, models.NotificationSeverityError, event.Payload.Severity)
+	})
+
+	t.Run("long summary", func(t *testing.T) {
+		event := notifier.buildEvent(models.Notification{Title: "test", Text: strings.Repeat("a", 2000)})
+		assert.Len(t, event.Payload.Summary, 1024)
+		assert.True(t, strings.HasSuffix(event.Payload.Summary, "..."))
+	})
+}
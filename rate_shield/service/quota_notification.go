@@ -0,0 +1,51 @@
+package service
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/x-sushant-x/RateShield/models"
+)
+
+type QuotaNotificationSVC struct {
+	notifier Notifier
+}
+
+func NewQuotaNotificationSVC(notifier Notifier) *QuotaNotificationSVC {
+	return &QuotaNotificationSVC{
+		notifier: notifier,
+	}
+}
+
+// SendThresholdNotification reports that a client used threshold percent of its quota. The notifier is the
+// notification dispatcher, so the request that crossed the threshold is not held up.
+func (q *QuotaNotificationSVC) SendThresholdNotification(usage models.QuotaUsage, threshold int) {
+	log.Info().Str("identity", usage.Identity).Str("endpoint", usage.Endpoint).Msgf("client used %d%% of its quota", threshold)
+
+	if q.notifier == nil {
+		return
+	}
+
+	severity := models.NotificationSeverityWarning
+	if threshold >= 100 {
+		severity = models.NotificationSeverityError
+	}
+
+	notification := models.Notification{
+		Event:    models.NotificationEventQuotaThreshold,
+		Severity: severity,
+		Key:      usage.Identity + ":" + usage.Endpoint + ":" + strconv.Itoa(threshold),
+		Title:    fmt.Sprintf("Quota %d%% used", threshold),
+		Text:     fmt.Sprintf("Used %d of %d requests this %s", usage.Used, usage.Limit, usage.Period),
+		Fields: map[string]string{
+			"Client":    usage.Identity,
+			"Endpoint":  usage.Endpoint,
+			"Resets At": time.Unix(usage.ResetsAt, 0).UTC().Format(time.RFC3339),
+		},
+		Timestamp: time.Now(),
+	}
+
+	q.notifier.Notify(notification)
+}
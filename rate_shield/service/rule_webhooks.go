@@ -0,0 +1,363 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	"github.com/x-sushant-x/RateShield/models"
+	redisClient "github.com/x-sushant-x/RateShield/redis"
+	"github.com/x-sushant-x/RateShield/utils"
+)
+
+const (
+	WebhookDeliveryHeader = "X-RateShield-Delivery"
+
+	ruleWebhookWorkers           = 2
+	ruleWebhookQueueSize         = 1000
+	defaultRuleWebhookMaxRetries = 5
+
+	ruleWebhookBaseBackoff = time.Second
+	ruleWebhookMaxBackoff  = time.Minute
+
+	defaultRuleWebhookDeliveryLimit = 50
+)
+
+var (
+	ErrNoRuleWebhookName   = errors.New("name must not be empty")
+	ErrNoDeliveryID        = errors.New("id must not be empty")
+	ErrRuleWebhookNotFound = errors.New("rule webhook not found")
+)
+
+var ruleChangeEvents = map[string]string{
+	models.AuditActionCreate: models.RuleEventCreated,
+	models.AuditActionUpdate: models.RuleEventUpdated,
+	models.AuditActionDelete: models.RuleEventDeleted,
+}
+
+// RuleWebhookService manages the webhooks told about rule changes and the log of their deliveries
+type RuleWebhookService interface {
+	ListRuleWebhooks() ([]models.RuleWebhook, error)
+	SaveRuleWebhook(webhook models.RuleWebhook, actor, ipAddress, userAgent string) (models.RuleWebhook, error)
+	DeleteRuleWebhook(name, actor, ipAddress, userAgent string) (bool, error)
+	ListDeliveries(webhook, status string, limit int) ([]models.RuleWebhookDelivery, error)
+	Redeliver(id string) (models.RuleWebhookDelivery, bool, error)
+}
+
+type ruleWebhookJob struct {
+	webhook  models.RuleWebhook
+	delivery models.RuleWebhookDelivery
+}
+
+// RuleWebhookServiceRedis posts every rule change recorded in the audit log to the rule webhooks. Events
+// are delivered in the background and retried with exponential backoff, every attempt is recorded in the
+// delivery log.
+type RuleWebhookServiceRedis struct {
+	redisClient redisClient.RedisRuleWebhookClient
+	auditSvc    AuditService
+	queue       *deliveryQueue[*ruleWebhookJob]
+}
+
+// NewRuleWebhookService creates the service and starts its workers. A negative maxRetries retries failed
+// deliveries 5 times.
+func NewRuleWebhookService(client redisClient.RedisRuleWebhookClient, auditSvc AuditService, maxRetries int) *RuleWebhookServiceRedis {
+	if maxRetries < 0 {
+		maxRetries = defaultRuleWebhookMaxRetries
+	}
+
+	s := &RuleWebhookServiceRedis{
+		redisClient: client,
+		auditSvc:    auditSvc,
+	}
+	s.queue = newDeliveryQueue(ruleWebhookWorkers, ruleWebhookQueueSize, maxRetries, ruleWebhookBaseBackoff, ruleWebhookMaxBackoff, s.deliver, s.giveUp)
+
+	return s
+}
+
+// ListRuleWebhooks returns every rule webhook without its secret
+func (s *RuleWebhookServiceRedis) ListRuleWebhooks() ([]models.RuleWebhook, error) {
+	webhooks, err := s.redisClient.GetRuleWebhooks()
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range webhooks {
+		webhooks[i].Secret = ""
+	}
+	return webhooks, nil
+}
+
+// SaveRuleWebhook creates or replaces the rule webhook with the same name. Replacing a webhook without a
+// secret keeps its current secret.
+func (s *RuleWebhookServiceRedis) SaveRuleWebhook(webhook models.RuleWebhook, actor, ipAddress, userAgent string) (models.RuleWebhook, error) {
+	if err := utils.ValidateRuleWebhook(webhook); err != nil {
+		return models.RuleWebhook{}, err
+	}
+
+	existing, existed, err := s.redisClient.GetRuleWebhook(webhook.Name)
+	if err != nil {
+		log.Err(err).Msg("unable to get rule webhook")
+		return models.RuleWebhook{}, err
+	}
+
+	if existed && webhook.Secret == "" {
+		webhook.Secret = existing.Secret
+	}
+
+	if err := s.redisClient.SetRuleWebhook(webhook); err != nil {
+		log.Err(err).Msg("unable to save rule webhook")
+		return models.RuleWebhook{}, err
+	}
+
+	details := "created rule webhook " + webhook.Name + " to " + webhook.URL
+	if existed {
+		details = "updated rule webhook " + webhook.Name + " to " + webhook.URL
+	}
+	s.logRuleWebhookChange(models.AuditActionSaveRuleWebhook, details, actor, ipAddress, userAgent)
+
+	webhook.Secret = ""
+	return webhook, nil
+}
+
+// DeleteRuleWebhook removes a rule webhook. Returns false if there was no webhook with that name.
+func (s *RuleWebhookServiceRedis) DeleteRuleWebhook(name, actor, ipAddress, userAgent string) (bool, error) {
+	if name == "" {
+		return false, ErrNoRuleWebhookName
+	}
+
+	deleted, err := s.redisClient.DeleteRuleWebhook(name)
+	if err != nil {
+		log.Err(err).Msg("unable to delete rule webhook")
+		return false, err
+	}
+
+	if deleted {
+		s.logRuleWebhookChange(models.AuditActionDeleteRuleWebhook, "deleted rule webhook "+name, actor, ipAddress, userAgent)
+	}
+
+	return deleted, nil
+}
+
+// ListDeliveries returns the newest deliveries, optionally only those of one webhook or with one status.
+// A limit of 0 returns 50 deliveries.
+func (s *RuleWebhookServiceRedis) ListDeliveries(webhook, status string, limit int) ([]models.RuleWebhookDelivery, error) {
+	if limit <= 0 {
+		limit = defaultRuleWebhookDeliveryLimit
+	}
+
+	deliveries, err := s.redisClient.GetDeliveries()
+	if err != nil {
+		return nil, err
+	}
+
+	filtered := []models.RuleWebhookDelivery{}
+	for _, delivery := range deliveries {
+		if (webhook != "" && delivery.Webhook != webhook) || (status != "" && delivery.Status != status) {
+			continue
+		}
+
+		filtered = append(filtered, delivery)
+		if len(filtered) == limit {
+			break
+		}
+	}
+
+	return filtered, nil
+}
+
+// Redeliver sends the event of a delivery again to the current URL of its webhook as a new delivery.
+// Returns false if the delivery is not in the log.
+func (s *RuleWebhookServiceRedis) Redeliver(id string) (models.RuleWebhookDelivery, bool, error) {
+	if id == "" {
+		return models.RuleWebhookDelivery{}, false, ErrNoDeliveryID
+	}
+
+	previous, found, err := s.redisClient.GetDelivery(id)
+	if err != nil || !found {
+		return models.RuleWebhookDelivery{}, false, err
+	}
+
+	webhook, found, err := s.redisClient.GetRuleWebhook(previous.Webhook)
+	if err != nil {
+		return models.RuleWebhookDelivery{}, true, err
+	}
+	if !found {
+		return models.RuleWebhookDelivery{}, true, ErrRuleWebhookNotFound
+	}
+
+	delivery := newRuleWebhookDelivery(*webhook, previous.EventID, previous.Event, previous.Endpoint, previous.Payload)
+	if err := s.enqueue(*webhook, delivery); err != nil {
+		return models.RuleWebhookDelivery{}, true, err
+	}
+
+	return delivery, true, nil
+}
+
+// EmitRuleChange queues an event for every enabled webhook interested in the endpoint of the change. It
+// never blocks on the webhooks.
+func (s *RuleWebhookServiceRedis) EmitRuleChange(auditLog models.AuditLog) {
+	eventType, ok := ruleChangeEvents[auditLog.Action]
+	if !ok {
+		return
+	}
+
+	webhooks, err := s.redisClient.GetRuleWebhooks()
+	if err != nil {
+		log.Err(err).Str("endpoint", auditLog.Endpoint).Msg("unable to get rule webhooks, rule change not sent")
+		return
+	}
+
+	var payload []byte
+	for _, webhook := range webhooks {
+		if webhook.Disabled || (len(webhook.Endpoints) > 0 && !slices.Contains(webhook.Endpoints, auditLog.Endpoint)) {
+			continue
+		}
+
+		if payload == nil {
+			payload, err = json.Marshal(models.RuleChangeEvent{
+				ID:        auditLog.ID,
+				Event:     eventType,
+				Timestamp: auditLog.Timestamp,
+				Actor:     auditLog.Actor,
+				Endpoint:  auditLog.Endpoint,
+				OldRule:   auditLog.OldRule,
+				NewRule:   auditLog.NewRule,
+				Diff:      DiffRuleFields(auditLog.OldRule, auditLog.NewRule),
+			})
+			if err != nil {
+				log.Err(err).Str("endpoint", auditLog.Endpoint).Msg("unable to marshal rule change event")
+				return
+			}
+		}
+
+		delivery := newRuleWebhookDelivery(webhook, auditLog.ID, eventType, auditLog.Endpoint, payload)
+		if err := s.enqueue(webhook, delivery); err != nil {
+			log.Err(err).Str("webhook", webhook.Name).Str("endpoint", auditLog.Endpoint).Msg("unable to queue rule change event")
+		}
+	}
+}
+
+// Shutdown stops accepting events and waits until the queued ones are delivered. When ctx ends first,
+// pending retries are abandoned and their deliveries recorded as failed.
+func (s *RuleWebhookServiceRedis) Shutdown(ctx context.Context) error {
+	return s.queue.shutdown(ctx)
+}
+
+func newRuleWebhookDelivery(webhook models.RuleWebhook, eventID, event, endpoint string, payload []byte) models.RuleWebhookDelivery {
+	now := time.Now().Unix()
+
+	return models.RuleWebhookDelivery{
+		ID:        uuid.New().String(),
+		Webhook:   webhook.Name,
+		URL:       webhook.URL,
+		EventID:   eventID,
+		Event:     event,
+		Endpoint:  endpoint,
+		Status:    models.RuleWebhookDeliveryPending,
+		CreatedAt: now,
+		UpdatedAt: now,
+		Payload:   payload,
+	}
+}
+
+// enqueue records the delivery in the log and queues it. A delivery that does not fit in the queue is
+// recorded as failed so it can be sent again later.
+func (s *RuleWebhookServiceRedis) enqueue(webhook models.RuleWebhook, delivery models.RuleWebhookDelivery) error {
+	if err := s.redisClient.AddDelivery(delivery); err != nil {
+		return err
+	}
+
+	if s.queue.enqueue(&ruleWebhookJob{webhook: webhook, delivery: delivery}) {
+		return nil
+	}
+
+	delivery.Status = models.RuleWebhookDeliveryFailed
+	delivery.Error = "delivery queue is full or shutting down"
+	delivery.UpdatedAt = time.Now().Unix()
+	s.updateDelivery(delivery)
+
+	return errors.New(delivery.Error)
+}
+
+// deliver makes one attempt and records its outcome
+func (s *RuleWebhookServiceRedis) deliver(job *ruleWebhookJob) error {
+	statusCode, err := s.post(job.webhook, job.delivery)
+
+	job.delivery.Attempts++
+	job.delivery.StatusCode = statusCode
+	job.delivery.UpdatedAt = time.Now().Unix()
+	job.delivery.Error = ""
+	if err != nil {
+		job.delivery.Error = err.Error()
+	} else {
+		job.delivery.Status = models.RuleWebhookDeliveryDelivered
+	}
+
+	s.updateDelivery(job.delivery)
+	return err
+}
+
+func (s *RuleWebhookServiceRedis) giveUp(job *ruleWebhookJob, err error, attempts int) {
+	log.Warn().Err(err).Str("webhook", job.webhook.Name).Str("delivery", job.delivery.ID).Int("attempts", attempts).Msg("giving up on rule change event")
+
+	job.delivery.Status = models.RuleWebhookDeliveryFailed
+	job.delivery.UpdatedAt = time.Now().Unix()
+	s.updateDelivery(job.delivery)
+}
+
+// post sends the event signed the same way as webhook notifications. Returns the HTTP status, 0 when no
+// response was received.
+func (s *RuleWebhookServiceRedis) post(webhook models.RuleWebhook, delivery models.RuleWebhookDelivery) (int, error) {
+	req, err := http.NewRequest(http.MethodPost, webhook.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+
+	timestamp := time.Now().Unix()
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookEventHeader, delivery.Event)
+	req.Header.Set(WebhookDeliveryHeader, delivery.ID)
+	req.Header.Set(WebhookTimestampHeader, strconv.FormatInt(timestamp, 10))
+	if webhook.Secret != "" {
+		req.Header.Set(WebhookSignatureHeader, utils.SignPayload(webhook.Secret, timestamp, delivery.Payload))
+	}
+
+	resp, err := notifierHTTPClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("received non-2xx response from webhook: %s", resp.Status)
+	}
+
+	return resp.StatusCode, nil
+}
+
+func (s *RuleWebhookServiceRedis) updateDelivery(delivery models.RuleWebhookDelivery) {
+	if err := s.redisClient.UpdateDelivery(delivery); err != nil {
+		log.Warn().Err(err).Str("delivery", delivery.ID).Msg("unable to record rule webhook delivery")
+	}
+}
+
+func (s *RuleWebhookServiceRedis) logRuleWebhookChange(action, details, actor, ipAddress, userAgent string) {
+	if s.auditSvc == nil {
+		return
+	}
+
+	err := s.auditSvc.LogLimiterAction(actor, action, "", "", details, ipAddress, userAgent)
+	if err != nil {
+		// Don't fail the operation if audit logging fails
+		log.Warn().Err(err).Msg("failed to log audit event for rule webhook change")
+	}
+}
@@ -0,0 +1,244 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/x-sushant-x/RateShield/models"
+	"github.com/x-sushant-x/RateShield/utils"
+)
+
+// memoryRuleWebhookClient keeps rule webhooks and deliveries in memory
+type memoryRuleWebhookClient struct {
+	mutex      sync.Mutex
+	webhooks   map[string]models.RuleWebhook
+	deliveries []models.RuleWebhookDelivery
+}
+
+func newMemoryRuleWebhookClient(webhooks ...models.RuleWebhook) *memoryRuleWebhookClient {
+	client := &memoryRuleWebhookClient{webhooks: map[string]models.RuleWebhook{}}
+	for _, webhook := range webhooks {
+		client.webhooks[webhook.Name] = webhook
+	}
+	return client
+}
+
+func (m *memoryRuleWebhookClient) GetRuleWebhooks() ([]models.RuleWebhook, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	webhooks := []models.RuleWebhook{}
+	for _, webhook := range m.webhooks {
+		webhooks = append(webhooks, webhook)
+	}
+	return webhooks, nil
+}
+
+func (m *memoryRuleWebhookClient) GetRuleWebhook(name string) (*models.RuleWebhook, bool, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	webhook, ok := m.webhooks[name]
+	return &webhook, ok, nil
+}
+
+func (m *memoryRuleWebhookClient) SetRuleWebhook(webhook models.RuleWebhook) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.webhooks[webhook.Name] = webhook
+	return nil
+}
+
+func (m *memoryRuleWebhookClient) DeleteRuleWebhook(name string) (bool, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	_, ok := m.webhooks[name]
+	delete(m.webhooks, name)
+	return ok, nil
+}
+
+func (m *memoryRuleWebhookClient) AddDelivery(delivery models.RuleWebhookDelivery) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.deliveries = append([]models.RuleWebhookDelivery{delivery}, m.deliveries...)
+	return nil
+}
+
+func (m *memoryRuleWebhookClient) UpdateDelivery(delivery models.RuleWebhookDelivery) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	for i := range m.deliveries {
+		if m.deliveries[i].ID == delivery.ID {
+			m.deliveries[i] = delivery
+		}
+	}
+	return nil
+}
+
+func (m *memoryRuleWebhookClient) GetDelivery(id string) (*models.RuleWebhookDelivery, bool, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	for _, delivery := range m.deliveries {
+		if delivery.ID == id {
+			return &delivery, true, nil
+		}
+	}
+	return nil, false, nil
+}
+
+func (m *memoryRuleWebhookClient) GetDeliveries() ([]models.RuleWebhookDelivery, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	return append([]models.RuleWebhookDelivery{}, m.deliveries...), nil
+}
+
+func newTestRuleWebhookService(client *memoryRuleWebhookClient, maxRetries int) *RuleWebhookServiceRedis {
+	s := NewRuleWebhookService(client, nil, maxRetries)
+	s.queue.baseBackoff = time.Millisecond
+	s.queue.maxBackoff = 4 * time.Millisecond
+	return s
+}
+
+func TestRuleWebhookDelivery(t *testing.T) {
+	var calls atomic.Int32
+	var mutex sync.Mutex
+	var received *http.Request
+	var body []byte
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The first attempt fails, the retry succeeds
+		if calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+
+		mutex.Lock()
+		defer mutex.Unlock()
+		received = r
+		body, _ = io.ReadAll(r.Body)
+	}))
+	defer server.Close()
+
+	client := newMemoryRuleWebhookClient(
+		models.RuleWebhook{Name: "change-management", URL: server.URL, Secret: "secret"},
+		models.RuleWebhook{Name: "other-team", URL: server.URL, Endpoints: []string{"/api/v1/other"}},
+		models.RuleWebhook{Name: "disabled", URL: server.URL, Disabled: true},
+	)
+	s := newTestRuleWebhookService(client, 3)
+
+	oldRule := &models.Rule{APIEndpoint: "/api/v1/search", Strategy: models.StrategyFixedWindowCounter, FixedWindowCounterRule: &models.FixedWindowCounterRule{MaxRequests: 10, Window: 60}}
+	newRule := &models.Rule{APIEndpoint: "/api/v1/search", Strategy: models.StrategyFixedWindowCounter, FixedWindowCounterRule: &models.FixedWindowCounterRule{MaxRequests: 20, Window: 60}}
+
+	s.EmitRuleChange(models.AuditLog{ID: "audit-1", Timestamp: 1700000000, Actor: "alice", Action: models.AuditActionUpdate, Endpoint: "/api/v1/search", OldRule: oldRule, NewRule: newRule})
+	assert.NoError(t, s.Shutdown(context.Background()))
+
+	assert.Equal(t, int32(2), calls.Load())
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	require.NotNil(t, received)
+
+	timestamp, err := strconv.ParseInt(received.Header.Get(WebhookTimestampHeader), 10, 64)
+	require.NoError(t, err)
+	assert.True(t, utils.VerifySignature("secret", timestamp, body, received.Header.Get(WebhookSignatureHeader)))
+	assert.Equal(t, models.RuleEventUpdated, received.Header.Get(WebhookEventHeader))
+
+	var event models.RuleChangeEvent
+	require.NoError(t, json.Unmarshal(body, &event))
+	assert.Equal(t, "audit-1", event.ID)
+	assert.Equal(t, "alice", event.Actor)
+	assert.Equal(t, []models.RuleFieldChange{{Field: "fixed_window_counter_rule.max_requests", Old: float64(10), New: float64(20)}}, event.Diff)
+
+	deliveries, err := s.ListDeliveries("", "", 0)
+	require.NoError(t, err)
+	require.Len(t, deliveries, 1)
+	assert.Equal(t, received.Header.Get(WebhookDeliveryHeader), deliveries[0].ID)
+	assert.Equal(t, "change-management", deliveries[0].Webhook)
+	assert.Equal(t, models.RuleWebhookDeliveryDelivered, deliveries[0].Status)
+	assert.Equal(t, 2, deliveries[0].Attempts)
+	assert.Equal(t, http.StatusOK, deliveries[0].StatusCode)
+	assert.Empty(t, deliveries[0].Error)
+}
+
+func TestRuleWebhookFailedDeliveryAndRedeliver(t *testing.T) {
+	var failing atomic.Bool
+	failing.Store(true)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if failing.Load() {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	client := newMemoryRuleWebhookClient(models.RuleWebhook{Name: "change-management", URL: server.URL})
+	s := newTestRuleWebhookService(client, 1)
+
+	s.EmitRuleChange(models.AuditLog{ID: "audit-1", Action: models.AuditActionDelete, Endpoint: "/api/v1/search", OldRule: &models.Rule{APIEndpoint: "/api/v1/search"}})
+	assert.Eventually(t, func() bool {
+		failed, _ := s.ListDeliveries("", models.RuleWebhookDeliveryFailed, 0)
+		return len(failed) == 1
+	}, time.Second, time.Millisecond)
+
+	failed, _ := s.ListDeliveries("change-management", models.RuleWebhookDeliveryFailed, 0)
+	require.Len(t, failed, 1)
+	assert.Equal(t, 2, failed[0].Attempts)
+	assert.Equal(t, http.StatusInternalServerError, failed[0].StatusCode)
+	assert.Equal(t, models.RuleEventDeleted, failed[0].Event)
+
+	failing.Store(false)
+
+	delivery, found, err := s.Redeliver(failed[0].ID)
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.NotEqual(t, failed[0].ID, delivery.ID)
+	assert.Equal(t, "audit-1", delivery.EventID)
+	assert.NoError(t, s.Shutdown(context.Background()))
+
+	delivered, _ := s.ListDeliveries("", models.RuleWebhookDeliveryDelivered, 0)
+	require.Len(t, delivered, 1)
+	assert.Equal(t, delivery.ID, delivered[0].ID)
+	assert.JSONEq(t, string(failed[0].Payload), string(delivered[0].Payload))
+
+	_, found, err = s.Redeliver("unknown")
+	assert.NoError(t, err)
+	assert.False(t, found)
+}
+
+func TestSaveRuleWebhookKeepsSecret(t *testing.T) {
+	client := newMemoryRuleWebhookClient()
+	s := newTestRuleWebhookService(client, 0)
+	defer s.Shutdown(context.Background())
+
+	saved, err := s.SaveRuleWebhook(models.RuleWebhook{Name: "cm", URL: "https://cm.example.com", Secret: "secret"}, "alice", "", "")
+	require.NoError(t, err)
+	assert.Empty(t, saved.Secret)
+
+	_, err = s.SaveRuleWebhook(models.RuleWebhook{Name: "cm", URL: "https://cm.example.com/v2"}, "alice", "", "")
+	require.NoError(t, err)
+
+	stored, _, _ := client.GetRuleWebhook("cm")
+	assert.Equal(t, "secret", stored.Secret)
+	assert.Equal(t, "https://cm.example.com/v2", stored.URL)
+
+	webhooks, err := s.ListRuleWebhooks()
+	require.NoError(t, err)
+	require.Len(t, webhooks, 1)
+	assert.Empty(t, webhooks[0].Secret)
+}
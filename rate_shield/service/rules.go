@@ -2,15 +2,40 @@ package service
 
 import (
 	"errors"
+	"fmt"
+	"sort"
 	"strings"
+	"time"
 
+	"github.com/go-co-op/gocron/v2"
 	"github.com/rs/zerolog/log"
 	"github.com/x-sushant-x/RateShield/models"
 	redisClient "github.com/x-sushant-x/RateShield/redis"
+	"github.com/x-sushant-x/RateShield/utils"
 )
 
 const (
-	redisChannel = "rules-update"
+	redisChannel    = "rules-update"
+	ruleExpiryActor = "rule-expiry"
+)
+
+const (
+	// AnyRuleVersion skips the version check when saving, deleting or rolling back a rule
+	AnyRuleVersion int64 = -1
+)
+
+const (
+	// RulesImportUpsert creates and updates the imported rules and keeps every other rule
+	RulesImportUpsert = "upsert"
+	// RulesImportReplace also deletes every rule missing from the import, except read only rules
+	RulesImportReplace = "replace"
+)
+
+var (
+	ErrRuleVersionNotFound = errors.New("rule version not found")
+	ErrRuleVersionConflict = errors.New("rule was modified by someone else, reload it and try again")
+	ErrRuleReadOnly        = errors.New("rule is managed by the rules file and is read only")
+	ErrInvalidImportMode   = errors.New("invalid import mode, use upsert or replace")
 )
 
 type RulesService interface {
@@ -19,8 +44,14 @@ type RulesService interface {
 	GetRule(key string) (*models.Rule, bool, error)
 	SearchRule(searchText string) ([]models.Rule, error)
 	CreateOrUpdateRule(rule models.Rule, actor, ipAddress, userAgent string) error
+	CreateOrUpdateRuleIfMatch(rule models.Rule, expectedVersion int64, actor, ipAddress, userAgent string) (*models.Rule, error)
 	DeleteRule(endpoint, actor, ipAddress, userAgent string) error
-	CacheRulesLocally() *map[string]*models.Rule
+	DeleteRuleIfMatch(endpoint string, expectedVersion int64, actor, ipAddress, userAgent string) error
+	GetRuleHistory(endpoint string) ([]models.RuleHistoryEntry, error)
+	RollbackRule(endpoint string, version, expectedVersion int64, actor, ipAddress, userAgent string) (*models.Rule, error)
+	ExportRules() ([]models.Rule, error)
+	ImportRules(rules []models.Rule, mode string, dryRun bool, actor, ipAddress, userAgent string) (models.RulesDiff, error)
+	CacheRulesLocally() (*map[string]*models.Rule, error)
 	ListenToRulesUpdate(updatesChannel chan string)
 }
 
@@ -49,11 +80,6 @@ func (s RulesServiceRedis) GetAllRules() ([]models.Rule, error) {
 	rules := []models.Rule{}
 
 	for _, key := range keys {
-		// Skip audit logs key
-		if key == "audit:logs" {
-			continue
-		}
-
 		rule, found, err := s.redisClient.GetRule(key)
 
 		if !found {
@@ -89,6 +115,30 @@ func (s RulesServiceRedis) SearchRule(searchText string) ([]models.Rule, error)
 }
 
 func (s RulesServiceRedis) CreateOrUpdateRule(rule models.Rule, actor, ipAddress, userAgent string) error {
+	_, err := s.saveRule(rule, AnyRuleVersion, actor, ipAddress, userAgent, false)
+	return err
+}
+
+// CreateOrUpdateRuleIfMatch saves the rule only if the stored rule still has expectedVersion (0 when the
+// rule must not exist yet), otherwise ErrRuleVersionConflict is returned. Returns the saved rule.
+func (s RulesServiceRedis) CreateOrUpdateRuleIfMatch(rule models.Rule, expectedVersion int64, actor, ipAddress, userAgent string) (*models.Rule, error) {
+	return s.saveRule(rule, expectedVersion, actor, ipAddress, userAgent, false)
+}
+
+// saveRule validates, versions, stores, audits and broadcasts a rule and returns the rule as it was stored.
+// internal is set for writes RateShield makes itself (rules file sync, rule expiry), which keep the rule's
+// management fields. Every other write takes the rule out of the file's management and is rejected if the
+// stored rule is read only.
+func (s RulesServiceRedis) saveRule(rule models.Rule, expectedVersion int64, actor, ipAddress, userAgent string, internal bool) (*models.Rule, error) {
+	if err := utils.ValidateRule(rule); err != nil {
+		return nil, err
+	}
+
+	if !internal {
+		rule.ManagedBy = ""
+		rule.ReadOnly = false
+	}
+
 	// Check if rule already exists to determine action (CREATE vs UPDATE)
 	existingRule, found, err := s.redisClient.GetRule(rule.APIEndpoint)
 
@@ -105,11 +155,52 @@ func (s RulesServiceRedis) CreateOrUpdateRule(rule models.Rule, actor, ipAddress
 		oldRule = nil
 	}
 
+	if found && existingRule.ReadOnly && !internal {
+		return nil, ErrRuleReadOnly
+	}
+
+	if expectedVersion != AnyRuleVersion {
+		if err != nil {
+			return nil, err
+		}
+
+		// Fail early so a stale update does not use up a version number. 0 means the rule must not exist,
+		// whatever version a rule stored before versioning has.
+		if found == (expectedVersion == 0) || (found && existingRule.Version != expectedVersion) {
+			return nil, ErrRuleVersionConflict
+		}
+	}
+
+	version, err := s.redisClient.NextRuleVersion(rule.APIEndpoint)
+	if err != nil {
+		log.Err(err).Msg("unable to get next rule version")
+		return nil, err
+	}
+	rule.Version = version
+
 	// Save the rule to Redis
-	err = s.redisClient.SetRule(rule.APIEndpoint, rule)
+	if expectedVersion == AnyRuleVersion {
+		err = s.redisClient.SetRule(rule.APIEndpoint, rule)
+	} else {
+		err = s.redisClient.SetRuleIfVersion(rule.APIEndpoint, rule, expectedVersion)
+	}
+
+	if errors.Is(err, redisClient.ErrRuleVersionConflict) {
+		return nil, ErrRuleVersionConflict
+	}
 	if err != nil {
 		log.Err(err).Msg("unable to create or update rule")
-		return err
+		return nil, err
+	}
+
+	historyErr := s.redisClient.AppendRuleHistory(rule.APIEndpoint, models.RuleHistoryEntry{
+		Version:   version,
+		Timestamp: time.Now().Unix(),
+		Actor:     actor,
+		Rule:      rule,
+	})
+	if historyErr != nil {
+		log.Warn().Err(historyErr).Msg("failed to store rule version in history")
 	}
 
 	// Log audit event
@@ -121,10 +212,20 @@ func (s RulesServiceRedis) CreateOrUpdateRule(rule models.Rule, actor, ipAddress
 		}
 	}
 
-	return s.redisClient.PublishMessage(redisChannel, "rule-updated")
+	return &rule, s.redisClient.PublishMessage(redisChannel, "rule-updated")
 }
 
 func (s RulesServiceRedis) DeleteRule(endpoint, actor, ipAddress, userAgent string) error {
+	return s.deleteRule(endpoint, AnyRuleVersion, actor, ipAddress, userAgent, false)
+}
+
+// DeleteRuleIfMatch deletes the rule only if the stored rule still has expectedVersion, otherwise
+// ErrRuleVersionConflict is returned
+func (s RulesServiceRedis) DeleteRuleIfMatch(endpoint string, expectedVersion int64, actor, ipAddress, userAgent string) error {
+	return s.deleteRule(endpoint, expectedVersion, actor, ipAddress, userAgent, false)
+}
+
+func (s RulesServiceRedis) deleteRule(endpoint string, expectedVersion int64, actor, ipAddress, userAgent string, internal bool) error {
 	// Get the existing rule before deleting for audit log
 	existingRule, found, err := s.redisClient.GetRule(endpoint)
 	if !found || err != nil {
@@ -132,8 +233,20 @@ func (s RulesServiceRedis) DeleteRule(endpoint, actor, ipAddress, userAgent stri
 		// Still attempt to delete in case of inconsistency
 	}
 
+	if found && existingRule.ReadOnly && !internal {
+		return ErrRuleReadOnly
+	}
+
 	// Delete the rule from Redis
-	err = s.redisClient.DeleteRule(endpoint)
+	if expectedVersion == AnyRuleVersion {
+		err = s.redisClient.DeleteRule(endpoint)
+	} else {
+		err = s.redisClient.DeleteRuleIfVersion(endpoint, expectedVersion)
+	}
+
+	if errors.Is(err, redisClient.ErrRuleVersionConflict) {
+		return ErrRuleVersionConflict
+	}
 	if err != nil {
 		log.Err(err).Msg("unable to delete rule")
 		return err
@@ -151,7 +264,303 @@ func (s RulesServiceRedis) DeleteRule(endpoint, actor, ipAddress, userAgent stri
 	return s.redisClient.PublishMessage(redisChannel, "rule-updated")
 }
 
-func (s RulesServiceRedis) CacheRulesLocally() *map[string]*models.Rule {
+// GetRuleHistory returns every stored version of a rule, newest first
+func (s RulesServiceRedis) GetRuleHistory(endpoint string) ([]models.RuleHistoryEntry, error) {
+	history, err := s.redisClient.GetRuleHistory(endpoint)
+	if err != nil {
+		log.Err(err).Msg("unable to get rule history")
+		return nil, err
+	}
+
+	reversedHistory := make([]models.RuleHistoryEntry, len(history))
+	for i, entry := range history {
+		reversedHistory[len(history)-1-i] = entry
+	}
+
+	return reversedHistory, nil
+}
+
+// RollbackRule restores a previous version of a rule. The restored rule is saved as a new version so the
+// rollback is audited and broadcast like any other change. Like CreateOrUpdateRuleIfMatch, it fails with
+// ErrRuleVersionConflict if the stored rule no longer has expectedVersion (AnyRuleVersion skips the check).
+func (s RulesServiceRedis) RollbackRule(endpoint string, version, expectedVersion int64, actor, ipAddress, userAgent string) (*models.Rule, error) {
+	history, err := s.redisClient.GetRuleHistory(endpoint)
+	if err != nil {
+		log.Err(err).Msg("unable to get rule history")
+		return nil, err
+	}
+
+	for _, entry := range history {
+		if entry.Version != version {
+			continue
+		}
+
+		// The rule saveRule stored is returned, reading it back could return a change made since
+		return s.saveRule(entry.Rule, expectedVersion, actor, ipAddress, userAgent, false)
+	}
+
+	return nil, ErrRuleVersionNotFound
+}
+
+// ExportRules returns every rule sorted by endpoint, without the fields RateShield manages itself, so the
+// rules can be imported into another environment
+func (s RulesServiceRedis) ExportRules() ([]models.Rule, error) {
+	rules, err := s.GetAllRules()
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range rules {
+		rules[i].Version = 0
+		rules[i].ManagedBy = ""
+		rules[i].ReadOnly = false
+	}
+
+	sort.Slice(rules, func(i, j int) bool {
+		return rules[i].APIEndpoint < rules[j].APIEndpoint
+	})
+
+	return rules, nil
+}
+
+// ImportRules applies a bundle of rules in a single transaction: either every change is saved or none is.
+// Each changed rule gets a new version and its own audit entry. With dryRun the changes are only computed.
+func (s RulesServiceRedis) ImportRules(rules []models.Rule, mode string, dryRun bool, actor, ipAddress, userAgent string) (models.RulesDiff, error) {
+	if mode != RulesImportUpsert && mode != RulesImportReplace {
+		return models.RulesDiff{}, ErrInvalidImportMode
+	}
+
+	if err := utils.ValidateRules(rules); err != nil {
+		return models.RulesDiff{}, err
+	}
+
+	desired := make([]models.Rule, len(rules))
+	for i, rule := range rules {
+		rule.Version = 0
+		rule.ManagedBy = ""
+		rule.ReadOnly = false
+		desired[i] = rule
+	}
+
+	current, err := s.GetAllRules()
+	if err != nil {
+		return models.RulesDiff{}, err
+	}
+
+	var deletable func(rule models.Rule) bool
+	if mode == RulesImportReplace {
+		deletable = func(rule models.Rule) bool {
+			return !rule.ReadOnly
+		}
+	}
+
+	diff := DiffRules(current, desired, deletable)
+
+	for _, change := range diff.Updated {
+		if change.Old.ReadOnly {
+			return diff, fmt.Errorf("%s: %w", change.Endpoint, ErrRuleReadOnly)
+		}
+	}
+
+	if dryRun || !diff.HasChanges() {
+		return diff, nil
+	}
+
+	expectedVersions := map[string]int64{}
+	savedRules := make([]models.Rule, 0, len(diff.Created)+len(diff.Updated))
+	deletedKeys := make([]string, 0, len(diff.Deleted))
+
+	for _, rule := range diff.Created {
+		expectedVersions[rule.APIEndpoint] = 0
+		savedRules = append(savedRules, rule)
+	}
+
+	for _, change := range diff.Updated {
+		expectedVersions[change.Endpoint] = change.Old.Version
+		savedRules = append(savedRules, change.New)
+	}
+
+	for _, rule := range diff.Deleted {
+		expectedVersions[rule.APIEndpoint] = rule.Version
+		deletedKeys = append(deletedKeys, rule.APIEndpoint)
+	}
+
+	for i := range savedRules {
+		version, err := s.redisClient.NextRuleVersion(savedRules[i].APIEndpoint)
+		if err != nil {
+			log.Err(err).Msg("unable to get next rule version")
+			return diff, err
+		}
+		savedRules[i].Version = version
+	}
+
+	err = s.redisClient.ApplyRules(savedRules, deletedKeys, expectedVersions)
+	if errors.Is(err, redisClient.ErrRuleVersionConflict) {
+		return diff, ErrRuleVersionConflict
+	}
+	if err != nil {
+		log.Err(err).Msg("unable to import rules")
+		return diff, err
+	}
+
+	oldRules := map[string]*models.Rule{}
+	for i := range diff.Updated {
+		oldRules[diff.Updated[i].Endpoint] = &diff.Updated[i].Old
+	}
+
+	for i := range savedRules {
+		rule := savedRules[i]
+
+		historyErr := s.redisClient.AppendRuleHistory(rule.APIEndpoint, models.RuleHistoryEntry{
+			Version:   rule.Version,
+			Timestamp: time.Now().Unix(),
+			Actor:     actor,
+			Rule:      rule,
+		})
+		if historyErr != nil {
+			log.Warn().Err(historyErr).Msg("failed to store rule version in history")
+		}
+
+		action := models.AuditActionCreate
+		oldRule, updated := oldRules[rule.APIEndpoint]
+		if updated {
+			action = models.AuditActionUpdate
+		}
+
+		s.logImportedRuleChange(actor, action, rule.APIEndpoint, oldRule, &rule, ipAddress, userAgent)
+	}
+
+	for i := range diff.Deleted {
+		s.logImportedRuleChange(actor, models.AuditActionDelete, diff.Deleted[i].APIEndpoint, &diff.Deleted[i], nil, ipAddress, userAgent)
+	}
+
+	// Versions were assigned on save, return them
+	for i, rule := range savedRules {
+		if i < len(diff.Created) {
+			diff.Created[i] = rule
+		} else {
+			diff.Updated[i-len(diff.Created)].New = rule
+		}
+	}
+
+	return diff, s.redisClient.PublishMessage(redisChannel, "rule-updated")
+}
+
+func (s RulesServiceRedis) logImportedRuleChange(actor, action, endpoint string, oldRule, newRule *models.Rule, ipAddress, userAgent string) {
+	if s.auditSvc == nil {
+		return
+	}
+
+	auditErr := s.auditSvc.LogRuleChange(actor, action, endpoint, oldRule, newRule, ipAddress, userAgent)
+	if auditErr != nil {
+		// Don't fail the import if audit logging fails
+		log.Warn().Err(auditErr).Msg("failed to log audit event for imported rule")
+	}
+}
+
+// RemoveExpiredRules deletes rules whose expires_at has passed and drops expired scheduled rules from the
+// rules that remain. Changes are audited under the rule-expiry actor. Returns the number of changed rules.
+func (s RulesServiceRedis) RemoveExpiredRules(now time.Time) (int, error) {
+	rules, err := s.GetAllRules()
+	if err != nil {
+		return 0, err
+	}
+
+	changed := 0
+
+	for _, rule := range rules {
+		if utils.IsRuleExpired(rule, now) {
+			deleted, err := s.deleteExpiredRule(rule)
+			if err != nil {
+				return changed, err
+			}
+			if deleted {
+				changed++
+			}
+			continue
+		}
+
+		activeScheduledRules := []models.Rule{}
+		for _, scheduledRule := range rule.ScheduledRules {
+			if !utils.IsRuleExpired(scheduledRule, now) {
+				activeScheduledRules = append(activeScheduledRules, scheduledRule)
+			}
+		}
+
+		if len(activeScheduledRules) == len(rule.ScheduledRules) {
+			continue
+		}
+
+		rule.ScheduledRules = nil
+		if len(activeScheduledRules) > 0 {
+			rule.ScheduledRules = activeScheduledRules
+		}
+
+		// Another instance or an admin changed the rule in the meantime, it is checked again on the next run
+		_, err := s.saveRule(rule, rule.Version, ruleExpiryActor, "", "", true)
+		if errors.Is(err, ErrRuleVersionConflict) {
+			continue
+		}
+		if err != nil {
+			return changed, err
+		}
+		changed++
+	}
+
+	return changed, nil
+}
+
+// deleteExpiredRule deletes a rule only if it was not changed since it was read. Every instance runs the
+// expiry job, so the deletion is only audited and broadcast by the instance that actually removed the rule.
+func (s RulesServiceRedis) deleteExpiredRule(rule models.Rule) (bool, error) {
+	err := s.redisClient.DeleteRuleIfVersion(rule.APIEndpoint, rule.Version)
+	if errors.Is(err, redisClient.ErrRuleVersionConflict) {
+		return false, nil
+	}
+	if err != nil {
+		log.Err(err).Msg("unable to delete expired rule")
+		return false, err
+	}
+
+	if s.auditSvc != nil {
+		auditErr := s.auditSvc.LogRuleChange(ruleExpiryActor, models.AuditActionDelete, rule.APIEndpoint, &rule, nil, "", "")
+		if auditErr != nil {
+			log.Warn().Err(auditErr).Msg("failed to log audit event for expired rule")
+		}
+	}
+
+	return true, s.redisClient.PublishMessage(redisChannel, "rule-updated")
+}
+
+// StartRuleExpiryJob removes expired rules every interval
+func (s RulesServiceRedis) StartRuleExpiryJob(interval time.Duration) {
+	scheduler, err := gocron.NewScheduler()
+	if err != nil {
+		panic(err)
+	}
+
+	_, err = scheduler.NewJob(gocron.DurationJob(interval), gocron.NewTask(func() {
+		changed, err := s.RemoveExpiredRules(time.Now())
+		if err != nil {
+			log.Err(err).Msg("unable to remove expired rules")
+			return
+		}
+
+		if changed > 0 {
+			log.Info().Msgf("Removed %d expired rules ✅", changed)
+		}
+	}))
+
+	if err != nil {
+		panic(err)
+	}
+
+	scheduler.Start()
+}
+
+// CacheRulesLocally returns the valid rules by endpoint. If they can't be read the map is empty and the
+// error is returned with it.
+func (s RulesServiceRedis) CacheRulesLocally() (*map[string]*models.Rule, error) {
 	rules, err := s.GetAllRules()
 	if err != nil {
 		log.Err(err).Msg("Unable to cache all rules locally")
@@ -160,11 +569,21 @@ func (s RulesServiceRedis) CacheRulesLocally() *map[string]*models.Rule {
 	cachedRules := make(map[string]*models.Rule)
 
 	for _, rule := range rules {
+		// Rules stored before validation existed may be unusable, e.g. missing their strategy's sub-rule
+		if err := utils.ValidateRule(rule); err != nil {
+			log.Error().Err(err).Str("endpoint", rule.APIEndpoint).Msg("skipping invalid rule")
+			continue
+		}
+
 		cachedRules[rule.APIEndpoint] = &rule
 	}
 
+	if err != nil {
+		return &cachedRules, err
+	}
+
 	log.Info().Msg("Rules locally cached ✅")
-	return &cachedRules
+	return &cachedRules, nil
 }
 
 func (s RulesServiceRedis) ListenToRulesUpdate(updatesChannel chan string) {
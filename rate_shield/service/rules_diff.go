@@ -0,0 +1,137 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/x-sushant-x/RateShield/models"
+)
+
+// DiffRules compares the stored rules with the desired rules. Stored rules missing from the desired rules
+// are only listed as deleted when deletable returns true for them, pass nil to never delete.
+func DiffRules(current, desired []models.Rule, deletable func(rule models.Rule) bool) models.RulesDiff {
+	diff := models.RulesDiff{
+		Created: []models.Rule{},
+		Updated: []models.RuleChange{},
+		Deleted: []models.Rule{},
+	}
+
+	currentRules := make(map[string]models.Rule, len(current))
+	for _, rule := range current {
+		currentRules[rule.APIEndpoint] = rule
+	}
+
+	desiredEndpoints := make(map[string]bool, len(desired))
+
+	for _, rule := range desired {
+		desiredEndpoints[rule.APIEndpoint] = true
+
+		currentRule, found := currentRules[rule.APIEndpoint]
+		switch {
+		case !found:
+			diff.Created = append(diff.Created, rule)
+		case !sameRule(currentRule, rule):
+			diff.Updated = append(diff.Updated, models.RuleChange{
+				Endpoint: rule.APIEndpoint,
+				Old:      currentRule,
+				New:      rule,
+			})
+		default:
+			diff.Unchanged++
+		}
+	}
+
+	for _, rule := range current {
+		if desiredEndpoints[rule.APIEndpoint] {
+			continue
+		}
+
+		if deletable != nil && deletable(rule) {
+			diff.Deleted = append(diff.Deleted, rule)
+		}
+	}
+
+	sort.Slice(diff.Deleted, func(i, j int) bool {
+		return diff.Deleted[i].APIEndpoint < diff.Deleted[j].APIEndpoint
+	})
+
+	return diff
+}
+
+// sameRule compares two rules ignoring their versions
+func sameRule(a, b models.Rule) bool {
+	a.Version = 0
+	b.Version = 0
+	return reflect.DeepEqual(a, b)
+}
+
+// DiffRuleFields lists the fields that differ between two versions of a rule, sorted by field. A nil rule
+// is a rule that did not exist, so every field of the other one is listed. The version is ignored.
+func DiffRuleFields(oldRule, newRule *models.Rule) []models.RuleFieldChange {
+	oldFields := flattenRule(oldRule)
+	newFields := flattenRule(newRule)
+
+	changes := []models.RuleFieldChange{}
+
+	for field, oldValue := range oldFields {
+		newValue, ok := newFields[field]
+		if !ok || !reflect.DeepEqual(oldValue, newValue) {
+			changes = append(changes, models.RuleFieldChange{Field: field, Old: oldValue, New: newValue})
+		}
+	}
+
+	for field, newValue := range newFields {
+		if _, ok := oldFields[field]; !ok {
+			changes = append(changes, models.RuleFieldChange{Field: field, New: newValue})
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Field < changes[j].Field
+	})
+
+	return changes
+}
+
+// flattenRule maps the JSON path of every leaf value of a rule to the value
+func flattenRule(rule *models.Rule) map[string]interface{} {
+	fields := map[string]interface{}{}
+	if rule == nil {
+		return fields
+	}
+
+	body, err := json.Marshal(rule)
+	if err != nil {
+		return fields
+	}
+
+	var value map[string]interface{}
+	if err := json.Unmarshal(body, &value); err != nil {
+		return fields
+	}
+	delete(value, "version")
+
+	flattenValue("", value, fields)
+	return fields
+}
+
+func flattenValue(path string, value interface{}, fields map[string]interface{}) {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		for key, child := range value {
+			childPath := key
+			if path != "" {
+				childPath = path + "." + key
+			}
+			flattenValue(childPath, child, fields)
+		}
+	case []interface{}:
+		for i, child := range value {
+			flattenValue(fmt.Sprintf("%s[%d]", path, i), child, fields)
+		}
+	default:
+		fields[path] = value
+	}
+}
@@ -0,0 +1,71 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/x-sushant-x/RateShield/models"
+)
+
+func TestDiffRuleFields(t *testing.T) {
+	oldRule := &models.Rule{
+		Strategy:    models.StrategyTokenBucket,
+		APIEndpoint: "/api/v1/search",
+		HTTPMethod:  "GET",
+		Version:     3,
+		TokenBucketRule: &models.TokenBucketRule{
+			BucketCapacity: 100,
+			TokenAddRate:   10,
+			RetentionTime:  60,
+		},
+		AllowList: []string{"10.0.0.1"},
+	}
+
+	t.Run("updated rule", func(t *testing.T) {
+		newRule := *oldRule
+		newRule.Version = 4
+		newRule.TokenBucketRule = &models.TokenBucketRule{BucketCapacity: 200, TokenAddRate: 10, RetentionTime: 60}
+		newRule.AllowList = []string{"10.0.0.1", "10.0.0.2"}
+		newRule.AllowOnError = true
+
+		assert.Equal(t, []models.RuleFieldChange{
+			{Field: "allow_list[1]", New: "10.0.0.2"},
+			{Field: "allow_on_error", Old: false, New: true},
+			{Field: "token_bucket_rule.bucket_capacity", Old: float64(100), New: float64(200)},
+		}, DiffRuleFields(oldRule, &newRule))
+	})
+
+	t.Run("created rule lists every field", func(t *testing.T) {
+		changes := DiffRuleFields(nil, oldRule)
+
+		fields := []string{}
+		for _, change := range changes {
+			assert.Nil(t, change.Old, change.Field)
+			fields = append(fields, change.Field)
+		}
+		assert.Equal(t, []string{
+			"allow_list[0]",
+			"allow_on_error",
+			"endpoint",
+			"http_method",
+			"strategy",
+			"token_bucket_rule.bucket_capacity",
+			"token_bucket_rule.retention_time",
+			"token_bucket_rule.token_add_rate",
+		}, fields)
+	})
+
+	t.Run("deleted rule", func(t *testing.T) {
+		changes := DiffRuleFields(oldRule, nil)
+		assert.Len(t, changes, 8)
+		for _, change := range changes {
+			assert.Nil(t, change.New, change.Field)
+		}
+	})
+
+	t.Run("unchanged rule", func(t *testing.T) {
+		newRule := *oldRule
+		newRule.Version = 4
+		assert.Empty(t, DiffRuleFields(oldRule, &newRule))
+	})
+}
@@ -0,0 +1,124 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/x-sushant-x/RateShield/models"
+	"github.com/x-sushant-x/RateShield/utils"
+)
+
+const (
+	rulesFileActor = "rules-file"
+)
+
+// RulesFileSyncer keeps the rules store in line with a rules file or directory. Rules defined in the file
+// are created or updated, rules that were loaded from the file and were removed from it are deleted and
+// rules created through the API are left alone.
+type RulesFileSyncer struct {
+	rulesSvc     RulesServiceRedis
+	path         string
+	readOnly     bool
+	interval     time.Duration
+	lastChecksum string
+}
+
+func NewRulesFileSyncer(rulesSvc RulesServiceRedis, path string, readOnly bool, interval time.Duration) *RulesFileSyncer {
+	return &RulesFileSyncer{
+		rulesSvc: rulesSvc,
+		path:     path,
+		readOnly: readOnly,
+		interval: interval,
+	}
+}
+
+// Sync loads the rules file and applies its changes. Nothing is applied if any rule in the file is
+// invalid. Returns the changes that were made.
+func (s *RulesFileSyncer) Sync() (models.RulesDiff, error) {
+	desired, checksum, err := utils.LoadRulesFile(s.path)
+	if err != nil {
+		return models.RulesDiff{}, err
+	}
+
+	diff, err := s.apply(desired)
+	if err == nil {
+		s.lastChecksum = checksum
+	}
+
+	return diff, err
+}
+
+// Watch checks the rules file for changes every interval and syncs it when it changed. Blocks forever.
+func (s *RulesFileSyncer) Watch() {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		desired, checksum, err := utils.LoadRulesFile(s.path)
+		if checksum != "" && checksum == s.lastChecksum {
+			continue
+		}
+
+		if err != nil {
+			// Remember the broken file so the error is only logged once
+			if s.lastChecksum != "invalid" {
+				log.Error().Err(err).Msg("unable to load rules file, keeping current rules")
+			}
+			s.lastChecksum = "invalid"
+			continue
+		}
+
+		// The checksum is only remembered once every change was applied, so failed changes are retried
+		diff, err := s.apply(desired)
+		if err != nil {
+			log.Error().Err(err).Msg("unable to apply every change from rules file")
+			continue
+		}
+
+		s.lastChecksum = checksum
+
+		if diff.HasChanges() {
+			log.Info().Msgf("Rules file synced: %d created, %d updated, %d deleted ✅", len(diff.Created), len(diff.Updated), len(diff.Deleted))
+		}
+	}
+}
+
+func (s *RulesFileSyncer) apply(desired []models.Rule) (models.RulesDiff, error) {
+	for i := range desired {
+		desired[i].ManagedBy = models.RuleManagedByFile
+		desired[i].ReadOnly = s.readOnly
+	}
+
+	current, err := s.rulesSvc.GetAllRules()
+	if err != nil {
+		return models.RulesDiff{}, err
+	}
+
+	diff := DiffRules(current, desired, func(rule models.Rule) bool {
+		return rule.ManagedBy == models.RuleManagedByFile
+	})
+
+	errs := []error{}
+
+	for _, rule := range diff.Created {
+		if _, err := s.rulesSvc.saveRule(rule, AnyRuleVersion, rulesFileActor, "", "", true); err != nil {
+			errs = append(errs, fmt.Errorf("create %s: %w", rule.APIEndpoint, err))
+		}
+	}
+
+	for _, change := range diff.Updated {
+		if _, err := s.rulesSvc.saveRule(change.New, AnyRuleVersion, rulesFileActor, "", "", true); err != nil {
+			errs = append(errs, fmt.Errorf("update %s: %w", change.Endpoint, err))
+		}
+	}
+
+	for _, rule := range diff.Deleted {
+		if err := s.rulesSvc.deleteRule(rule.APIEndpoint, AnyRuleVersion, rulesFileActor, "", "", true); err != nil {
+			errs = append(errs, fmt.Errorf("delete %s: %w", rule.APIEndpoint, err))
+		}
+	}
+
+	return diff, errors.Join(errs...)
+}
@@ -0,0 +1,42 @@
+package service
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/x-sushant-x/RateShield/utils"
+)
+
+func TestRulesFileSyncChecksum(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rules.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+rules:
+  - endpoint: /api/v1/search
+    strategy: FIXED WINDOW COUNTER
+    fixed_window_counter_rule:
+      max_requests: 10
+      window: 60
+`), 0o600))
+
+	_, checksum, err := utils.LoadRulesFile(path)
+	require.NoError(t, err)
+
+	rulesSvc, ruleClient, _ := newTestRulesService()
+	syncer := NewRulesFileSyncer(rulesSvc, path, false, 0)
+
+	// A file that was not applied completely is synced again on the next check
+	ruleClient.setRuleErr = errors.New("redis unavailable")
+	_, err = syncer.Sync()
+	assert.Error(t, err)
+	assert.Empty(t, syncer.lastChecksum)
+
+	ruleClient.setRuleErr = nil
+	diff, err := syncer.Sync()
+	require.NoError(t, err)
+	assert.Len(t, diff.Created, 1)
+	assert.Equal(t, checksum, syncer.lastChecksum)
+}
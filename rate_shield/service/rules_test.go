@@ -0,0 +1,525 @@
+package service
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/x-sushant-x/RateShield/models"
+	redisClient "github.com/x-sushant-x/RateShield/redis"
+)
+
+// memoryRuleClient keeps rules, their version counters and history in memory
+type memoryRuleClient struct {
+	mutex      sync.Mutex
+	rules      map[string]models.Rule
+	versions   map[string]int64
+	history    map[string][]models.RuleHistoryEntry
+	published  int
+	setRuleErr error // Returned by SetRule when set
+
+	// Runs in SetRuleIfVersion and DeleteRuleIfVersion between the version check and the write, like a
+	// write of another instance between WATCH and EXEC
+	beforeConditionalWrite func()
+}
+
+func newMemoryRuleClient(rules ...models.Rule) *memoryRuleClient {
+	client := &memoryRuleClient{
+		rules:    map[string]models.Rule{},
+		versions: map[string]int64{},
+		history:  map[string][]models.RuleHistoryEntry{},
+	}
+	for _, rule := range rules {
+		client.rules[rule.APIEndpoint] = rule
+		client.versions[rule.APIEndpoint] = rule.Version
+	}
+	return client
+}
+
+func (m *memoryRuleClient) GetRule(key string) (*models.Rule, bool, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	rule, found := m.rules[key]
+	if !found {
+		return nil, false, nil
+	}
+	return &rule, true, nil
+}
+
+func (m *memoryRuleClient) GetAllRuleKeys() ([]string, bool, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	keys := []string{}
+	for key := range m.rules {
+		keys = append(keys, key)
+	}
+	return keys, true, nil
+}
+
+func (m *memoryRuleClient) SetRule(key string, val interface{}) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if m.setRuleErr != nil {
+		return m.setRuleErr
+	}
+
+	m.rules[key] = val.(models.Rule)
+	return nil
+}
+
+func (m *memoryRuleClient) SetRuleIfVersion(key string, rule models.Rule, expectedVersion int64) error {
+	m.mutex.Lock()
+	current, found := m.rules[key]
+	m.mutex.Unlock()
+
+	if !m.hasVersion(key, expectedVersion) {
+		return redisClient.ErrRuleVersionConflict
+	}
+
+	if m.beforeConditionalWrite != nil {
+		m.beforeConditionalWrite()
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	// The watched rule changed, the transaction is discarded
+	if latest, stillFound := m.rules[key]; stillFound != found || latest.Version != current.Version {
+		return redisClient.ErrRuleVersionConflict
+	}
+
+	m.rules[key] = rule
+	return nil
+}
+
+func (m *memoryRuleClient) ApplyRules(rules []models.Rule, deleteKeys []string, expectedVersions map[string]int64) error {
+	for key, expectedVersion := range expectedVersions {
+		if !m.hasVersion(key, expectedVersion) {
+			return redisClient.ErrRuleVersionConflict
+		}
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	for _, rule := range rules {
+		m.rules[rule.APIEndpoint] = rule
+	}
+	for _, key := range deleteKeys {
+		delete(m.rules, key)
+	}
+	return nil
+}
+
+// hasVersion checks a stored rule like RedisRules does, version 0 only matches a rule that does not exist
+func (m *memoryRuleClient) hasVersion(key string, expectedVersion int64) bool {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	current, found := m.rules[key]
+	if !found {
+		return expectedVersion == 0
+	}
+	return expectedVersion != 0 && current.Version == expectedVersion
+}
+
+func (m *memoryRuleClient) NextRuleVersion(key string) (int64, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.versions[key]++
+	return m.versions[key], nil
+}
+
+func (m *memoryRuleClient) AppendRuleHistory(key string, entry models.RuleHistoryEntry) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.history[key] = append(m.history[key], entry)
+	return nil
+}
+
+func (m *memoryRuleClient) GetRuleHistory(key string) ([]models.RuleHistoryEntry, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	return append([]models.RuleHistoryEntry{}, m.history[key]...), nil
+}
+
+func (m *memoryRuleClient) DeleteRule(key string) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	delete(m.rules, key)
+	return nil
+}
+
+func (m *memoryRuleClient) DeleteRuleIfVersion(key string, expectedVersion int64) error {
+	if m.beforeConditionalWrite != nil {
+		m.beforeConditionalWrite()
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	current, found := m.rules[key]
+	if !found || current.Version != expectedVersion {
+		return redisClient.ErrRuleVersionConflict
+	}
+
+	delete(m.rules, key)
+	return nil
+}
+
+func (m *memoryRuleClient) MigrateLegacyRules() (int, error) {
+	return 0, nil
+}
+
+func (m *memoryRuleClient) PublishMessage(channel, msg string) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.published++
+	return nil
+}
+
+func (m *memoryRuleClient) ListenToRulesUpdate(updatesChannel chan string) {}
+
+// memoryAuditClient keeps audit logs in memory, in the order they were written
+type memoryAuditClient struct {
+	mutex sync.Mutex
+	logs  []models.AuditLog
+}
+
+func (m *memoryAuditClient) AppendAuditLog(auditLog models.AuditLog) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.logs = append(m.logs, auditLog)
+	return nil
+}
+
+func (m *memoryAuditClient) GetAuditLogs(start, end int64) ([]models.AuditLog, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	end = min(end+1, int64(len(m.logs)))
+	if start >= end {
+		return []models.AuditLog{}, nil
+	}
+	return append([]models.AuditLog{}, m.logs[start:end]...), nil
+}
+
+func (m *memoryAuditClient) GetAuditLogCount() (int64, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	return int64(len(m.logs)), nil
+}
+
+func (m *memoryAuditClient) GetAllAuditLogs() ([]models.AuditLog, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	return append([]models.AuditLog{}, m.logs...), nil
+}
+
+func (m *memoryAuditClient) actions() []string {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	actions := []string{}
+	for _, auditLog := range m.logs {
+		actions = append(actions, auditLog.Action)
+	}
+	return actions
+}
+
+func newTestRulesService(rules ...models.Rule) (RulesServiceRedis, *memoryRuleClient, *memoryAuditClient) {
+	ruleClient := newMemoryRuleClient(rules...)
+	auditClient := &memoryAuditClient{}
+
+	return NewRedisRulesService(ruleClient, NewAuditService(auditClient, nil)), ruleClient, auditClient
+}
+
+func fixedWindowRule(endpoint string, maxRequests int64) models.Rule {
+	return models.Rule{
+		APIEndpoint:            endpoint,
+		Strategy:               "FIXED WINDOW COUNTER",
+		FixedWindowCounterRule: &models.FixedWindowCounterRule{MaxRequests: maxRequests, Window: 60},
+	}
+}
+
+func TestRuleVersions(t *testing.T) {
+	svc, ruleClient, auditClient := newTestRulesService()
+	endpoint := "/api/v1/search"
+
+	require.NoError(t, svc.CreateOrUpdateRule(fixedWindowRule(endpoint, 10), "alice", "", ""))
+	require.NoError(t, svc.CreateOrUpdateRule(fixedWindowRule(endpoint, 20), "bob", "", ""))
+
+	rule, found, err := svc.GetRule(endpoint)
+	require.NoError(t, err)
+	require.True(t, found)
+	assert.Equal(t, int64(2), rule.Version)
+
+	// Versions keep increasing when a rule is deleted and created again
+	require.NoError(t, svc.DeleteRule(endpoint, "alice", "", ""))
+	require.NoError(t, svc.CreateOrUpdateRule(fixedWindowRule(endpoint, 30), "alice", "", ""))
+
+	rule, _, err = svc.GetRule(endpoint)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), rule.Version)
+
+	assert.Equal(t, []string{models.AuditActionCreate, models.AuditActionUpdate, models.AuditActionDelete, models.AuditActionCreate}, auditClient.actions())
+	assert.Equal(t, 4, ruleClient.published)
+}
+
+func TestRuleHistory(t *testing.T) {
+	svc, _, _ := newTestRulesService()
+	endpoint := "/api/v1/search"
+
+	for _, maxRequests := range []int64{10, 20, 30} {
+		require.NoError(t, svc.CreateOrUpdateRule(fixedWindowRule(endpoint, maxRequests), "alice", "", ""))
+	}
+
+	history, err := svc.GetRuleHistory(endpoint)
+	require.NoError(t, err)
+	require.Len(t, history, 3)
+
+	// Newest first, every entry holds the rule as it was saved
+	for i, entry := range history {
+		assert.Equal(t, int64(3-i), entry.Version)
+		assert.Equal(t, entry.Version, entry.Rule.Version)
+		assert.Equal(t, "alice", entry.Actor)
+	}
+	assert.Equal(t, int64(30), history[0].Rule.FixedWindowCounterRule.MaxRequests)
+	assert.Equal(t, int64(10), history[2].Rule.FixedWindowCounterRule.MaxRequests)
+
+	history, err = svc.GetRuleHistory("/api/v1/unknown")
+	require.NoError(t, err)
+	assert.Empty(t, history)
+}
+
+func TestRollbackRule(t *testing.T) {
+	svc, _, auditClient := newTestRulesService()
+	endpoint := "/api/v1/search"
+
+	require.NoError(t, svc.CreateOrUpdateRule(fixedWindowRule(endpoint, 10), "alice", "", ""))
+	require.NoError(t, svc.CreateOrUpdateRule(fixedWindowRule(endpoint, 20), "alice", "", ""))
+
+	// The restored rule is saved as a new version
+	restored, err := svc.RollbackRule(endpoint, 1, AnyRuleVersion, "bob", "", "")
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), restored.Version)
+	assert.Equal(t, int64(10), restored.FixedWindowCounterRule.MaxRequests)
+
+	history, err := svc.GetRuleHistory(endpoint)
+	require.NoError(t, err)
+	assert.Equal(t, "bob", history[0].Actor)
+
+	logs, err := auditClient.GetAllAuditLogs()
+	require.NoError(t, err)
+	assert.Equal(t, models.AuditActionUpdate, logs[len(logs)-1].Action)
+	assert.Equal(t, "bob", logs[len(logs)-1].Actor)
+
+	t.Run("deleted rule", func(t *testing.T) {
+		require.NoError(t, svc.DeleteRule(endpoint, "alice", "", ""))
+
+		restored, err := svc.RollbackRule(endpoint, 2, AnyRuleVersion, "bob", "", "")
+		require.NoError(t, err)
+		assert.Equal(t, int64(4), restored.Version)
+		assert.Equal(t, int64(20), restored.FixedWindowCounterRule.MaxRequests)
+	})
+
+	t.Run("unknown version", func(t *testing.T) {
+		_, err := svc.RollbackRule(endpoint, 42, AnyRuleVersion, "bob", "", "")
+		assert.ErrorIs(t, err, ErrRuleVersionNotFound)
+	})
+
+	t.Run("stale version", func(t *testing.T) {
+		_, err := svc.RollbackRule(endpoint, 1, 3, "bob", "", "")
+		assert.ErrorIs(t, err, ErrRuleVersionConflict)
+
+		restored, err := svc.RollbackRule(endpoint, 1, 4, "bob", "", "")
+		require.NoError(t, err)
+		assert.Equal(t, int64(5), restored.Version)
+	})
+}
+
+func TestDeleteRuleIfMatch(t *testing.T) {
+	svc, ruleClient, auditClient := newTestRulesService()
+	endpoint := "/api/v1/search"
+
+	require.NoError(t, svc.CreateOrUpdateRule(fixedWindowRule(endpoint, 10), "alice", "", ""))
+	require.NoError(t, svc.CreateOrUpdateRule(fixedWindowRule(endpoint, 20), "alice", "", ""))
+
+	assert.ErrorIs(t, svc.DeleteRuleIfMatch(endpoint, 1, "bob", "", ""), ErrRuleVersionConflict)
+
+	_, found, err := svc.GetRule(endpoint)
+	require.NoError(t, err)
+	assert.True(t, found)
+
+	require.NoError(t, svc.DeleteRuleIfMatch(endpoint, 2, "bob", "", ""))
+
+	_, found, err = svc.GetRule(endpoint)
+	require.NoError(t, err)
+	assert.False(t, found)
+
+	// Deleting it again is stale too
+	assert.ErrorIs(t, svc.DeleteRuleIfMatch(endpoint, 2, "bob", "", ""), ErrRuleVersionConflict)
+
+	assert.Equal(t, []string{models.AuditActionCreate, models.AuditActionUpdate, models.AuditActionDelete}, auditClient.actions())
+	assert.Equal(t, 3, ruleClient.published)
+}
+
+func TestCreateOrUpdateRuleIfMatch(t *testing.T) {
+	endpoint := "/api/v1/search"
+
+	t.Run("matching version", func(t *testing.T) {
+		svc, _, _ := newTestRulesService()
+
+		created, err := svc.CreateOrUpdateRuleIfMatch(fixedWindowRule(endpoint, 10), 0, "alice", "", "")
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), created.Version)
+
+		updated, err := svc.CreateOrUpdateRuleIfMatch(fixedWindowRule(endpoint, 20), created.Version, "alice", "", "")
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), updated.Version)
+	})
+
+	t.Run("stale version", func(t *testing.T) {
+		svc, ruleClient, auditClient := newTestRulesService()
+
+		require.NoError(t, svc.CreateOrUpdateRule(fixedWindowRule(endpoint, 10), "alice", "", ""))
+		require.NoError(t, svc.CreateOrUpdateRule(fixedWindowRule(endpoint, 20), "bob", "", ""))
+
+		_, err := svc.CreateOrUpdateRuleIfMatch(fixedWindowRule(endpoint, 30), 1, "alice", "", "")
+		assert.ErrorIs(t, err, ErrRuleVersionConflict)
+
+		rule, _, _ := svc.GetRule(endpoint)
+		assert.Equal(t, int64(20), rule.FixedWindowCounterRule.MaxRequests)
+		assert.Equal(t, int64(2), ruleClient.versions[endpoint], "a stale update must not use up a version")
+		assert.Len(t, auditClient.actions(), 2)
+	})
+
+	t.Run("version of a rule that does not exist", func(t *testing.T) {
+		svc, _, _ := newTestRulesService()
+
+		_, err := svc.CreateOrUpdateRuleIfMatch(fixedWindowRule(endpoint, 10), 3, "alice", "", "")
+		assert.ErrorIs(t, err, ErrRuleVersionConflict)
+	})
+
+	t.Run("create only", func(t *testing.T) {
+		svc, _, _ := newTestRulesService()
+
+		require.NoError(t, svc.CreateOrUpdateRule(fixedWindowRule(endpoint, 10), "alice", "", ""))
+
+		_, err := svc.CreateOrUpdateRuleIfMatch(fixedWindowRule(endpoint, 20), 0, "bob", "", "")
+		assert.ErrorIs(t, err, ErrRuleVersionConflict)
+	})
+
+	t.Run("create only with a rule stored before versioning", func(t *testing.T) {
+		svc, _, _ := newTestRulesService(fixedWindowRule(endpoint, 10))
+
+		_, err := svc.CreateOrUpdateRuleIfMatch(fixedWindowRule(endpoint, 20), 0, "bob", "", "")
+		assert.ErrorIs(t, err, ErrRuleVersionConflict)
+
+		rule, _, _ := svc.GetRule(endpoint)
+		assert.Equal(t, int64(10), rule.FixedWindowCounterRule.MaxRequests)
+	})
+
+	t.Run("write between check and save", func(t *testing.T) {
+		svc, ruleClient, auditClient := newTestRulesService()
+
+		created, err := svc.CreateOrUpdateRuleIfMatch(fixedWindowRule(endpoint, 10), 0, "alice", "", "")
+		require.NoError(t, err)
+
+		ruleClient.beforeConditionalWrite = func() {
+			ruleClient.beforeConditionalWrite = nil
+			require.NoError(t, svc.CreateOrUpdateRule(fixedWindowRule(endpoint, 20), "bob", "", ""))
+		}
+
+		_, err = svc.CreateOrUpdateRuleIfMatch(fixedWindowRule(endpoint, 30), created.Version, "alice", "", "")
+		assert.ErrorIs(t, err, ErrRuleVersionConflict)
+
+		rule, _, _ := svc.GetRule(endpoint)
+		assert.Equal(t, int64(20), rule.FixedWindowCounterRule.MaxRequests)
+
+		// Only the successful writes are recorded
+		history, err := svc.GetRuleHistory(endpoint)
+		require.NoError(t, err)
+		assert.Len(t, history, 2)
+		assert.Equal(t, []string{models.AuditActionCreate, models.AuditActionUpdate}, auditClient.actions())
+	})
+}
+
+func TestRemoveExpiredRules(t *testing.T) {
+	now := time.Now()
+	endpoint := "/api/v1/search"
+
+	expired := fixedWindowRule(endpoint, 10)
+	expired.Version = 2
+	expired.ExpiresAt = now.Add(-time.Minute).Unix()
+
+	active := fixedWindowRule("/api/v1/users", 10)
+	active.Version = 1
+	active.ExpiresAt = now.Add(time.Hour).Unix()
+
+	svc, ruleClient, auditClient := newTestRulesService(expired, active)
+
+	changed, err := svc.RemoveExpiredRules(now)
+	require.NoError(t, err)
+	assert.Equal(t, 1, changed)
+
+	keys, _, _ := ruleClient.GetAllRuleKeys()
+	assert.Equal(t, []string{"/api/v1/users"}, keys)
+	assert.Equal(t, []string{models.AuditActionDelete}, auditClient.actions())
+	assert.Equal(t, 1, ruleClient.published)
+
+	t.Run("expired on every instance at once", func(t *testing.T) {
+		svc, ruleClient, auditClient := newTestRulesService(expired)
+		otherSvc := NewRedisRulesService(ruleClient, NewAuditService(auditClient, nil))
+
+		// Another instance removes the rule after this one listed it
+		ruleClient.beforeConditionalWrite = func() {
+			ruleClient.beforeConditionalWrite = nil
+
+			changed, err := otherSvc.RemoveExpiredRules(now)
+			require.NoError(t, err)
+			assert.Equal(t, 1, changed)
+		}
+
+		changed, err := svc.RemoveExpiredRules(now)
+		require.NoError(t, err)
+		assert.Equal(t, 0, changed)
+
+		assert.Equal(t, []string{models.AuditActionDelete}, auditClient.actions())
+		assert.Equal(t, 1, ruleClient.published)
+	})
+
+	t.Run("changed after it was listed", func(t *testing.T) {
+		svc, ruleClient, auditClient := newTestRulesService(expired)
+
+		extended := expired
+		extended.Version = 3
+		extended.ExpiresAt = now.Add(time.Hour).Unix()
+
+		ruleClient.beforeConditionalWrite = func() {
+			ruleClient.beforeConditionalWrite = nil
+			require.NoError(t, ruleClient.SetRule(endpoint, extended))
+		}
+
+		changed, err := svc.RemoveExpiredRules(now)
+		require.NoError(t, err)
+		assert.Equal(t, 0, changed)
+
+		_, found, _ := ruleClient.GetRule(endpoint)
+		assert.True(t, found)
+		assert.Empty(t, auditClient.actions())
+	})
+}
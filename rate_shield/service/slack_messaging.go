@@ -26,6 +26,14 @@ func NewSlackService(token, channel string) *SlackService {
 	}
 }
 
+func (s *SlackService) Name() string {
+	return models.NotificationChannelSlack
+}
+
+func (s *SlackService) Notify(notification models.Notification) error {
+	return s.SendSlackMessage(formatNotificationText(notification))
+}
+
 func (s *SlackService) SendSlackMessage(msg string) error {
 	message := buildSlackMessageObject(s.Channel, msg)
 
@@ -59,8 +67,7 @@ func (s *SlackService) setRequestHeaders(req *http.Request) {
 }
 
 func (s *SlackService) sendRequestToSlackAPI(req *http.Request) error {
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	resp, err := notifierHTTPClient.Do(req)
 	if err != nil {
 		log.Err(err).Msgf("error sending request: %s", err)
 		return err
@@ -0,0 +1,107 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+	"github.com/x-sushant-x/RateShield/models"
+	redisClient "github.com/x-sushant-x/RateShield/redis"
+)
+
+var (
+	ErrNoClientIdentity = errors.New("identity must not be empty")
+	ErrInvalidTierName  = errors.New("tier must not be empty or contain whitespace")
+)
+
+// TierService manages which tier each client is on
+type TierService interface {
+	GetClientTier(identity string) (string, bool, error)
+	AssignTier(identity, tier, actor, ipAddress, userAgent string) error
+	UnassignTier(identity, actor, ipAddress, userAgent string) (bool, error)
+}
+
+type TierServiceRedis struct {
+	redisClient redisClient.RedisTierClient
+	auditSvc    AuditService
+}
+
+func NewTierService(client redisClient.RedisTierClient, auditSvc AuditService) TierServiceRedis {
+	return TierServiceRedis{
+		redisClient: client,
+		auditSvc:    auditSvc,
+	}
+}
+
+func (s TierServiceRedis) GetClientTier(identity string) (string, bool, error) {
+	return s.redisClient.GetClientTier(identity)
+}
+
+// AssignTier puts a client on a tier. The tier does not have to exist on any rule yet, rules without it
+// apply their default limits to the client.
+func (s TierServiceRedis) AssignTier(identity, tier, actor, ipAddress, userAgent string) error {
+	if strings.TrimSpace(identity) == "" {
+		return ErrNoClientIdentity
+	}
+
+	if strings.TrimSpace(tier) == "" || strings.ContainsAny(tier, " \t\r\n") {
+		return ErrInvalidTierName
+	}
+
+	previous, err := s.redisClient.SetClientTier(identity, tier)
+	if err != nil {
+		log.Err(err).Msg("unable to assign tier")
+		return err
+	}
+
+	if previous == tier {
+		return nil
+	}
+
+	details := fmt.Sprintf("assigned tier %s", tier)
+	if previous != "" {
+		details = fmt.Sprintf("changed tier from %s to %s", previous, tier)
+	}
+	s.logTierChange(models.AuditActionAssignTier, identity, details, actor, ipAddress, userAgent)
+
+	return s.publishTierUpdate()
+}
+
+// UnassignTier removes a client from its tier. Returns false if the client had no tier.
+func (s TierServiceRedis) UnassignTier(identity, actor, ipAddress, userAgent string) (bool, error) {
+	if strings.TrimSpace(identity) == "" {
+		return false, ErrNoClientIdentity
+	}
+
+	previous, found, err := s.redisClient.DeleteClientTier(identity)
+	if err != nil {
+		log.Err(err).Msg("unable to unassign tier")
+		return false, err
+	}
+
+	if !found {
+		return false, nil
+	}
+
+	s.logTierChange(models.AuditActionUnassignTier, identity, "removed from tier "+previous, actor, ipAddress, userAgent)
+
+	return true, s.publishTierUpdate()
+}
+
+// Limiters cache the tiers of clients and drop the cache when rules are reloaded
+func (s TierServiceRedis) publishTierUpdate() error {
+	return s.redisClient.PublishMessage(redisChannel, "tiers-updated")
+}
+
+func (s TierServiceRedis) logTierChange(action, identity, details, actor, ipAddress, userAgent string) {
+	if s.auditSvc == nil {
+		return
+	}
+
+	err := s.auditSvc.LogLimiterAction(actor, action, "", identity, details, ipAddress, userAgent)
+	if err != nil {
+		// Don't fail the operation if audit logging fails
+		log.Warn().Err(err).Msg("failed to log audit event for tier change")
+	}
+}
@@ -0,0 +1,69 @@
+package service
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/x-sushant-x/RateShield/models"
+	"github.com/x-sushant-x/RateShield/utils"
+)
+
+const (
+	WebhookSignatureHeader = "X-RateShield-Signature"
+	WebhookTimestampHeader = "X-RateShield-Timestamp"
+	WebhookEventHeader     = "X-RateShield-Event"
+)
+
+// WebhookNotifier posts notifications as JSON to any URL. When a secret is set the body is signed with
+// HMAC-SHA256 over "<timestamp>.<body>", receivers check it with the signature and timestamp headers.
+type WebhookNotifier struct {
+	url    string
+	secret string
+}
+
+func NewWebhookNotifier(url, secret string) *WebhookNotifier {
+	return &WebhookNotifier{
+		url:    url,
+		secret: secret,
+	}
+}
+
+func (w *WebhookNotifier) Name() string {
+	return models.NotificationChannelWebhook
+}
+
+func (w *WebhookNotifier) Notify(notification models.Notification) error {
+	body, err := utils.MarshalJSON(notification)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	timestamp := time.Now().Unix()
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookEventHeader, notification.Event)
+	req.Header.Set(WebhookTimestampHeader, strconv.FormatInt(timestamp, 10))
+	if w.secret != "" {
+		req.Header.Set(WebhookSignatureHeader, utils.SignPayload(w.secret, timestamp, body))
+	}
+
+	resp, err := notifierHTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("received non-2xx response from webhook: %s", resp.Status)
+	}
+
+	return nil
+}
@@ -0,0 +1,93 @@
+package utils
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/x-sushant-x/RateShield/models"
+)
+
+var (
+	validAlertTypes = []string{
+		models.AlertTypeDenyRate,
+		models.AlertTypeThrottledClient,
+		models.AlertTypeQuotaExhaustion,
+		models.AlertTypeRedisFallback,
+	}
+
+	validNotificationSeverities = []string{
+		models.NotificationSeverityCritical,
+		models.NotificationSeverityError,
+		models.NotificationSeverityWarning,
+		models.NotificationSeverityInfo,
+	}
+
+	validNotificationChannels = []string{
+		models.NotificationChannelSlack,
+		models.NotificationChannelWebhook,
+		models.NotificationChannelEmail,
+		models.NotificationChannelPagerDuty,
+	}
+)
+
+// ValidateAlertRule checks that an alert rule carries the settings its type needs. Returns a
+// *RuleValidationError listing every invalid field, or nil if the alert rule is valid.
+func ValidateAlertRule(alert models.AlertRule) error {
+	errs := &RuleValidationError{}
+
+	if len(strings.TrimSpace(alert.Name)) == 0 {
+		errs.add("name", "must not be empty")
+	} else if strings.ContainsAny(alert.Name, " \t\r\n") {
+		errs.add("name", "must not contain whitespace")
+	}
+
+	if strings.ContainsAny(alert.Endpoint, " \t\r\n") {
+		errs.add("endpoint", "must not contain whitespace")
+	}
+
+	switch alert.Type {
+	case models.AlertTypeDenyRate:
+		validateAlertThreshold(alert.Threshold, errs)
+		if alert.Window <= 0 {
+			errs.add("window", "must be greater than 0")
+		}
+		if alert.MinRequests < 0 {
+			errs.add("min_requests", "must not be negative")
+		}
+	case models.AlertTypeThrottledClient:
+		if alert.Duration <= 0 {
+			errs.add("duration", "must be greater than 0")
+		}
+	case models.AlertTypeQuotaExhaustion:
+		validateAlertThreshold(alert.Threshold, errs)
+	case models.AlertTypeRedisFallback:
+	default:
+		errs.add("type", fmt.Sprintf("must be one of %s", strings.Join(validAlertTypes, ", ")))
+	}
+
+	if alert.Severity != "" && !slices.Contains(validNotificationSeverities, alert.Severity) {
+		errs.add("severity", fmt.Sprintf("must be one of %s", strings.Join(validNotificationSeverities, ", ")))
+	}
+
+	for i, channel := range alert.Channels {
+		if !slices.Contains(validNotificationChannels, channel) {
+			errs.add(fmt.Sprintf("channels[%d]", i), fmt.Sprintf("must be one of %s", strings.Join(validNotificationChannels, ", ")))
+		}
+	}
+
+	if alert.Cooldown < 0 {
+		errs.add("cooldown", "must not be negative")
+	}
+
+	if len(errs.Errors) > 0 {
+		return errs
+	}
+	return nil
+}
+
+func validateAlertThreshold(threshold float64, errs *RuleValidationError) {
+	if threshold <= 0 || threshold > 100 {
+		errs.add("threshold", "must be a percentage greater than 0 and at most 100")
+	}
+}
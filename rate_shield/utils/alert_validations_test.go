@@ -0,0 +1,61 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/x-sushant-x/RateShield/models"
+)
+
+func TestValidateAlertRule(t *testing.T) {
+	t.Run("valid alert rules", func(t *testing.T) {
+		alerts := []models.AlertRule{
+			{Name: "search-denials", Type: models.AlertTypeDenyRate, Endpoint: "/api/v1/search", Threshold: 50, Window: 300, MinRequests: 100},
+			{Name: "stuck-clients", Type: models.AlertTypeThrottledClient, Duration: 600, Severity: models.NotificationSeverityError, Channels: []string{"pagerduty"}},
+			{Name: "quota-90", Type: models.AlertTypeQuotaExhaustion, Threshold: 90, Cooldown: 3600},
+			{Name: "fallback", Type: models.AlertTypeRedisFallback, Channels: []string{"slack", "email"}},
+		}
+
+		for _, alert := range alerts {
+			assert.NoError(t, ValidateAlertRule(alert), alert.Name)
+		}
+	})
+
+	tests := []struct {
+		name   string
+		alert  models.AlertRule
+		fields []string
+	}{
+		{
+			name:   "missing name and unknown type",
+			alert:  models.AlertRule{Type: "SLOW"},
+			fields: []string{"name", "type"},
+		},
+		{
+			name:   "deny rate without threshold and window",
+			alert:  models.AlertRule{Name: "denials", Type: models.AlertTypeDenyRate, MinRequests: -1},
+			fields: []string{"threshold", "window", "min_requests"},
+		},
+		{
+			name:   "threshold above 100",
+			alert:  models.AlertRule{Name: "quota", Type: models.AlertTypeQuotaExhaustion, Threshold: 120},
+			fields: []string{"threshold"},
+		},
+		{
+			name:   "throttled client without duration",
+			alert:  models.AlertRule{Name: "stuck clients", Type: models.AlertTypeThrottledClient},
+			fields: []string{"name", "duration"},
+		},
+		{
+			name:   "unknown severity, channel and negative cooldown",
+			alert:  models.AlertRule{Name: "fallback", Type: models.AlertTypeRedisFallback, Severity: "fatal", Channels: []string{"slack", "sms"}, Cooldown: -1},
+			fields: []string{"severity", "channels[1]", "cooldown"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.fields, fieldsOf(ValidateAlertRule(tt.alert)))
+		})
+	}
+}
@@ -23,7 +23,81 @@ func BadRequestError(w http.ResponseWriter) {
 		"error":  "Invalid Request Body",
 	}
 
-	w.WriteHeader(http.StatusInternalServerError)
+	w.WriteHeader(http.StatusBadRequest)
+	bytes, _ := json.Marshal(msg)
+	w.Write(bytes)
+}
+
+// InvalidRequestError responds with 400 and a message explaining what is wrong with the request
+func InvalidRequestError(w http.ResponseWriter, message string) {
+	msg := map[string]string{
+		"status":  "fail",
+		"error":   "Invalid Request",
+		"message": message,
+	}
+
+	w.WriteHeader(http.StatusBadRequest)
+	bytes, _ := json.Marshal(msg)
+	w.Write(bytes)
+}
+
+// ValidationErrorResponse responds with 400 and the field level details of why a rule is invalid
+func ValidationErrorResponse(w http.ResponseWriter, err *RuleValidationError) {
+	msg := map[string]interface{}{
+		"status":  "fail",
+		"error":   "Invalid Rule",
+		"details": err.Errors,
+	}
+
+	w.WriteHeader(http.StatusBadRequest)
+	bytes, _ := json.Marshal(msg)
+	w.Write(bytes)
+}
+
+func NotFoundError(w http.ResponseWriter, message string) {
+	msg := map[string]string{
+		"status":  "fail",
+		"error":   "Not Found",
+		"message": message,
+	}
+
+	w.WriteHeader(http.StatusNotFound)
+	bytes, _ := json.Marshal(msg)
+	w.Write(bytes)
+}
+
+func ConflictError(w http.ResponseWriter, message string) {
+	msg := map[string]string{
+		"status":  "fail",
+		"error":   "Conflict",
+		"message": message,
+	}
+
+	w.WriteHeader(http.StatusConflict)
+	bytes, _ := json.Marshal(msg)
+	w.Write(bytes)
+}
+
+func ForbiddenError(w http.ResponseWriter, message string) {
+	msg := map[string]string{
+		"status":  "fail",
+		"error":   "Forbidden",
+		"message": message,
+	}
+
+	w.WriteHeader(http.StatusForbidden)
+	bytes, _ := json.Marshal(msg)
+	w.Write(bytes)
+}
+
+func InvalidPreconditionError(w http.ResponseWriter, message string) {
+	msg := map[string]string{
+		"status":  "fail",
+		"error":   "Invalid Precondition",
+		"message": message,
+	}
+
+	w.WriteHeader(http.StatusBadRequest)
 	bytes, _ := json.Marshal(msg)
 	w.Write(bytes)
 }
@@ -2,9 +2,12 @@ package utils
 
 import (
 	"os"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/rs/zerolog/log"
+	"github.com/x-sushant-x/RateShield/models"
 )
 
 func GetApplicationEnviroment() string {
@@ -41,8 +44,231 @@ func GetRedisClusterPassword() string {
 	return password
 }
 
+// (string, bool, time.Duration) -> Path, Read Only, Sync Interval
+// Path is empty when rules are only managed through the API
+func GetRulesFileDetails() (string, bool, time.Duration) {
+	path := os.Getenv("RULES_FILE_PATH")
+
+	readOnly := false
+	if value := os.Getenv("RULES_FILE_READ_ONLY"); len(value) != 0 {
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			log.Fatal().Msg("RULES_FILE_READ_ONLY must be true or false")
+		}
+		readOnly = parsed
+	}
+
+	interval := 10 * time.Second
+	if value := os.Getenv("RULES_FILE_SYNC_INTERVAL"); len(value) != 0 {
+		seconds, err := strconv.Atoi(value)
+		if err != nil || seconds <= 0 {
+			log.Fatal().Msg("RULES_FILE_SYNC_INTERVAL must be a number of seconds greater than 0")
+		}
+		interval = time.Duration(seconds) * time.Second
+	}
+
+	return path, readOnly, interval
+}
+
+// (string, string) -> Token, Channel
+// Both are empty when Slack notifications are not used
+func GetSlackDetails() (string, string) {
+	token := os.Getenv("SLACK_TOKEN")
+	channel := os.Getenv("SLACK_CHANNEL")
+
+	if (len(token) == 0) != (len(channel) == 0) {
+		log.Fatal().Msg("SLACK_TOKEN and SLACK_CHANNEL must be provided together")
+	}
+
+	return token, channel
+}
+
+// (string, string) -> URL, Secret
+// URL is empty when webhook notifications are not used, deliveries are unsigned without a secret
+func GetWebhookNotifierDetails() (string, string) {
+	return os.Getenv("NOTIFY_WEBHOOK_URL"), os.Getenv("NOTIFY_WEBHOOK_SECRET")
+}
+
+// Host is empty when email notifications are not used
+func GetSMTPDetails() models.SMTPConfig {
+	config := models.SMTPConfig{
+		Host:     os.Getenv("SMTP_HOST"),
+		Port:     587,
+		Username: os.Getenv("SMTP_USERNAME"),
+		Password: os.Getenv("SMTP_PASSWORD"),
+		From:     os.Getenv("SMTP_FROM"),
+	}
+
+	if len(config.Host) == 0 {
+		return config
+	}
+
+	if value := os.Getenv("SMTP_PORT"); len(value) != 0 {
+		port, err := strconv.Atoi(value)
+		if err != nil || port <= 0 || port > 65535 {
+			log.Fatal().Msg("SMTP_PORT must be a valid port number")
+		}
+		config.Port = port
+	}
+
+	checkEmptyENV(config.From, "SMTP_FROM must be provided when SMTP_HOST is set")
+
+	for _, to := range strings.Split(os.Getenv("SMTP_TO"), ",") {
+		if to = strings.TrimSpace(to); len(to) != 0 {
+			config.To = append(config.To, to)
+		}
+	}
+	if len(config.To) == 0 {
+		log.Fatal().Msg("SMTP_TO must be provided when SMTP_HOST is set. Specify comma seperated email addresses.")
+	}
+
+	return config
+}
+
+// (string, string) -> Routing Key, Events URL
+// Routing Key is empty when PagerDuty notifications are not used
+func GetPagerDutyDetails() (string, string) {
+	return os.Getenv("PAGERDUTY_ROUTING_KEY"), os.Getenv("PAGERDUTY_EVENTS_URL")
+}
+
+// (bool, time.Duration) -> Shared through Redis, Cooldown
+// Cooldown is 0 when not set so the services apply their defaults
+func GetNotificationDedupDetails() (bool, time.Duration) {
+	shared := false
+	switch value := os.Getenv("NOTIFICATION_DEDUP"); value {
+	case "", "local":
+	case "redis":
+		shared = true
+	default:
+		log.Fatal().Msg("NOTIFICATION_DEDUP must be local or redis")
+	}
+
+	var cooldown time.Duration
+	if value := os.Getenv("NOTIFICATION_COOLDOWN"); len(value) != 0 {
+		seconds, err := strconv.Atoi(value)
+		if err != nil || seconds <= 0 {
+			log.Fatal().Msg("NOTIFICATION_COOLDOWN must be a number of seconds greater than 0")
+		}
+		cooldown = time.Duration(seconds) * time.Second
+	}
+
+	return shared, cooldown
+}
+
+// (int, int, int) -> Workers, Queue Size, Max Retries
+// Values not set are 0 for workers and queue size and -1 for retries so the dispatcher applies its defaults
+func GetNotificationDispatcherDetails() (int, int, int) {
+	workers := getPositiveIntENV("NOTIFICATION_WORKERS", 0)
+	queueSize := getPositiveIntENV("NOTIFICATION_QUEUE_SIZE", 0)
+
+	maxRetries := -1
+	if value := os.Getenv("NOTIFICATION_MAX_RETRIES"); len(value) != 0 {
+		retries, err := strconv.Atoi(value)
+		if err != nil || retries < 0 {
+			log.Fatal().Msg("NOTIFICATION_MAX_RETRIES must be a number greater than or equal to 0")
+		}
+		maxRetries = retries
+	}
+
+	return workers, queueSize, maxRetries
+}
+
+// Returns -1 when RULE_WEBHOOK_MAX_RETRIES is not set so the rule webhook service applies its default
+func GetRuleWebhookMaxRetries() int {
+	value := os.Getenv("RULE_WEBHOOK_MAX_RETRIES")
+	if len(value) == 0 {
+		return -1
+	}
+
+	retries, err := strconv.Atoi(value)
+	if err != nil || retries < 0 {
+		log.Fatal().Msg("RULE_WEBHOOK_MAX_RETRIES must be a number greater than or equal to 0")
+	}
+	return retries
+}
+
+// Returns the tokens accepted by the gRPC admin services mapped to the actor recorded in the audit log.
+// GRPC_ADMIN_TOKENS is a comma separated list of name:token pairs. Without it the admin services are disabled.
+func GetGRPCAdminTokens() map[string]string {
+	tokens := map[string]string{}
+
+	value := os.Getenv("GRPC_ADMIN_TOKENS")
+	if len(value) == 0 {
+		return tokens
+	}
+
+	for _, entry := range strings.Split(value, ",") {
+		name, token, found := strings.Cut(strings.TrimSpace(entry), ":")
+		if !found || len(name) == 0 || len(token) == 0 {
+			log.Fatal().Msg("GRPC_ADMIN_TOKENS must be a comma separated list of name:token pairs")
+		}
+		tokens[token] = name
+	}
+	return tokens
+}
+
+// Returns the settings of the gRPC server, every variable is optional
+func GetGRPCServerDetails() models.GRPCServerConfig {
+	config := models.GRPCServerConfig{
+		Port:             "50051",
+		AdminTokens:      GetGRPCAdminTokens(),
+		KeepaliveTime:    getSecondsENV("GRPC_KEEPALIVE_TIME", 60),
+		KeepaliveTimeout: getSecondsENV("GRPC_KEEPALIVE_TIMEOUT", 20),
+		KeepaliveMinTime: getSecondsENV("GRPC_KEEPALIVE_MIN_TIME", 10),
+		TLSCertFile:      os.Getenv("GRPC_TLS_CERT_FILE"),
+		TLSKeyFile:       os.Getenv("GRPC_TLS_KEY_FILE"),
+		TLSClientCAFile:  os.Getenv("GRPC_TLS_CLIENT_CA_FILE"),
+	}
+
+	if value := os.Getenv("GRPC_PORT"); len(value) != 0 {
+		port, err := strconv.Atoi(value)
+		if err != nil || port <= 0 || port > 65535 {
+			log.Fatal().Msg("GRPC_PORT must be a port number between 1 and 65535")
+		}
+		config.Port = value
+	}
+
+	config.MaxConcurrentStreams = uint32(getPositiveIntENV("GRPC_MAX_CONCURRENT_STREAMS", 0))
+	config.StreamMaxInFlight = getPositiveIntENV("GRPC_STREAM_MAX_IN_FLIGHT", 64)
+
+	if (len(config.TLSCertFile) == 0) != (len(config.TLSKeyFile) == 0) {
+		log.Fatal().Msg("GRPC_TLS_CERT_FILE and GRPC_TLS_KEY_FILE must be provided together")
+	}
+
+	if len(config.TLSClientCAFile) != 0 && len(config.TLSCertFile) == 0 {
+		log.Fatal().Msg("GRPC_TLS_CLIENT_CA_FILE requires GRPC_TLS_CERT_FILE and GRPC_TLS_KEY_FILE")
+	}
+
+	if value := os.Getenv("GRPC_REFLECTION"); len(value) != 0 {
+		enabled, err := strconv.ParseBool(value)
+		if err != nil {
+			log.Fatal().Msg("GRPC_REFLECTION must be true or false")
+		}
+		config.Reflection = enabled
+	}
+
+	return config
+}
+
+func getPositiveIntENV(name string, fallback int) int {
+	value := os.Getenv(name)
+	if len(value) == 0 {
+		return fallback
+	}
+
+	number, err := strconv.Atoi(value)
+	if err != nil || number <= 0 {
+		log.Fatal().Msgf("%s must be a number greater than 0", name)
+	}
+	return number
+}
+
 func checkEmptyENV(Var string, message string) {
 	if len(Var) == 0 {
 		log.Fatal().Msg(message)
 	}
 }
+
+func getSecondsENV(name string, fallback int) time.Duration {
+	return time.Duration(getPositiveIntENV(name, fallback)) * time.Second
+}
@@ -0,0 +1,95 @@
+package utils
+
+import (
+	"errors"
+	"net/netip"
+	"strings"
+)
+
+var (
+	ErrInvalidIPEntry = errors.New("must be an IP address or a CIDR range such as 10.0.0.0/8 or 2001:db8::/32")
+)
+
+// IPMatcher matches IP addresses against a list of addresses and CIDR ranges
+type IPMatcher struct {
+	prefixes []netip.Prefix
+	entries  []string
+}
+
+// NewIPMatcher builds a matcher from entries accepted by ParseIPEntry
+func NewIPMatcher(entries []string) (*IPMatcher, error) {
+	matcher := &IPMatcher{
+		prefixes: make([]netip.Prefix, 0, len(entries)),
+		entries:  make([]string, 0, len(entries)),
+	}
+
+	for _, entry := range entries {
+		prefix, err := ParseIPEntry(entry)
+		if err != nil {
+			return nil, err
+		}
+
+		matcher.prefixes = append(matcher.prefixes, prefix)
+		matcher.entries = append(matcher.entries, entry)
+	}
+
+	return matcher, nil
+}
+
+// Match returns the first entry containing the IP address. A nil matcher matches nothing.
+func (m *IPMatcher) Match(ip netip.Addr) (string, bool) {
+	if m == nil {
+		return "", false
+	}
+
+	ip = ip.Unmap().WithZone("")
+
+	for i, prefix := range m.prefixes {
+		if prefix.Contains(ip) {
+			return m.entries[i], true
+		}
+	}
+
+	return "", false
+}
+
+// ParseIPEntry parses an IPv4 or IPv6 address or CIDR range. An address is treated as a range holding only
+// that address. IPv4 addresses written as IPv6 (::ffff:10.0.0.1) are treated as IPv4.
+func ParseIPEntry(entry string) (netip.Prefix, error) {
+	entry = strings.TrimSpace(entry)
+
+	if strings.Contains(entry, "/") {
+		prefix, err := netip.ParsePrefix(entry)
+		if err != nil {
+			return netip.Prefix{}, ErrInvalidIPEntry
+		}
+
+		if prefix.Addr().Is4In6() && prefix.Bits() >= 96 {
+			prefix = netip.PrefixFrom(prefix.Addr().Unmap(), prefix.Bits()-96)
+		}
+
+		return prefix.Masked(), nil
+	}
+
+	addr, err := netip.ParseAddr(entry)
+	if err != nil || addr.Zone() != "" {
+		return netip.Prefix{}, ErrInvalidIPEntry
+	}
+
+	addr = addr.Unmap()
+	return netip.PrefixFrom(addr, addr.BitLen()), nil
+}
+
+// NormalizeIPEntry returns the canonical form of an entry so the same range is always stored the same way
+func NormalizeIPEntry(entry string) (string, error) {
+	prefix, err := ParseIPEntry(entry)
+	if err != nil {
+		return "", err
+	}
+
+	if prefix.IsSingleIP() {
+		return prefix.Addr().String(), nil
+	}
+
+	return prefix.String(), nil
+}
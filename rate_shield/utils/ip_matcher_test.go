@@ -0,0 +1,63 @@
+package utils
+
+import (
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIPMatcher(t *testing.T) {
+	matcher, err := NewIPMatcher([]string{"10.0.0.0/8", "192.0.2.7", "2001:db8::/32", "::ffff:198.51.100.0/120"})
+	assert.NoError(t, err)
+
+	tests := []struct {
+		ip    string
+		entry string
+		found bool
+	}{
+		{"10.1.2.3", "10.0.0.0/8", true},
+		{"192.0.2.7", "192.0.2.7", true},
+		{"192.0.2.8", "", false},
+		{"2001:db8:1::1", "2001:db8::/32", true},
+		{"2001:db9::1", "", false},
+		{"::ffff:10.9.9.9", "10.0.0.0/8", true},
+		{"198.51.100.20", "::ffff:198.51.100.0/120", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ip, func(t *testing.T) {
+			entry, found := matcher.Match(netip.MustParseAddr(tt.ip))
+			assert.Equal(t, tt.found, found)
+			assert.Equal(t, tt.entry, entry)
+		})
+	}
+
+	var nilMatcher *IPMatcher
+	_, found := nilMatcher.Match(netip.MustParseAddr("10.0.0.1"))
+	assert.False(t, found)
+
+	_, err = NewIPMatcher([]string{"10.0.0.0/33"})
+	assert.ErrorIs(t, err, ErrInvalidIPEntry)
+}
+
+func TestNormalizeIPEntry(t *testing.T) {
+	tests := map[string]string{
+		"10.1.2.3/8":          "10.0.0.0/8",
+		" 192.0.2.7 ":         "192.0.2.7",
+		"2001:DB8::1/32":      "2001:db8::/32",
+		"::ffff:192.0.2.7":    "192.0.2.7",
+		"::ffff:10.0.0.0/104": "10.0.0.0/8",
+	}
+
+	for entry, expected := range tests {
+		normalized, err := NormalizeIPEntry(entry)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, normalized, entry)
+	}
+
+	for _, entry := range []string{"", "not-an-ip", "10.0.0.1/", "fe80::1%eth0"} {
+		_, err := NormalizeIPEntry(entry)
+		assert.ErrorIs(t, err, ErrInvalidIPEntry, entry)
+	}
+}
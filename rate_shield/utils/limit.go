@@ -23,3 +23,21 @@ func BuildRateLimitSuccessResponse(limit, remaining int64) *models.RateLimitResp
 		HTTPStatusCode:      http.StatusOK,
 	}
 }
+
+// BuildRateLimitDeniedResponse rejects a request without going through a strategy, e.g. for a denied IP
+func BuildRateLimitDeniedResponse(reason string) *models.RateLimitResponse {
+	return &models.RateLimitResponse{
+		RateLimit_Limit:     -1,
+		RateLimit_Remaining: -1,
+		Success:             false,
+		HTTPStatusCode:      http.StatusForbidden,
+		Reason:              reason,
+	}
+}
+
+// BuildRateLimitAllowedResponse accepts a request without going through a strategy, e.g. for an allowed IP
+func BuildRateLimitAllowedResponse(reason string) *models.RateLimitResponse {
+	resp := BuildRateLimitSuccessResponse(0, 0)
+	resp.Reason = reason
+	return resp
+}
@@ -0,0 +1,76 @@
+package utils
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/x-sushant-x/RateShield/models"
+)
+
+var (
+	ErrUnknownQuotaPeriod = errors.New("unknown quota period, use hour, day or month")
+
+	// Loaded time zones by name, quotas are checked on every request
+	quotaLocations sync.Map
+)
+
+// QuotaPeriodBounds returns the start and end of the calendar period of a quota containing now. Periods
+// follow the quota's time zone, so a day is shorter or longer than 24 hours when daylight saving changes.
+func QuotaPeriodBounds(quota models.QuotaRule, now time.Time) (time.Time, time.Time, error) {
+	location, err := loadQuotaLocation(quota.Timezone)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+
+	now = now.In(location)
+	year, month, day := now.Date()
+
+	switch quota.Period {
+	case models.QuotaPeriodHour:
+		// Go back to the full local hour, this also works for zones with a half hour offset and for the
+		// hour that repeats when daylight saving ends
+		sinceHour := time.Duration(now.Minute())*time.Minute + time.Duration(now.Second())*time.Second + time.Duration(now.Nanosecond())
+		start := now.Add(-sinceHour)
+		return start, start.Add(time.Hour), nil
+	case models.QuotaPeriodDay:
+		start := time.Date(year, month, day, 0, 0, 0, 0, location)
+		return start, time.Date(year, month, day+1, 0, 0, 0, 0, location), nil
+	case models.QuotaPeriodMonth:
+		start := time.Date(year, month, 1, 0, 0, 0, 0, location)
+		return start, time.Date(year, month+1, 1, 0, 0, 0, 0, location), nil
+	}
+
+	return time.Time{}, time.Time{}, ErrUnknownQuotaPeriod
+}
+
+// QuotaPeriodID names the period starting at start, e.g. 2024-09 for a month. Counters are stored per
+// period so a new period always starts from zero. Hours carry their UTC offset, the hour that repeats when
+// daylight saving ends would otherwise share the counter of the first one.
+func QuotaPeriodID(quota models.QuotaRule, start time.Time) string {
+	switch quota.Period {
+	case models.QuotaPeriodHour:
+		return start.Format("2006-01-02T15Z0700")
+	case models.QuotaPeriodDay:
+		return start.Format("2006-01-02")
+	}
+	return start.Format("2006-01")
+}
+
+func loadQuotaLocation(timezone string) (*time.Location, error) {
+	if timezone == "" {
+		return time.UTC, nil
+	}
+
+	if cached, found := quotaLocations.Load(timezone); found {
+		return cached.(*time.Location), nil
+	}
+
+	location, err := time.LoadLocation(timezone)
+	if err != nil {
+		return nil, errors.New("unknown time zone")
+	}
+	quotaLocations.Store(timezone, location)
+
+	return location, nil
+}
@@ -0,0 +1,93 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/x-sushant-x/RateShield/models"
+)
+
+func TestQuotaPeriodBounds(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name  string
+		quota models.QuotaRule
+		now   time.Time
+		start time.Time
+		end   time.Time
+		id    string
+	}{
+		{
+			name:  "month",
+			quota: models.QuotaRule{Period: models.QuotaPeriodMonth},
+			now:   time.Date(2024, 12, 31, 23, 59, 0, 0, time.UTC),
+			start: time.Date(2024, 12, 1, 0, 0, 0, 0, time.UTC),
+			end:   time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+			id:    "2024-12",
+		},
+		{
+			name:  "month in time zone",
+			quota: models.QuotaRule{Period: models.QuotaPeriodMonth, Timezone: "Europe/Berlin"},
+			now:   time.Date(2024, 9, 30, 22, 30, 0, 0, time.UTC),
+			start: time.Date(2024, 10, 1, 0, 0, 0, 0, berlin),
+			end:   time.Date(2024, 11, 1, 0, 0, 0, 0, berlin),
+			id:    "2024-10",
+		},
+		{
+			name:  "day with daylight saving change",
+			quota: models.QuotaRule{Period: models.QuotaPeriodDay, Timezone: "Europe/Berlin"},
+			now:   time.Date(2024, 10, 27, 12, 0, 0, 0, berlin),
+			start: time.Date(2024, 10, 27, 0, 0, 0, 0, berlin),
+			end:   time.Date(2024, 10, 27, 0, 0, 0, 0, berlin).Add(25 * time.Hour),
+			id:    "2024-10-27",
+		},
+		{
+			name:  "hour",
+			quota: models.QuotaRule{Period: models.QuotaPeriodHour, Timezone: "Asia/Kolkata"},
+			now:   time.Date(2024, 9, 9, 10, 15, 30, 0, time.UTC),
+			start: time.Date(2024, 9, 9, 9, 30, 0, 0, time.UTC),
+			end:   time.Date(2024, 9, 9, 10, 30, 0, 0, time.UTC),
+			id:    "2024-09-09T15+0530",
+		},
+		{
+			name:  "hour in utc",
+			quota: models.QuotaRule{Period: models.QuotaPeriodHour},
+			now:   time.Date(2024, 9, 9, 10, 15, 30, 0, time.UTC),
+			start: time.Date(2024, 9, 9, 10, 0, 0, 0, time.UTC),
+			end:   time.Date(2024, 9, 9, 11, 0, 0, 0, time.UTC),
+			id:    "2024-09-09T10Z",
+		},
+		{
+			name:  "first hour that repeats when daylight saving ends",
+			quota: models.QuotaRule{Period: models.QuotaPeriodHour, Timezone: "Europe/Berlin"},
+			now:   time.Date(2024, 10, 27, 0, 20, 0, 0, time.UTC),
+			start: time.Date(2024, 10, 27, 0, 0, 0, 0, time.UTC),
+			end:   time.Date(2024, 10, 27, 1, 0, 0, 0, time.UTC),
+			id:    "2024-10-27T02+0200",
+		},
+		{
+			name:  "second hour that repeats when daylight saving ends",
+			quota: models.QuotaRule{Period: models.QuotaPeriodHour, Timezone: "Europe/Berlin"},
+			now:   time.Date(2024, 10, 27, 1, 20, 0, 0, time.UTC),
+			start: time.Date(2024, 10, 27, 1, 0, 0, 0, time.UTC),
+			end:   time.Date(2024, 10, 27, 2, 0, 0, 0, time.UTC),
+			id:    "2024-10-27T02+0100",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, end, err := QuotaPeriodBounds(tt.quota, tt.now)
+			assert.NoError(t, err)
+			assert.True(t, tt.start.Equal(start), "start %s", start)
+			assert.True(t, tt.end.Equal(end), "end %s", end)
+			assert.Equal(t, tt.id, QuotaPeriodID(tt.quota, start))
+		})
+	}
+
+	_, _, err = QuotaPeriodBounds(models.QuotaRule{Period: "week"}, time.Now())
+	assert.ErrorIs(t, err, ErrUnknownQuotaPeriod)
+}
@@ -0,0 +1,41 @@
+package utils
+
+import "github.com/x-sushant-x/RateShield/models"
+
+// WithRuleLimits returns a copy of the rule using the limits of its strategy. Returns false if the limits
+// have none for the strategy.
+func WithRuleLimits(rule *models.Rule, limits models.RuleLimits) (*models.Rule, bool) {
+	limited := *rule
+
+	switch rule.Strategy {
+	case models.StrategyTokenBucket:
+		if limits.TokenBucketRule == nil {
+			return rule, false
+		}
+		limited.TokenBucketRule = limits.TokenBucketRule
+	case models.StrategyFixedWindowCounter:
+		if limits.FixedWindowCounterRule == nil {
+			return rule, false
+		}
+		limited.FixedWindowCounterRule = limits.FixedWindowCounterRule
+	case models.StrategySlidingWindowCounter:
+		if limits.SlidingWindowCounterRule == nil {
+			return rule, false
+		}
+		limited.SlidingWindowCounterRule = limits.SlidingWindowCounterRule
+	case models.StrategyQuota:
+		if limits.QuotaRule == nil {
+			return rule, false
+		}
+		limited.QuotaRule = limits.QuotaRule
+	case models.StrategyConcurrency:
+		if limits.ConcurrencyRule == nil {
+			return rule, false
+		}
+		limited.ConcurrencyRule = limits.ConcurrencyRule
+	default:
+		return rule, false
+	}
+
+	return &limited, true
+}
@@ -0,0 +1,97 @@
+package utils
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	"github.com/x-sushant-x/RateShield/models"
+)
+
+var (
+	// Parsed schedules by cron expression and time zone, rules are checked on every request
+	parsedSchedules sync.Map
+)
+
+type parsedSchedule struct {
+	schedule cron.Schedule
+	location *time.Location
+}
+
+func parseRuleSchedule(schedule models.RuleSchedule) (parsedSchedule, error) {
+	cacheKey := schedule.Timezone + "|" + schedule.Cron
+	if cached, found := parsedSchedules.Load(cacheKey); found {
+		return cached.(parsedSchedule), nil
+	}
+
+	location := time.UTC
+	if schedule.Timezone != "" {
+		loc, err := time.LoadLocation(schedule.Timezone)
+		if err != nil {
+			return parsedSchedule{}, errors.New("unknown time zone")
+		}
+		location = loc
+	}
+
+	cronSchedule, err := cron.ParseStandard(schedule.Cron)
+	if err != nil {
+		return parsedSchedule{}, err
+	}
+
+	parsed := parsedSchedule{
+		schedule: cronSchedule,
+		location: location,
+	}
+	parsedSchedules.Store(cacheKey, parsed)
+
+	return parsed, nil
+}
+
+// IsRuleExpired reports whether a rule's expires_at has passed
+func IsRuleExpired(rule models.Rule, now time.Time) bool {
+	return rule.ExpiresAt != 0 && now.Unix() >= rule.ExpiresAt
+}
+
+// IsRuleActive reports whether a rule applies at the given time: it has not expired and, if it has a
+// schedule, one of its windows is open
+func IsRuleActive(rule models.Rule, now time.Time) bool {
+	if IsRuleExpired(rule, now) {
+		return false
+	}
+
+	if rule.Schedule == nil {
+		return true
+	}
+
+	parsed, err := parseRuleSchedule(*rule.Schedule)
+	if err != nil {
+		return false
+	}
+
+	// A window is open if the schedule fired within the last Duration seconds
+	windowStart := now.In(parsed.location).Add(-time.Duration(rule.Schedule.Duration) * time.Second)
+	return !parsed.schedule.Next(windowStart).After(now)
+}
+
+// SelectActiveRule returns the rule to enforce at the given time: nothing once the rule expired, else the
+// first active scheduled rule, else the rule itself if it is active. Scheduled rules are returned with the
+// endpoint of their rule.
+func SelectActiveRule(rule *models.Rule, now time.Time) *models.Rule {
+	if rule == nil || IsRuleExpired(*rule, now) {
+		return nil
+	}
+
+	for _, scheduledRule := range rule.ScheduledRules {
+		if IsRuleActive(scheduledRule, now) {
+			scheduledRule.APIEndpoint = rule.APIEndpoint
+			return &scheduledRule
+		}
+	}
+
+	if !IsRuleActive(*rule, now) {
+		return nil
+	}
+
+	return rule
+}
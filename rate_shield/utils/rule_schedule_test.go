@@ -0,0 +1,76 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/x-sushant-x/RateShield/models"
+)
+
+func TestIsRuleActive(t *testing.T) {
+	// Business hours in Berlin: 09:00 to 17:00 Monday to Friday
+	businessHours := models.Rule{
+		Schedule: &models.RuleSchedule{Cron: "0 9 * * MON-FRI", Duration: 8 * 60 * 60, Timezone: "Europe/Berlin"},
+	}
+
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name   string
+		now    time.Time
+		active bool
+	}{
+		{"window start", time.Date(2024, 9, 9, 9, 0, 0, 0, berlin), true},
+		{"during window", time.Date(2024, 9, 9, 13, 30, 0, 0, berlin), true},
+		{"window end", time.Date(2024, 9, 9, 17, 0, 0, 0, berlin), false},
+		{"before window", time.Date(2024, 9, 9, 8, 59, 0, 0, berlin), false},
+		{"weekend", time.Date(2024, 9, 8, 13, 30, 0, 0, berlin), false},
+		{"during window in UTC", time.Date(2024, 9, 9, 7, 30, 0, 0, time.UTC), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.active, IsRuleActive(businessHours, tt.now))
+		})
+	}
+
+	t.Run("expiry", func(t *testing.T) {
+		now := time.Unix(1_700_000_000, 0)
+
+		assert.True(t, IsRuleActive(models.Rule{ExpiresAt: now.Unix() + 1}, now))
+		assert.False(t, IsRuleActive(models.Rule{ExpiresAt: now.Unix()}, now))
+		assert.True(t, IsRuleActive(models.Rule{}, now))
+	})
+}
+
+func TestSelectActiveRule(t *testing.T) {
+	now := time.Date(2024, 9, 9, 9, 30, 0, 0, time.UTC)
+
+	rule := &models.Rule{
+		APIEndpoint: "/api/v1/get-data",
+		Strategy:    models.StrategyFixedWindowCounter,
+		ScheduledRules: []models.Rule{
+			{
+				Strategy:  models.StrategyFixedWindowCounter,
+				ExpiresAt: now.Unix() - 60,
+			},
+			{
+				Strategy: models.StrategySlidingWindowCounter,
+				Schedule: &models.RuleSchedule{Cron: "0 9 * * *", Duration: 3600},
+			},
+		},
+	}
+
+	selected := SelectActiveRule(rule, now)
+	assert.Equal(t, models.StrategySlidingWindowCounter, selected.Strategy)
+	assert.Equal(t, rule.APIEndpoint, selected.APIEndpoint)
+
+	assert.Same(t, rule, SelectActiveRule(rule, now.Add(time.Hour)))
+
+	rule.ExpiresAt = now.Unix()
+	assert.Nil(t, SelectActiveRule(rule, now))
+
+	assert.Nil(t, SelectActiveRule(nil, now))
+}
@@ -0,0 +1,395 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	"github.com/x-sushant-x/RateShield/models"
+)
+
+var (
+	validHTTPMethods = []string{"GET", "POST", "PUT", "PATCH", "DELETE", "HEAD", "OPTIONS"}
+)
+
+// FieldError describes why a single field of a rule is invalid
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// RuleValidationError holds every problem found in a rule
+type RuleValidationError struct {
+	Errors []FieldError `json:"errors"`
+}
+
+func (e *RuleValidationError) Error() string {
+	messages := make([]string, 0, len(e.Errors))
+	for _, fieldErr := range e.Errors {
+		messages = append(messages, fieldErr.Field+": "+fieldErr.Message)
+	}
+	return "invalid rule: " + strings.Join(messages, "; ")
+}
+
+func (e *RuleValidationError) add(field, message string) {
+	e.Errors = append(e.Errors, FieldError{Field: field, Message: message})
+}
+
+// ValidateRule checks that a rule's strategy matches the sub-rule it carries and that every limit is in
+// range. Returns a *RuleValidationError listing every invalid field, or nil if the rule is valid.
+func ValidateRule(rule models.Rule) error {
+	errs := &RuleValidationError{}
+
+	if len(strings.TrimSpace(rule.APIEndpoint)) == 0 {
+		errs.add("endpoint", "must not be empty")
+	} else if strings.ContainsAny(rule.APIEndpoint, " \t\r\n") {
+		errs.add("endpoint", "must not contain whitespace")
+	}
+
+	if rule.HTTPMethod != "" && !isValidHTTPMethod(rule.HTTPMethod) {
+		errs.add("http_method", fmt.Sprintf("must be one of %s", strings.Join(validHTTPMethods, ", ")))
+	}
+
+	switch rule.Strategy {
+	case models.StrategyTokenBucket:
+		validateTokenBucketRule(rule.TokenBucketRule, errs)
+	case models.StrategyFixedWindowCounter:
+		validateFixedWindowCounterRule(rule.FixedWindowCounterRule, errs)
+	case models.StrategySlidingWindowCounter:
+		validateSlidingWindowCounterRule(rule.SlidingWindowCounterRule, errs)
+	case models.StrategyQuota:
+		validateQuotaRule(rule.QuotaRule, errs)
+	case models.StrategyConcurrency:
+		validateConcurrencyRule(rule.ConcurrencyRule, errs)
+	default:
+		errs.add("strategy", fmt.Sprintf("must be one of %s, %s, %s, %s, %s",
+			models.StrategyTokenBucket, models.StrategyFixedWindowCounter, models.StrategySlidingWindowCounter,
+			models.StrategyQuota, models.StrategyConcurrency))
+	}
+
+	if rule.Strategy != models.StrategyTokenBucket && rule.TokenBucketRule != nil {
+		errs.add("token_bucket_rule", "must only be set for strategy "+models.StrategyTokenBucket)
+	}
+
+	if rule.Strategy != models.StrategyFixedWindowCounter && rule.FixedWindowCounterRule != nil {
+		errs.add("fixed_window_counter_rule", "must only be set for strategy "+models.StrategyFixedWindowCounter)
+	}
+
+	if rule.Strategy != models.StrategySlidingWindowCounter && rule.SlidingWindowCounterRule != nil {
+		errs.add("sliding_window_counter_rule", "must only be set for strategy "+models.StrategySlidingWindowCounter)
+	}
+
+	if rule.Strategy != models.StrategyQuota && rule.QuotaRule != nil {
+		errs.add("quota_rule", "must only be set for strategy "+models.StrategyQuota)
+	}
+
+	if rule.Strategy != models.StrategyConcurrency && rule.ConcurrencyRule != nil {
+		errs.add("concurrency_rule", "must only be set for strategy "+models.StrategyConcurrency)
+	}
+
+	if rule.ExpiresAt < 0 {
+		errs.add("expires_at", "must be a unix timestamp")
+	}
+
+	if rule.Schedule != nil {
+		validateRuleSchedule(*rule.Schedule, errs)
+	}
+
+	validateIPList(rule.AllowList, "allow_list", errs)
+	validateIPList(rule.DenyList, "deny_list", errs)
+
+	validateRuleOverrides(rule, errs)
+	validateRuleTiers(rule, errs)
+
+	if rule.Penalty != nil {
+		validatePenaltyPolicy(*rule.Penalty, errs)
+	}
+
+	for i, scheduledRule := range rule.ScheduledRules {
+		validateScheduledRule(rule, scheduledRule, fmt.Sprintf("scheduled_rules[%d]", i), errs)
+	}
+
+	if len(errs.Errors) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// ValidateRules validates a set of rules that are saved together, such as an import bundle. Fields are
+// reported as rules[<index>].<field> and endpoints defined more than once are rejected.
+func ValidateRules(rules []models.Rule) error {
+	errs := &RuleValidationError{}
+	seen := map[string]int{}
+
+	for i, rule := range rules {
+		var ruleErr *RuleValidationError
+		if err := ValidateRule(rule); errors.As(err, &ruleErr) {
+			for _, fieldErr := range ruleErr.Errors {
+				errs.add(fmt.Sprintf("rules[%d].%s", i, fieldErr.Field), fieldErr.Message)
+			}
+		}
+
+		if first, found := seen[rule.APIEndpoint]; found {
+			errs.add(fmt.Sprintf("rules[%d].endpoint", i), fmt.Sprintf("is already defined by rules[%d]", first))
+			continue
+		}
+		seen[rule.APIEndpoint] = i
+	}
+
+	if len(errs.Errors) > 0 {
+		return errs
+	}
+	return nil
+}
+
+func validateTokenBucketRule(rule *models.TokenBucketRule, errs *RuleValidationError) {
+	if rule == nil {
+		errs.add("token_bucket_rule", "is required for strategy "+models.StrategyTokenBucket)
+		return
+	}
+
+	if rule.BucketCapacity <= 0 {
+		errs.add("token_bucket_rule.bucket_capacity", "must be greater than 0")
+	}
+
+	if rule.TokenAddRate <= 0 {
+		errs.add("token_bucket_rule.token_add_rate", "must be greater than 0")
+	}
+
+	if rule.RetentionTime <= 0 {
+		errs.add("token_bucket_rule.retention_time", "must be greater than 0")
+	}
+}
+
+func validateFixedWindowCounterRule(rule *models.FixedWindowCounterRule, errs *RuleValidationError) {
+	if rule == nil {
+		errs.add("fixed_window_counter_rule", "is required for strategy "+models.StrategyFixedWindowCounter)
+		return
+	}
+
+	if rule.MaxRequests <= 0 {
+		errs.add("fixed_window_counter_rule.max_requests", "must be greater than 0")
+	}
+
+	if rule.Window <= 0 {
+		errs.add("fixed_window_counter_rule.window", "must be greater than 0")
+	}
+}
+
+func validateSlidingWindowCounterRule(rule *models.SlidingWindowCounterRule, errs *RuleValidationError) {
+	if rule == nil {
+		errs.add("sliding_window_counter_rule", "is required for strategy "+models.StrategySlidingWindowCounter)
+		return
+	}
+
+	if rule.MaxRequests <= 0 {
+		errs.add("sliding_window_counter_rule.max_requests", "must be greater than 0")
+	}
+
+	if rule.WindowSize <= 0 {
+		errs.add("sliding_window_counter_rule.window", "must be greater than 0")
+	}
+}
+
+func validateQuotaRule(rule *models.QuotaRule, errs *RuleValidationError) {
+	if rule == nil {
+		errs.add("quota_rule", "is required for strategy "+models.StrategyQuota)
+		return
+	}
+
+	if rule.Limit <= 0 {
+		errs.add("quota_rule.limit", "must be greater than 0")
+	}
+
+	switch rule.Period {
+	case models.QuotaPeriodHour, models.QuotaPeriodDay, models.QuotaPeriodMonth:
+	default:
+		errs.add("quota_rule.period", fmt.Sprintf("must be one of %s, %s, %s",
+			models.QuotaPeriodHour, models.QuotaPeriodDay, models.QuotaPeriodMonth))
+	}
+
+	if rule.Timezone != "" {
+		if _, err := loadQuotaLocation(rule.Timezone); err != nil {
+			errs.add("quota_rule.timezone", "must be an IANA time zone such as Europe/Berlin")
+		}
+	}
+}
+
+func validateConcurrencyRule(rule *models.ConcurrencyRule, errs *RuleValidationError) {
+	if rule == nil {
+		errs.add("concurrency_rule", "is required for strategy "+models.StrategyConcurrency)
+		return
+	}
+
+	if rule.MaxConcurrent <= 0 {
+		errs.add("concurrency_rule.max_concurrent", "must be greater than 0")
+	}
+
+	if rule.LeaseTimeout <= 0 {
+		errs.add("concurrency_rule.lease_timeout", "must be greater than 0")
+	}
+
+	switch rule.Scope {
+	case "", models.ConcurrencyScopeClient, models.ConcurrencyScopeEndpoint:
+	default:
+		errs.add("concurrency_rule.scope", fmt.Sprintf("must be %s or %s", models.ConcurrencyScopeClient, models.ConcurrencyScopeEndpoint))
+	}
+}
+
+func validatePenaltyPolicy(policy models.PenaltyPolicy, errs *RuleValidationError) {
+	if policy.MaxDenials <= 0 {
+		errs.add("penalty.max_denials", "must be greater than 0")
+	}
+
+	if policy.Window <= 0 {
+		errs.add("penalty.window", "must be greater than 0")
+	}
+
+	if policy.BanDuration <= 0 {
+		errs.add("penalty.ban_duration", "must be greater than 0")
+	}
+
+	if policy.MaxBanDuration != 0 && policy.MaxBanDuration < policy.BanDuration {
+		errs.add("penalty.max_ban_duration", "must not be less than ban_duration")
+	}
+}
+
+func validateRuleSchedule(schedule models.RuleSchedule, errs *RuleValidationError) {
+	if schedule.Timezone != "" {
+		if _, err := time.LoadLocation(schedule.Timezone); err != nil {
+			errs.add("schedule.timezone", "must be an IANA time zone such as Europe/Berlin")
+		}
+	}
+
+	if _, err := cron.ParseStandard(schedule.Cron); err != nil {
+		errs.add("schedule.cron", "must be a 5 field cron expression: "+err.Error())
+	}
+
+	if schedule.Duration <= 0 {
+		errs.add("schedule.duration", "must be greater than 0")
+	}
+}
+
+func validateScheduledRule(rule, scheduledRule models.Rule, field string, errs *RuleValidationError) {
+	if scheduledRule.APIEndpoint != "" && scheduledRule.APIEndpoint != rule.APIEndpoint {
+		errs.add(field+".endpoint", "must be empty or the endpoint of the rule")
+	}
+
+	if scheduledRule.Schedule == nil && scheduledRule.ExpiresAt == 0 {
+		errs.add(field, "must have a schedule or expires_at")
+	}
+
+	if len(scheduledRule.AllowList) > 0 || len(scheduledRule.DenyList) > 0 {
+		errs.add(field, "allow_list and deny_list must be set on the rule itself")
+	}
+
+	if len(scheduledRule.Overrides) > 0 || len(scheduledRule.Tiers) > 0 {
+		errs.add(field, "overrides and tiers must be set on the rule itself")
+	}
+
+	if scheduledRule.Penalty != nil {
+		errs.add(field+".penalty", "must be set on the rule itself")
+	}
+
+	if len(scheduledRule.ScheduledRules) > 0 {
+		errs.add(field+".scheduled_rules", "must not be set on a scheduled rule")
+		return
+	}
+
+	scheduledRule.APIEndpoint = rule.APIEndpoint
+
+	var scheduledErr *RuleValidationError
+	if err := ValidateRule(scheduledRule); errors.As(err, &scheduledErr) {
+		for _, fieldErr := range scheduledErr.Errors {
+			errs.add(field+"."+fieldErr.Field, fieldErr.Message)
+		}
+	}
+}
+
+func validateRuleOverrides(rule models.Rule, errs *RuleValidationError) {
+	seen := map[string]bool{}
+
+	for i, override := range rule.Overrides {
+		field := fmt.Sprintf("overrides[%d]", i)
+
+		if strings.TrimSpace(override.Identity) == "" {
+			errs.add(field+".identity", "must not be empty")
+		} else if seen[override.Identity] {
+			errs.add(field+".identity", "is already overridden")
+		}
+		seen[override.Identity] = true
+
+		validateRuleLimits(rule, override.RuleLimits, field, errs)
+	}
+}
+
+func validateRuleTiers(rule models.Rule, errs *RuleValidationError) {
+	seen := map[string]bool{}
+
+	for i, tier := range rule.Tiers {
+		field := fmt.Sprintf("tiers[%d]", i)
+
+		if strings.TrimSpace(tier.Name) == "" || strings.ContainsAny(tier.Name, " \t\r\n") {
+			errs.add(field+".name", "must not be empty or contain whitespace")
+		} else if seen[tier.Name] {
+			errs.add(field+".name", "is already defined")
+		}
+		seen[tier.Name] = true
+
+		validateRuleLimits(rule, tier.RuleLimits, field, errs)
+	}
+}
+
+// validateRuleLimits checks limits like the sub-rules of the rule itself, they must belong to its strategy
+func validateRuleLimits(rule models.Rule, limits models.RuleLimits, field string, errs *RuleValidationError) {
+	limitsRule := models.Rule{
+		APIEndpoint:              rule.APIEndpoint,
+		Strategy:                 rule.Strategy,
+		TokenBucketRule:          limits.TokenBucketRule,
+		FixedWindowCounterRule:   limits.FixedWindowCounterRule,
+		SlidingWindowCounterRule: limits.SlidingWindowCounterRule,
+		QuotaRule:                limits.QuotaRule,
+		ConcurrencyRule:          limits.ConcurrencyRule,
+	}
+
+	var limitsErr *RuleValidationError
+	if err := ValidateRule(limitsRule); errors.As(err, &limitsErr) {
+		for _, fieldErr := range limitsErr.Errors {
+			// An unknown strategy is already reported for the rule
+			if fieldErr.Field == "strategy" {
+				continue
+			}
+			errs.add(field+"."+fieldErr.Field, fieldErr.Message)
+		}
+	}
+
+	// Quota counters are stored per period, so every client of a rule has to share its periods
+	if limits.QuotaRule != nil && rule.QuotaRule != nil &&
+		(limits.QuotaRule.Period != rule.QuotaRule.Period || limits.QuotaRule.Timezone != rule.QuotaRule.Timezone) {
+		errs.add(field+".quota_rule", "must use the period and timezone of the rule")
+	}
+
+	// Leases are counted per scope, a client can't be counted alone while others share the endpoint
+	if limits.ConcurrencyRule != nil && rule.ConcurrencyRule != nil && limits.ConcurrencyRule.Scope != rule.ConcurrencyRule.Scope {
+		errs.add(field+".concurrency_rule", "must use the scope of the rule")
+	}
+}
+
+func validateIPList(entries []string, field string, errs *RuleValidationError) {
+	for i, entry := range entries {
+		if _, err := ParseIPEntry(entry); err != nil {
+			errs.add(fmt.Sprintf("%s[%d]", field, i), err.Error())
+		}
+	}
+}
+
+func isValidHTTPMethod(method string) bool {
+	for _, valid := range validHTTPMethods {
+		if method == valid {
+			return true
+		}
+	}
+	return false
+}
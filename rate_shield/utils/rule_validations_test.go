@@ -0,0 +1,247 @@
+package utils
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/x-sushant-x/RateShield/models"
+)
+
+func fieldsOf(err error) []string {
+	var validationErr *RuleValidationError
+	if !errors.As(err, &validationErr) {
+		return nil
+	}
+
+	fields := []string{}
+	for _, fieldErr := range validationErr.Errors {
+		fields = append(fields, fieldErr.Field)
+	}
+	return fields
+}
+
+func TestValidateRule(t *testing.T) {
+	t.Run("valid rules", func(t *testing.T) {
+		rules := []models.Rule{
+			{
+				Strategy:        models.StrategyTokenBucket,
+				APIEndpoint:     "/api/v1/get-data",
+				HTTPMethod:      "GET",
+				TokenBucketRule: &models.TokenBucketRule{BucketCapacity: 10, TokenAddRate: 5, RetentionTime: 60},
+			},
+			{
+				Strategy:               models.StrategyFixedWindowCounter,
+				APIEndpoint:            "/api/v1/create",
+				HTTPMethod:             "POST",
+				FixedWindowCounterRule: &models.FixedWindowCounterRule{MaxRequests: 100, Window: 60},
+			},
+			{
+				Strategy:                 models.StrategySlidingWindowCounter,
+				APIEndpoint:              "/api/v1/search",
+				SlidingWindowCounterRule: &models.SlidingWindowCounterRule{MaxRequests: 100, WindowSize: 60},
+			},
+		}
+
+		for _, rule := range rules {
+			assert.NoError(t, ValidateRule(rule), rule.Strategy)
+		}
+	})
+
+	t.Run("unknown strategy and empty endpoint", func(t *testing.T) {
+		err := ValidateRule(models.Rule{Strategy: "LEAKY BUCKET"})
+		assert.ElementsMatch(t, []string{"endpoint", "strategy"}, fieldsOf(err))
+	})
+
+	t.Run("missing sub-rule", func(t *testing.T) {
+		err := ValidateRule(models.Rule{Strategy: models.StrategyTokenBucket, APIEndpoint: "/test"})
+		assert.Equal(t, []string{"token_bucket_rule"}, fieldsOf(err))
+	})
+
+	t.Run("sub-rule of another strategy", func(t *testing.T) {
+		err := ValidateRule(models.Rule{
+			Strategy:               models.StrategySlidingWindowCounter,
+			APIEndpoint:            "/test",
+			FixedWindowCounterRule: &models.FixedWindowCounterRule{MaxRequests: 10, Window: 10},
+		})
+		assert.ElementsMatch(t, []string{"sliding_window_counter_rule", "fixed_window_counter_rule"}, fieldsOf(err))
+	})
+
+	t.Run("out of range values", func(t *testing.T) {
+		err := ValidateRule(models.Rule{
+			Strategy:        models.StrategyTokenBucket,
+			APIEndpoint:     "/test",
+			HTTPMethod:      "FETCH",
+			TokenBucketRule: &models.TokenBucketRule{BucketCapacity: -1, TokenAddRate: 0, RetentionTime: 0},
+		})
+		assert.ElementsMatch(t, []string{
+			"http_method",
+			"token_bucket_rule.bucket_capacity",
+			"token_bucket_rule.token_add_rate",
+			"token_bucket_rule.retention_time",
+		}, fieldsOf(err))
+
+		err = ValidateRule(models.Rule{
+			Strategy:               models.StrategyFixedWindowCounter,
+			APIEndpoint:            "/test",
+			FixedWindowCounterRule: &models.FixedWindowCounterRule{MaxRequests: 10, Window: 0},
+		})
+		assert.Equal(t, []string{"fixed_window_counter_rule.window"}, fieldsOf(err))
+	})
+}
+
+func TestValidateRules(t *testing.T) {
+	valid := models.Rule{
+		Strategy:               models.StrategyFixedWindowCounter,
+		APIEndpoint:            "/api/v1/create",
+		FixedWindowCounterRule: &models.FixedWindowCounterRule{MaxRequests: 100, Window: 60},
+	}
+
+	assert.NoError(t, ValidateRules([]models.Rule{valid}))
+
+	invalid := valid
+	invalid.APIEndpoint = "/api/v1/other"
+	invalid.FixedWindowCounterRule = &models.FixedWindowCounterRule{MaxRequests: 0, Window: 60}
+
+	err := ValidateRules([]models.Rule{valid, invalid, valid})
+	assert.ElementsMatch(t, []string{"rules[1].fixed_window_counter_rule.max_requests", "rules[2].endpoint"}, fieldsOf(err))
+}
+
+func TestValidateRuleSchedule(t *testing.T) {
+	rule := models.Rule{
+		Strategy:               models.StrategyFixedWindowCounter,
+		APIEndpoint:            "/api/v1/create",
+		FixedWindowCounterRule: &models.FixedWindowCounterRule{MaxRequests: 100, Window: 60},
+		Schedule:               &models.RuleSchedule{Cron: "0 9 * * MON-FRI", Duration: 28800, Timezone: "Europe/Berlin"},
+		ScheduledRules: []models.Rule{
+			{
+				Strategy:               models.StrategyFixedWindowCounter,
+				FixedWindowCounterRule: &models.FixedWindowCounterRule{MaxRequests: 10, Window: 60},
+				ExpiresAt:              1_900_000_000,
+			},
+		},
+	}
+	assert.NoError(t, ValidateRule(rule))
+
+	rule.Schedule = &models.RuleSchedule{Cron: "every day", Duration: 0, Timezone: "Mars/Olympus"}
+	rule.ScheduledRules = []models.Rule{
+		{
+			APIEndpoint:            "/api/v1/other",
+			Strategy:               models.StrategyFixedWindowCounter,
+			FixedWindowCounterRule: &models.FixedWindowCounterRule{MaxRequests: 0, Window: 60},
+		},
+	}
+
+	assert.ElementsMatch(t, []string{
+		"schedule.cron",
+		"schedule.duration",
+		"schedule.timezone",
+		"scheduled_rules[0].endpoint",
+		"scheduled_rules[0]",
+		"scheduled_rules[0].fixed_window_counter_rule.max_requests",
+	}, fieldsOf(ValidateRule(rule)))
+}
+
+func TestValidateRuleOverridesAndTiers(t *testing.T) {
+	rule := models.Rule{
+		Strategy:               models.StrategyFixedWindowCounter,
+		APIEndpoint:            "/api/v1/create",
+		FixedWindowCounterRule: &models.FixedWindowCounterRule{MaxRequests: 100, Window: 60},
+		Overrides: []models.RuleOverride{
+			{Identity: "customer-42", RuleLimits: models.RuleLimits{FixedWindowCounterRule: &models.FixedWindowCounterRule{MaxRequests: 1000, Window: 60}}},
+		},
+		Tiers: []models.RuleTier{
+			{Name: "free", RuleLimits: models.RuleLimits{FixedWindowCounterRule: &models.FixedWindowCounterRule{MaxRequests: 10, Window: 60}}},
+			{Name: "pro", RuleLimits: models.RuleLimits{FixedWindowCounterRule: &models.FixedWindowCounterRule{MaxRequests: 500, Window: 60}}},
+		},
+	}
+	assert.NoError(t, ValidateRule(rule))
+
+	rule.Overrides = append(rule.Overrides, models.RuleOverride{
+		Identity:   "customer-42",
+		RuleLimits: models.RuleLimits{TokenBucketRule: &models.TokenBucketRule{BucketCapacity: 10, TokenAddRate: 1}},
+	})
+	rule.Tiers = append(rule.Tiers, models.RuleTier{
+		Name:       "pro plus",
+		RuleLimits: models.RuleLimits{FixedWindowCounterRule: &models.FixedWindowCounterRule{MaxRequests: 0, Window: 60}},
+	})
+
+	assert.ElementsMatch(t, []string{
+		"overrides[1].identity",
+		"overrides[1].fixed_window_counter_rule",
+		"overrides[1].token_bucket_rule",
+		"tiers[2].name",
+		"tiers[2].fixed_window_counter_rule.max_requests",
+	}, fieldsOf(ValidateRule(rule)))
+}
+
+func TestValidateQuotaRule(t *testing.T) {
+	rule := models.Rule{
+		Strategy:    models.StrategyQuota,
+		APIEndpoint: "/api/v1/search",
+		QuotaRule:   &models.QuotaRule{Limit: 10000, Period: models.QuotaPeriodMonth, Timezone: "Europe/Berlin"},
+		Tiers: []models.RuleTier{
+			{Name: "pro", RuleLimits: models.RuleLimits{QuotaRule: &models.QuotaRule{Limit: 100000, Period: models.QuotaPeriodMonth, Timezone: "Europe/Berlin"}}},
+		},
+	}
+	assert.NoError(t, ValidateRule(rule))
+
+	rule.QuotaRule = &models.QuotaRule{Limit: 0, Period: "week", Timezone: "Mars/Olympus"}
+	rule.FixedWindowCounterRule = &models.FixedWindowCounterRule{MaxRequests: 10, Window: 60}
+
+	assert.ElementsMatch(t, []string{
+		"quota_rule.limit",
+		"quota_rule.period",
+		"quota_rule.timezone",
+		"fixed_window_counter_rule",
+		"tiers[0].quota_rule",
+	}, fieldsOf(ValidateRule(rule)))
+}
+
+func TestValidateConcurrencyRule(t *testing.T) {
+	rule := models.Rule{
+		Strategy:        models.StrategyConcurrency,
+		APIEndpoint:     "/api/v1/report",
+		ConcurrencyRule: &models.ConcurrencyRule{MaxConcurrent: 5, LeaseTimeout: 30},
+		Overrides: []models.RuleOverride{
+			{Identity: "customer-42", RuleLimits: models.RuleLimits{ConcurrencyRule: &models.ConcurrencyRule{MaxConcurrent: 20, LeaseTimeout: 30}}},
+		},
+	}
+	assert.NoError(t, ValidateRule(rule))
+
+	rule.ConcurrencyRule = &models.ConcurrencyRule{MaxConcurrent: 0, LeaseTimeout: -1, Scope: "global"}
+
+	assert.ElementsMatch(t, []string{
+		"concurrency_rule.max_concurrent",
+		"concurrency_rule.lease_timeout",
+		"concurrency_rule.scope",
+		"overrides[0].concurrency_rule",
+	}, fieldsOf(ValidateRule(rule)))
+}
+
+func TestValidatePenaltyPolicy(t *testing.T) {
+	rule := models.Rule{
+		Strategy:               models.StrategyFixedWindowCounter,
+		APIEndpoint:            "/api/v1/login",
+		FixedWindowCounterRule: &models.FixedWindowCounterRule{MaxRequests: 5, Window: 60},
+		Penalty:                &models.PenaltyPolicy{MaxDenials: 20, Window: 300, BanDuration: 600},
+	}
+	assert.NoError(t, ValidateRule(rule))
+
+	rule.Penalty = &models.PenaltyPolicy{MaxDenials: 0, Window: 0, BanDuration: 600, MaxBanDuration: 60}
+	rule.ScheduledRules = []models.Rule{
+		{
+			Strategy:               models.StrategyFixedWindowCounter,
+			FixedWindowCounterRule: &models.FixedWindowCounterRule{MaxRequests: 1, Window: 60},
+			ExpiresAt:              1_900_000_000,
+			Penalty:                &models.PenaltyPolicy{MaxDenials: 1, Window: 60, BanDuration: 60},
+		},
+	}
+
+	assert.ElementsMatch(t, []string{
+		"penalty.max_denials",
+		"penalty.window",
+		"penalty.max_ban_duration",
+		"scheduled_rules[0].penalty",
+	}, fieldsOf(ValidateRule(rule)))
+}
@@ -0,0 +1,38 @@
+package utils
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/x-sushant-x/RateShield/models"
+)
+
+// ValidateRuleWebhook checks the name, URL and endpoints of a rule webhook. Returns a *RuleValidationError
+// listing every invalid field, or nil if the webhook is valid.
+func ValidateRuleWebhook(webhook models.RuleWebhook) error {
+	errs := &RuleValidationError{}
+
+	if len(strings.TrimSpace(webhook.Name)) == 0 {
+		errs.add("name", "must not be empty")
+	} else if strings.ContainsAny(webhook.Name, " \t\r\n") {
+		errs.add("name", "must not contain whitespace")
+	}
+
+	if len(webhook.URL) == 0 {
+		errs.add("url", "must not be empty")
+	} else if parsed, err := url.Parse(webhook.URL); err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		errs.add("url", "must be an absolute http or https URL")
+	}
+
+	for i, endpoint := range webhook.Endpoints {
+		if len(strings.TrimSpace(endpoint)) == 0 || strings.ContainsAny(endpoint, " \t\r\n") {
+			errs.add(fmt.Sprintf("endpoints[%d]", i), "must not be empty or contain whitespace")
+		}
+	}
+
+	if len(errs.Errors) > 0 {
+		return errs
+	}
+	return nil
+}
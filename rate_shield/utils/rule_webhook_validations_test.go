@@ -0,0 +1,54 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/x-sushant-x/RateShield/models"
+)
+
+func TestValidateRuleWebhook(t *testing.T) {
+	t.Run("valid webhooks", func(t *testing.T) {
+		webhooks := []models.RuleWebhook{
+			{Name: "change-management", URL: "https://cm.example.com/hooks/rateshield", Secret: "s3cret"},
+			{Name: "search-team", URL: "http://10.0.0.5:8080/rules", Endpoints: []string{"/api/v1/search"}, Disabled: true},
+		}
+
+		for _, webhook := range webhooks {
+			assert.NoError(t, ValidateRuleWebhook(webhook), webhook.Name)
+		}
+	})
+
+	tests := []struct {
+		name    string
+		webhook models.RuleWebhook
+		fields  []string
+	}{
+		{
+			name:    "missing name and url",
+			webhook: models.RuleWebhook{},
+			fields:  []string{"name", "url"},
+		},
+		{
+			name:    "name with whitespace and relative url",
+			webhook: models.RuleWebhook{Name: "change management", URL: "/hooks/rateshield"},
+			fields:  []string{"name", "url"},
+		},
+		{
+			name:    "unsupported scheme",
+			webhook: models.RuleWebhook{Name: "cm", URL: "ftp://cm.example.com/hooks"},
+			fields:  []string{"url"},
+		},
+		{
+			name:    "empty endpoint",
+			webhook: models.RuleWebhook{Name: "cm", URL: "https://cm.example.com", Endpoints: []string{"/api/v1/search", " "}},
+			fields:  []string{"endpoints[1]"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.fields, fieldsOf(ValidateRuleWebhook(tt.webhook)))
+		})
+	}
+}
@@ -0,0 +1,178 @@
+package utils
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/x-sushant-x/RateShield/models"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	ErrUnsupportedRulesFormat = errors.New("unsupported rules file format, use .yaml, .yml or .json")
+)
+
+// rulesDocument is the layout of a rules file. A file may also be a plain list of rules.
+type rulesDocument struct {
+	Rules []models.Rule `json:"rules"`
+}
+
+// LoadRulesFile reads the rules defined in a file, or in every .yaml, .yml and .json file under a
+// directory, and checks that they are valid. It also returns a checksum of the files so callers can tell
+// when they change.
+func LoadRulesFile(path string) ([]models.Rule, string, error) {
+	files, err := listRulesFiles(path)
+	if err != nil {
+		return nil, "", err
+	}
+
+	hash := sha256.New()
+	rules := []models.Rule{}
+	definedIn := map[string]string{}
+
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, "", err
+		}
+
+		hash.Write([]byte(file))
+		hash.Write(data)
+
+		fileRules, err := ParseRulesDocument(data, filepath.Ext(file))
+		if err != nil {
+			return nil, "", fmt.Errorf("%s: %w", file, err)
+		}
+
+		for i, rule := range fileRules {
+			if err := ValidateRule(rule); err != nil {
+				return nil, "", fmt.Errorf("%s: rule %d: %w", file, i+1, err)
+			}
+
+			if otherFile, found := definedIn[rule.APIEndpoint]; found {
+				return nil, "", fmt.Errorf("%s: rule %d: endpoint %s is already defined in %s", file, i+1, rule.APIEndpoint, otherFile)
+			}
+			definedIn[rule.APIEndpoint] = file
+
+			rules = append(rules, rule)
+		}
+	}
+
+	return rules, hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// ParseRulesDocument parses rules written as YAML or JSON depending on the file extension. Fields are
+// named like in the API and unknown fields are rejected so typos don't go unnoticed.
+func ParseRulesDocument(data []byte, extension string) ([]models.Rule, error) {
+	var document interface{}
+
+	switch strings.ToLower(extension) {
+	case ".yaml", ".yml":
+		if err := yaml.Unmarshal(data, &document); err != nil {
+			return nil, err
+		}
+	case ".json":
+		if err := json.Unmarshal(data, &document); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, ErrUnsupportedRulesFormat
+	}
+
+	// Empty file
+	if document == nil {
+		return []models.Rule{}, nil
+	}
+
+	if rulesList, ok := document.([]interface{}); ok {
+		document = map[string]interface{}{"rules": rulesList}
+	}
+
+	// Go through JSON so YAML files use the same field names as the API
+	jsonData, err := json.Marshal(document)
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(jsonData))
+	decoder.DisallowUnknownFields()
+
+	var parsed rulesDocument
+	if err := decoder.Decode(&parsed); err != nil {
+		return nil, err
+	}
+
+	if parsed.Rules == nil {
+		return []models.Rule{}, nil
+	}
+
+	return parsed.Rules, nil
+}
+
+// MarshalRulesDocument writes rules in the layout read by ParseRulesDocument, as "json" or "yaml". Versions
+// are left out since they are assigned by the store the rules are loaded into.
+func MarshalRulesDocument(rules []models.Rule, format string) ([]byte, error) {
+	jsonData, err := json.Marshal(rulesDocument{Rules: rules})
+	if err != nil {
+		return nil, err
+	}
+
+	var document map[string][]map[string]interface{}
+	if err := json.Unmarshal(jsonData, &document); err != nil {
+		return nil, err
+	}
+
+	for _, rule := range document["rules"] {
+		delete(rule, "version")
+	}
+
+	switch format {
+	case "json":
+		return json.MarshalIndent(document, "", "  ")
+	case "yaml":
+		return yaml.Marshal(document)
+	}
+
+	return nil, ErrUnsupportedRulesFormat
+}
+
+func listRulesFiles(path string) ([]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if !info.IsDir() {
+		return []string{path}, nil
+	}
+
+	files := []string{}
+
+	// WalkDir visits files in lexical order so the checksum is stable
+	err = filepath.WalkDir(path, func(file string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if entry.IsDir() {
+			return nil
+		}
+
+		switch strings.ToLower(filepath.Ext(file)) {
+		case ".yaml", ".yml", ".json":
+			files = append(files, file)
+		}
+
+		return nil
+	})
+
+	return files, err
+}
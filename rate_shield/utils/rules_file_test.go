@@ -0,0 +1,136 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/x-sushant-x/RateShield/models"
+)
+
+func TestParseRulesDocument(t *testing.T) {
+	expected := []models.Rule{
+		{
+			Strategy:        models.StrategyTokenBucket,
+			APIEndpoint:     "/api/v1/get-data",
+			HTTPMethod:      "GET",
+			TokenBucketRule: &models.TokenBucketRule{BucketCapacity: 10, TokenAddRate: 5, RetentionTime: 60},
+		},
+	}
+
+	t.Run("yaml", func(t *testing.T) {
+		data := []byte(`
+rules:
+  - endpoint: /api/v1/get-data
+    http_method: GET
+    strategy: TOKEN BUCKET
+    token_bucket_rule:
+      bucket_capacity: 10
+      token_add_rate: 5
+      retention_time: 60
+`)
+		rules, err := ParseRulesDocument(data, ".yaml")
+		assert.NoError(t, err)
+		assert.Equal(t, expected, rules)
+	})
+
+	t.Run("json list", func(t *testing.T) {
+		data := []byte(`[{"endpoint": "/api/v1/get-data", "http_method": "GET", "strategy": "TOKEN BUCKET",
+			"token_bucket_rule": {"bucket_capacity": 10, "token_add_rate": 5, "retention_time": 60}}]`)
+		rules, err := ParseRulesDocument(data, ".json")
+		assert.NoError(t, err)
+		assert.Equal(t, expected, rules)
+	})
+
+	t.Run("empty file", func(t *testing.T) {
+		rules, err := ParseRulesDocument([]byte(""), ".yml")
+		assert.NoError(t, err)
+		assert.Empty(t, rules)
+	})
+
+	t.Run("unknown field", func(t *testing.T) {
+		_, err := ParseRulesDocument([]byte("rules:\n  - endpoint: /a\n    stratgy: TOKEN BUCKET\n"), ".yaml")
+		assert.Error(t, err)
+	})
+
+	t.Run("unsupported format", func(t *testing.T) {
+		_, err := ParseRulesDocument([]byte(""), ".toml")
+		assert.ErrorIs(t, err, ErrUnsupportedRulesFormat)
+	})
+}
+
+func TestLoadRulesFile(t *testing.T) {
+	writeFile := func(t *testing.T, path, content string) {
+		assert.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	}
+
+	t.Run("directory", func(t *testing.T) {
+		dir := t.TempDir()
+		writeFile(t, filepath.Join(dir, "a.yaml"), "- endpoint: /a\n  strategy: FIXED WINDOW COUNTER\n  fixed_window_counter_rule:\n    max_requests: 10\n    window: 60\n")
+		writeFile(t, filepath.Join(dir, "b.json"), `[{"endpoint": "/b", "strategy": "SLIDING WINDOW COUNTER", "sliding_window_counter_rule": {"max_requests": 5, "window": 10}}]`)
+		writeFile(t, filepath.Join(dir, "README.md"), "not rules")
+
+		rules, checksum, err := LoadRulesFile(dir)
+		assert.NoError(t, err)
+		assert.Len(t, rules, 2)
+		assert.Equal(t, "/a", rules[0].APIEndpoint)
+		assert.Equal(t, "/b", rules[1].APIEndpoint)
+
+		_, sameChecksum, _ := LoadRulesFile(dir)
+		assert.Equal(t, checksum, sameChecksum)
+
+		writeFile(t, filepath.Join(dir, "b.json"), `[]`)
+		_, newChecksum, err := LoadRulesFile(dir)
+		assert.NoError(t, err)
+		assert.NotEqual(t, checksum, newChecksum)
+	})
+
+	t.Run("invalid rule", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "rules.yaml")
+		writeFile(t, path, "- endpoint: /a\n  strategy: FIXED WINDOW COUNTER\n")
+
+		_, _, err := LoadRulesFile(path)
+		assert.Error(t, err)
+	})
+
+	t.Run("duplicate endpoint", func(t *testing.T) {
+		dir := t.TempDir()
+		rule := "- endpoint: /a\n  strategy: FIXED WINDOW COUNTER\n  fixed_window_counter_rule:\n    max_requests: 10\n    window: 60\n"
+		writeFile(t, filepath.Join(dir, "a.yaml"), rule)
+		writeFile(t, filepath.Join(dir, "b.yaml"), rule)
+
+		_, _, err := LoadRulesFile(dir)
+		assert.ErrorContains(t, err, "already defined")
+	})
+}
+
+func TestMarshalRulesDocument(t *testing.T) {
+	rules := []models.Rule{
+		{
+			Strategy:                 models.StrategySlidingWindowCounter,
+			APIEndpoint:              "/api/v1/get-data",
+			HTTPMethod:               "GET",
+			Version:                  4,
+			SlidingWindowCounterRule: &models.SlidingWindowCounterRule{MaxRequests: 1000, WindowSize: 60},
+		},
+	}
+
+	for _, format := range []string{"json", "yaml"} {
+		t.Run(format, func(t *testing.T) {
+			document, err := MarshalRulesDocument(rules, format)
+			assert.NoError(t, err)
+			assert.NotContains(t, string(document), "version")
+
+			parsed, err := ParseRulesDocument(document, "."+format)
+			assert.NoError(t, err)
+
+			expected := rules[0]
+			expected.Version = 0
+			assert.Equal(t, []models.Rule{expected}, parsed)
+		})
+	}
+
+	_, err := MarshalRulesDocument(rules, "toml")
+	assert.ErrorIs(t, err, ErrUnsupportedRulesFormat)
+}
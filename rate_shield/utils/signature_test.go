@@ -0,0 +1,21 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSignPayload(t *testing.T) {
+	body := []byte(`{"event":"SYSTEM_ERROR"}`)
+
+	// echo -n '1700000000.{"event":"SYSTEM_ERROR"}' | openssl dgst -sha256 -hmac secret
+	signature := SignPayload("secret", 1700000000, body)
+	assert.Equal(t, "sha256=d93bd5602da43e3a3c5b1a6fe169e86fdbcae1b403f6bd99f8c97575dbb1b063", signature)
+
+	assert.True(t, VerifySignature("secret", 1700000000, body, signature))
+	assert.False(t, VerifySignature("other", 1700000000, body, signature))
+	assert.False(t, VerifySignature("secret", 1700000001, body, signature))
+	assert.False(t, VerifySignature("secret", 1700000000, []byte(`{}`), signature))
+	assert.False(t, VerifySignature("secret", 1700000000, body, signature[len("sha256="):]))
+}
@@ -9,7 +9,61 @@ export interface rule {
     fixed_window_counter_rule: fixedWindowCounterRule | null;
     sliding_window_counter_rule: slidingWindowCounterRule | null;
     token_bucket_rule: tokenBucketRule | null;
+    quota_rule?: quotaRule;
+    concurrency_rule?: concurrencyRule;
     allow_on_error: boolean;
+    version?: number;
+    managed_by?: string;
+    read_only?: boolean;
+    schedule?: ruleSchedule;
+    expires_at?: number;
+    scheduled_rules?: rule[];
+    allow_list?: string[];
+    deny_list?: string[];
+    overrides?: ruleOverride[];
+    tiers?: ruleTier[];
+    penalty?: penaltyPolicy;
+}
+
+export interface penaltyPolicy {
+    max_denials: number;
+    window: number;
+    ban_duration: number;
+    max_ban_duration?: number;
+}
+
+export interface ruleLimits {
+    fixed_window_counter_rule?: fixedWindowCounterRule;
+    sliding_window_counter_rule?: slidingWindowCounterRule;
+    token_bucket_rule?: tokenBucketRule;
+    quota_rule?: quotaRule;
+    concurrency_rule?: concurrencyRule;
+}
+
+export interface concurrencyRule {
+    max_concurrent: number;
+    lease_timeout: number;
+    scope?: string;
+}
+
+export interface quotaRule {
+    limit: number;
+    period: string;
+    timezone?: string;
+}
+
+export interface ruleOverride extends ruleLimits {
+    identity: string;
+}
+
+export interface ruleTier extends ruleLimits {
+    name: string;
+}
+
+export interface ruleSchedule {
+    cron: string;
+    duration: number;
+    timezone?: string;
 }
 
 export interface paginatedRules {
@@ -120,29 +174,36 @@ export async function searchRulesViaEndpoint(
     }
 }
 
-export async function createNewRule(rule: rule) {
+export async function createNewRule(rule: rule, expectedVersion?: number) {
     const url = `${baseUrl}/rule/add`;
 
+    const headers: Record<string, string> = {
+        "Content-Type" : "application/json"
+    }
+
+    // Rejects the update with 409 if someone else changed the rule since it was loaded
+    if (expectedVersion !== undefined) {
+        headers["If-Match"] = `"${expectedVersion}"`
+    }
+
     try {
         console.log("URL: " + url)
         const response = await axios.post(url, JSON.stringify(rule), {
-            headers: {
-                "Content-Type" : "application/json"
-            }
+            headers: headers,
+            validateStatus: () => true,
         })
 
+        if (response.status === 409) {
+            throw new Error("This rule was changed by someone else. Reload the page and try again.");
+        }
 
-        // const response = await fetch(url, { 
-        //     method: "POST",
-        //     headers: {
-        //         "Content-Type": "application/json",
-        //     },
-        //     body: JSON.stringify(rule),
-        // });
+        if (response.status === 403) {
+            throw new Error("This rule is managed by the rules file. Change it there instead.");
+        }
 
         if (response.status != 200) {
             const errorText = await response.data;
-            throw new Error(errorText);
+            throw new Error(JSON.stringify(errorText));
         }
     } catch (error) {
         console.error("Failed to add rule: ", error);
@@ -5,8 +5,14 @@ import BackArrow from "../assets/BackArrow.png";
 import {
     createNewRule,
     deleteRule,
+    concurrencyRule,
     fixedWindowCounterRule,
+    penaltyPolicy,
+    quotaRule,
     rule,
+    ruleOverride,
+    ruleSchedule,
+    ruleTier,
     slidingWindowCounterRule,
     tokenBucketRule,
 } from "../api/rules";
@@ -23,6 +29,18 @@ interface Props {
     token_bucket_rule: tokenBucketRule | null;
     sliding_window_counter_rule: slidingWindowCounterRule | null;
     allow_on_error: boolean;
+    version?: number;
+    // Not editable in the dashboard yet, kept so saving a rule does not drop them
+    quota_rule?: quotaRule;
+    concurrency_rule?: concurrencyRule;
+    schedule?: ruleSchedule;
+    expires_at?: number;
+    scheduled_rules?: rule[];
+    allow_list?: string[];
+    deny_list?: string[];
+    overrides?: ruleOverride[];
+    tiers?: ruleTier[];
+    penalty?: penaltyPolicy;
 }
 
 const AddOrUpdateRule: React.FC<Props> = ({
@@ -35,6 +53,17 @@ const AddOrUpdateRule: React.FC<Props> = ({
     fixed_window_counter_rule,
     sliding_window_counter_rule,
     allow_on_error,
+    version,
+    quota_rule,
+    concurrency_rule,
+    schedule,
+    expires_at,
+    scheduled_rules,
+    allow_list,
+    deny_list,
+    overrides,
+    tiers,
+    penalty,
 }) => {
     const [apiEndpoint, setApiEndpoint] = useState(endpoint || "");
     const [limitStrategy, setLimitStrategy] = useState(strategy);
@@ -49,10 +78,21 @@ const AddOrUpdateRule: React.FC<Props> = ({
             endpoint: apiEndpoint,
             http_method: method,
             strategy: limitStrategy,
-            fixed_window_counter_rule: fixedWindowCounter,
-            token_bucket_rule: tokenBucket,
-            sliding_window_counter_rule: slidingWindowCounter,
+            // Only send the sub-rule of the selected strategy, the backend rejects rules carrying others
+            fixed_window_counter_rule: limitStrategy === "FIXED WINDOW COUNTER" ? fixedWindowCounter : null,
+            token_bucket_rule: limitStrategy === "TOKEN BUCKET" ? tokenBucket : null,
+            sliding_window_counter_rule: limitStrategy === "SLIDING WINDOW COUNTER" ? slidingWindowCounter : null,
+            quota_rule: limitStrategy === "QUOTA" ? quota_rule : undefined,
+            concurrency_rule: limitStrategy === "CONCURRENCY" ? concurrency_rule : undefined,
             allow_on_error: allowOnError,
+            schedule: schedule,
+            expires_at: expires_at,
+            scheduled_rules: scheduled_rules,
+            allow_list: allow_list,
+            deny_list: deny_list,
+            overrides: overrides,
+            tiers: tiers,
+            penalty: penalty,
         };
         
 
@@ -77,7 +117,7 @@ const AddOrUpdateRule: React.FC<Props> = ({
         }
 
         try {
-            await createNewRule(newRule);
+            await createNewRule(newRule, action === "UPDATE" ? version : undefined);
             closeAddNewRule();
         } catch (error) {
             toast.error("Unable to save rule: " + error, {
@@ -72,17 +72,23 @@ const RulesTable: React.FC<Props> = ({
                                         className="text-center pt-6"
                                         style={{ width: "20%" }}
                                     >
-                                        <center>
-                                            <img
-                                                src={modifyRule}
-                                                className="cursor-pointer"
-                                                onClick={() => {
-                                                    openAddOrUpdateRuleDialog(
-                                                        item,
-                                                    );
-                                                }}
-                                            />
-                                        </center>
+                                        {item.read_only ? (
+                                            <span className="text-gray-500">
+                                                Managed by file
+                                            </span>
+                                        ) : (
+                                            <center>
+                                                <img
+                                                    src={modifyRule}
+                                                    className="cursor-pointer"
+                                                    onClick={() => {
+                                                        openAddOrUpdateRuleDialog(
+                                                            item,
+                                                        );
+                                                    }}
+                                                />
+                                            </center>
+                                        )}
                                     </td>
                                 </tr>
 
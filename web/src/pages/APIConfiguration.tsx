@@ -90,6 +90,17 @@ export default function APIConfiguration() {
                     sliding_window_counter_rule={selectedRule?.sliding_window_counter_rule || null}
                     token_bucket_rule={selectedRule?.token_bucket_rule || null}
                     allow_on_error={selectedRule?.allow_on_error || false}
+                    version={selectedRule?.version}
+                    quota_rule={selectedRule?.quota_rule}
+                    concurrency_rule={selectedRule?.concurrency_rule}
+                    schedule={selectedRule?.schedule}
+                    expires_at={selectedRule?.expires_at}
+                    scheduled_rules={selectedRule?.scheduled_rules}
+                    allow_list={selectedRule?.allow_list}
+                    deny_list={selectedRule?.deny_list}
+                    overrides={selectedRule?.overrides}
+                    tiers={selectedRule?.tiers}
+                    penalty={selectedRule?.penalty}
                 />
             ) : (
                 <RulesTable